@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/kanban-simple/internal/api/handlers"
+	"github.com/kanban-simple/internal/database"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/mcp"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+func main() {
+	// Parse command line flags
+	var (
+		dbPath         = flag.String("db", getEnv("DATABASE_PATH", "./data/kanban.db"), "Database path")
+		migrationsPath = flag.String("migrations", getEnv("MIGRATIONS_PATH", "./migrations"), "Migrations path")
+		maxCards       = flag.Int("max-cards-per-list", getEnvInt("MAX_CARDS_PER_LIST", 0), "Most unarchived cards a list may hold (0 is unlimited)")
+		maxComment     = flag.Int("max-comment-length", getEnvInt("MAX_COMMENT_LENGTH", 0), "Longest comment allowed, in characters (0 is unlimited)")
+		readOnly       = flag.Bool("readonly", getEnvBool("READ_ONLY", false), "Reject all tools that change data")
+	)
+	flag.Parse()
+
+	// stdout carries the protocol, so all logging goes to stderr
+	log.SetOutput(os.Stderr)
+
+	// Initialize database
+	db, err := database.NewConnection(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Run migrations
+	if err := db.RunMigrations(*migrationsPath); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	quotas := models.Quotas{
+		MaxCardsPerList:  *maxCards,
+		MaxCommentLength: *maxComment,
+	}
+	boards := repository.NewBoardRepository(db.DB)
+	lists := repository.NewListRepository(db.DB)
+	cards := repository.NewCardRepository(db.DB)
+	labels := repository.NewLabelRepository(db.DB)
+
+	// Tool changes go through the API's event reactions, so mentions notify,
+	// card references are recorded and capacity alerts fire as they would
+	// for the same change made through the API. Link previews are left to
+	// the API server.
+	bus := events.NewBus()
+	cardHandler := handlers.NewCardHandler(
+		cards, lists, boards, labels,
+		repository.NewSprintRepository(db.DB),
+		repository.NewMilestoneRepository(db.DB),
+		repository.NewSnippetRepository(db.DB),
+		repository.NewUserRepository(db.DB),
+		repository.NewNotificationRepository(db.DB),
+		repository.NewWebhookRepository(db.DB),
+		repository.NewMemberRepository(db.DB),
+		nil, quotas, bus,
+	)
+	cardHandler.Subscribe(bus)
+
+	kanban := &mcp.Kanban{
+		Boards:   boards,
+		Lists:    lists,
+		Cards:    cards,
+		Labels:   labels,
+		Members:  repository.NewMemberRepository(db.DB),
+		Audit:    repository.NewAuditRepository(db.DB),
+		Events:   bus,
+		Quotas:   quotas,
+		ReadOnly: *readOnly,
+	}
+	if *readOnly {
+		log.Printf("Read-only mode enabled: tools that change data will be rejected")
+	}
+
+	server := mcp.NewServer("kanban-simple", "1.0.0", kanban.Tools())
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatalf("MCP server stopped: %v", err)
+	}
+}
+
+// getEnv gets an environment variable with a fallback value
+func getEnv(key, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt gets an integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// getEnvBool gets a boolean environment variable with a fallback value
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kanban-simple/internal/imageproxy"
+	"github.com/kanban-simple/internal/models"
+)
+
+// configFile holds the settings read from CONFIG_FILE. The process
+// environment takes precedence over it; it is reread on reload.
+var configFile = struct {
+	sync.RWMutex
+	values map[string]string
+}{}
+
+// loadConfigFile reads an environment file of KEY=VALUE lines into
+// configFile. Blank lines and lines starting with # are skipped, and values
+// may be wrapped in single or double quotes.
+func loadConfigFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	configFile.Lock()
+	configFile.values = values
+	configFile.Unlock()
+	return nil
+}
+
+// lookupEnv looks a setting up in the environment, then in the config file
+func lookupEnv(key string) (string, bool) {
+	if value, exists := os.LookupEnv(key); exists {
+		return value, true
+	}
+
+	configFile.RLock()
+	defer configFile.RUnlock()
+	value, exists := configFile.values[key]
+	return value, exists
+}
+
+// loadSecret gets a secret from a setting, or from the file named by its
+// _FILE variant so secret mounts stay out of the environment
+func loadSecret(key string) (string, error) {
+	path, fromFile := lookupEnv(key + "_FILE")
+	if !fromFile {
+		return getEnv(key, ""), nil
+	}
+	if _, exists := lookupEnv(key); exists {
+		return "", fmt.Errorf("both %s and %s_FILE are set", key, key)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", key, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// splitList splits a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadTheme reads the web UI's theme from INSTANCE_NAME, THEME_PRIMARY_COLOR,
+// THEME_ACCENT_COLOR and THEME_LOGO_URL; unset ones are left empty for the
+// defaults
+func loadTheme() (models.Theme, error) {
+	theme := models.Theme{
+		InstanceName: getEnv("INSTANCE_NAME", ""),
+		PrimaryColor: getEnv("THEME_PRIMARY_COLOR", ""),
+		AccentColor:  getEnv("THEME_ACCENT_COLOR", ""),
+		LogoURL:      getEnv("THEME_LOGO_URL", ""),
+	}
+	for key, color := range map[string]string{"THEME_PRIMARY_COLOR": theme.PrimaryColor, "THEME_ACCENT_COLOR": theme.AccentColor} {
+		if color != "" && !models.IsValidColor(color) {
+			return theme, fmt.Errorf("%s %q is not a hex color code or palette name", key, color)
+		}
+	}
+	if theme.LogoURL != "" && !models.IsValidLogoURL(theme.LogoURL) {
+		return theme, fmt.Errorf("THEME_LOGO_URL %q is not an http(s) URL or absolute path", theme.LogoURL)
+	}
+	return theme, nil
+}
+
+// loadImageProxy creates the proxy for images in rendered Markdown, or nil
+// when maxSize is zero. Its URLs are signed with IMAGE_PROXY_KEY, or with a
+// random key when that is unset, which is enough for a single server but
+// invalidates rendered image links on restart.
+func loadImageProxy(maxSize, cacheSize int) (*imageproxy.Proxy, error) {
+	if maxSize <= 0 {
+		return nil, nil
+	}
+
+	secret, err := loadSecret("IMAGE_PROXY_KEY")
+	if err != nil {
+		return nil, err
+	}
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate image proxy key: %w", err)
+		}
+	}
+	return imageproxy.New(key, int64(maxSize), int64(cacheSize), 24*time.Hour), nil
+}
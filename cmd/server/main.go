@@ -1,63 +1,419 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kanban-simple/internal/api"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/apispec"
 	"github.com/kanban-simple/internal/database"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/i18n"
+	"github.com/kanban-simple/internal/models"
 	"github.com/kanban-simple/internal/repository"
+	"github.com/kanban-simple/internal/scheduler"
+	"github.com/kanban-simple/internal/webhooks"
 )
 
+// shutdownTimeout is how long requests in flight get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	// Settings in CONFIG_FILE fill in for environment variables that are not
+	// set; the file is reread on reload
+	configPath := os.Getenv("CONFIG_FILE")
+	if configPath != "" {
+		if err := loadConfigFile(configPath); err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+	}
+
 	// Parse command line flags
 	var (
-		dbPath         = flag.String("db", getEnv("DATABASE_PATH", "./data/kanban.db"), "Database path")
+		dbPath         = flag.String("db", getEnv("DATABASE_PATH", "./data/kanban.db"), "Database path; :memory: holds the database in memory until the server stops")
+		snapshotPath   = flag.String("snapshot", getEnv("SNAPSHOT_PATH", ""), "Write a copy of the database to this file on shutdown, e.g. to keep an in-memory database (disabled when empty)")
 		migrationsPath = flag.String("migrations", getEnv("MIGRATIONS_PATH", "./migrations"), "Migrations path")
-		port          = flag.String("port", getEnv("PORT", "8080"), "Server port")
-		mode          = flag.String("mode", getEnv("GIN_MODE", "debug"), "Gin mode (debug/release)")
+		queryTimeout   = flag.Duration("query-timeout", getEnvDuration("QUERY_TIMEOUT", 10*time.Second), "Longest a database statement may run before it is stopped (0 is unlimited)")
+		slowQuery      = flag.Duration("slow-query-threshold", getEnvDuration("SLOW_QUERY_THRESHOLD", 500*time.Millisecond), "Log database statements taking at least this long, with their arguments redacted (0 disables the log)")
+		port           = flag.String("port", getEnv("PORT", "8080"), "Server port")
+		basePath       = flag.String("base-path", getEnv("BASE_PATH", ""), "Path to serve everything under, e.g. /kanban behind a reverse proxy forwarding a subdirectory (empty serves at the root)")
+		mode           = flag.String("mode", getEnv("GIN_MODE", "debug"), "Gin mode (debug/release)")
+		readOnly       = flag.Bool("readonly", getEnvBool("READ_ONLY", false), "Reject all mutating API requests")
+		specPath       = flag.String("spec", getEnv("OPENAPI_PATH", "./openapi.yaml"), "OpenAPI specification path")
+		strictSpec     = flag.Bool("strict-spec", getEnvBool("STRICT_SPEC", false), "Refuse to start when routes and the OpenAPI spec disagree")
+		localesPath    = flag.String("locales", getEnv("LOCALES_PATH", ""), "Directory of extra <locale>.json message catalogs")
+		adminToken     = flag.String("admin-token", "", "Bearer token for the admin endpoints (disabled when empty; defaults to ADMIN_TOKEN or the file named by ADMIN_TOKEN_FILE)")
+		checkDB        = flag.Bool("check-db", getEnvBool("CHECK_DB", false), "Log a database consistency report at startup")
+		retentionDays  = flag.Int("retention-days", getEnvInt("RETENTION_DAYS", 0), "Purge cards archived this many days ago on boards without their own retention (0 keeps them)")
+		changeDays     = flag.Int("changes-retention-days", getEnvInt("CHANGES_RETENTION_DAYS", 30), "Prune change log entries, card revision history and sync operation IDs older than this many days (0 keeps them)")
+		walCheckpoint  = flag.Duration("wal-checkpoint-interval", getEnvDuration("WAL_CHECKPOINT_INTERVAL", time.Hour), "How often the write-ahead log is checkpointed and truncated (0 leaves it to SQLite, which never shrinks it)")
+		schedInterval  = flag.Duration("scheduler-interval", getEnvDuration("SCHEDULER_INTERVAL", time.Minute), "How often background jobs run (0 disables them)")
+		maxBoards      = flag.Int("max-boards", getEnvInt("MAX_BOARDS", 0), "Most boards the instance may hold (0 is unlimited)")
+		maxLists       = flag.Int("max-lists-per-board", getEnvInt("MAX_LISTS_PER_BOARD", 0), "Most lists a board may have (0 is unlimited)")
+		maxCards       = flag.Int("max-cards-per-list", getEnvInt("MAX_CARDS_PER_LIST", 0), "Most unarchived cards a list may hold (0 is unlimited)")
+		maxComment     = flag.Int("max-comment-length", getEnvInt("MAX_COMMENT_LENGTH", 0), "Longest comment allowed, in characters (0 is unlimited)")
+		maxBodySize    = flag.Int("max-body-size", getEnvInt("MAX_BODY_SIZE", 1<<20), "Largest request body in bytes (0 is unlimited)")
+		maxImportSize  = flag.Int("max-import-size", getEnvInt("MAX_IMPORT_SIZE", 32<<20), "Largest board import body in bytes (0 is unlimited)")
+		logEvents      = flag.Bool("log-events", getEnvBool("LOG_EVENTS", false), "Write every change event to the log")
+		webhookEvery   = flag.Duration("webhook-interval", getEnvDuration("WEBHOOK_INTERVAL", 5*time.Second), "How often the webhook outbox is checked for due deliveries (0 disables delivery)")
+		webhookTries   = flag.Int("webhook-max-attempts", getEnvInt("WEBHOOK_MAX_ATTEMPTS", 10), "Attempts at delivering an event to a webhook before giving up on it")
+		trustedHeader  = flag.String("trusted-header", getEnv("TRUSTED_HEADER", ""), "Header naming the user signed in by a reverse proxy, e.g. Remote-User (disabled when empty)")
+		corsOrigins    = flag.String("cors-origins", getEnv("CORS_ORIGINS", "*"), "Comma-separated origins allowed to make cross-origin requests (* allows any)")
+		trustedProxies = flag.String("trusted-proxies", getEnv("TRUSTED_PROXIES", ""), "Comma-separated CIDRs of the proxies allowed to send the trusted header")
+		userHeader     = flag.String("user-header", getEnv("USER_HEADER", "auto"), "When X-Kanban-User names the acting user: auto (until an API token is issued), on or off; never with a trusted header")
+		imageMaxSize   = flag.Int("image-proxy-max-size", getEnvInt("IMAGE_PROXY_MAX_SIZE", 5<<20), "Largest external image shown in rendered Markdown, in bytes (0 disables the image proxy and strips images)")
+		imageCacheSize = flag.Int("image-proxy-cache-size", getEnvInt("IMAGE_PROXY_CACHE_SIZE", 32<<20), "Memory for caching proxied images, in bytes (0 disables the cache)")
+		unfurlLinks    = flag.Bool("unfurl-links", getEnvBool("UNFURL_LINKS", true), "Fetch previews of links in card descriptions and comments")
 	)
 	flag.Parse()
 
+	// Secrets are loaded after parsing so they never show up as flag defaults
+	if *adminToken == "" {
+		token, err := loadSecret("ADMIN_TOKEN")
+		if err != nil {
+			log.Fatalf("Failed to load secrets: %v", err)
+		}
+		*adminToken = token
+	}
+
 	// Set Gin mode
 	gin.SetMode(*mode)
 
+	mountPath, err := middleware.CleanBasePath(*basePath)
+	if err != nil {
+		log.Fatalf("Invalid base path: %v", err)
+	}
+
 	// Initialize database
 	db, err := database.NewConnection(*dbPath)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
+	if *dbPath == database.Memory && *snapshotPath == "" {
+		log.Printf("Using an in-memory database; everything is lost when the server stops")
+	}
 
 	// Run migrations
 	if err := db.RunMigrations(*migrationsPath); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	// Migrations may take long on large databases; only what runs after
+	// them is limited and measured
+	db.SetQueryTimeout(*queryTimeout)
+	db.Instrument(*slowQuery)
+
+	// Add message catalogs on top of the bundled translations
+	if *localesPath != "" {
+		if err := loadLocales(*localesPath); err != nil {
+			log.Fatalf("Failed to load locales: %v", err)
+		}
+		log.Printf("Message locales: %v", i18n.Locales())
+	}
+
 	// Initialize repositories
 	repos := &api.Repositories{
-		Board: repository.NewBoardRepository(db.DB),
-		List:  repository.NewListRepository(db.DB),
-		Card:  repository.NewCardRepository(db.DB),
-		Label: repository.NewLabelRepository(db.DB),
+		Board:        repository.NewBoardRepository(db.DB),
+		List:         repository.NewListRepository(db.DB),
+		Card:         repository.NewCardRepository(db.DB),
+		Label:        repository.NewLabelRepository(db.DB),
+		Sprint:       repository.NewSprintRepository(db.DB),
+		Milestone:    repository.NewMilestoneRepository(db.DB),
+		Transfer:     repository.NewTransferRepository(db.DB),
+		User:         repository.NewUserRepository(db.DB),
+		Notification: repository.NewNotificationRepository(db.DB),
+		Consistency:  repository.NewConsistencyRepository(db.DB),
+		Dashboard:    repository.NewDashboardRepository(db.DB),
+		Retention:    repository.NewRetentionRepository(db.DB),
+		Audit:        repository.NewAuditRepository(db.DB),
+		APIToken:     repository.NewAPITokenRepository(db.DB),
+		Instance:     repository.NewInstanceRepository(db.DB),
+		Webhook:      repository.NewWebhookRepository(db.DB),
+		InboundHook:  repository.NewInboundHookRepository(db.DB),
+		Change:       repository.NewChangeRepository(db.DB),
+		Sync:         repository.NewSyncRepository(db.DB),
+		Member:       repository.NewMemberRepository(db.DB),
+		Group:        repository.NewGroupRepository(db.DB),
+		Snippet:      repository.NewSnippetRepository(db.DB),
+		Digest:       repository.NewDigestRepository(db.DB),
+	}
+
+	// Report integrity problems left by older versions; repairs are done
+	// through the admin API so they can be reviewed first
+	if *checkDB {
+		report, err := repos.Consistency.Check(false)
+		if err != nil {
+			log.Fatalf("Failed to check database consistency: %v", err)
+		}
+		for _, check := range report.Checks {
+			if check.Count > 0 {
+				log.Printf("Consistency check %s: %d (%s)", check.Name, check.Count, check.Description)
+			}
+		}
+		log.Printf("Database consistency check found %d problems", report.Problems)
+	}
+
+	// Load the OpenAPI spec; the server still runs without it
+	spec, err := apispec.Load(*specPath)
+	if err != nil {
+		if *strictSpec {
+			log.Fatalf("OpenAPI spec unavailable: %v", err)
+		}
+		log.Printf("OpenAPI spec unavailable: %v", err)
+	}
+
+	// Reverse-proxy authentication replaces the X-Kanban-User header
+	var proxyAuth *middleware.TrustedHeader
+	if *trustedHeader != "" {
+		proxyAuth, err = middleware.NewTrustedHeader(*trustedHeader, *trustedProxies)
+		if err != nil {
+			log.Fatalf("Invalid trusted header configuration: %v", err)
+		}
+		log.Printf("Trusting the %s header from proxies %s", *trustedHeader, *trustedProxies)
+	}
+	userHeaderPolicy, err := middleware.ParseUserHeaderPolicy(*userHeader)
+	if err != nil {
+		log.Fatalf("Invalid user header configuration: %v", err)
+	}
+
+	// External images in rendered Markdown are fetched through the server
+	imageProxy, err := loadImageProxy(*imageMaxSize, *imageCacheSize)
+	if err != nil {
+		log.Fatalf("Failed to set up image proxy: %v", err)
 	}
+	if imageProxy == nil {
+		log.Printf("Image proxy disabled: images in Markdown will be stripped")
+	}
+
+	// The admin token, CORS origins, theme and extra locales can be reloaded
+	// with SIGHUP or POST /api/admin/reload. Flags given on the command line
+	// win over the environment and config file, so reloads leave them alone.
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	theme, err := loadTheme()
+	if err != nil {
+		log.Fatalf("Invalid theme: %v", err)
+	}
+	runtime := middleware.NewRuntime(middleware.RuntimeSettings{
+		AdminToken:  *adminToken,
+		CORSOrigins: splitList(*corsOrigins),
+		Theme:       theme,
+	})
+	reload := func() error {
+		if configPath != "" {
+			if err := loadConfigFile(configPath); err != nil {
+				return err
+			}
+		}
 
-	// Initialize router
-	router := api.NewRouter(repos)
+		settings := runtime.Get()
+		if !explicit["admin-token"] {
+			token, err := loadSecret("ADMIN_TOKEN")
+			if err != nil {
+				return err
+			}
+			settings.AdminToken = token
+		}
+		if !explicit["cors-origins"] {
+			settings.CORSOrigins = splitList(getEnv("CORS_ORIGINS", "*"))
+		}
+		theme, err := loadTheme()
+		if err != nil {
+			return err
+		}
+		settings.Theme = theme
+		if *localesPath != "" {
+			if err := loadLocales(*localesPath); err != nil {
+				return err
+			}
+		}
+
+		runtime.Set(settings)
+		log.Printf("Reloaded configuration")
+		return nil
+	}
+
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			if err := reload(); err != nil {
+				log.Printf("Failed to reload configuration: %v", err)
+			}
+		}
+	}()
+
+	// Changes made through the API and by background jobs are published here
+	bus := events.NewBus()
+	if *logEvents {
+		bus.Subscribe(events.Log)
+	}
+
+	// Initialize router; read-only mode can be switched later through the
+	// admin API
+	readOnlyMode := middleware.NewReadOnlyMode(*readOnly)
+	router := api.NewRouter(repos, api.Config{
+		ReadOnly:      readOnlyMode,
+		Spec:          spec,
+		Runtime:       runtime,
+		Reload:        reload,
+		DBMetrics:     db.Metrics,
+		RetentionDays: *retentionDays,
+		Quotas: models.Quotas{
+			MaxBoards:        *maxBoards,
+			MaxListsPerBoard: *maxLists,
+			MaxCardsPerList:  *maxCards,
+			MaxCommentLength: *maxComment,
+		},
+		MaxBodySize:   int64(*maxBodySize),
+		MaxImportSize: int64(*maxImportSize),
+		TrustedHeader: proxyAuth,
+		UserHeader:    userHeaderPolicy,
+		Events:        bus,
+		BasePath:      mountPath,
+		ImageProxy:    imageProxy,
+		UnfurlLinks:   *unfurlLinks,
+	})
+	if *readOnly {
+		log.Printf("Read-only mode enabled: mutating requests will be rejected")
+	}
+
+	// Check the spec still describes the routes actually registered
+	if spec != nil {
+		undocumented := spec.Undocumented(router.Routes())
+		unrouted := spec.Unrouted(router.Routes())
+		for _, route := range undocumented {
+			log.Printf("Route missing from OpenAPI spec: %s", route)
+		}
+		for _, op := range unrouted {
+			log.Printf("OpenAPI operation has no route: %s", op)
+		}
+		if *strictSpec && len(undocumented)+len(unrouted) > 0 {
+			log.Fatalf("OpenAPI spec is out of date: %d undocumented routes, %d unrouted operations", len(undocumented), len(unrouted))
+		}
+	}
+
+	// Background jobs change data, so they pause while the instance is read-only
+	if *schedInterval > 0 {
+		sched := scheduler.New(*schedInterval)
+		sched.PauseWhile(readOnlyMode.Enabled)
+		sched.Add("auto-move", scheduler.AutoMove(repos.Card, bus))
+		sched.Add("overdue", scheduler.EscalateOverdue(repos.Card, repos.Notification, repos.User, bus))
+		sched.Add("sla", scheduler.CheckSLAs(repos.Card, repos.List, repos.Notification, repos.User, repos.Webhook))
+		sched.Add("purge", scheduler.Purge(repos.Retention, *retentionDays))
+		sched.Add("digests", scheduler.Digests(repos.Digest, repos.Webhook, repos.Notification, repos.User))
+		if *changeDays > 0 {
+			sched.Add("prune-changes", scheduler.PruneChanges(repos.Change, *changeDays))
+			sched.Add("prune-sync", scheduler.PruneSync(repos.Sync, *changeDays))
+		}
+		if *walCheckpoint > 0 {
+			sched.Add("checkpoint-wal", scheduler.Checkpoint(repos.Instance, *walCheckpoint))
+		}
+		sched.Start()
+		defer sched.Stop()
+	}
+
+	// Webhooks are delivered from the outbox the database fills as changes
+	// commit; published events wake the dispatcher so it need not wait for
+	// its next tick
+	if *webhookEvery > 0 {
+		dispatcher := webhooks.NewDispatcher(repos.Webhook, repos.Board, repos.List, repos.Card, *webhookEvery, max(*webhookTries, 1))
+		bus.Subscribe(func(events.Event) { dispatcher.Notify() })
+		dispatcher.Start()
+		defer dispatcher.Stop()
+	}
 
 	// Start server
-	log.Printf("Starting server on port %s", *port)
-	if err := router.Run(":" + *port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if mountPath != "" {
+		log.Printf("Serving under %s/", mountPath)
+	}
+	server := &http.Server{Addr: ":" + *port, Handler: middleware.Mount(mountPath, router)}
+	go func() {
+		log.Printf("Starting server on port %s", *port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// On SIGINT or SIGTERM, let requests in flight finish and snapshot the
+	// database; background jobs stop as main returns
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+	log.Printf("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Requests still running at shutdown were cut off: %v", err)
+	}
+
+	if *snapshotPath != "" {
+		if err := db.Snapshot(*snapshotPath); err != nil {
+			log.Printf("Failed to snapshot the database: %v", err)
+		} else {
+			log.Printf("Saved a snapshot of the database to %s", *snapshotPath)
+		}
 	}
 }
 
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := lookupEnv(key); exists {
 		return value
 	}
 	return fallback
-}
\ No newline at end of file
+}
+
+// loadLocales replaces the extra message catalogs with the ones in dir,
+// layered over the bundled translations
+func loadLocales(dir string) error {
+	extra, err := i18n.LoadDir(dir)
+	if err != nil {
+		return err
+	}
+	catalog := i18n.Bundled()
+	catalog.Merge(extra)
+	i18n.SetCatalog(catalog)
+	return nil
+}
+
+// getEnvInt gets an integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) int {
+	if value, exists := lookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// getEnvDuration gets a duration environment variable with a fallback value
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := lookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// getEnvBool gets a boolean environment variable with a fallback value
+func getEnvBool(key string, fallback bool) bool {
+	if value, exists := lookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/database"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// newTestRouter serves the API from a fresh database
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.NewConnection(filepath.Join(t.TempDir(), "kanban.db"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.RunMigrations("../../migrations"); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	repos := &Repositories{
+		Board:        repository.NewBoardRepository(db.DB),
+		List:         repository.NewListRepository(db.DB),
+		Card:         repository.NewCardRepository(db.DB),
+		Label:        repository.NewLabelRepository(db.DB),
+		Sprint:       repository.NewSprintRepository(db.DB),
+		Milestone:    repository.NewMilestoneRepository(db.DB),
+		Transfer:     repository.NewTransferRepository(db.DB),
+		User:         repository.NewUserRepository(db.DB),
+		Notification: repository.NewNotificationRepository(db.DB),
+		Consistency:  repository.NewConsistencyRepository(db.DB),
+		Dashboard:    repository.NewDashboardRepository(db.DB),
+		Retention:    repository.NewRetentionRepository(db.DB),
+		Audit:        repository.NewAuditRepository(db.DB),
+		APIToken:     repository.NewAPITokenRepository(db.DB),
+		Instance:     repository.NewInstanceRepository(db.DB),
+		Webhook:      repository.NewWebhookRepository(db.DB),
+		InboundHook:  repository.NewInboundHookRepository(db.DB),
+		Change:       repository.NewChangeRepository(db.DB),
+		Sync:         repository.NewSyncRepository(db.DB),
+		Member:       repository.NewMemberRepository(db.DB),
+		Group:        repository.NewGroupRepository(db.DB),
+		Snippet:      repository.NewSnippetRepository(db.DB),
+		Digest:       repository.NewDigestRepository(db.DB),
+	}
+	return NewRouter(repos, Config{
+		ReadOnly:   middleware.NewReadOnlyMode(false),
+		Runtime:    middleware.NewRuntime(middleware.RuntimeSettings{CORSOrigins: []string{"*"}}),
+		UserHeader: middleware.UserHeaderOn,
+	})
+}
+
+// call sends a JSON request as user, or anonymously when user is empty
+func call(router *gin.Engine, user, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.Header.Set(middleware.UserHeader, user)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// create sends a request that must succeed and returns the ID it created
+func create(t *testing.T, router *gin.Engine, user, path string, body interface{}) int {
+	t.Helper()
+	w := call(router, user, http.MethodPost, path, body)
+	if w.Code != http.StatusCreated && w.Code != http.StatusOK {
+		t.Fatalf("POST %s: %d %s", path, w.Code, w.Body.String())
+	}
+	var created struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	return created.ID
+}
+
+func TestBoardRoles(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, username := range []string{"olivia", "eddie", "vera", "nora"} {
+		create(t, router, "", "/api/users", map[string]string{"username": username})
+	}
+	board := create(t, router, "", "/api/boards", map[string]string{"name": "Shared"})
+	list := create(t, router, "", fmt.Sprintf("/api/boards/%d/lists", board), map[string]string{"name": "Todo"})
+	card := create(t, router, "", fmt.Sprintf("/api/lists/%d/cards", list), map[string]string{"title": "First"})
+	comment := create(t, router, "", fmt.Sprintf("/api/cards/%d/comments", card), map[string]string{"content": "Hello"})
+	label := create(t, router, "", "/api/labels", map[string]string{"name": "bug", "color": "#ff0000"})
+
+	// Until someone owns the board, anyone can change it
+	if w := call(router, "", http.MethodPut, fmt.Sprintf("/api/cards/%d", card), map[string]string{"title": "Open"}); w.Code != http.StatusOK {
+		t.Fatalf("update card on open board: %d %s", w.Code, w.Body.String())
+	}
+
+	// Vera is a viewer through a group grant, the others direct members
+	group := create(t, router, "", "/api/groups", map[string]string{"name": "Readers"})
+	create(t, router, "", fmt.Sprintf("/api/groups/%d/members", group), map[string]string{"username": "vera"})
+	create(t, router, "", fmt.Sprintf("/api/boards/%d/groups", board), map[string]interface{}{"group_id": group, "role": "viewer"})
+	create(t, router, "", fmt.Sprintf("/api/boards/%d/members", board), map[string]string{"username": "eddie", "role": "editor"})
+	create(t, router, "", fmt.Sprintf("/api/boards/%d/members", board), map[string]string{"username": "olivia", "role": "owner"})
+
+	type request struct {
+		method, path string
+		body         interface{}
+	}
+	reads := []request{
+		{http.MethodGet, fmt.Sprintf("/api/boards/%d", board), nil},
+		{http.MethodGet, fmt.Sprintf("/api/boards/%d/lists", board), nil},
+		{http.MethodGet, fmt.Sprintf("/api/lists/%d", list), nil},
+		{http.MethodGet, fmt.Sprintf("/api/lists/%d/cards", list), nil},
+		{http.MethodGet, fmt.Sprintf("/api/cards/%d", card), nil},
+		{http.MethodGet, fmt.Sprintf("/api/cards/%d/comments", card), nil},
+		{http.MethodGet, fmt.Sprintf("/api/cards/%d/labels", card), nil},
+	}
+	writes := []request{
+		{http.MethodPut, fmt.Sprintf("/api/boards/%d", board), map[string]string{"name": "Renamed"}},
+		{http.MethodPut, fmt.Sprintf("/api/lists/%d", list), map[string]string{"name": "Doing"}},
+		{http.MethodPost, fmt.Sprintf("/api/lists/%d/cards", list), map[string]string{"title": "Second"}},
+		{http.MethodPut, fmt.Sprintf("/api/cards/%d", card), map[string]string{"title": "Changed"}},
+		{http.MethodPost, fmt.Sprintf("/api/cards/%d/comments", card), map[string]string{"content": "Reply"}},
+		{http.MethodPut, fmt.Sprintf("/api/comments/%d", comment), map[string]string{"content": "Edited"}},
+		{http.MethodPost, fmt.Sprintf("/api/cards/%d/labels/%d", card, label), nil},
+		{http.MethodPost, fmt.Sprintf("/api/labels/%d/cards", label), map[string][]int{"card_ids": {card}}},
+		{http.MethodPatch, fmt.Sprintf("/api/cards/%d/move", card), map[string]interface{}{"list_id": list, "position": 1}},
+	}
+	manages := []request{
+		{http.MethodGet, fmt.Sprintf("/api/boards/%d/invitations", board), nil},
+	}
+
+	tests := []struct {
+		user                string
+		read, write, manage bool
+	}{
+		{"olivia", true, true, true},
+		{"eddie", true, true, false},
+		{"vera", true, false, false},
+		{"nora", false, false, false},
+		{"", false, false, false},
+	}
+	for _, tt := range tests {
+		check := func(requests []request, allowed bool) {
+			for _, r := range requests {
+				w := call(router, tt.user, r.method, r.path, r.body)
+				if allowed && w.Code >= 400 {
+					t.Errorf("%q %s %s: %d %s, want success", tt.user, r.method, r.path, w.Code, w.Body.String())
+				}
+				if !allowed && w.Code != http.StatusForbidden {
+					t.Errorf("%q %s %s: %d, want 403", tt.user, r.method, r.path, w.Code)
+				}
+			}
+		}
+		check(reads, tt.read)
+		check(writes, tt.write)
+		check(manages, tt.manage)
+	}
+
+	// Adding a member is left for last, as it changes the board's access
+	members := fmt.Sprintf("/api/boards/%d/members", board)
+	nora := map[string]string{"username": "nora", "role": "viewer"}
+	if w := call(router, "eddie", http.MethodPost, members, nora); w.Code != http.StatusForbidden {
+		t.Errorf("editor adds member: %d, want 403", w.Code)
+	}
+	if w := call(router, "olivia", http.MethodPost, members, nora); w.Code != http.StatusCreated {
+		t.Errorf("owner adds member: %d %s, want 201", w.Code, w.Body.String())
+	}
+	if w := call(router, "nora", http.MethodGet, fmt.Sprintf("/api/cards/%d", card), nil); w.Code != http.StatusOK {
+		t.Errorf("new viewer reads card: %d, want 200", w.Code)
+	}
+}
+
+func TestRestrictedBoardsLeftOutOfCrossBoardReads(t *testing.T) {
+	router := newTestRouter(t)
+
+	olivia := create(t, router, "", "/api/users", map[string]string{"username": "olivia"})
+	create(t, router, "", "/api/users", map[string]string{"username": "nora"})
+	board := create(t, router, "", "/api/boards", map[string]string{"name": "Secret board"})
+	list := create(t, router, "", fmt.Sprintf("/api/boards/%d/lists", board), map[string]string{"name": "Todo"})
+	create(t, router, "", fmt.Sprintf("/api/boards/%d/members", board), map[string]string{"username": "olivia", "role": "owner"})
+	card := create(t, router, "olivia", fmt.Sprintf("/api/lists/%d/cards", list), map[string]string{"title": "Secret plan"})
+	create(t, router, "olivia", fmt.Sprintf("/api/cards/%d/comments", card), map[string]string{"content": "Secret reply"})
+
+	// Routes on the board itself refuse non-members
+	for _, path := range []string{
+		fmt.Sprintf("/api/v1/lists/%d/cards", list),
+		fmt.Sprintf("/api/v1/cards/%d/comments", card),
+	} {
+		if w := call(router, "nora", http.MethodGet, path, nil); w.Code != http.StatusForbidden {
+			t.Errorf("nora GET %s: %d, want 403", path, w.Code)
+		}
+	}
+
+	// Routes spanning boards leave it out
+	onBoard := fmt.Sprintf(`"board_id":%d`, board)
+	tests := []struct {
+		user, path, secret string
+	}{
+		{"nora", "/api/search?query=Secret", "Secret plan"},
+		{"nora", "/api/v1/search?query=Secret", "Secret plan"},
+		{"nora", fmt.Sprintf("/api/cards?board_id=%d", board), "Secret plan"},
+		{"", fmt.Sprintf("/api/cards?board_id=%d", board), "Secret plan"},
+		{"nora", "/api/dashboard", "Secret"},
+		{"nora", fmt.Sprintf("/api/users/%d/activity", olivia), "Secret plan"},
+		{"nora", "/api/boards", "Secret board"},
+		{"nora", "/api/v1/boards", "Secret board"},
+		{"", "/api/boards", "Secret board"},
+		{"nora", "/api/changes?since=0", onBoard},
+		{"nora", "/api/v1/changes?since=0", onBoard},
+	}
+	for _, tt := range tests {
+		w := call(router, tt.user, http.MethodGet, tt.path, nil)
+		if w.Code != http.StatusOK {
+			t.Errorf("%q GET %s: %d %s", tt.user, tt.path, w.Code, w.Body.String())
+			continue
+		}
+		if strings.Contains(w.Body.String(), tt.secret) {
+			t.Errorf("%q GET %s shows %s", tt.user, tt.path, tt.secret)
+		}
+
+		// The owner still sees the board everywhere
+		if w := call(router, "olivia", http.MethodGet, tt.path, nil); !strings.Contains(w.Body.String(), tt.secret) {
+			t.Errorf("olivia GET %s: %d, missing %s", tt.path, w.Code, tt.secret)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kanban-simple/internal/api/middleware"
+)
+
+func TestCardETagFollowsVisibilityAndBlocking(t *testing.T) {
+	router := newTestRouter(t)
+
+	create(t, router, "", "/api/users", map[string]string{"username": "alice"})
+	board := create(t, router, "", "/api/boards", map[string]string{"name": "Work"})
+	list := create(t, router, "", fmt.Sprintf("/api/boards/%d/lists", board), map[string]string{"name": "Todo"})
+	card := create(t, router, "alice", fmt.Sprintf("/api/lists/%d/cards", list), map[string]string{"title": "Plan"})
+	path := fmt.Sprintf("/api/cards/%d", card)
+
+	etag := func() string {
+		w := call(router, "alice", http.MethodGet, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", path, w.Code, w.Body.String())
+		}
+		return w.Header().Get("ETag")
+	}
+
+	changes := []struct {
+		name, method, path string
+		body               interface{}
+	}{
+		{"visibility", http.MethodPut, path, map[string]string{"visibility": "restricted"}},
+		{"block", http.MethodPost, path + "/block", map[string]string{"reason": "Waiting on legal"}},
+		{"unblock", http.MethodPost, path + "/unblock", nil},
+	}
+	for _, change := range changes {
+		before := etag()
+		if w := call(router, "alice", change.method, change.path, change.body); w.Code >= 400 {
+			t.Fatalf("%s: %d %s", change.name, w.Code, w.Body.String())
+		}
+		if after := etag(); after == before {
+			t.Errorf("%s left the ETag at %s", change.name, after)
+		}
+	}
+}
+
+func TestCardUpdateConflictsAfterVisibilityChange(t *testing.T) {
+	router := newTestRouter(t)
+
+	create(t, router, "", "/api/users", map[string]string{"username": "alice"})
+	board := create(t, router, "", "/api/boards", map[string]string{"name": "Work"})
+	list := create(t, router, "", fmt.Sprintf("/api/boards/%d/lists", board), map[string]string{"name": "Todo"})
+	card := create(t, router, "alice", fmt.Sprintf("/api/lists/%d/cards", list), map[string]string{"title": "Plan"})
+	path := fmt.Sprintf("/api/cards/%d", card)
+
+	// Both writers start from the same copy
+	etag := call(router, "alice", http.MethodGet, path, nil).Header().Get("ETag")
+
+	put := func(body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.UserHeader, "alice")
+		req.Header.Set("If-Match", etag)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := put(map[string]string{"visibility": "restricted"}); w.Code != http.StatusOK {
+		t.Fatalf("first writer changes visibility: %d %s", w.Code, w.Body.String())
+	}
+	if w := put(map[string]string{"title": "Plan v2", "visibility": "board"}); w.Code != http.StatusConflict {
+		t.Errorf("second writer with the old ETag: %d %s, want 409", w.Code, w.Body.String())
+	}
+	var current struct {
+		Visibility string `json:"visibility"`
+	}
+	json.Unmarshal(call(router, "alice", http.MethodGet, path, nil).Body.Bytes(), &current)
+	if current.Visibility != "restricted" {
+		t.Errorf("visibility is %q after the conflict, want restricted", current.Visibility)
+	}
+}
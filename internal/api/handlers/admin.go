@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// AdminHandler handles maintenance endpoints
+type AdminHandler struct {
+	consistencyRepo *repository.ConsistencyRepository
+	retentionRepo   *repository.RetentionRepository
+	auditRepo       *repository.AuditRepository
+	userRepo        *repository.UserRepository
+	tokenRepo       *repository.APITokenRepository
+	instanceRepo    *repository.InstanceRepository
+	readOnly        *middleware.ReadOnlyMode
+	runtime         *middleware.Runtime
+	reload          func() error
+	dbMetrics       func() models.DBMetrics
+	retentionDays   int
+}
+
+// NewAdminHandler creates a new admin handler. retentionDays is the default
+// retention for boards without their own; reload refreshes runtime, and
+// dbMetrics reports database use.
+func NewAdminHandler(consistencyRepo *repository.ConsistencyRepository, retentionRepo *repository.RetentionRepository, auditRepo *repository.AuditRepository, userRepo *repository.UserRepository, tokenRepo *repository.APITokenRepository, instanceRepo *repository.InstanceRepository, readOnly *middleware.ReadOnlyMode, runtime *middleware.Runtime, reload func() error, dbMetrics func() models.DBMetrics, retentionDays int) *AdminHandler {
+	return &AdminHandler{
+		consistencyRepo: consistencyRepo,
+		retentionRepo:   retentionRepo,
+		auditRepo:       auditRepo,
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		instanceRepo:    instanceRepo,
+		readOnly:        readOnly,
+		runtime:         runtime,
+		reload:          reload,
+		dbMetrics:       dbMetrics,
+		retentionDays:   retentionDays,
+	}
+}
+
+// Consistency reports orphaned rows, dangling references and duplicate
+// positions without changing anything
+func (h *AdminHandler) Consistency(c *gin.Context) {
+	report, err := h.consistencyRepo.Check(false)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to check consistency")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RepairConsistency runs the consistency checks and fixes what they find
+func (h *AdminHandler) RepairConsistency(c *gin.Context) {
+	report, err := h.consistencyRepo.Check(true)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to repair consistency")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Purge deletes archived cards past their board's retention period now
+// instead of waiting for the scheduler; with ?dry_run=true it only reports
+func (h *AdminHandler) Purge(c *gin.Context) {
+	report, err := h.retentionRepo.Purge(models.PurgeOptions{
+		Now:         time.Now(),
+		DefaultDays: h.retentionDays,
+		DryRun:      c.Query("dry_run") == "true",
+	})
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to purge archived cards")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
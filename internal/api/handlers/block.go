@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/models"
+)
+
+// Block flags a card blocked with a reason and the cards blocking it
+func (h *CardHandler) Block(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	var req models.BlockCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if h.cardIDHidden(c, id) {
+		return
+	}
+
+	// Blockers are checked like the card itself: cards the caller cannot see
+	// are reported missing. A completed blocker could never unblock the card.
+	for _, blockerID := range req.BlockedBy {
+		if blockerID == id {
+			middleware.HandleError(c, http.StatusBadRequest, "A card cannot block itself")
+			return
+		}
+		blocker, err := h.cardRepo.GetByID(blockerID)
+		if err != nil && err.Error() != "card not found" {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+			return
+		}
+		if err != nil || !blocker.VisibleTo(actingUserID(c)) {
+			middleware.HandleError(c, http.StatusBadRequest, "Blocking card not found")
+			return
+		}
+		if blocker.Completed {
+			middleware.HandleError(c, http.StatusBadRequest, "Blocking card is already completed")
+			return
+		}
+	}
+
+	if err := h.cardRepo.Block(id, req.Reason, req.BlockedBy); err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to block card")
+		}
+		return
+	}
+
+	h.respondBlocked(c, id)
+}
+
+// Unblock clears a card's blocked flag, reason and blockers
+func (h *CardHandler) Unblock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	if h.cardIDHidden(c, id) {
+		return
+	}
+
+	if err := h.cardRepo.Unblock(id); err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to unblock card")
+		}
+		return
+	}
+
+	h.respondBlocked(c, id)
+}
+
+// respondBlocked publishes the change to a card's blocked flag and writes
+// the card with its blockers
+func (h *CardHandler) respondBlocked(c *gin.Context, id int) {
+	card, err := h.cardRepo.GetByID(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		return
+	}
+	if card.BlockedBy, err = h.cardRepo.GetOpenBlockers(id); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card blockers")
+		return
+	}
+
+	h.publish(c, events.Event{Type: events.CardUpdated, Card: card})
+
+	c.JSON(http.StatusOK, card)
+}
+
+// publishUnblocked publishes card.updated for cards a completion unblocked
+func (h *CardHandler) publishUnblocked(c *gin.Context, ids []int) {
+	for _, id := range ids {
+		h.publishCard(c, events.CardUpdated, id)
+	}
+}
@@ -3,36 +3,67 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
 	"github.com/kanban-simple/internal/models"
 	"github.com/kanban-simple/internal/repository"
 )
 
 // BoardHandler handles board-related HTTP requests
 type BoardHandler struct {
-	repo *repository.BoardRepository
+	repo      *repository.BoardRepository
+	listRepo  *repository.ListRepository
+	labelRepo *repository.LabelRepository
+	quotas    models.Quotas
+	bus       *events.Bus
 }
 
 // NewBoardHandler creates a new board handler
-func NewBoardHandler(repo *repository.BoardRepository) *BoardHandler {
-	return &BoardHandler{repo: repo}
+func NewBoardHandler(repo *repository.BoardRepository, listRepo *repository.ListRepository, labelRepo *repository.LabelRepository, quotas models.Quotas, bus *events.Bus) *BoardHandler {
+	return &BoardHandler{
+		repo:      repo,
+		listRepo:  listRepo,
+		labelRepo: labelRepo,
+		quotas:    quotas,
+		bus:       bus,
+	}
 }
 
 // GetAll retrieves all boards
 func (h *BoardHandler) GetAll(c *gin.Context) {
-	boards, err := h.repo.GetAll()
+	fields, ok := fieldsParam(c, models.Board{})
+	if !ok {
+		return
+	}
+
+	updatedAfter, ok := updatedAfterParam(c)
+	if !ok {
+		return
+	}
+
+	boards, err := h.repo.GetAll(updatedAfter, actingUserID(c))
 	if err != nil {
 		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve boards")
 		return
 	}
 
-	c.JSON(http.StatusOK, boards)
+	if wantsHTML(c) {
+		renderBoards(boards)
+	}
+
+	writeCollection(c, boards, fields)
 }
 
 // GetByID retrieves a board by ID
 func (h *BoardHandler) GetByID(c *gin.Context) {
+	fields, ok := fieldsParam(c, models.Board{})
+	if !ok {
+		return
+	}
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
@@ -49,27 +80,66 @@ func (h *BoardHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, board)
+	if wantsHTML(c) {
+		renderBoard(board)
+	}
+
+	writeFields(c, http.StatusOK, board, fields)
 }
 
 // Create creates a new board
 func (h *BoardHandler) Create(c *gin.Context) {
 	var req models.CreateBoardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
 
 	board := &models.Board{
 		Name:        req.Name,
 		Description: req.Description,
+		Icon:        req.Icon,
+		KeyPrefix:   req.KeyPrefix,
+	}
+
+	lists := append([]models.List{}, models.DefaultBoardLists...)
+	if req.Lists != nil {
+		lists = []models.List{}
+		seen := make(map[string]bool)
+		for _, name := range req.Lists {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				middleware.HandleError(c, http.StatusBadRequest, "List names must not be blank")
+				return
+			}
+			if seen[strings.ToLower(name)] {
+				middleware.HandleErrorf(c, http.StatusBadRequest, "Duplicate list name: %s", name)
+				return
+			}
+			seen[strings.ToLower(name)] = true
+			lists = append(lists, models.List{Name: name})
+		}
 	}
 
-	if err := h.repo.Create(board); err != nil {
-		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create board")
+	if !withinBoardQuota(c, h.quotas, h.repo) || !withinQuota(c, h.quotas.MaxListsPerBoard, 0, len(lists), models.ListQuotaMessage) {
 		return
 	}
 
+	if err := h.repo.CreateWithLists(board, lists); err != nil {
+		if err.Error() == "key prefix taken" {
+			middleware.HandleError(c, http.StatusConflict, "Key prefix is already used by another board")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to create board")
+		}
+		return
+	}
+
+	publish(c, h.bus, events.Event{Type: events.BoardCreated, BoardID: board.ID, Board: board})
+
+	if wantsHTML(c) {
+		renderBoard(board)
+	}
+
 	c.JSON(http.StatusCreated, board)
 }
 
@@ -95,7 +165,7 @@ func (h *BoardHandler) Update(c *gin.Context) {
 	// Bind update request
 	var req models.UpdateBoardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -106,13 +176,29 @@ func (h *BoardHandler) Update(c *gin.Context) {
 	if req.Description != "" {
 		board.Description = req.Description
 	}
+	if req.Icon != nil {
+		board.Icon = *req.Icon
+	}
+	if req.KeyPrefix != nil {
+		board.KeyPrefix = *req.KeyPrefix
+	}
 
 	// Save updates
 	if err := h.repo.Update(board); err != nil {
-		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update board")
+		if err.Error() == "key prefix taken" {
+			middleware.HandleError(c, http.StatusConflict, "Key prefix is already used by another board")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update board")
+		}
 		return
 	}
 
+	publish(c, h.bus, events.Event{Type: events.BoardUpdated, BoardID: board.ID, Board: board})
+
+	if wantsHTML(c) {
+		renderBoard(board)
+	}
+
 	c.JSON(http.StatusOK, board)
 }
 
@@ -124,6 +210,22 @@ func (h *BoardHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	preview, err := h.repo.DeletePreview(id)
+	if err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to preview delete")
+		}
+		return
+	}
+	if !deleteConfirmed(c, "board", id, preview) {
+		return
+	}
+
+	// Loaded first so the event can describe what was deleted
+	board, _ := h.repo.GetByID(id)
+
 	if err := h.repo.Delete(id); err != nil {
 		if err.Error() == "board not found" {
 			middleware.HandleError(c, http.StatusNotFound, "Board not found")
@@ -133,5 +235,156 @@ func (h *BoardHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Board deleted successfully"})
-}
\ No newline at end of file
+	if board != nil {
+		publish(c, h.bus, events.Event{Type: events.BoardDeleted, BoardID: id, Board: board})
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Board deleted successfully"))
+}
+
+// Star stars or unstars a board
+func (h *BoardHandler) Star(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	var req models.StarBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if err := h.repo.SetStarred(id, *req.Starred); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to star board")
+		}
+		return
+	}
+
+	board, err := h.repo.GetByID(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// Reorder sets the display order of boards
+func (h *BoardHandler) Reorder(c *gin.Context) {
+	var req models.ReorderBoardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if err := h.repo.Reorder(req.BoardIDs); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to reorder boards")
+		}
+		return
+	}
+
+	boards, err := h.repo.GetAll(nil, actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve boards")
+		return
+	}
+
+	c.JSON(http.StatusOK, boards)
+}
+
+// GetSettings retrieves the settings of a board
+func (h *BoardHandler) GetSettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	board, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, board.Settings)
+}
+
+// UpdateSettings replaces the settings of a board
+func (h *BoardHandler) UpdateSettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	// Verify board exists
+	if _, err := h.repo.GetByID(id); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	var settings models.BoardSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	// The quick-create list must belong to this board
+	if settings.QuickCreateListID != nil {
+		list, err := h.listRepo.GetByID(*settings.QuickCreateListID)
+		if err != nil || list.BoardID != id {
+			middleware.HandleError(c, http.StatusBadRequest, "Quick create list must belong to the board")
+			return
+		}
+	}
+
+	// Automation targets must belong to this board too
+	for _, target := range []*int{settings.OverdueListID, settings.CompletedListID} {
+		if target == nil {
+			continue
+		}
+		list, err := h.listRepo.GetByID(*target)
+		if err != nil || list.BoardID != id {
+			middleware.HandleError(c, http.StatusBadRequest, "Automation lists must belong to the board")
+			return
+		}
+	}
+
+	if settings.OverdueLabelID != nil {
+		if _, err := h.labelRepo.GetByID(*settings.OverdueLabelID); err != nil {
+			if err.Error() == "label not found" {
+				middleware.HandleError(c, http.StatusBadRequest, "Overdue label not found")
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve labels")
+			}
+			return
+		}
+	}
+
+	if err := h.repo.UpdateSettings(id, settings); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update board settings")
+		return
+	}
+
+	if board, err := h.repo.GetByID(id); err == nil {
+		publish(c, h.bus, events.Event{Type: events.BoardUpdated, BoardID: id, Board: board})
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
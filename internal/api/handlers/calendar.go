@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+)
+
+// maxCalendarDays bounds the range a single calendar request may cover
+const maxCalendarDays = 366
+
+// Calendar returns a board's cards grouped by due date between from and to
+// (inclusive, YYYY-MM-DD). Without a range the current month is used. Cards
+// starting on a day are listed under that day's starting.
+func (h *CardHandler) Calendar(c *gin.Context) {
+	boardID, from, to, loc, ok := h.dateRangeRequest(c)
+	if !ok {
+		return
+	}
+
+	cards, err := h.cardRepo.GetByBoardID(boardID, c.Query("archived") == "true", actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve cards")
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCards(cards)
+	}
+
+	calendar := models.BoardCalendar{
+		BoardID: boardID,
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		Days:    []models.CalendarDay{},
+		Undated: []models.Card{},
+	}
+
+	// sprint_id narrows the calendar to one sprint; 0 selects the backlog
+	sprintFilter := -1
+	if v := c.Query("sprint_id"); v != "" {
+		if sprintFilter, err = strconv.Atoi(v); err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid sprint ID")
+			return
+		}
+	}
+
+	byDate := make(map[string][]models.Card)
+	startingByDate := make(map[string][]models.Card)
+	end := to.AddDate(0, 0, 1)
+	for _, card := range cards {
+		if sprintFilter >= 0 && !inSprint(card, sprintFilter) {
+			continue
+		}
+		if card.StartDate != nil {
+			start := card.StartDate.In(loc)
+			if !start.Before(from) && start.Before(end) {
+				date := start.Format("2006-01-02")
+				startingByDate[date] = append(startingByDate[date], card)
+			}
+		}
+		if card.DueDate == nil {
+			calendar.Undated = append(calendar.Undated, card)
+			continue
+		}
+		due := card.DueDate.In(loc)
+		if due.Before(from) || !due.Before(end) {
+			continue
+		}
+		date := due.Format("2006-01-02")
+		byDate[date] = append(byDate[date], card)
+	}
+
+	// Walk the range so days come out in date order
+	for day := from; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		dayCards, due := byDate[date]
+		starting, started := startingByDate[date]
+		if due || started {
+			if dayCards == nil {
+				dayCards = []models.Card{}
+			}
+			calendar.Days = append(calendar.Days, models.CalendarDay{Date: date, Cards: dayCards, Starting: starting})
+		}
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// Timeline returns a Gantt-style view of a board: each card with a start or
+// due date becomes a span overlapping from..to (inclusive, YYYY-MM-DD).
+// Without a range the current month is used.
+func (h *CardHandler) Timeline(c *gin.Context) {
+	boardID, from, to, loc, ok := h.dateRangeRequest(c)
+	if !ok {
+		return
+	}
+
+	cards, err := h.cardRepo.GetByBoardID(boardID, c.Query("archived") == "true", actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve cards")
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCards(cards)
+	}
+
+	timeline := models.BoardTimeline{
+		BoardID:     boardID,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Items:       []models.TimelineItem{},
+		Unscheduled: []models.Card{},
+	}
+
+	for _, card := range cards {
+		if card.StartDate == nil && card.DueDate == nil {
+			timeline.Unscheduled = append(timeline.Unscheduled, card)
+			continue
+		}
+
+		// A card with one date spans that day
+		var start, end time.Time
+		if card.StartDate != nil {
+			start = card.StartDate.In(loc)
+		} else {
+			start = card.DueDate.In(loc)
+		}
+		if card.DueDate != nil {
+			end = card.DueDate.In(loc)
+		} else {
+			end = start
+		}
+		startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+		if endDay.Before(from) || startDay.After(to) {
+			continue
+		}
+
+		timeline.Items = append(timeline.Items, models.TimelineItem{
+			Start: startDay.Format("2006-01-02"),
+			End:   endDay.Format("2006-01-02"),
+			Days:  int(endDay.Sub(startDay).Round(24*time.Hour).Hours()/24) + 1,
+			Card:  card,
+		})
+	}
+
+	sort.SliceStable(timeline.Items, func(i, j int) bool {
+		a, b := timeline.Items[i], timeline.Items[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		return a.End < b.End
+	})
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// dateRangeRequest parses the board ID and the from, to and tz query
+// parameters shared by the calendar and timeline, defaulting to the current
+// month. It writes an error response and returns false on bad input.
+func (h *CardHandler) dateRangeRequest(c *gin.Context) (int, time.Time, time.Time, *time.Location, bool) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return 0, time.Time{}, time.Time{}, nil, false
+	}
+
+	loc := time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid time zone")
+			return 0, time.Time{}, time.Time{}, nil, false
+		}
+	}
+
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 1, -1)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid from date; use YYYY-MM-DD")
+			return 0, time.Time{}, time.Time{}, nil, false
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid to date; use YYYY-MM-DD")
+			return 0, time.Time{}, time.Time{}, nil, false
+		}
+	}
+	if to.Before(from) {
+		middleware.HandleError(c, http.StatusBadRequest, "to must not be before from")
+		return 0, time.Time{}, time.Time{}, nil, false
+	}
+	if to.Sub(from) > maxCalendarDays*24*time.Hour {
+		middleware.HandleError(c, http.StatusBadRequest, "Date range is too large")
+		return 0, time.Time{}, time.Time{}, nil, false
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return 0, time.Time{}, time.Time{}, nil, false
+	}
+
+	return boardID, from, to, loc, true
+}
+
+// inSprint reports whether card belongs to sprintID, where 0 means no sprint
+func inSprint(card models.Card, sprintID int) bool {
+	if card.SprintID == nil {
+		return sprintID == 0
+	}
+	return *card.SprintID == sprintID
+}
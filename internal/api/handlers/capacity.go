@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/kanban-simple/internal/i18n"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/netguard"
+)
+
+// checkListCapacity alerts a list's capacity recipients when the card that
+// just entered it took the list over capacity. Lists already over capacity
+// do not alert again until they drop back within it. Webhook alerts go
+// through the outbox like other events. Failures are logged rather than
+// failing the request.
+func (h *CardHandler) checkListCapacity(card *models.Card) {
+	list, err := h.listRepo.GetByID(card.ListID)
+	if err != nil {
+		log.Printf("Failed to check capacity of list %d: %v", card.ListID, err)
+		return
+	}
+
+	capacity := list.Settings.Capacity
+	if capacity == 0 || list.CardCount != capacity+1 {
+		return
+	}
+
+	for _, userID := range list.Settings.CapacityNotifyUserIDs {
+		user, err := h.userRepo.GetByID(userID)
+		if err != nil {
+			continue
+		}
+
+		cardID := card.ID
+		notification := &models.Notification{
+			UserID:  user.ID,
+			Type:    models.NotificationListCapacity,
+			CardID:  &cardID,
+			Message: i18n.Sprintf(user.Locale, "List %q is over capacity (%d of %d cards)", list.Name, list.CardCount, capacity),
+		}
+		if err := h.notificationRepo.Create(notification); err != nil {
+			log.Printf("Failed to notify %s of list capacity: %v", user.Username, err)
+		}
+	}
+
+	if url := list.Settings.CapacityWebhookURL; url != "" {
+		alert := models.CapacityAlert{
+			Event:     "list.over_capacity",
+			BoardID:   list.BoardID,
+			ListID:    list.ID,
+			ListName:  list.Name,
+			Capacity:  capacity,
+			CardCount: list.CardCount,
+			CardID:    card.ID,
+			CreatedAt: time.Now(),
+		}
+		h.enqueueCapacityAlert(url, alert)
+	}
+}
+
+// enqueueCapacityAlert queues an alert for the webhook dispatcher, logging
+// any failure
+func (h *CardHandler) enqueueCapacityAlert(url string, alert models.CapacityAlert) {
+	// A URL saved before internal addresses were refused would only fail
+	// delivery; the dispatcher's client refuses it either way
+	if !netguard.PublicURL(url) {
+		log.Printf("Not sending capacity alert for list %d to internal URL %s", alert.ListID, url)
+		return
+	}
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		log.Printf("Failed to encode capacity alert: %v", err)
+		return
+	}
+
+	boardID := alert.BoardID
+	entry := &models.OutboxEntry{
+		Event:    alert.Event,
+		EntityID: alert.ListID,
+		BoardID:  &boardID,
+		URL:      url,
+		Payload:  string(body),
+	}
+	if err := h.webhookRepo.Enqueue(entry); err != nil {
+		log.Printf("Failed to queue capacity alert for list %d: %v", alert.ListID, err)
+	}
+}
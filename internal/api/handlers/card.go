@@ -1,33 +1,71 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/i18n"
 	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/quickadd"
 	"github.com/kanban-simple/internal/repository"
+	"github.com/kanban-simple/internal/unfurl"
 )
 
 // CardHandler handles card-related HTTP requests
 type CardHandler struct {
-	cardRepo  *repository.CardRepository
-	listRepo  *repository.ListRepository
-	boardRepo *repository.BoardRepository
+	cardRepo         *repository.CardRepository
+	listRepo         *repository.ListRepository
+	boardRepo        *repository.BoardRepository
+	labelRepo        *repository.LabelRepository
+	sprintRepo       *repository.SprintRepository
+	milestoneRepo    *repository.MilestoneRepository
+	snippetRepo      *repository.SnippetRepository
+	userRepo         *repository.UserRepository
+	notificationRepo *repository.NotificationRepository
+	webhookRepo      *repository.WebhookRepository
+	memberRepo       *repository.MemberRepository
+	unfurler         *unfurl.Unfurler // Fetches link previews; nil disables fetching
+	quotas           models.Quotas
+	bus              *events.Bus
 }
 
 // NewCardHandler creates a new card handler
-func NewCardHandler(cardRepo *repository.CardRepository, listRepo *repository.ListRepository, boardRepo *repository.BoardRepository) *CardHandler {
+func NewCardHandler(cardRepo *repository.CardRepository, listRepo *repository.ListRepository, boardRepo *repository.BoardRepository, labelRepo *repository.LabelRepository, sprintRepo *repository.SprintRepository, milestoneRepo *repository.MilestoneRepository, snippetRepo *repository.SnippetRepository, userRepo *repository.UserRepository, notificationRepo *repository.NotificationRepository, webhookRepo *repository.WebhookRepository, memberRepo *repository.MemberRepository, unfurler *unfurl.Unfurler, quotas models.Quotas, bus *events.Bus) *CardHandler {
 	return &CardHandler{
-		cardRepo:  cardRepo,
-		listRepo:  listRepo,
-		boardRepo: boardRepo,
+		cardRepo:         cardRepo,
+		listRepo:         listRepo,
+		boardRepo:        boardRepo,
+		labelRepo:        labelRepo,
+		sprintRepo:       sprintRepo,
+		milestoneRepo:    milestoneRepo,
+		snippetRepo:      snippetRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		webhookRepo:      webhookRepo,
+		memberRepo:       memberRepo,
+		unfurler:         unfurler,
+		quotas:           quotas,
+		bus:              bus,
 	}
 }
 
 // GetByID retrieves a card by ID
 func (h *CardHandler) GetByID(c *gin.Context) {
+	fields, ok := fieldsParam(c, models.Card{})
+	if !ok {
+		return
+	}
+	expand, ok := expandParam(c, "comments")
+	if !ok {
+		return
+	}
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
@@ -43,18 +81,176 @@ func (h *CardHandler) GetByID(c *gin.Context) {
 		}
 		return
 	}
+	if cardHidden(c, card) {
+		return
+	}
 
 	// Get comments for the card
-	comments, err := h.cardRepo.GetComments(id)
-	if err == nil {
-		card.Comments = comments
+	if !h.expandCard(c, card, expand, fields) {
+		return
 	}
 
-	c.JSON(http.StatusOK, card)
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
+	c.Header("ETag", cardETag(card))
+	writeFields(c, http.StatusOK, card, fields)
+}
+
+// GetByNumber retrieves a card by its sequential number on a board
+func (h *CardHandler) GetByNumber(c *gin.Context) {
+	fields, ok := fieldsParam(c, models.Card{})
+	if !ok {
+		return
+	}
+	expand, ok := expandParam(c, "comments")
+	if !ok {
+		return
+	}
+
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	number, err := strconv.Atoi(strings.TrimPrefix(c.Param("number"), "#"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card number")
+		return
+	}
+
+	card, err := h.cardRepo.GetByNumber(boardID, number)
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) {
+		return
+	}
+
+	if !h.expandCard(c, card, expand, fields) {
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
+	writeFields(c, http.StatusOK, card, fields)
+}
+
+// GetByKey retrieves a card by its stable public key
+func (h *CardHandler) GetByKey(c *gin.Context) {
+	fields, ok := fieldsParam(c, models.Card{})
+	if !ok {
+		return
+	}
+	expand, ok := expandParam(c, "comments")
+	if !ok {
+		return
+	}
+
+	card, err := h.cardRepo.GetByKey(c.Param("key"))
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) || !h.requireCardRole(c, card.ID, models.MemberRoleViewer) {
+		return
+	}
+
+	if !h.expandCard(c, card, expand, fields) {
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
+	writeFields(c, http.StatusOK, card, fields)
+}
+
+// requireCardRole checks the acting user's role on the board of a card the
+// route does not name by :id. It writes an error response and returns false
+// when they lack the role.
+func (h *CardHandler) requireCardRole(c *gin.Context, cardID int, role string) bool {
+	boardID, err := h.memberRepo.BoardOf("cards", cardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to check board access")
+		return false
+	}
+	return middleware.RequireBoardRole(c, h.memberRepo, boardID, role)
+}
+
+// Permalink redirects /c/:key to the card in the web UI
+func (h *CardHandler) Permalink(c *gin.Context) {
+	card, err := h.cardRepo.GetByKey(c.Param("key"))
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) {
+		return
+	}
+
+	list, err := h.listRepo.GetByID(card.ListID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve list")
+		return
+	}
+
+	c.Redirect(http.StatusFound, middleware.GetBasePath(c)+fmt.Sprintf("/?board=%d&card=%d", list.BoardID, card.ID))
+}
+
+// ResolveReference lets a card route take a reference such as OPS-214 in
+// place of the card ID, swapping in the ID before the handler runs. Other
+// values are left for the handler to reject.
+func (h *CardHandler) ResolveReference(c *gin.Context) {
+	for i, param := range c.Params {
+		if param.Key != "id" {
+			continue
+		}
+		if _, _, ok := models.ParseCardReference(param.Value); !ok {
+			return
+		}
+		id, err := h.cardRepo.GetIDByReference(param.Value)
+		if err != nil {
+			if err.Error() == "card not found" {
+				middleware.HandleError(c, http.StatusNotFound, "Card not found")
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+			}
+			return
+		}
+		c.Params[i].Value = strconv.Itoa(id)
+	}
 }
 
 // GetByListID retrieves all cards for a list
 func (h *CardHandler) GetByListID(c *gin.Context) {
+	fields, ok := fieldsParam(c, models.Card{})
+	if !ok {
+		return
+	}
+	expand, ok := expandParam(c, "labels")
+	if !ok {
+		return
+	}
+
 	listID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		middleware.HandleError(c, http.StatusBadRequest, "Invalid list ID")
@@ -62,7 +258,38 @@ func (h *CardHandler) GetByListID(c *gin.Context) {
 	}
 
 	// Check if archived cards should be included
-	includeArchived := c.Query("archived") == "true"
+	opts := models.CardListOptions{IncludeArchived: c.Query("archived") == "true"}
+
+	if sprintID := c.Query("sprint_id"); sprintID != "" {
+		id, err := strconv.Atoi(sprintID)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid sprint ID")
+			return
+		}
+		opts.SprintID = &id
+	}
+
+	sort := models.CardSort{Field: c.DefaultQuery("sort", "position")}
+	if !models.CardSortFields[sort.Field] {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid sort field; use position, due_date, created_at, priority, title or snooze_count")
+		return
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		sort.Desc = true
+	default:
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid order; use asc or desc")
+		return
+	}
+	opts.Sort = sort
+
+	updatedAfter, ok := updatedAfterParam(c)
+	if !ok {
+		return
+	}
+	opts.UpdatedAfter = updatedAfter
+	opts.ViewerID = actingUserID(c)
 
 	// Verify list exists
 	if _, err := h.listRepo.GetByID(listID); err != nil {
@@ -74,13 +301,21 @@ func (h *CardHandler) GetByListID(c *gin.Context) {
 		return
 	}
 
-	cards, err := h.cardRepo.GetByListID(listID, includeArchived)
+	cards, err := h.cardRepo.GetByListID(listID, opts)
 	if err != nil {
 		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve cards")
 		return
 	}
 
-	c.JSON(http.StatusOK, cards)
+	if !h.expandCards(c, cards, expand, fields) {
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCards(cards)
+	}
+
+	writeCollection(c, cards, fields)
 }
 
 // Create creates a new card
@@ -92,7 +327,8 @@ func (h *CardHandler) Create(c *gin.Context) {
 	}
 
 	// Verify list exists
-	if _, err := h.listRepo.GetByID(listID); err != nil {
+	list, err := h.listRepo.GetByID(listID)
+	if err != nil {
 		if err.Error() == "list not found" {
 			middleware.HandleError(c, http.StatusNotFound, "List not found")
 		} else {
@@ -103,7 +339,7 @@ func (h *CardHandler) Create(c *gin.Context) {
 
 	var req models.CreateCardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -113,18 +349,100 @@ func (h *CardHandler) Create(c *gin.Context) {
 		Description: req.Description,
 		Position:    req.Position,
 		Color:       req.Color,
+		StartDate:   req.StartDate,
 		DueDate:     req.DueDate,
 		Archived:    false,
+		Priority:    req.Priority,
+		Points:      req.Points,
+		Visibility:  req.Visibility,
+		CreatedBy:   actingUserID(c),
 	}
 
-	if err := h.cardRepo.Create(card); err != nil {
-		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create card")
+	if !validDateRange(c, card) {
+		return
+	}
+
+	if len(req.Snippets) > 0 {
+		description, ok := expandSnippets(c, h.snippetRepo, list.BoardID, card.Description, req.Snippets)
+		if !ok {
+			return
+		}
+		card.Description = description
+	}
+
+	if !checkQuota(c, h.quotas.CheckCards(list.CardCount, 1)) {
 		return
 	}
 
+	if req.AssigneeID != nil && *req.AssigneeID != 0 {
+		if !h.assigneeExists(c, *req.AssigneeID) {
+			return
+		}
+		card.AssigneeID = req.AssigneeID
+	}
+
+	if req.SprintID != nil && *req.SprintID != 0 {
+		if !h.sprintOnBoard(c, *req.SprintID, list.BoardID) {
+			return
+		}
+		card.SprintID = req.SprintID
+	}
+
+	if req.MilestoneID != nil && *req.MilestoneID != 0 {
+		if !h.milestoneOnBoard(c, *req.MilestoneID, list.BoardID) {
+			return
+		}
+		card.MilestoneID = req.MilestoneID
+	}
+
+	if !seenBySomeone(c, card) {
+		return
+	}
+
+	if !h.insertCard(c, list, card, req.DueDate != nil) {
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
 	c.JSON(http.StatusCreated, card)
 }
 
+// insertCard saves a new card on a list with the list's rules and the
+// board's default color applied, assigns the rule labels and publishes
+// card.created. It writes an error response and returns false on failure.
+func (h *CardHandler) insertCard(c *gin.Context, list *models.List, card *models.Card, explicitDue bool) bool {
+	if card.CreatedBy == nil {
+		card.CreatedBy = actingUserID(c)
+	}
+
+	// The list's rules come first, then the board's default card color
+	list.Settings.Apply(card, time.Now(), explicitDue)
+	card.Labels = h.listRuleLabels(list.Settings)
+	if card.Color == "" {
+		if board, err := h.boardRepo.GetByID(list.BoardID); err == nil {
+			card.Color = board.Settings.DefaultCardColor
+		}
+	}
+
+	if err := h.cardRepo.Create(card); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create card")
+		return false
+	}
+
+	for _, label := range card.Labels {
+		if err := h.labelRepo.AssignToCard(card.ID, label.ID); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to assign labels")
+			return false
+		}
+	}
+
+	h.publish(c, events.Event{Type: events.CardCreated, BoardID: list.BoardID, Card: card})
+	return true
+}
+
 // Update updates a card
 func (h *CardHandler) Update(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -143,11 +461,30 @@ func (h *CardHandler) Update(c *gin.Context) {
 		}
 		return
 	}
+	if cardHidden(c, card) {
+		return
+	}
 
 	// Bind update request
 	var req models.UpdateCardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	// The write checks the revision again, so a change committed while this
+	// request is handled is caught as well. An updated_at is checked against
+	// the card as read here, and then stands for its revision.
+	revision := baseRevision(c, req.Revision)
+	if since := unmodifiedSince(c, req.LastKnownUpdatedAt); since != nil && revision == 0 {
+		if modifiedSince(card.UpdatedAt, *since) {
+			cardConflict(c, card)
+			return
+		}
+		revision = card.Revision
+	}
+	if revision != 0 && revision != card.Revision {
+		cardConflict(c, card)
 		return
 	}
 
@@ -161,19 +498,268 @@ func (h *CardHandler) Update(c *gin.Context) {
 	if req.Color != "" {
 		card.Color = req.Color
 	}
+	if req.StartDate != nil {
+		card.StartDate = req.StartDate
+	}
 	if req.DueDate != nil {
 		card.DueDate = req.DueDate
 	}
+	if !validDateRange(c, card) {
+		return
+	}
+	if req.Priority != "" {
+		card.Priority = req.Priority
+	}
+	if req.AssigneeID != nil {
+		// An assignee_id of 0 unassigns the card
+		if *req.AssigneeID == 0 {
+			card.AssigneeID = nil
+		} else {
+			if !h.assigneeExists(c, *req.AssigneeID) {
+				return
+			}
+			card.AssigneeID = req.AssigneeID
+		}
+	}
+	if req.SprintID != nil {
+		// A sprint_id of 0 moves the card back to the backlog
+		if *req.SprintID == 0 {
+			card.SprintID = nil
+		} else {
+			list, err := h.listRepo.GetByID(card.ListID)
+			if err != nil {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify list")
+				return
+			}
+			if !h.sprintOnBoard(c, *req.SprintID, list.BoardID) {
+				return
+			}
+			card.SprintID = req.SprintID
+		}
+	}
+	if req.MilestoneID != nil {
+		// A milestone_id of 0 removes the card from its milestone
+		if *req.MilestoneID == 0 {
+			card.MilestoneID = nil
+		} else {
+			list, err := h.listRepo.GetByID(card.ListID)
+			if err != nil {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify list")
+				return
+			}
+			if !h.milestoneOnBoard(c, *req.MilestoneID, list.BoardID) {
+				return
+			}
+			card.MilestoneID = req.MilestoneID
+		}
+	}
+	if req.Points != nil {
+		card.Points = req.Points
+	}
+	if req.Visibility != "" {
+		card.Visibility = req.Visibility
+	}
+	if !seenBySomeone(c, card) {
+		return
+	}
 
 	// Save updates
-	if err := h.cardRepo.Update(card); err != nil {
-		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update card")
+	if err := h.cardRepo.UpdateRevision(card, revision); err != nil {
+		if err.Error() != "card has changed" {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update card")
+			return
+		}
+		current, err := h.cardRepo.GetByID(id)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+			return
+		}
+		cardConflict(c, current)
 		return
 	}
 
+	h.publish(c, events.Event{Type: events.CardUpdated, Card: card})
+
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
+	c.Header("ETag", cardETag(card))
 	c.JSON(http.StatusOK, card)
 }
 
+// cardConflict writes the 409 for an update based on an outdated copy of a
+// card, with the card as it is now
+func cardConflict(c *gin.Context, card *models.Card) {
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+	c.AbortWithStatusJSON(http.StatusConflict, models.CardConflictResponse{
+		Error:   http.StatusText(http.StatusConflict),
+		Message: i18n.Translate(middleware.GetLocale(c), "Card was updated since you last read it; merge your changes with the current card and retry"),
+		Card:    card,
+	})
+}
+
+// cardETag is a card's entity tag, its revision
+func cardETag(card *models.Card) string {
+	return `"` + strconv.Itoa(card.Revision) + `"`
+}
+
+// baseRevision returns the revision a client based a change on, from the
+// request body or else an If-Match header holding the card's ETag, or 0
+// when it gave neither or If-Match is "*". Any other If-Match matches no
+// revision, so -1 is returned and the update conflicts.
+func baseRevision(c *gin.Context, revision *int) int {
+	if revision != nil {
+		return *revision
+	}
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0
+	}
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) >= 2 && tag[0] == '"' && tag[len(tag)-1] == '"' {
+		if n, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil && n > 0 {
+			return n
+		}
+	}
+	return -1
+}
+
+// unmodifiedSince returns the updated_at a client based a change on, from
+// the request body or else an If-Unmodified-Since header, or nil when it
+// gave neither. An invalid header date is ignored, as HTTP requires.
+func unmodifiedSince(c *gin.Context, lastKnown *time.Time) *time.Time {
+	if lastKnown != nil {
+		return lastKnown
+	}
+	if header := c.GetHeader("If-Unmodified-Since"); header != "" {
+		if t, err := http.ParseTime(header); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// modifiedSince reports whether updatedAt is later than since. updated_at is
+// stored to the second, so a change within the same second is not detected.
+func modifiedSince(updatedAt, since time.Time) bool {
+	return updatedAt.Truncate(time.Second).After(since.Truncate(time.Second))
+}
+
+// sprintOnBoard reports whether the sprint exists on the board, writing an error response if not
+func (h *CardHandler) sprintOnBoard(c *gin.Context, sprintID, boardID int) bool {
+	sprint, err := h.sprintRepo.GetByID(sprintID)
+	if err != nil {
+		if err.Error() == "sprint not found" {
+			middleware.HandleError(c, http.StatusBadRequest, "Sprint not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify sprint")
+		}
+		return false
+	}
+	if sprint.BoardID != boardID {
+		middleware.HandleError(c, http.StatusBadRequest, "Sprint belongs to another board")
+		return false
+	}
+	return true
+}
+
+// milestoneOnBoard reports whether the milestone exists on the board, writing an error response if not
+func (h *CardHandler) milestoneOnBoard(c *gin.Context, milestoneID, boardID int) bool {
+	milestone, err := h.milestoneRepo.GetByID(milestoneID)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			middleware.HandleError(c, http.StatusBadRequest, "Milestone not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify milestone")
+		}
+		return false
+	}
+	if milestone.BoardID != boardID {
+		middleware.HandleError(c, http.StatusBadRequest, "Milestone belongs to another board")
+		return false
+	}
+	return true
+}
+
+// parseDateParam accepts an RFC 3339 timestamp or a YYYY-MM-DD date (midnight UTC)
+func parseDateParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// updatedAfterParam parses ?updated_after=, which limits a collection to
+// what was updated at or after that second so pollers can fetch only
+// changes. It writes an error response and returns false when the value is
+// not a date.
+func updatedAfterParam(c *gin.Context) (*time.Time, bool) {
+	value := c.Query("updated_after")
+	if value == "" {
+		return nil, true
+	}
+	t, err := parseDateParam(value)
+	if err != nil {
+		middleware.HandleErrorf(c, http.StatusBadRequest, "Invalid %s; use RFC 3339 or YYYY-MM-DD", "updated_after")
+		return nil, false
+	}
+	return &t, true
+}
+
+// validDateRange checks that a card does not start after it is due, writing
+// an error response if it does
+func validDateRange(c *gin.Context, card *models.Card) bool {
+	if card.StartDate != nil && card.DueDate != nil && card.StartDate.After(*card.DueDate) {
+		middleware.HandleError(c, http.StatusBadRequest, "start_date must not be after due_date")
+		return false
+	}
+	return true
+}
+
+// assigneeExists reports whether the user exists, writing an error response if not
+func (h *CardHandler) assigneeExists(c *gin.Context, userID int) bool {
+	if _, err := h.userRepo.GetByID(userID); err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusBadRequest, "Assignee not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify assignee")
+		}
+		return false
+	}
+	return true
+}
+
+// cardHidden writes the 404 a missing card gets, and returns true, when the
+// acting user may not see card, so restricted cards do not reveal that they
+// exist
+func cardHidden(c *gin.Context, card *models.Card) bool {
+	if card.VisibleTo(actingUserID(c)) {
+		return false
+	}
+	middleware.HandleError(c, http.StatusNotFound, "Card not found")
+	return true
+}
+
+// cardIDHidden is cardHidden for a card known by ID. A card that cannot be
+// loaded is left for the caller to report.
+func (h *CardHandler) cardIDHidden(c *gin.Context, id int) bool {
+	card, err := h.cardRepo.GetByID(id)
+	return err == nil && cardHidden(c, card)
+}
+
+// seenBySomeone rejects a restricted card with neither an assignee nor a
+// creator, which no one could see, writing an error response
+func seenBySomeone(c *gin.Context, card *models.Card) bool {
+	if card.Visibility == models.CardVisibilityRestricted && card.AssigneeID == nil && card.CreatedBy == nil {
+		middleware.HandleError(c, http.StatusBadRequest, "A restricted card with no creator needs an assignee")
+		return false
+	}
+	return true
+}
+
 // Move moves a card to a different list and/or position
 func (h *CardHandler) Move(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -184,7 +770,7 @@ func (h *CardHandler) Move(c *gin.Context) {
 
 	var req models.MoveCardRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -198,9 +784,13 @@ func (h *CardHandler) Move(c *gin.Context) {
 		}
 		return
 	}
+	if cardHidden(c, card) {
+		return
+	}
 
 	// Verify target list exists
-	if _, err := h.listRepo.GetByID(req.ListID); err != nil {
+	target, err := h.listRepo.GetByID(req.ListID)
+	if err != nil {
 		if err.Error() == "list not found" {
 			middleware.HandleError(c, http.StatusNotFound, "Target list not found")
 		} else {
@@ -208,18 +798,153 @@ func (h *CardHandler) Move(c *gin.Context) {
 		}
 		return
 	}
+	if !middleware.RequireBoardRole(c, h.memberRepo, target.BoardID, models.MemberRoleEditor) {
+		return
+	}
+
+	if target.ID != card.ListID && !card.Archived && !checkQuota(c, h.quotas.CheckCards(target.CardCount, 1)) {
+		return
+	}
+
+	opts := models.CardMoveOptions{
+		DetachLabels: req.Labels == "detach",
+		LabelMap:     req.LabelMap,
+		KeepNumber:   req.Renumber != nil && !*req.Renumber,
+		UserID:       actingUserID(c),
+	}
+	for _, labelID := range req.LabelMap {
+		if labelID == 0 {
+			continue
+		}
+		if _, err := h.labelRepo.GetByID(labelID); err != nil {
+			if err.Error() == "label not found" {
+				middleware.HandleErrorf(c, http.StatusBadRequest, "Label not found: %d", labelID)
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve labels")
+			}
+			return
+		}
+	}
 
 	// Move the card using the position calculated by the frontend
-	if err := h.cardRepo.Move(id, req.ListID, req.Position); err != nil {
-		middleware.HandleError(c, http.StatusInternalServerError, "Failed to move card")
+	if err := h.cardRepo.Move(id, req.ListID, req.Position, opts); err != nil {
+		if err.Error() == "card number taken" {
+			middleware.HandleError(c, http.StatusConflict, "Card number is already used on the target board")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to move card")
+		}
 		return
 	}
 
-	card.ListID = req.ListID
-	card.Position = req.Position
+	// Re-read the card; moving to another board assigns a new number
+	previousListID := card.ListID
+	if moved, err := h.cardRepo.GetByID(id); err == nil {
+		card = moved
+	} else {
+		card.ListID = req.ListID
+		card.Position = req.Position
+	}
+
+	// Entering a list applies its rules; reordering within one does not
+	if previousListID != req.ListID && !h.enterList(c, card, target) {
+		return
+	}
+
+	h.publish(c, events.Event{Type: events.CardMoved, BoardID: target.BoardID, Card: card, FromListID: previousListID})
+
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
 	c.JSON(http.StatusOK, card)
 }
 
+// GetMoves lists the lists a card has been in, oldest first, with who moved
+// it and why automation did
+func (h *CardHandler) GetMoves(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	card, err := h.cardRepo.GetByID(id)
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) {
+		return
+	}
+
+	moves, err := h.cardRepo.GetMovesByCard(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card history")
+		return
+	}
+
+	c.JSON(http.StatusOK, moves)
+}
+
+// GetBacklinks lists the cards whose description or comments refer to a
+// card, newest reference first
+func (h *CardHandler) GetBacklinks(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	card, err := h.cardRepo.GetByID(id)
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) {
+		return
+	}
+
+	backlinks, err := h.cardRepo.GetBacklinks(id, actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card backlinks")
+		return
+	}
+
+	c.JSON(http.StatusOK, backlinks)
+}
+
+// enterList applies the rules of the list a card has just moved to and
+// assigns its rule labels. It writes an error response and returns false on
+// failure.
+func (h *CardHandler) enterList(c *gin.Context, card *models.Card, list *models.List) bool {
+	list.Settings.Apply(card, time.Now(), false)
+	if err := h.cardRepo.Update(card); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to apply list rules")
+		return false
+	}
+	labels := h.listRuleLabels(list.Settings)
+	for _, label := range labels {
+		if err := h.labelRepo.AssignToCard(card.ID, label.ID); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to assign labels")
+			return false
+		}
+	}
+	if len(labels) > 0 {
+		if cardLabels, err := h.labelRepo.GetCardLabels(card.ID); err == nil {
+			card.Labels = cardLabels
+		}
+	}
+	return true
+}
+
 // Archive archives a card
 func (h *CardHandler) Archive(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -228,6 +953,10 @@ func (h *CardHandler) Archive(c *gin.Context) {
 		return
 	}
 
+	if h.cardIDHidden(c, id) {
+		return
+	}
+
 	if err := h.cardRepo.Archive(id, true); err != nil {
 		if err.Error() == "card not found" {
 			middleware.HandleError(c, http.StatusNotFound, "Card not found")
@@ -237,7 +966,9 @@ func (h *CardHandler) Archive(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Card archived successfully"})
+	h.publishCard(c, events.CardArchived, id)
+
+	c.JSON(http.StatusOK, successMessage(c, "Card archived successfully"))
 }
 
 // Unarchive unarchives a card
@@ -248,6 +979,22 @@ func (h *CardHandler) Unarchive(c *gin.Context) {
 		return
 	}
 
+	if h.cardIDHidden(c, id) {
+		return
+	}
+
+	// Unarchiving puts the card back in its list's count
+	if card, err := h.cardRepo.GetByID(id); err == nil && card.Archived {
+		list, err := h.listRepo.GetByID(card.ListID)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify list")
+			return
+		}
+		if !checkQuota(c, h.quotas.CheckCards(list.CardCount, 1)) {
+			return
+		}
+	}
+
 	if err := h.cardRepo.Archive(id, false); err != nil {
 		if err.Error() == "card not found" {
 			middleware.HandleError(c, http.StatusNotFound, "Card not found")
@@ -257,7 +1004,63 @@ func (h *CardHandler) Unarchive(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Card unarchived successfully"})
+	h.publishCard(c, events.CardUnarchived, id)
+
+	c.JSON(http.StatusOK, successMessage(c, "Card unarchived successfully"))
+}
+
+// Complete marks a card as completed without archiving it
+func (h *CardHandler) Complete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	if h.cardIDHidden(c, id) {
+		return
+	}
+
+	unblocked, err := h.cardRepo.Complete(id, true)
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to complete card")
+		}
+		return
+	}
+
+	h.publishCard(c, events.CardCompleted, id)
+	h.publishUnblocked(c, unblocked)
+
+	c.JSON(http.StatusOK, successMessage(c, "Card completed successfully"))
+}
+
+// Uncomplete clears a card's completion
+func (h *CardHandler) Uncomplete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	if h.cardIDHidden(c, id) {
+		return
+	}
+
+	if _, err := h.cardRepo.Complete(id, false); err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to uncomplete card")
+		}
+		return
+	}
+
+	h.publishCard(c, events.CardUncompleted, id)
+
+	c.JSON(http.StatusOK, successMessage(c, "Card uncompleted successfully"))
 }
 
 // Delete deletes a card
@@ -268,6 +1071,12 @@ func (h *CardHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	// Loaded first so the event can describe what was deleted
+	card, _ := h.cardRepo.GetByID(id)
+	if card != nil && cardHidden(c, card) {
+		return
+	}
+
 	if err := h.cardRepo.Delete(id); err != nil {
 		if err.Error() == "card not found" {
 			middleware.HandleError(c, http.StatusNotFound, "Card not found")
@@ -277,15 +1086,103 @@ func (h *CardHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Card deleted successfully"})
+	if card != nil {
+		h.publish(c, events.Event{Type: events.CardDeleted, Card: card})
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Card deleted successfully"))
 }
 
 // Search searches for cards based on criteria
 func (h *CardHandler) Search(c *gin.Context) {
+	fields, ok := fieldsParam(c, models.Card{})
+	if !ok {
+		return
+	}
+	expand, ok := expandParam(c, "labels")
+	if !ok {
+		return
+	}
+
+	params, ok := searchParams(c)
+	if !ok {
+		return
+	}
+
+	cards, err := h.cardRepo.Search(params)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to search cards")
+		return
+	}
+
+	if !h.expandCards(c, cards, expand, fields) {
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCards(cards)
+	}
+
+	writeCollection(c, cards, fields)
+}
+
+// Count counts a board's cards matching the search filters without loading
+// them, for badges; the count is also in X-Total-Count, so HEAD works too
+func (h *CardHandler) Count(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	params, ok := searchParams(c)
+	if !ok {
+		return
+	}
+	params.BoardID = boardID
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	count, err := h.cardRepo.CountSearch(params)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to count cards")
+		return
+	}
+
+	c.Header(totalCountHeader, strconv.Itoa(count))
+	c.JSON(http.StatusOK, models.CardCount{Count: count})
+}
+
+// searchParams reads the search filters from the query string, for the acting
+// user, writing a 400 for an unparsable date
+func searchParams(c *gin.Context) (models.SearchCardsRequest, bool) {
 	var params models.SearchCardsRequest
 
-	// Parse query parameters
+	// Parse query parameters; is:blocked in the text is a filter, not a term
 	params.Query = c.Query("query")
+	if terms := strings.Fields(params.Query); len(terms) > 0 {
+		kept := terms[:0]
+		for _, term := range terms {
+			if strings.EqualFold(term, "is:blocked") {
+				blocked := true
+				params.Blocked = &blocked
+				continue
+			}
+			kept = append(kept, term)
+		}
+		if params.Blocked != nil {
+			params.Query = strings.Join(kept, " ")
+		}
+	}
+
+	params.Fuzzy = c.Query("fuzzy") == "true"
 
 	if boardID := c.Query("board_id"); boardID != "" {
 		if id, err := strconv.Atoi(boardID); err == nil {
@@ -310,13 +1207,67 @@ func (h *CardHandler) Search(c *gin.Context) {
 		}
 	}
 
-	cards, err := h.cardRepo.Search(params)
-	if err != nil {
-		middleware.HandleError(c, http.StatusInternalServerError, "Failed to search cards")
-		return
+	dateParams := map[string]**time.Time{
+		"start_before":     &params.StartBefore,
+		"start_after":      &params.StartAfter,
+		"active_on":        &params.ActiveOn,
+		"due_before":       &params.DueBefore,
+		"due_after":        &params.DueAfter,
+		"created_before":   &params.CreatedBefore,
+		"created_after":    &params.CreatedAfter,
+		"updated_after":    &params.UpdatedAfter,
+		"completed_before": &params.CompletedBefore,
+		"completed_after":  &params.CompletedAfter,
+	}
+	for name, dest := range dateParams {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := parseDateParam(value)
+		if err != nil {
+			middleware.HandleErrorf(c, http.StatusBadRequest, "Invalid %s; use RFC 3339 or YYYY-MM-DD", name)
+			return params, false
+		}
+		*dest = &t
+	}
+
+	if sprintID := c.Query("sprint_id"); sprintID != "" {
+		if id, err := strconv.Atoi(sprintID); err == nil {
+			params.SprintID = &id
+		}
+	}
+
+	if milestoneID := c.Query("milestone_id"); milestoneID != "" {
+		if id, err := strconv.Atoi(milestoneID); err == nil {
+			params.MilestoneID = &id
+		}
+	}
+
+	if completed := c.Query("completed"); completed != "" {
+		completedBool := completed == "true"
+		params.Completed = &completedBool
 	}
 
-	c.JSON(http.StatusOK, cards)
+	if blocked := c.Query("blocked"); blocked != "" {
+		blockedBool := blocked == "true"
+		params.Blocked = &blockedBool
+	}
+
+	if hasDueDate := c.Query("has_due_date"); hasDueDate != "" {
+		hasDueDateBool := hasDueDate == "true"
+		params.HasDueDate = &hasDueDateBool
+	}
+
+	if minSnoozes := c.Query("min_snoozes"); minSnoozes != "" {
+		if n, err := strconv.Atoi(minSnoozes); err == nil {
+			params.MinSnoozes = n
+		}
+	}
+
+	params.ViewerID = actingUserID(c)
+
+	return params, true
 }
 
 // AddComment adds a comment to a card
@@ -328,7 +1279,8 @@ func (h *CardHandler) AddComment(c *gin.Context) {
 	}
 
 	// Verify card exists
-	if _, err := h.cardRepo.GetByID(cardID); err != nil {
+	card, err := h.cardRepo.GetByID(cardID)
+	if err != nil {
 		if err.Error() == "card not found" {
 			middleware.HandleError(c, http.StatusNotFound, "Card not found")
 		} else {
@@ -336,16 +1288,41 @@ func (h *CardHandler) AddComment(c *gin.Context) {
 		}
 		return
 	}
+	if cardHidden(c, card) {
+		return
+	}
 
 	var req models.CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
+		return
+	}
+	if !withinCommentQuota(c, h.quotas, req.Content) {
 		return
 	}
 
 	comment := &models.Comment{
-		CardID:  cardID,
-		Content: req.Content,
+		CardID:   cardID,
+		Content:  req.Content,
+		AuthorID: req.AuthorID,
+	}
+
+	// Verify author exists
+	if req.AuthorID != nil {
+		author, err := h.userRepo.GetByID(*req.AuthorID)
+		if err != nil {
+			if err.Error() == "user not found" {
+				middleware.HandleError(c, http.StatusBadRequest, "Author not found")
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify author")
+			}
+			return
+		}
+		comment.Author = author
+	} else if user := middleware.GetCurrentUser(c); user != nil {
+		// Attribute the comment to the acting user
+		comment.AuthorID = &user.ID
+		comment.Author = user
 	}
 
 	if err := h.cardRepo.AddComment(comment); err != nil {
@@ -353,6 +1330,12 @@ func (h *CardHandler) AddComment(c *gin.Context) {
 		return
 	}
 
+	h.publish(c, events.Event{Type: events.CommentCreated, Card: card, Comment: comment})
+
+	if wantsHTML(c) {
+		renderComment(comment)
+	}
+
 	c.JSON(http.StatusCreated, comment)
 }
 
@@ -365,7 +1348,8 @@ func (h *CardHandler) GetComments(c *gin.Context) {
 	}
 
 	// Verify card exists
-	if _, err := h.cardRepo.GetByID(cardID); err != nil {
+	card, err := h.cardRepo.GetByID(cardID)
+	if err != nil {
 		if err.Error() == "card not found" {
 			middleware.HandleError(c, http.StatusNotFound, "Card not found")
 		} else {
@@ -373,31 +1357,168 @@ func (h *CardHandler) GetComments(c *gin.Context) {
 		}
 		return
 	}
+	if cardHidden(c, card) {
+		return
+	}
 
-	comments, err := h.cardRepo.GetComments(cardID)
+	// Optionally filter by author
+	authorID := 0
+	if author := c.Query("author_id"); author != "" {
+		if id, err := strconv.Atoi(author); err == nil {
+			authorID = id
+		}
+	}
+
+	updatedAfter, ok := updatedAfterParam(c)
+	if !ok {
+		return
+	}
+	expand, ok := expandParam(c)
+	if !ok {
+		return
+	}
+
+	comments, err := h.cardRepo.GetComments(cardID, authorID, updatedAfter)
 	if err != nil {
 		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve comments")
 		return
 	}
 
-	c.JSON(http.StatusOK, comments)
+	if expand["unfurls"] {
+		if err := h.loadUnfurls(commentLinkTexts(comments)); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve link previews")
+			return
+		}
+	}
+
+	if wantsHTML(c) {
+		renderComments(comments)
+	}
+
+	writeCollection(c, comments, nil)
+}
+
+// UpdateComment edits the content of a comment
+func (h *CardHandler) UpdateComment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	comment, err := h.cardRepo.GetComment(id)
+	if err != nil {
+		if err.Error() == "comment not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Comment not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve comment")
+		}
+		return
+	}
+	if h.commentHidden(c, comment) {
+		return
+	}
+
+	var req models.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+	if !withinCommentQuota(c, h.quotas, req.Content) {
+		return
+	}
+
+	comment.Content = req.Content
+	if err := h.cardRepo.UpdateComment(comment); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update comment")
+		return
+	}
+
+	if card, err := h.cardRepo.GetByID(comment.CardID); err == nil {
+		h.publish(c, events.Event{Type: events.CommentUpdated, Card: card, Comment: comment})
+	}
+
+	if wantsHTML(c) {
+		renderComment(comment)
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment deletes a comment
+func (h *CardHandler) DeleteComment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	// Loaded first so the event can describe what was deleted
+	comment, _ := h.cardRepo.GetComment(id)
+	if comment != nil && h.commentHidden(c, comment) {
+		return
+	}
+
+	if err := h.cardRepo.DeleteComment(id); err != nil {
+		if err.Error() == "comment not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Comment not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete comment")
+		}
+		return
+	}
+
+	if comment != nil {
+		if card, err := h.cardRepo.GetByID(comment.CardID); err == nil {
+			h.publish(c, events.Event{Type: events.CommentDeleted, Card: card, Comment: comment})
+		}
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Comment deleted successfully"))
+}
+
+// commentHidden writes the 404 a missing comment gets, and returns true, when
+// the acting user may not see the comment's card
+func (h *CardHandler) commentHidden(c *gin.Context, comment *models.Comment) bool {
+	card, err := h.cardRepo.GetByID(comment.CardID)
+	if err != nil || card.VisibleTo(actingUserID(c)) {
+		return false
+	}
+	middleware.HandleError(c, http.StatusNotFound, "Comment not found")
+	return true
 }
 
 // QuickCreate creates a card quickly (for bot integration)
 func (h *CardHandler) QuickCreate(c *gin.Context) {
 	type QuickCreateRequest struct {
-		BoardName   string `json:"board_name,omitempty"`
-		ListName    string `json:"list_name,omitempty"`
-		Title       string `json:"title" binding:"required"`
-		Description string `json:"description,omitempty"`
-		Color       string `json:"color,omitempty"`
+		BoardName     string     `json:"board_name,omitempty"`
+		ListName      string     `json:"list_name,omitempty"`
+		Title         string     `json:"title" binding:"required,max=255"`
+		Description   string     `json:"description,omitempty" binding:"max=5000"`
+		Color         string     `json:"color,omitempty" binding:"omitempty,color"`
+		DueDate       *time.Time `json:"due_date,omitempty"`
+		Labels        []string   `json:"labels,omitempty"` // Label names
+		Position      float64    `json:"position,omitempty"`
+		CreateMissing bool       `json:"create_missing,omitempty"` // Create the named board/list instead of falling back
+		Parse         bool       `json:"parse,omitempty"`          // Interpret inline due dates, #labels, !priority and @assignee
+		DryRun        bool       `json:"dry_run,omitempty"`        // Return the interpretation without creating the card
 	}
 
 	var req QuickCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
+	dryRun := req.DryRun || c.Query("dry_run") == "true"
+
+	var parsed quickadd.Result
+	if req.Parse {
+		parsed = quickadd.Parse(req.Title, time.Now())
+		if parsed.Title == "" {
+			middleware.HandleError(c, http.StatusBadRequest, "Title is empty after parsing")
+			return
+		}
+	}
 
 	// Default to first board and "Backlog" list if not specified
 	boardName := req.BoardName
@@ -410,28 +1531,52 @@ func (h *CardHandler) QuickCreate(c *gin.Context) {
 		listName = "Backlog"
 	}
 
-	// Find the board by name
+	// Find the board by name; with create_missing a named board that does
+	// not exist is created instead of falling back to the first board
+	var newBoard, newList bool
 	board, err := h.boardRepo.GetByName(boardName)
 	if err != nil {
-		// If board not found, try to get the first board
-		boards, err := h.boardRepo.GetAll()
-		if err != nil || len(boards) == 0 {
-			middleware.HandleError(c, http.StatusNotFound, "No boards available. Please create a board first.")
-			return
+		if req.CreateMissing && req.BoardName != "" {
+			board = &models.Board{Name: req.BoardName}
+			newBoard = true
+		} else {
+			// If board not found, try to get the first board
+			boards, err := h.boardRepo.GetAll(nil, actingUserID(c))
+			if err != nil || len(boards) == 0 {
+				middleware.HandleError(c, http.StatusNotFound, "No boards available. Please create a board first.")
+				return
+			}
+			board = &boards[0]
 		}
-		board = &boards[0]
+	}
+	if !newBoard && !middleware.RequireBoardRole(c, h.memberRepo, board.ID, models.MemberRoleEditor) {
+		return
 	}
 
-	// Find the list by board ID and name
-	list, err := h.listRepo.GetByBoardAndName(board.ID, listName)
-	if err != nil {
-		// If list not found, try to get the first list in the board
-		lists, err := h.listRepo.GetByBoardID(board.ID)
-		if err != nil || len(lists) == 0 {
-			middleware.HandleError(c, http.StatusNotFound, "No lists available in the board. Please create a list first.")
-			return
+	// Prefer the board's configured quick-create list when no list was named
+	var list *models.List
+	if newBoard {
+		list = &models.List{Name: listName}
+		newList = true
+	} else {
+		if req.ListName == "" && board.Settings.QuickCreateListID != nil {
+			list, err = h.listRepo.GetByID(*board.Settings.QuickCreateListID)
+		} else {
+			list, err = h.listRepo.GetByBoardAndName(board.ID, listName)
+		}
+		if err != nil {
+			// If list not found, try to get the first list in the board
+			lists, err := h.listRepo.GetByBoardID(board.ID, nil)
+			if req.CreateMissing && (req.ListName != "" || (err == nil && len(lists) == 0)) {
+				list = &models.List{BoardID: board.ID, Name: listName}
+				newList = true
+			} else if err != nil || len(lists) == 0 {
+				middleware.HandleError(c, http.StatusNotFound, "No lists available in the board. Please create a list first.")
+				return
+			} else {
+				list = &lists[0]
+			}
 		}
-		list = &lists[0]
 	}
 
 	// Create the card
@@ -439,14 +1584,263 @@ func (h *CardHandler) QuickCreate(c *gin.Context) {
 		ListID:      list.ID,
 		Title:       req.Title,
 		Description: req.Description,
+		Position:    req.Position,
 		Color:       req.Color,
+		DueDate:     req.DueDate,
 		Archived:    false,
 	}
 
+	// Explicit label names must all exist
+	labels, missing, err := h.matchLabels(req.Labels)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve labels")
+		return
+	}
+	if len(missing) > 0 {
+		middleware.HandleErrorf(c, http.StatusBadRequest, "Label not found: %s", missing[0])
+		return
+	}
+	card.Labels = labels
+
+	// Apply the parsed interpretation; unknown labels and users are reported, not created
+	unmatched := gin.H{}
+	if req.Parse {
+		card.Title = parsed.Title
+		if card.DueDate == nil {
+			card.DueDate = parsed.DueDate
+		}
+		card.Priority = parsed.Priority
+
+		labels, missing, err := h.matchLabels(parsed.Labels)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve labels")
+			return
+		}
+		card.Labels = mergeLabels(card.Labels, labels)
+		if len(missing) > 0 {
+			unmatched["labels"] = missing
+		}
+
+		if parsed.Assignee != "" {
+			if user, err := h.userRepo.GetByUsername(parsed.Assignee); err == nil {
+				card.AssigneeID = &user.ID
+			} else {
+				unmatched["assignee"] = parsed.Assignee
+			}
+		}
+	}
+
+	// The list's rules come first, then the board's default card color
+	list.Settings.Apply(card, time.Now(), card.DueDate != nil)
+	card.Labels = mergeLabels(card.Labels, h.listRuleLabels(list.Settings))
+	if card.Color == "" {
+		card.Color = board.Settings.DefaultCardColor
+	}
+
+	switch {
+	case newBoard && !withinBoardQuota(c, h.quotas, h.boardRepo):
+		return
+	case newList && !newBoard && !withinListQuota(c, h.quotas, h.listRepo, board.ID, 1):
+		return
+	case !newList && !checkQuota(c, h.quotas.CheckCards(list.CardCount, 1)):
+		return
+	}
+
+	if dryRun {
+		preview := gin.H{
+			"dry_run":   true,
+			"board":     gin.H{"id": board.ID, "name": board.Name},
+			"list":      gin.H{"id": list.ID, "name": list.Name},
+			"creates":   gin.H{"board": newBoard, "list": newList},
+			"card":      card,
+			"unmatched": unmatched,
+		}
+		if req.Parse {
+			preview["parsed"] = parsed
+		}
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+
+	if newBoard {
+		if err := h.boardRepo.Create(board); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to create board")
+			return
+		}
+		list.BoardID = board.ID
+		h.publish(c, events.Event{Type: events.BoardCreated, BoardID: board.ID, Board: board})
+	}
+	if newList {
+		if err := h.listRepo.Create(list); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to create list")
+			return
+		}
+		card.ListID = list.ID
+		h.publish(c, events.Event{Type: events.ListCreated, BoardID: list.BoardID, List: list})
+	}
+
+	card.CreatedBy = actingUserID(c)
 	if err := h.cardRepo.Create(card); err != nil {
 		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create card")
 		return
 	}
 
+	for _, label := range card.Labels {
+		if err := h.labelRepo.AssignToCard(card.ID, label.ID); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to assign labels")
+			return
+		}
+	}
+
+	h.publish(c, events.Event{Type: events.CardCreated, BoardID: list.BoardID, Card: card})
+
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
 	c.JSON(http.StatusCreated, card)
-}
\ No newline at end of file
+}
+
+// mergeLabels appends labels not already present in existing
+func mergeLabels(existing, labels []models.Label) []models.Label {
+	for _, label := range labels {
+		found := false
+		for _, e := range existing {
+			if e.ID == label.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, label)
+		}
+	}
+	return existing
+}
+
+// matchLabels resolves label names case-insensitively, treating hyphens and
+// underscores as spaces, and returns the names that matched no label
+func (h *CardHandler) matchLabels(names []string) ([]models.Label, []string, error) {
+	if len(names) == 0 {
+		return nil, nil, nil
+	}
+
+	all, err := h.labelRepo.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	normalize := func(name string) string {
+		name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+		return strings.ToLower(strings.Join(strings.Fields(name), " "))
+	}
+	byName := make(map[string]models.Label, len(all))
+	for _, label := range all {
+		byName[normalize(label.Name)] = label
+	}
+
+	var labels []models.Label
+	var missing []string
+	for _, name := range names {
+		if label, ok := byName[normalize(name)]; ok {
+			labels = append(labels, label)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return labels, missing, nil
+}
+
+// expandCard joins the related resources in expand, a blocked card's open
+// blockers and the cards it refers to into a single card, the ones fields
+// leaves out excepted, writing an error response when it cannot
+func (h *CardHandler) expandCard(c *gin.Context, card *models.Card, expand expandSet, fields fieldSet) bool {
+	if expand["comments"] && fields.has("comments") {
+		comments, err := h.cardRepo.GetComments(card.ID, 0, nil)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve comments")
+			return false
+		}
+		card.Comments = comments
+	}
+
+	if expand["labels"] && fields.has("labels") {
+		labels, err := h.labelRepo.GetCardLabels(card.ID)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card labels")
+			return false
+		}
+		card.Labels = labels
+	}
+
+	if expand["unfurls"] && (fields.has("unfurls") || fields.has("comments")) {
+		if err := h.loadUnfurls(cardLinkTexts(card)); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve link previews")
+			return false
+		}
+	}
+
+	if card.Blocked && fields.has("blocked_by") {
+		blockers, err := h.cardRepo.GetOpenBlockers(card.ID)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card blockers")
+			return false
+		}
+		card.BlockedBy = blockers
+	}
+
+	if fields.has("references") {
+		refs, err := h.cardRepo.GetReferences(card.ID, actingUserID(c))
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card references")
+			return false
+		}
+		card.References = refs
+	}
+
+	return true
+}
+
+// expandCards joins comment counts and the related resources in expand into
+// a listing, the ones fields leaves out excepted, and nothing for HEAD,
+// writing an error response when it cannot
+func (h *CardHandler) expandCards(c *gin.Context, cards []models.Card, expand expandSet, fields fieldSet) bool {
+	// HEAD only wants the count
+	if c.Request.Method == http.MethodHead {
+		return true
+	}
+
+	if fields.has("comment_count") {
+		if err := h.cardRepo.LoadCommentCounts(cards); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to count comments")
+			return false
+		}
+	}
+
+	if expand["labels"] && fields.has("labels") {
+		if err := h.cardRepo.LoadLabels(cards); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card labels")
+			return false
+		}
+	}
+
+	if expand["comments"] && fields.has("comments") {
+		if err := h.cardRepo.LoadComments(cards); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve comments")
+			return false
+		}
+	}
+
+	if expand["unfurls"] && (fields.has("unfurls") || fields.has("comments")) {
+		var texts []linkText
+		for i := range cards {
+			texts = append(texts, cardLinkTexts(&cards[i])...)
+		}
+		if err := h.loadUnfurls(texts); err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve link previews")
+			return false
+		}
+	}
+
+	return true
+}
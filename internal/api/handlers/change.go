@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// Longest a poll may wait, and how often a waiting poll rechecks for
+// changes made without a published event, such as by background jobs
+const (
+	maxPollTimeout = 60 * time.Second
+	pollRecheck    = 5 * time.Second
+)
+
+// ChangeHandler serves the change log
+type ChangeHandler struct {
+	repo      *repository.ChangeRepository
+	boardRepo *repository.BoardRepository
+	signal    *events.Signal
+}
+
+// NewChangeHandler creates a new change handler; polls wake on the events
+// published on bus
+func NewChangeHandler(repo *repository.ChangeRepository, boardRepo *repository.BoardRepository, bus *events.Bus) *ChangeHandler {
+	return &ChangeHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+		signal:    events.NewSignal(bus),
+	}
+}
+
+// GetChanges lists the changes after ?since=, oldest first, optionally only
+// those on ?board_id=. Without since, no changes are listed and next is the
+// latest sequence number: the cursor to read on from after a full sync.
+// Reading on from a since whose following changes were pruned is refused
+// with 410, as the changes in between can no longer be listed.
+func (h *ChangeHandler) GetChanges(c *gin.Context) {
+	var boardID *int
+	if v := c.Query("board_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+			return
+		}
+		boardID = &id
+	}
+
+	since, ok := h.since(c)
+	if !ok {
+		return
+	}
+
+	limit := queryLimit(c, 100)
+	changes, more, err := h.repo.Since(since, boardID, actingUserID(c), limit)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve changes")
+		return
+	}
+
+	feed := changeFeed(since, changes, more)
+	if !middleware.Enveloped(c) {
+		c.JSON(http.StatusOK, feed)
+		return
+	}
+
+	total, err := h.repo.Count(since, boardID, actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve changes")
+		return
+	}
+
+	// The feed pages by sequence number, so offset stays 0, and there is
+	// always a cursor to read on from once more changes are made
+	c.JSON(http.StatusOK, models.Collection{
+		Data: feed.Changes,
+		Meta: models.CollectionMeta{Total: total, Limit: limit, NextCursor: encodeCursor(feed.Next)},
+	})
+}
+
+// Poll is GetChanges for one board that waits while there are none: it
+// holds the request until a change after ?since= is made on the board or
+// ?timeout= (default 30s, at most 60s) elapses, and then responds with the
+// changes, or with none. Clients read on from next as from GetChanges.
+func (h *ChangeHandler) Poll(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	timeout := 30 * time.Second
+	if v := c.Query("timeout"); v != "" {
+		// Bare numbers are seconds
+		if _, err := strconv.Atoi(v); err == nil {
+			v += "s"
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid timeout; use a duration such as 30s")
+			return
+		}
+		timeout = min(parsed, maxPollTimeout)
+	}
+
+	since, ok := h.since(c)
+	if !ok {
+		return
+	}
+
+	limit := queryLimit(c, 100)
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	recheck := time.NewTicker(pollRecheck)
+	defer recheck.Stop()
+
+	for {
+		// Taken before checking, so an event published during the check
+		// still wakes the wait
+		wake := h.signal.Wait()
+
+		changes, more, err := h.repo.Since(since, &boardID, actingUserID(c), limit)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve changes")
+			return
+		}
+		if len(changes) > 0 {
+			c.JSON(http.StatusOK, changeFeed(since, changes, more))
+			return
+		}
+
+		select {
+		case <-wake:
+		case <-recheck.C:
+		case <-deadline.C:
+			c.JSON(http.StatusOK, changeFeed(since, changes, false))
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// since reads ?since=, or under /api/v1 ?cursor=, checking that reading on
+// from it skips nothing. Without either it responds with an empty page whose
+// next is the latest sequence number. It writes a response and returns false
+// unless changes should be listed.
+func (h *ChangeHandler) since(c *gin.Context) (int, bool) {
+	latest, err := h.repo.Latest()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve changes")
+		return 0, false
+	}
+
+	value := c.Query("since")
+	if cursor := c.Query("cursor"); cursor != "" && middleware.Enveloped(c) {
+		seq, ok := decodeCursor(cursor)
+		if !ok {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid cursor")
+			return 0, false
+		}
+		value = strconv.Itoa(seq)
+	}
+
+	if value == "" {
+		if middleware.Enveloped(c) {
+			c.JSON(http.StatusOK, models.Collection{
+				Data: []models.Change{},
+				Meta: models.CollectionMeta{Limit: queryLimit(c, 100), NextCursor: encodeCursor(latest)},
+			})
+		} else {
+			c.JSON(http.StatusOK, models.ChangeFeed{Changes: []models.Change{}, Next: latest})
+		}
+		return 0, false
+	}
+
+	return parseSince(c, h.repo, value, latest)
+}
+
+// parseSince parses a change log sequence number to read on from, checking
+// that no changes after it were pruned. It writes a response and returns
+// false when it is unusable.
+func parseSince(c *gin.Context, changes *repository.ChangeRepository, value string, latest int) (int, bool) {
+	// A since past the latest change cannot have come from this server, and
+	// reading on from it would skip the changes up to it
+	since, err := strconv.Atoi(value)
+	if err != nil || since < 0 || since > latest {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid since; use the next value of an earlier response")
+		return 0, false
+	}
+
+	pruned, err := changes.Pruned(since)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve changes")
+		return 0, false
+	}
+	if pruned {
+		middleware.HandleError(c, http.StatusGone, "Changes after this sequence number have been pruned; sync everything again and read on from the latest")
+		return 0, false
+	}
+
+	return since, true
+}
+
+// changeFeed is the page for changes listed after since
+func changeFeed(since int, changes []models.Change, more bool) models.ChangeFeed {
+	feed := models.ChangeFeed{Changes: changes, Next: since, HasMore: more}
+	if len(changes) > 0 {
+		feed.Next = changes[len(changes)-1].Seq
+	}
+	return feed
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+)
+
+// totalCountHeader carries the size of a whole collection, so HEAD requests
+// can count without reading it
+const totalCountHeader = "X-Total-Count"
+
+// writeCollection writes items, a slice, with the fields in fields. Under
+// /api/v1 it writes the page asked for with ?limit= and ?offset= or
+// ?cursor= in a models.Collection; elsewhere the whole slice as an array.
+// Either way X-Total-Count is the length of items.
+func writeCollection(c *gin.Context, items interface{}, fields fieldSet) {
+	all := reflect.ValueOf(items)
+	total := all.Len()
+	c.Header(totalCountHeader, strconv.Itoa(total))
+
+	if !middleware.Enveloped(c) {
+		writeFields(c, http.StatusOK, items, fields)
+		return
+	}
+
+	limit := queryLimit(c, models.DefaultPageSize)
+	offset, ok := pageOffset(c)
+	if !ok {
+		return
+	}
+
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	page := reflect.AppendSlice(reflect.MakeSlice(all.Type(), 0, end-start), all.Slice(start, end))
+	data, err := encodeFields(page.Interface(), fields)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+
+	meta := models.CollectionMeta{Total: total, Limit: limit, Offset: start}
+	if end < total {
+		meta.NextCursor = encodeCursor(end)
+	}
+
+	c.JSON(http.StatusOK, models.Collection{Data: data, Meta: meta})
+}
+
+// pageOffset reads where a page starts from ?cursor=, or else ?offset=,
+// writing a 400 when either is invalid
+func pageOffset(c *gin.Context) (int, bool) {
+	if cursor := c.Query("cursor"); cursor != "" {
+		offset, ok := decodeCursor(cursor)
+		if !ok {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid cursor")
+		}
+		return offset, ok
+	}
+
+	v := c.Query("offset")
+	if v == "" {
+		return 0, true
+	}
+	offset, err := strconv.Atoi(v)
+	if err != nil || offset < 0 {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid offset")
+		return 0, false
+	}
+	return offset, true
+}
+
+// encodeCursor makes the opaque next_cursor for a position in a collection
+func encodeCursor(position int) *string {
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(position)))
+	return &cursor
+}
+
+// decodeCursor reads a position back from a cursor made by encodeCursor
+func decodeCursor(cursor string) (int, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	position, err := strconv.Atoi(string(raw))
+	if err != nil || position < 0 {
+		return 0, false
+	}
+	return position, true
+}
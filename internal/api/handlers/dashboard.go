@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// recentlyUpdatedWindow is how far back the dashboard looks for updated cards
+const recentlyUpdatedWindow = 7 * 24 * time.Hour
+
+// DashboardHandler handles the cross-board landing page
+type DashboardHandler struct {
+	repo *repository.DashboardRepository
+}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler(repo *repository.DashboardRepository) *DashboardHandler {
+	return &DashboardHandler{repo: repo}
+}
+
+// Get returns cards due in the next seven days, overdue cards, cards updated
+// in the last seven days and per-board counts. ?limit= caps each card list.
+func (h *DashboardHandler) Get(c *gin.Context) {
+	now := time.Now()
+	dashboard, err := h.repo.Get(models.DashboardOptions{
+		Now:          now,
+		DueBy:        now.Add(7 * 24 * time.Hour),
+		UpdatedSince: now.Add(-recentlyUpdatedWindow),
+		Limit:        queryLimit(c, 10),
+		ViewerID:     actingUserID(c),
+	})
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to build dashboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+)
+
+// confirmDeleteCards is the number of cards at which a board or list delete
+// must be confirmed with the token from its delete preview
+const confirmDeleteCards = 10
+
+// DeletePreview reports what deleting a board would remove
+func (h *BoardHandler) DeletePreview(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	preview, err := h.repo.DeletePreview(id)
+	if err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to preview delete")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, sealPreview("board", id, preview))
+}
+
+// DeletePreview reports what deleting a list would remove
+func (h *ListHandler) DeletePreview(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	preview, err := h.listRepo.DeletePreview(id)
+	if err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to preview delete")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, sealPreview("list", id, preview))
+}
+
+// sealPreview fills in whether the delete needs confirming and the token
+// that confirms it. The token is derived from the counts, so it stops
+// working once the board or list changes.
+func sealPreview(kind string, id int, preview *models.DeletePreview) *models.DeletePreview {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%d:%d:%d:%d", kind, id,
+		preview.Lists, preview.Cards, preview.Comments, preview.Sprints, preview.Milestones)))
+	preview.ConfirmToken = hex.EncodeToString(sum[:8])
+	preview.ConfirmationRequired = preview.Cards >= confirmDeleteCards
+	return preview
+}
+
+// deleteConfirmed reports whether a delete may go ahead: small deletes always
+// may, large ones only with ?confirm= set to the preview's token. It writes an
+// error response if not.
+func deleteConfirmed(c *gin.Context, kind string, id int, preview *models.DeletePreview) bool {
+	preview = sealPreview(kind, id, preview)
+	if !preview.ConfirmationRequired || c.Query("confirm") == preview.ConfirmToken {
+		return true
+	}
+
+	if c.Query("confirm") == "" {
+		middleware.HandleErrorf(c, http.StatusConflict, "This delete removes %d cards; confirm it with the token from the delete preview", preview.Cards)
+	} else {
+		middleware.HandleError(c, http.StatusConflict, "Confirm token does not match; request a new delete preview")
+	}
+	return false
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/markdown"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// DigestHandler handles digest subscription HTTP requests
+type DigestHandler struct {
+	repo      *repository.DigestRepository
+	boardRepo *repository.BoardRepository
+	userRepo  *repository.UserRepository
+}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler(repo *repository.DigestRepository, boardRepo *repository.BoardRepository, userRepo *repository.UserRepository) *DigestHandler {
+	return &DigestHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetAll retrieves every digest, or those of the ?user_id= or ?board_id=
+// given
+func (h *DigestHandler) GetAll(c *gin.Context) {
+	var userID, boardID *int
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = &id
+	}
+	if v := c.Query("board_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+			return
+		}
+		boardID = &id
+	}
+
+	digests, err := h.repo.GetAll(userID, boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve digests")
+		return
+	}
+
+	c.JSON(http.StatusOK, digests)
+}
+
+// Create subscribes a user, a URL or both to a digest
+func (h *DigestHandler) Create(c *gin.Context) {
+	var req models.CreateDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	digest := &models.Digest{
+		UserID:    req.UserID,
+		BoardID:   req.BoardID,
+		URL:       req.URL,
+		Kind:      req.Kind,
+		Cadence:   req.Cadence,
+		Hour:      models.DefaultDigestHour,
+		Weekday:   models.DefaultDigestWeekday,
+		StaleDays: req.StaleDays,
+	}
+	if req.Hour != nil {
+		digest.Hour = *req.Hour
+	}
+	if req.Weekday != nil {
+		digest.Weekday = *req.Weekday
+	}
+	if digest.Kind == "" {
+		digest.Kind = models.DigestKindCards
+	}
+	if digest.StaleDays == 0 {
+		digest.StaleDays = models.DefaultDigestStaleDays
+	}
+	if !h.checkDigest(c, digest) {
+		return
+	}
+
+	if err := h.repo.Create(digest); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create digest")
+		return
+	}
+
+	c.JSON(http.StatusCreated, digest)
+}
+
+// GetByID retrieves a digest
+func (h *DigestHandler) GetByID(c *gin.Context) {
+	digest, ok := h.loadDigest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
+
+// Update changes a digest's recipients, board or schedule
+func (h *DigestHandler) Update(c *gin.Context) {
+	digest, ok := h.loadDigest(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.UserID != nil {
+		digest.UserID = req.UserID
+		if *req.UserID == 0 {
+			digest.UserID = nil
+		}
+	}
+	if req.BoardID != nil {
+		digest.BoardID = req.BoardID
+		if *req.BoardID == 0 {
+			digest.BoardID = nil
+		}
+	}
+	if req.URL != nil {
+		digest.URL = *req.URL
+	}
+	if req.Kind != nil {
+		digest.Kind = *req.Kind
+	}
+	if req.Cadence != nil {
+		digest.Cadence = *req.Cadence
+	}
+	if req.Hour != nil {
+		digest.Hour = *req.Hour
+	}
+	if req.Weekday != nil {
+		digest.Weekday = *req.Weekday
+	}
+	if req.StaleDays != nil {
+		digest.StaleDays = *req.StaleDays
+	}
+	if !h.checkDigest(c, digest) {
+		return
+	}
+
+	if err := h.repo.Update(digest); err != nil {
+		if err.Error() == "digest not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Digest not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update digest")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
+
+// Delete unsubscribes from a digest
+func (h *DigestHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid digest ID")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if err.Error() == "digest not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Digest not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete digest")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Digest deleted successfully"))
+}
+
+// Preview builds the report the digest would send now, without sending it;
+// for report digests, the board report, which ?render=html adds HTML to
+func (h *DigestHandler) Preview(c *gin.Context) {
+	digest, ok := h.loadDigest(c)
+	if !ok {
+		return
+	}
+
+	if digest.Kind == models.DigestKindReport {
+		report, err := h.repo.StatusReport(digest, time.Now())
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to build digest")
+			return
+		}
+		if wantsHTML(c) {
+			report.HTML = markdown.Render(markdown.Report(report))
+		}
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	report, err := h.repo.Report(digest, time.Now())
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to build digest")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// BoardReport builds a board's status report over ?from= to ?to= (dates,
+// inclusive), by default the seven days up to now. ?render=html adds the
+// report rendered from Markdown.
+func (h *DigestHandler) BoardReport(c *gin.Context) {
+	report, ok := h.boardReport(c)
+	if !ok {
+		return
+	}
+
+	if wantsHTML(c) {
+		report.HTML = markdown.Render(markdown.Report(report))
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// BoardReportMarkdown is BoardReport as Markdown, for pasting into a status
+// email
+func (h *DigestHandler) BoardReportMarkdown(c *gin.Context) {
+	report, ok := h.boardReport(c)
+	if !ok {
+		return
+	}
+
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(markdown.Report(report)))
+}
+
+// boardReport builds the report BoardReport describes, writing an error
+// response when it cannot
+func (h *DigestHandler) boardReport(c *gin.Context) (*models.BoardReport, bool) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return nil, false
+	}
+
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid to date; use YYYY-MM-DD")
+			return nil, false
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -models.ReportDays)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid from date; use YYYY-MM-DD")
+			return nil, false
+		}
+		from = t
+	}
+	if !to.After(from) {
+		middleware.HandleError(c, http.StatusBadRequest, "to must not be before from")
+		return nil, false
+	}
+
+	report, err := h.repo.BoardReport(boardID, from, to, actingUserID(c))
+	if err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to build board report")
+		}
+		return nil, false
+	}
+
+	return report, true
+}
+
+// loadDigest resolves the :id parameter, writing an error response when the
+// digest cannot be loaded
+func (h *DigestHandler) loadDigest(c *gin.Context) (*models.Digest, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid digest ID")
+		return nil, false
+	}
+
+	digest, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "digest not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Digest not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve digest")
+		}
+		return nil, false
+	}
+
+	return digest, true
+}
+
+// checkDigest makes sure a digest has somewhere to go, that a report digest
+// has a board, and that its user and board exist, writing an error response
+// when not
+func (h *DigestHandler) checkDigest(c *gin.Context, digest *models.Digest) bool {
+	if digest.UserID == nil && digest.URL == "" {
+		middleware.HandleError(c, http.StatusBadRequest, "Give user_id, url or both")
+		return false
+	}
+
+	if digest.Kind == models.DigestKindReport && digest.BoardID == nil {
+		middleware.HandleError(c, http.StatusBadRequest, "A report digest needs a board_id")
+		return false
+	}
+
+	if digest.UserID != nil {
+		if _, err := h.userRepo.GetByID(*digest.UserID); err != nil {
+			if err.Error() == "user not found" {
+				middleware.HandleError(c, http.StatusNotFound, "User not found")
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user")
+			}
+			return false
+		}
+	}
+
+	if digest.BoardID != nil {
+		if _, err := h.boardRepo.GetByID(*digest.BoardID); err != nil {
+			if err.Error() == "board not found" {
+				middleware.HandleError(c, http.StatusNotFound, "Board not found")
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+			}
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+)
+
+// publish sends an event for a committed change, attributed to the acting
+// user
+func publish(c *gin.Context, bus *events.Bus, event events.Event) {
+	event.Actor = middleware.GetCurrentUser(c)
+	bus.Publish(event)
+}
+
+// publish sends an event about a card, filling in the board from the card's
+// list when unset
+func (h *CardHandler) publish(c *gin.Context, event events.Event) {
+	if event.BoardID == 0 && event.Card != nil {
+		if list, err := h.listRepo.GetByID(event.Card.ListID); err == nil {
+			event.BoardID = list.BoardID
+		}
+	}
+	publish(c, h.bus, event)
+}
+
+// publishCard sends an event about the card with the given ID as it is
+// after the change
+func (h *CardHandler) publishCard(c *gin.Context, eventType events.Type, id int) {
+	card, err := h.cardRepo.GetByID(id)
+	if err != nil {
+		log.Printf("Failed to load card %d for %s event: %v", id, eventType, err)
+		return
+	}
+	h.publish(c, events.Event{Type: eventType, Card: card})
+}
+
+// Subscribe registers the card handler's reactions to changes: @mention
+// notifications, recording card references, fetching link previews, and
+// capacity alerts when a card enters a list. They run for
+// automated changes such as auto-moves as well as for requests.
+func (h *CardHandler) Subscribe(bus *events.Bus) {
+	bus.Subscribe(h.onMentionable, events.CardCreated, events.CardUpdated, events.CommentCreated, events.CommentUpdated)
+	bus.Subscribe(h.onReferencing, events.CardCreated, events.CardUpdated, events.CommentCreated, events.CommentUpdated)
+	bus.Subscribe(h.onLinking, events.CardCreated, events.CardUpdated, events.CommentCreated, events.CommentUpdated)
+	bus.Subscribe(h.onCardEnteredList, events.CardCreated, events.CardMoved, events.CardUnarchived)
+}
+
+// onMentionable notifies users mentioned in a card description or comment.
+// Comments are attributed to their author rather than the acting user.
+func (h *CardHandler) onMentionable(event events.Event) {
+	if event.Comment != nil {
+		h.notifyMentions(event.Card, &event.Comment.ID, event.Comment.Content, event.Comment.Author)
+		return
+	}
+	h.notifyMentions(event.Card, nil, event.Card.Description, event.Actor)
+}
+
+// onReferencing records the card references in a card description or
+// comment. Failures are logged; the text stays as written.
+func (h *CardHandler) onReferencing(event events.Event) {
+	var err error
+	if event.Comment != nil {
+		err = h.cardRepo.SetReferences(event.Card.ID, &event.Comment.ID, event.Comment.Content)
+	} else {
+		err = h.cardRepo.SetReferences(event.Card.ID, nil, event.Card.Description)
+	}
+	if err != nil {
+		log.Printf("Failed to record references of card %d: %v", event.Card.ID, err)
+	}
+}
+
+// onCardEnteredList checks the capacity of a list a card was added to.
+// Reordering within a list and moving archived cards do not count.
+func (h *CardHandler) onCardEnteredList(event events.Event) {
+	if event.Card.Archived {
+		return
+	}
+	if event.Type == events.CardMoved && event.FromListID == event.Card.ListID {
+		return
+	}
+	h.checkListCapacity(event.Card)
+}
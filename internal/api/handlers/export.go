@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/markdown"
+)
+
+// ExportMarkdown renders a board as Markdown for pasting into wikis and
+// release notes. Archived cards are left out unless ?archived=true.
+func (h *CardHandler) ExportMarkdown(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	board, err := h.boardRepo.GetByID(boardID)
+	if err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	lists, err := h.listRepo.GetByBoardID(boardID, nil)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve lists")
+		return
+	}
+
+	cards, err := h.cardRepo.GetByBoardID(boardID, c.Query("archived") == "true", actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve cards")
+		return
+	}
+
+	if err := h.cardRepo.LoadSummaries(cards); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card labels")
+		return
+	}
+
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(markdown.ExportBoard(board, lists, cards)))
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+)
+
+// fieldSet is the top-level fields a client asked for with
+// ?fields=id,title,labels; nil when it sent none and wants them all
+type fieldSet map[string]bool
+
+// has reports whether a field is wanted, so handlers can skip loading the
+// ones that are not
+func (f fieldSet) has(name string) bool {
+	return f == nil || f[name]
+}
+
+// fieldsParam parses ?fields= against the JSON field names of model, a
+// struct, writing a 400 for names it does not have
+func fieldsParam(c *gin.Context, model interface{}) (fieldSet, bool) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, true
+	}
+
+	known := make(map[string]bool)
+	for _, name := range jsonFields(reflect.TypeOf(model)) {
+		known[name] = true
+	}
+
+	fields := make(fieldSet)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			middleware.HandleErrorf(c, http.StatusBadRequest, "Unknown field: %s", name)
+			return nil, false
+		}
+		fields[name] = true
+	}
+	if len(fields) == 0 {
+		return nil, true
+	}
+
+	return fields, true
+}
+
+// writeFields writes v, a struct or a slice of structs, like c.JSON, keeping
+// only the top-level fields in fields
+func writeFields(c *gin.Context, status int, v interface{}, fields fieldSet) {
+	if fields == nil {
+		c.JSON(status, v)
+		return
+	}
+
+	data, err := encodeFields(v, fields)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", data)
+}
+
+// encodeFields encodes v, a struct or a slice of structs, keeping only the
+// top-level fields in fields, in the order the struct declares them. Nested
+// values such as a card's labels are written whole.
+func encodeFields(v interface{}, fields fieldSet) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil || fields == nil {
+		return data, err
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	order := jsonFields(t)
+
+	var buf bytes.Buffer
+	if bytes.HasPrefix(data, []byte("[")) {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeObject(&buf, item, order, fields)
+		}
+		buf.WriteByte(']')
+	} else {
+		var item map[string]json.RawMessage
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, err
+		}
+		writeObject(&buf, item, order, fields)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeObject writes the wanted fields of one encoded object. Fields left
+// out by omitempty stay left out.
+func writeObject(buf *bytes.Buffer, item map[string]json.RawMessage, order []string, fields fieldSet) {
+	buf.WriteByte('{')
+	first := true
+	for _, name := range order {
+		value, ok := item[name]
+		if !ok || !fields[name] {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+}
+
+// jsonFields lists the JSON names of a struct's fields in declaration
+// order, including those of embedded structs
+func jsonFields(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			names = append(names, jsonFields(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// cardExpansions are the related resources ?expand= can join into cards
+var cardExpansions = map[string]bool{"comments": true, "labels": true, "unfurls": true}
+
+// expandSet is the related resources a client asked to have joined in with
+// ?expand=comments,labels
+type expandSet map[string]bool
+
+// expandParam parses ?expand=, writing a 400 for resources cards do not
+// have. Without the parameter the endpoint's defaults are used; an empty
+// ?expand= joins nothing.
+func expandParam(c *gin.Context, defaults ...string) (expandSet, bool) {
+	expand := make(expandSet)
+	raw, given := c.GetQuery("expand")
+	if !given {
+		for _, name := range defaults {
+			expand[name] = true
+		}
+		return expand, true
+	}
+
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !cardExpansions[name] {
+			middleware.HandleErrorf(c, http.StatusBadRequest, "Unknown expansion: %s; use comments, labels or unfurls", name)
+			return nil, false
+		}
+		expand[name] = true
+	}
+
+	return expand, true
+}
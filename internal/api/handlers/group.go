@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// GroupHandler handles group, group member and board grant HTTP requests
+type GroupHandler struct {
+	repo      *repository.GroupRepository
+	boardRepo *repository.BoardRepository
+	userRepo  *repository.UserRepository
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(repo *repository.GroupRepository, boardRepo *repository.BoardRepository, userRepo *repository.UserRepository) *GroupHandler {
+	return &GroupHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetAll retrieves every group
+func (h *GroupHandler) GetAll(c *gin.Context) {
+	groups, err := h.repo.GetAll()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve groups")
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// Create creates a group
+func (h *GroupHandler) Create(c *gin.Context) {
+	var req models.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	// Group names must be unique, ignoring case
+	if _, err := h.repo.GetByName(req.Name); err == nil {
+		middleware.HandleError(c, http.StatusConflict, "Group name already exists")
+		return
+	}
+
+	group := &models.Group{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	if err := h.repo.Create(group); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create group")
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// GetByID retrieves a group
+func (h *GroupHandler) GetByID(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// Update renames a group or changes its description
+func (h *GroupHandler) Update(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		if existing, err := h.repo.GetByName(*req.Name); err == nil && existing.ID != group.ID {
+			middleware.HandleError(c, http.StatusConflict, "Group name already exists")
+			return
+		}
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+
+	if err := h.repo.Update(group); err != nil {
+		if err.Error() == "group not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Group not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update group")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// Delete removes a group; boards shared with it stop being shared with its
+// members
+func (h *GroupHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if err.Error() == "group not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Group not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete group")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Group deleted successfully"))
+}
+
+// GetMembers retrieves a group's members
+func (h *GroupHandler) GetMembers(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.GetMembers(group.ID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve group members")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember puts an existing user in a group
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	group, ok := h.loadGroup(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+	if (req.UserID == nil) == (req.Username == "") {
+		middleware.HandleError(c, http.StatusBadRequest, "Give either user_id or username")
+		return
+	}
+
+	var user *models.User
+	var err error
+	if req.UserID != nil {
+		user, err = h.userRepo.GetByID(*req.UserID)
+	} else {
+		user, err = h.userRepo.GetByUsername(req.Username)
+	}
+	if err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusNotFound, "User not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		}
+		return
+	}
+
+	member, err := h.repo.AddMember(group.ID, user.ID)
+	if err != nil {
+		if err.Error() == "group member already exists" {
+			middleware.HandleError(c, http.StatusConflict, "User is already in this group")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to add group member")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// RemoveMember takes a user out of a group
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.repo.RemoveMember(id, userID); err != nil {
+		if err.Error() == "group member not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Group member not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to remove group member")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Group member removed successfully"))
+}
+
+// GetBoardGrants retrieves the groups a board is shared with
+func (h *GroupHandler) GetBoardGrants(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	grants, err := h.repo.GetGrants(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve group grants")
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// GrantBoard shares a board with a group
+func (h *GroupHandler) GrantBoard(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	var req models.GrantBoardGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	group, ok := h.groupByID(c, req.GroupID)
+	if !ok {
+		return
+	}
+
+	grant, err := h.repo.Grant(boardID, group.ID, memberRole(req.Role), actingUserID(c))
+	if err != nil {
+		if err.Error() == "group grant already exists" {
+			middleware.HandleError(c, http.StatusConflict, "Board is already shared with this group")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to share board with group")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, grant)
+}
+
+// UpdateBoardGrant changes the role a group has on a board
+func (h *GroupHandler) UpdateBoardGrant(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(c.Param("group_id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	var req models.UpdateBoardMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	grant, err := h.repo.SetGrantRole(boardID, groupID, req.Role)
+	if err != nil {
+		if err.Error() == "group grant not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Group grant not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update group grant")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// RevokeBoard stops sharing a board with a group
+func (h *GroupHandler) RevokeBoard(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.Atoi(c.Param("group_id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	if err := h.repo.Revoke(boardID, groupID); err != nil {
+		if err.Error() == "group grant not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Group grant not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to revoke group grant")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Group grant revoked successfully"))
+}
+
+// loadGroup resolves the :id parameter, writing an error response when the
+// group cannot be loaded
+func (h *GroupHandler) loadGroup(c *gin.Context) (*models.Group, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid group ID")
+		return nil, false
+	}
+
+	return h.groupByID(c, id)
+}
+
+// groupByID loads a group, writing an error response when it cannot
+func (h *GroupHandler) groupByID(c *gin.Context, id int) (*models.Group, bool) {
+	group, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "group not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Group not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve group")
+		}
+		return nil, false
+	}
+
+	return group, true
+}
+
+// boardID resolves the :id parameter to an existing board, writing an error
+// response when it cannot
+func (h *GroupHandler) boardID(c *gin.Context) (int, bool) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return 0, false
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return 0, false
+	}
+
+	return boardID, true
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/imageproxy"
+)
+
+// ImageHandler serves external images referenced in rendered Markdown
+type ImageHandler struct {
+	proxy *imageproxy.Proxy
+}
+
+// NewImageHandler creates a new image handler; a nil proxy disables it
+func NewImageHandler(proxy *imageproxy.Proxy) *ImageHandler {
+	return &ImageHandler{proxy: proxy}
+}
+
+// Get fetches the image at ?url= through the proxy. The URL must carry the
+// signature rendered Markdown links it with as ?sig=, which is what stands
+// in for authentication: img tags cannot send tokens.
+func (h *ImageHandler) Get(c *gin.Context) {
+	if h.proxy == nil {
+		middleware.HandleError(c, http.StatusNotImplemented, "Image proxy is disabled")
+		return
+	}
+
+	rawURL := c.Query("url")
+	sig := c.Query("sig")
+	if rawURL == "" || sig == "" {
+		middleware.HandleError(c, http.StatusBadRequest, "Image URL and signature are required")
+		return
+	}
+	if !h.proxy.Verify(rawURL, sig) {
+		middleware.HandleError(c, http.StatusForbidden, "Invalid image signature")
+		return
+	}
+
+	image, err := h.proxy.Fetch(c.Request.Context(), rawURL)
+	if err != nil {
+		switch {
+		case errors.Is(err, imageproxy.ErrForbiddenAddress):
+			middleware.HandleError(c, http.StatusForbidden, "Image address not allowed")
+		case errors.Is(err, imageproxy.ErrTooLarge):
+			middleware.HandleError(c, http.StatusRequestEntityTooLarge, "Image too large")
+		case errors.Is(err, imageproxy.ErrNotImage):
+			middleware.HandleError(c, http.StatusUnsupportedMediaType, "Not an image")
+		default:
+			middleware.HandleError(c, http.StatusBadGateway, "Failed to fetch image")
+		}
+		return
+	}
+
+	// The image is served from this origin, so nothing in it may run
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'")
+	c.Data(http.StatusOK, image.ContentType, image.Data)
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/mapping"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// InboundHookHandler handles inbound hooks and the payloads posted to them
+type InboundHookHandler struct {
+	repo     *repository.InboundHookRepository
+	listRepo *repository.ListRepository
+	cards    *CardHandler
+}
+
+// NewInboundHookHandler creates a new inbound hook handler; cards are
+// created the way the card handler creates them
+func NewInboundHookHandler(repo *repository.InboundHookRepository, listRepo *repository.ListRepository, cards *CardHandler) *InboundHookHandler {
+	return &InboundHookHandler{
+		repo:     repo,
+		listRepo: listRepo,
+		cards:    cards,
+	}
+}
+
+// Receive turns a payload posted to a hook's URL into a card on the hook's
+// list. JSON bodies and form submissions are accepted; with ?dry_run=true
+// the rendered title and description are returned instead.
+func (h *InboundHookHandler) Receive(c *gin.Context) {
+	hook, err := h.repo.Authenticate(c.Param("token"))
+	if err != nil {
+		if err.Error() == "inbound hook not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Inbound hook not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve inbound hook")
+		}
+		return
+	}
+
+	payload, err := readPayload(c)
+	if err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	preview := models.InboundPreview{
+		Title:       truncateRunes(strings.Join(strings.Fields(mapping.Render(hook.TitleTemplate, payload)), " "), 255),
+		Description: truncateRunes(strings.TrimSpace(mapping.Render(hook.DescriptionTemplate, payload)), 5000),
+	}
+	if preview.Title == "" {
+		middleware.HandleError(c, http.StatusUnprocessableEntity, "Mapped title is empty")
+		return
+	}
+	if c.Query("dry_run") == "true" {
+		c.JSON(http.StatusOK, preview)
+		return
+	}
+
+	list, err := h.listRepo.GetByID(hook.ListID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify list")
+		return
+	}
+	if !checkQuota(c, h.cards.quotas.CheckCards(list.CardCount, 1)) {
+		return
+	}
+
+	card := &models.Card{
+		ListID:      list.ID,
+		Title:       preview.Title,
+		Description: preview.Description,
+	}
+	if !h.cards.insertCard(c, list, card, false) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+// readPayload decodes a JSON body, or failing that a posted form into a map
+// of its fields, with repeated fields as arrays. Senders often post JSON
+// with a form content type, so the content type alone is not trusted.
+func readPayload(c *gin.Context) (interface{}, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, io.EOF
+	}
+
+	var payload interface{}
+	jsonErr := json.Unmarshal(body, &payload)
+	if jsonErr == nil {
+		return payload, nil
+	}
+
+	contentType := c.ContentType()
+	if contentType != "application/x-www-form-urlencoded" && contentType != "multipart/form-data" {
+		return nil, jsonErr
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err := c.Request.ParseMultipartForm(32 << 10); err != nil && err != http.ErrNotMultipart {
+		return nil, err
+	}
+	fields := make(map[string]interface{}, len(c.Request.PostForm))
+	for key, values := range c.Request.PostForm {
+		if len(values) == 1 {
+			fields[key] = values[0]
+			continue
+		}
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			items[i] = value
+		}
+		fields[key] = items
+	}
+	return fields, nil
+}
+
+// truncateRunes shortens s to at most max characters
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+// GetAll retrieves every inbound hook, or those of the list given by
+// ?list_id
+func (h *InboundHookHandler) GetAll(c *gin.Context) {
+	var listID *int
+	if v := c.Query("list_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid list ID")
+			return
+		}
+		listID = &id
+	}
+
+	hooks, err := h.repo.GetAll(listID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve inbound hooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+// Create creates an inbound hook. The response carries its token and URL,
+// which are not shown again.
+func (h *InboundHookHandler) Create(c *gin.Context) {
+	var req models.CreateInboundHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if !h.checkList(c, req.ListID) {
+		return
+	}
+
+	hook := &models.InboundHook{
+		Name:                req.Name,
+		ListID:              req.ListID,
+		TitleTemplate:       req.TitleTemplate,
+		DescriptionTemplate: req.DescriptionTemplate,
+	}
+	if err := h.repo.Create(hook); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create inbound hook")
+		return
+	}
+	hook.URL = inboundHookURL(c, hook.Token)
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// GetByID retrieves an inbound hook
+func (h *InboundHookHandler) GetByID(c *gin.Context) {
+	hook, ok := h.loadHook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// Update changes an inbound hook's name, list or templates
+func (h *InboundHookHandler) Update(c *gin.Context) {
+	hook, ok := h.loadHook(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateInboundHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		hook.Name = *req.Name
+	}
+	if req.ListID != nil {
+		if !h.checkList(c, *req.ListID) {
+			return
+		}
+		hook.ListID = *req.ListID
+	}
+	if req.TitleTemplate != nil {
+		hook.TitleTemplate = *req.TitleTemplate
+	}
+	if req.DescriptionTemplate != nil {
+		hook.DescriptionTemplate = *req.DescriptionTemplate
+	}
+
+	if err := h.repo.Update(hook); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update inbound hook")
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// Rotate replaces an inbound hook's token, returning the new one and its URL
+func (h *InboundHookHandler) Rotate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid inbound hook ID")
+		return
+	}
+
+	hook, err := h.repo.Rotate(id)
+	if err != nil {
+		if err.Error() == "inbound hook not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Inbound hook not found")
+			return
+		}
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to rotate inbound hook token")
+		return
+	}
+	hook.URL = inboundHookURL(c, hook.Token)
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// Delete removes an inbound hook
+func (h *InboundHookHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid inbound hook ID")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if err.Error() == "inbound hook not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Inbound hook not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete inbound hook")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Inbound hook deleted successfully"))
+}
+
+// loadHook resolves the :id parameter, writing an error response when the
+// hook cannot be loaded
+func (h *InboundHookHandler) loadHook(c *gin.Context) (*models.InboundHook, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid inbound hook ID")
+		return nil, false
+	}
+
+	hook, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "inbound hook not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Inbound hook not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve inbound hook")
+		}
+		return nil, false
+	}
+
+	return hook, true
+}
+
+// checkList makes sure a hook's list exists and that the acting user may
+// add cards to it, writing an error response when not
+func (h *InboundHookHandler) checkList(c *gin.Context, listID int) bool {
+	list, err := h.listRepo.GetByID(listID)
+	if err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify list")
+		}
+		return false
+	}
+	return middleware.RequireBoardRole(c, h.cards.memberRepo, list.BoardID, models.MemberRoleEditor)
+}
+
+// inboundHookURL is the path payloads for a hook are posted to, under the
+// base path
+func inboundHookURL(c *gin.Context, token string) string {
+	return middleware.GetBasePath(c) + "/api/hooks/" + token
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/i18n"
+	"github.com/kanban-simple/internal/models"
+)
+
+// Stats reports row counts, database file sizes and whether the instance is
+// read-only
+func (h *AdminHandler) Stats(c *gin.Context) {
+	stats, err := h.instanceRepo.Stats()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve instance stats")
+		return
+	}
+	stats.ReadOnly = h.readOnly.Enabled()
+	stats.GeneratedAt = time.Now()
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// Checkpoint copies the write-ahead log into the database file now, in
+// ?mode= passive, full, restart or truncate (the default, which also empties
+// the log file). A checkpoint held up by other connections is reported with
+// busy set rather than as an error.
+func (h *AdminHandler) Checkpoint(c *gin.Context) {
+	mode := c.DefaultQuery("mode", models.CheckpointTruncate)
+	if !models.CheckpointModes[mode] {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid checkpoint mode; use passive, full, restart or truncate")
+		return
+	}
+
+	result, err := h.instanceRepo.Checkpoint(mode)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to checkpoint the write-ahead log")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Metrics reports database statement durations, per operation and table,
+// and connection pool use in the Prometheus text format, for scraping with
+// the admin token
+func (h *AdminHandler) Metrics(c *gin.Context) {
+	if h.dbMetrics == nil {
+		middleware.HandleError(c, http.StatusNotImplemented, "Database metrics are not available")
+		return
+	}
+	metrics := h.dbMetrics()
+
+	var b strings.Builder
+	b.WriteString("# HELP kanban_db_statement_duration_seconds Time spent running database statements, by operation and the table named first.\n")
+	b.WriteString("# TYPE kanban_db_statement_duration_seconds histogram\n")
+	for _, s := range metrics.Statements {
+		labels := fmt.Sprintf(`operation="%s",table="%s"`, s.Operation, s.Table)
+		for i, bound := range s.Buckets {
+			fmt.Fprintf(&b, "kanban_db_statement_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(bound, 'g', -1, 64), s.Counts[i])
+		}
+		fmt.Fprintf(&b, "kanban_db_statement_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.Count)
+		fmt.Fprintf(&b, "kanban_db_statement_duration_seconds_sum{%s} %s\n", labels, seconds(s.Sum))
+		fmt.Fprintf(&b, "kanban_db_statement_duration_seconds_count{%s} %d\n", labels, s.Count)
+	}
+
+	for _, m := range []struct {
+		name, kind, help string
+		value            string
+	}{
+		{"kanban_db_slow_statements_total", "counter", "Statements that took at least the slow query threshold.", strconv.FormatInt(metrics.SlowStatements, 10)},
+		{"kanban_db_statement_timeouts_total", "counter", "Statements stopped at the query timeout.", strconv.FormatInt(metrics.TimedOut, 10)},
+		{"kanban_db_connections_open", "gauge", "Open database connections.", strconv.Itoa(metrics.OpenConnections)},
+		{"kanban_db_connections_in_use", "gauge", "Database connections running a statement or transaction.", strconv.Itoa(metrics.InUseConnections)},
+		{"kanban_db_connections_idle", "gauge", "Idle database connections.", strconv.Itoa(metrics.IdleConnections)},
+		{"kanban_db_connection_waits_total", "counter", "Times a statement waited for a free connection.", strconv.FormatInt(metrics.WaitCount, 10)},
+		{"kanban_db_connection_wait_seconds_total", "counter", "Time spent waiting for a free connection.", seconds(metrics.WaitDuration)},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+// seconds formats a duration as Prometheus seconds
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// SetReadOnly switches read-only mode without a restart
+func (h *AdminHandler) SetReadOnly(c *gin.Context) {
+	var req models.ReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	h.readOnly.Set(*req.ReadOnly)
+	c.JSON(http.StatusOK, gin.H{"read_only": h.readOnly.Enabled()})
+}
+
+// Reload rereads the configuration file and secrets and applies the
+// settings that can change without a restart, as SIGHUP does
+func (h *AdminHandler) Reload(c *gin.Context) {
+	if h.reload == nil {
+		middleware.HandleError(c, http.StatusNotImplemented, "Configuration reload is not available")
+		return
+	}
+	if err := h.reload(); err != nil {
+		middleware.HandleErrorf(c, http.StatusInternalServerError, "Failed to reload configuration: %v", err)
+		return
+	}
+
+	settings := h.runtime.Get()
+	c.JSON(http.StatusOK, gin.H{
+		"admin_enabled": settings.AdminToken != "",
+		"cors_origins":  settings.CORSOrigins,
+		"locales":       i18n.Locales(),
+	})
+}
+
+// GetUsers lists every user; ?disabled=true or false narrows the list
+func (h *AdminHandler) GetUsers(c *gin.Context) {
+	var disabled *bool
+	if raw := c.Query("disabled"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid disabled filter; use true or false")
+			return
+		}
+		disabled = &value
+	}
+
+	users, err := h.userRepo.GetAll()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve users")
+		return
+	}
+
+	if disabled != nil {
+		filtered := []models.User{}
+		for _, user := range users {
+			if user.Disabled == *disabled {
+				filtered = append(filtered, user)
+			}
+		}
+		users = filtered
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// DisableUser stops a user from acting through the API, whether by header
+// or by API token. Their comments and assignments are kept.
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	h.setUserDisabled(c, true)
+}
+
+// EnableUser lets a disabled user act again
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	h.setUserDisabled(c, false)
+}
+
+// setUserDisabled serves DisableUser and EnableUser
+func (h *AdminHandler) setUserDisabled(c *gin.Context, disabled bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.userRepo.SetDisabled(id, disabled); err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusNotFound, "User not found")
+			return
+		}
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	user, err := h.userRepo.GetByID(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetTokens lists API tokens without their secrets; ?user_id= narrows the
+// list to one user's
+func (h *AdminHandler) GetTokens(c *gin.Context) {
+	var userID *int
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = &id
+	}
+
+	tokens, err := h.tokenRepo.GetAll(userID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve API tokens")
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateToken issues an API token acting as a user. The secret is in the
+// response only.
+func (h *AdminHandler) CreateToken(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if _, err := h.userRepo.GetByID(userID); err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusNotFound, "User not found")
+			return
+		}
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		return
+	}
+
+	token, err := h.tokenRepo.Create(userID, &req)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create API token")
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// UpdateToken changes a token's name, scopes or rate limit, e.g. to throttle
+// or restrict a misbehaving bot without revoking it. Changes apply from the
+// token's next request.
+func (h *AdminHandler) UpdateToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid API token ID")
+		return
+	}
+
+	var req models.UpdateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	token, err := h.tokenRepo.Update(id, &req)
+	if err != nil {
+		if err.Error() == "api token not found" {
+			middleware.HandleError(c, http.StatusNotFound, "API token not found")
+			return
+		}
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update API token")
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// RotateToken replaces a token's secret, returning the new one. The old
+// secret stops working immediately.
+func (h *AdminHandler) RotateToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid API token ID")
+		return
+	}
+
+	token, err := h.tokenRepo.Rotate(id)
+	if err != nil {
+		if err.Error() == "api token not found" {
+			middleware.HandleError(c, http.StatusNotFound, "API token not found")
+			return
+		}
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to rotate API token")
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// RevokeToken permanently disables a token
+func (h *AdminHandler) RevokeToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid API token ID")
+		return
+	}
+
+	if err := h.tokenRepo.Revoke(id); err != nil {
+		if err.Error() == "api token not found" {
+			middleware.HandleError(c, http.StatusNotFound, "API token not found")
+			return
+		}
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to revoke API token")
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "API token revoked successfully"))
+}
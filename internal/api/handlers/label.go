@@ -5,21 +5,24 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
 	"github.com/kanban-simple/internal/models"
 	"github.com/kanban-simple/internal/repository"
 )
 
 // LabelHandler handles label-related HTTP requests
 type LabelHandler struct {
-	labelRepo *repository.LabelRepository
-	cardRepo  *repository.CardRepository
+	labelRepo  *repository.LabelRepository
+	cardRepo   *repository.CardRepository
+	memberRepo *repository.MemberRepository
 }
 
 // NewLabelHandler creates a new label handler
-func NewLabelHandler(labelRepo *repository.LabelRepository, cardRepo *repository.CardRepository) *LabelHandler {
+func NewLabelHandler(labelRepo *repository.LabelRepository, cardRepo *repository.CardRepository, memberRepo *repository.MemberRepository) *LabelHandler {
 	return &LabelHandler{
-		labelRepo: labelRepo,
-		cardRepo:  cardRepo,
+		labelRepo:  labelRepo,
+		cardRepo:   cardRepo,
+		memberRepo: memberRepo,
 	}
 }
 
@@ -43,7 +46,7 @@ func (h *LabelHandler) GetByID(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid label ID",
+			"message": localize(c, "Invalid label ID"),
 		})
 		return
 	}
@@ -53,7 +56,7 @@ func (h *LabelHandler) GetByID(c *gin.Context) {
 		if err.Error() == "label not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Not Found",
-				"message": "Label not found",
+				"message": localize(c, "Label not found"),
 			})
 			return
 		}
@@ -71,10 +74,7 @@ func (h *LabelHandler) GetByID(c *gin.Context) {
 func (h *LabelHandler) Create(c *gin.Context) {
 	var req models.CreateLabelRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Bad Request",
-			"message": err.Error(),
-		})
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -96,17 +96,14 @@ func (h *LabelHandler) Update(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid label ID",
+			"message": localize(c, "Invalid label ID"),
 		})
 		return
 	}
 
 	var req models.CreateLabelRequest // Reusing the same request struct
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Bad Request",
-			"message": err.Error(),
-		})
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -115,7 +112,7 @@ func (h *LabelHandler) Update(c *gin.Context) {
 		if err.Error() == "label not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Not Found",
-				"message": "Label not found",
+				"message": localize(c, "Label not found"),
 			})
 			return
 		}
@@ -135,7 +132,7 @@ func (h *LabelHandler) Delete(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid label ID",
+			"message": localize(c, "Invalid label ID"),
 		})
 		return
 	}
@@ -144,7 +141,7 @@ func (h *LabelHandler) Delete(c *gin.Context) {
 		if err.Error() == "label not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Not Found",
-				"message": "Label not found",
+				"message": localize(c, "Label not found"),
 			})
 			return
 		}
@@ -164,7 +161,7 @@ func (h *LabelHandler) AssignToCard(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid card ID",
+			"message": localize(c, "Invalid card ID"),
 		})
 		return
 	}
@@ -173,18 +170,18 @@ func (h *LabelHandler) AssignToCard(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid label ID",
+			"message": localize(c, "Invalid label ID"),
 		})
 		return
 	}
 
 	// Verify card exists
-	_, err = h.cardRepo.GetByID(cardID)
+	card, err := h.cardRepo.GetByID(cardID)
 	if err != nil {
 		if err.Error() == "card not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Not Found",
-				"message": "Card not found",
+				"message": localize(c, "Card not found"),
 			})
 			return
 		}
@@ -194,6 +191,9 @@ func (h *LabelHandler) AssignToCard(c *gin.Context) {
 		})
 		return
 	}
+	if cardHidden(c, card) {
+		return
+	}
 
 	// Verify label exists
 	_, err = h.labelRepo.GetByID(labelID)
@@ -201,7 +201,7 @@ func (h *LabelHandler) AssignToCard(c *gin.Context) {
 		if err.Error() == "label not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Not Found",
-				"message": "Label not found",
+				"message": localize(c, "Label not found"),
 			})
 			return
 		}
@@ -222,17 +222,131 @@ func (h *LabelHandler) AssignToCard(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Label assigned to card successfully",
+		"message": localize(c, "Label assigned to card successfully"),
+	})
+}
+
+// AssignToCards assigns a label to many cards at once
+func (h *LabelHandler) AssignToCards(c *gin.Context) {
+	labelID, req, ok := h.bindBulkRequest(c)
+	if !ok {
+		return
+	}
+
+	assigned, err := h.labelRepo.AssignToCards(labelID, req.CardIDs)
+	if err != nil {
+		if err.Error() == "card not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Not Found",
+				"message": localize(c, "Card not found"),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": localize(c, "Label assigned to cards successfully"),
+		"count":   assigned,
+	})
+}
+
+// RemoveFromCards removes a label from many cards at once
+func (h *LabelHandler) RemoveFromCards(c *gin.Context) {
+	labelID, req, ok := h.bindBulkRequest(c)
+	if !ok {
+		return
+	}
+
+	removed, err := h.labelRepo.RemoveFromCards(labelID, req.CardIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": localize(c, "Label removed from cards successfully"),
+		"count":   removed,
 	})
 }
 
+// bindBulkRequest parses the label ID and card ID list of a bulk request
+func (h *LabelHandler) bindBulkRequest(c *gin.Context) (int, *models.BulkLabelRequest, bool) {
+	labelID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": localize(c, "Invalid label ID"),
+		})
+		return 0, nil, false
+	}
+
+	var req models.BulkLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return 0, nil, false
+	}
+
+	// Verify label exists
+	if _, err := h.labelRepo.GetByID(labelID); err != nil {
+		if err.Error() == "label not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Not Found",
+				"message": localize(c, "Label not found"),
+			})
+			return 0, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": err.Error(),
+		})
+		return 0, nil, false
+	}
+
+	// Restricted cards the acting user may not see are treated as missing,
+	// and every card's board must let them edit it
+	checked := make(map[int]bool)
+	for _, cardID := range req.CardIDs {
+		if card, err := h.cardRepo.GetByID(cardID); err == nil && cardHidden(c, card) {
+			return 0, nil, false
+		}
+		boardID, err := h.memberRepo.BoardOf("cards", cardID)
+		if err != nil {
+			if err.Error() == "card not found" {
+				continue
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Internal Server Error",
+				"message": err.Error(),
+			})
+			return 0, nil, false
+		}
+		if checked[boardID] {
+			continue
+		}
+		if !middleware.RequireBoardRole(c, h.memberRepo, boardID, models.MemberRoleEditor) {
+			return 0, nil, false
+		}
+		checked[boardID] = true
+	}
+
+	return labelID, &req, true
+}
+
 // RemoveFromCard removes a label from a card
 func (h *LabelHandler) RemoveFromCard(c *gin.Context) {
 	cardID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid card ID",
+			"message": localize(c, "Invalid card ID"),
 		})
 		return
 	}
@@ -241,16 +355,20 @@ func (h *LabelHandler) RemoveFromCard(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid label ID",
+			"message": localize(c, "Invalid label ID"),
 		})
 		return
 	}
 
+	if card, err := h.cardRepo.GetByID(cardID); err == nil && cardHidden(c, card) {
+		return
+	}
+
 	if err := h.labelRepo.RemoveFromCard(cardID, labelID); err != nil {
 		if err.Error() == "label assignment not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Not Found",
-				"message": "Label assignment not found",
+				"message": localize(c, "Label assignment not found"),
 			})
 			return
 		}
@@ -270,18 +388,18 @@ func (h *LabelHandler) GetCardLabels(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Bad Request",
-			"message": "Invalid card ID",
+			"message": localize(c, "Invalid card ID"),
 		})
 		return
 	}
 
 	// Verify card exists
-	_, err = h.cardRepo.GetByID(cardID)
+	card, err := h.cardRepo.GetByID(cardID)
 	if err != nil {
 		if err.Error() == "card not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "Not Found",
-				"message": "Card not found",
+				"message": localize(c, "Card not found"),
 			})
 			return
 		}
@@ -291,6 +409,9 @@ func (h *LabelHandler) GetCardLabels(c *gin.Context) {
 		})
 		return
 	}
+	if cardHidden(c, card) {
+		return
+	}
 
 	labels, err := h.labelRepo.GetCardLabels(cardID)
 	if err != nil {
@@ -302,4 +423,4 @@ func (h *LabelHandler) GetCardLabels(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, labels)
-}
\ No newline at end of file
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
 	"github.com/kanban-simple/internal/models"
 	"github.com/kanban-simple/internal/repository"
 )
@@ -14,13 +15,21 @@ import (
 type ListHandler struct {
 	listRepo  *repository.ListRepository
 	boardRepo *repository.BoardRepository
+	labelRepo *repository.LabelRepository
+	userRepo  *repository.UserRepository
+	quotas    models.Quotas
+	bus       *events.Bus
 }
 
 // NewListHandler creates a new list handler
-func NewListHandler(listRepo *repository.ListRepository, boardRepo *repository.BoardRepository) *ListHandler {
+func NewListHandler(listRepo *repository.ListRepository, boardRepo *repository.BoardRepository, labelRepo *repository.LabelRepository, userRepo *repository.UserRepository, quotas models.Quotas, bus *events.Bus) *ListHandler {
 	return &ListHandler{
 		listRepo:  listRepo,
 		boardRepo: boardRepo,
+		labelRepo: labelRepo,
+		userRepo:  userRepo,
+		quotas:    quotas,
+		bus:       bus,
 	}
 }
 
@@ -63,7 +72,12 @@ func (h *ListHandler) GetByBoardID(c *gin.Context) {
 		return
 	}
 
-	lists, err := h.listRepo.GetByBoardID(boardID)
+	updatedAfter, ok := updatedAfterParam(c)
+	if !ok {
+		return
+	}
+
+	lists, err := h.listRepo.GetByBoardID(boardID, updatedAfter)
 	if err != nil {
 		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve lists")
 		return
@@ -92,7 +106,7 @@ func (h *ListHandler) Create(c *gin.Context) {
 
 	var req models.CreateListRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -108,11 +122,17 @@ func (h *ListHandler) Create(c *gin.Context) {
 		list.Color = "#6b7280"
 	}
 
+	if !withinListQuota(c, h.quotas, h.listRepo, boardID, 1) {
+		return
+	}
+
 	if err := h.listRepo.Create(list); err != nil {
 		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create list")
 		return
 	}
 
+	publish(c, h.bus, events.Event{Type: events.ListCreated, BoardID: list.BoardID, List: list})
+
 	c.JSON(http.StatusCreated, list)
 }
 
@@ -138,7 +158,7 @@ func (h *ListHandler) Update(c *gin.Context) {
 	// Bind update request
 	var req models.UpdateListRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -159,6 +179,8 @@ func (h *ListHandler) Update(c *gin.Context) {
 		return
 	}
 
+	publish(c, h.bus, events.Event{Type: events.ListUpdated, BoardID: list.BoardID, List: list})
+
 	c.JSON(http.StatusOK, list)
 }
 
@@ -172,7 +194,7 @@ func (h *ListHandler) Move(c *gin.Context) {
 
 	var req models.MoveListRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		middleware.HandleError(c, http.StatusBadRequest, "Invalid request body")
+		middleware.HandleBindError(c, err)
 		return
 	}
 
@@ -204,6 +226,8 @@ func (h *ListHandler) Move(c *gin.Context) {
 	}
 
 	list.Position = newPosition
+	publish(c, h.bus, events.Event{Type: events.ListUpdated, BoardID: list.BoardID, List: list})
+
 	c.JSON(http.StatusOK, list)
 }
 
@@ -215,6 +239,22 @@ func (h *ListHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	preview, err := h.listRepo.DeletePreview(id)
+	if err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to preview delete")
+		}
+		return
+	}
+	if !deleteConfirmed(c, "list", id, preview) {
+		return
+	}
+
+	// Loaded first so the event can describe what was deleted
+	list, _ := h.listRepo.GetByID(id)
+
 	if err := h.listRepo.Delete(id); err != nil {
 		if err.Error() == "list not found" {
 			middleware.HandleError(c, http.StatusNotFound, "List not found")
@@ -224,5 +264,9 @@ func (h *ListHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "List deleted successfully"})
-}
\ No newline at end of file
+	if list != nil {
+		publish(c, h.bus, events.Event{Type: events.ListDeleted, BoardID: list.BoardID, List: list})
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "List deleted successfully"))
+}
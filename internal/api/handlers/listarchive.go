@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/models"
+)
+
+// ArchiveList archives a list's cards in one go, optionally only those that
+// are ?completed=true (or false) and those not updated or moved for
+// ?older_than= (e.g. 30d or 2w)
+func (h *CardHandler) ArchiveList(c *gin.Context) {
+	listID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	var opts models.ArchiveCardsOptions
+	if v := c.Query("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid completed; use true or false")
+			return
+		}
+		opts.Completed = &completed
+	}
+	if v := c.Query("older_than"); v != "" {
+		d, err := models.ParseRelativeDuration(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid older_than; use e.g. 30d, 2w or 12h")
+			return
+		}
+		before := time.Now().Add(-d)
+		opts.UpdatedBefore = &before
+	}
+
+	list, err := h.listRepo.GetByID(listID)
+	if err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify list")
+		}
+		return
+	}
+
+	opts.ViewerID = actingUserID(c)
+	ids, err := h.cardRepo.ArchiveByList(listID, opts)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to archive cards")
+		return
+	}
+
+	for _, id := range ids {
+		if card, err := h.cardRepo.GetByID(id); err == nil {
+			h.publish(c, events.Event{Type: events.CardArchived, BoardID: list.BoardID, Card: card})
+		}
+	}
+
+	c.JSON(http.StatusOK, models.ArchiveCardsResult{
+		ListID:   listID,
+		Archived: len(ids),
+		CardIDs:  ids,
+	})
+}
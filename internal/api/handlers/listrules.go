@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/models"
+)
+
+// GetSettings retrieves the settings of a list
+func (h *ListHandler) GetSettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	list, err := h.listRepo.GetByID(id)
+	if err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve list")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, list.Settings)
+}
+
+// UpdateSettings replaces the rules of a list
+func (h *ListHandler) UpdateSettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	// Verify list exists
+	if _, err := h.listRepo.GetByID(id); err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve list")
+		}
+		return
+	}
+
+	var settings models.ListSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if settings.DueIn != "" {
+		if _, err := models.ParseRelativeDuration(settings.DueIn); err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid due_in; use e.g. 3d, 2w or 4h")
+			return
+		}
+	}
+
+	if settings.SLA != "" {
+		if _, err := models.ParseRelativeDuration(settings.SLA); err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid sla; use e.g. 3d, 2w or 4h")
+			return
+		}
+	}
+
+	for _, labelID := range settings.LabelIDs {
+		if _, err := h.labelRepo.GetByID(labelID); err != nil {
+			if err.Error() == "label not found" {
+				middleware.HandleErrorf(c, http.StatusBadRequest, "Label not found: %d", labelID)
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve labels")
+			}
+			return
+		}
+	}
+
+	notify := append(append([]int{}, settings.CapacityNotifyUserIDs...), settings.SLANotifyUserIDs...)
+	for _, userID := range notify {
+		if _, err := h.userRepo.GetByID(userID); err != nil {
+			if err.Error() == "user not found" {
+				middleware.HandleErrorf(c, http.StatusBadRequest, "User not found: %d", userID)
+			} else {
+				middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve users")
+			}
+			return
+		}
+	}
+
+	if err := h.listRepo.UpdateSettings(id, settings); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update list settings")
+		return
+	}
+
+	if list, err := h.listRepo.GetByID(id); err == nil {
+		publish(c, h.bus, events.Event{Type: events.ListUpdated, BoardID: list.BoardID, List: list})
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// listRuleLabels returns the labels a list adds to entering cards, skipping
+// any deleted since the rule was saved
+func (h *CardHandler) listRuleLabels(settings models.ListSettings) []models.Label {
+	labels, err := h.labelRepo.GetByIDs(settings.LabelIDs)
+	if err != nil {
+		return []models.Label{}
+	}
+	return labels
+}
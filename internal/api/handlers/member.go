@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// MemberHandler handles board member and invitation HTTP requests
+type MemberHandler struct {
+	repo      *repository.MemberRepository
+	boardRepo *repository.BoardRepository
+	userRepo  *repository.UserRepository
+}
+
+// NewMemberHandler creates a new member handler
+func NewMemberHandler(repo *repository.MemberRepository, boardRepo *repository.BoardRepository, userRepo *repository.UserRepository) *MemberHandler {
+	return &MemberHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetByBoardID retrieves a board's members
+func (h *MemberHandler) GetByBoardID(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.GetByBoardID(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve members")
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// Add adds an existing user to a board
+func (h *MemberHandler) Add(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddBoardMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+	if (req.UserID == nil) == (req.Username == "") {
+		middleware.HandleError(c, http.StatusBadRequest, "Give either user_id or username")
+		return
+	}
+
+	var user *models.User
+	var err error
+	if req.UserID != nil {
+		user, err = h.userRepo.GetByID(*req.UserID)
+	} else {
+		user, err = h.userRepo.GetByUsername(req.Username)
+	}
+	if err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusNotFound, "User not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		}
+		return
+	}
+
+	member, err := h.repo.Add(boardID, user.ID, memberRole(req.Role), actingUserID(c))
+	if err != nil {
+		if err.Error() == "member already exists" {
+			middleware.HandleError(c, http.StatusConflict, "User is already a member of this board")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to add member")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// Update changes a member's role
+func (h *MemberHandler) Update(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateBoardMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	member, err := h.repo.SetRole(boardID, userID, req.Role)
+	if err != nil {
+		if err.Error() == "member not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Member not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update member")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// Remove takes a user off a board
+func (h *MemberHandler) Remove(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.repo.Remove(boardID, userID); err != nil {
+		if err.Error() == "member not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Member not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to remove member")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Member removed successfully"))
+}
+
+// Access resolves each user's effective role on a board from their direct
+// membership and their groups' grants, for one user with ?user_id=
+func (h *MemberHandler) Access(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	var userID *int
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = &id
+	}
+
+	access, err := h.repo.Access(boardID, userID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board access")
+		return
+	}
+
+	c.JSON(http.StatusOK, access)
+}
+
+// GetInvitations retrieves a board's pending invitations
+func (h *MemberHandler) GetInvitations(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.repo.GetPendingInvitations(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve invitations")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// Invite creates an invite link to a board. No email is sent; the response
+// carries the token to pass on, which is not shown again.
+func (h *MemberHandler) Invite(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBoardInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = models.DefaultInvitationDays
+	}
+	expiresAt := time.Now().AddDate(0, 0, days)
+
+	invitation, err := h.repo.Invite(boardID, req.Email, memberRole(req.Role), expiresAt, actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create invitation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// RevokeInvitation withdraws a pending invitation
+func (h *MemberHandler) RevokeInvitation(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("invitation_id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	if err := h.repo.RevokeInvitation(boardID, id); err != nil {
+		if err.Error() == "invitation not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Invitation not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to revoke invitation")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Invitation revoked successfully"))
+}
+
+// AcceptInvitation makes the acting user a member of the board an
+// invitation token is for
+func (h *MemberHandler) AcceptInvitation(c *gin.Context) {
+	user := requireCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	member, err := h.repo.Accept(req.Token, user.ID)
+	if err != nil {
+		switch err.Error() {
+		case "invitation not found":
+			middleware.HandleError(c, http.StatusNotFound, "Invitation not found")
+		case "invitation already accepted":
+			middleware.HandleError(c, http.StatusGone, "Invitation has already been accepted")
+		case "invitation expired":
+			middleware.HandleError(c, http.StatusGone, "Invitation has expired")
+		default:
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to accept invitation")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// boardID resolves the :id parameter to an existing board, writing an error
+// response when it cannot
+func (h *MemberHandler) boardID(c *gin.Context) (int, bool) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return 0, false
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return 0, false
+	}
+
+	return boardID, true
+}
+
+// memberRole defaults an omitted role to editor
+func memberRole(role string) string {
+	if role == "" {
+		return models.MemberRoleEditor
+	}
+	return role
+}
+
+// actingUserID is the acting user's ID, or nil without one
+func actingUserID(c *gin.Context) *int {
+	if user := middleware.GetCurrentUser(c); user != nil {
+		return &user.ID
+	}
+	return nil
+}
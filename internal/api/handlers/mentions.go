@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/kanban-simple/internal/i18n"
+	"github.com/kanban-simple/internal/mentions"
+	"github.com/kanban-simple/internal/models"
+)
+
+// notifyMentions records @mentions found in text and notifies each newly
+// mentioned user. Failures are logged rather than failing the request.
+func (h *CardHandler) notifyMentions(card *models.Card, commentID *int, text string, actor *models.User) {
+	for _, username := range mentions.Parse(text) {
+		user, err := h.userRepo.GetByUsername(username)
+		if err != nil {
+			continue
+		}
+
+		// Don't notify people about their own mentions
+		if actor != nil && actor.ID == user.ID {
+			continue
+		}
+
+		// Nor about restricted cards they cannot see
+		if !card.VisibleTo(&user.ID) {
+			continue
+		}
+
+		mention := &models.Mention{
+			UserID:    user.ID,
+			CardID:    card.ID,
+			CommentID: commentID,
+		}
+		created, err := h.notificationRepo.AddMention(mention)
+		if err != nil {
+			log.Printf("Failed to record mention of %s: %v", username, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+
+		// Written in the recipient's language, not the actor's
+		who := i18n.Translate(user.Locale, "Someone")
+		if actor != nil {
+			who = actor.Username
+		}
+		cardID := card.ID
+		notification := &models.Notification{
+			UserID:    user.ID,
+			Type:      models.NotificationMention,
+			CardID:    &cardID,
+			CommentID: commentID,
+			Message:   i18n.Sprintf(user.Locale, "%s mentioned you on %q", who, card.Title),
+		}
+		if err := h.notificationRepo.Create(notification); err != nil {
+			log.Printf("Failed to notify %s of mention: %v", username, err)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/metrics"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// defaultMetricsDays is the range reported when no from date is given
+const defaultMetricsDays = 90
+
+// MetricsHandler handles board analytics requests
+type MetricsHandler struct {
+	cardRepo  *repository.CardRepository
+	listRepo  *repository.ListRepository
+	boardRepo *repository.BoardRepository
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(cardRepo *repository.CardRepository, listRepo *repository.ListRepository, boardRepo *repository.BoardRepository) *MetricsHandler {
+	return &MetricsHandler{
+		cardRepo:  cardRepo,
+		listRepo:  listRepo,
+		boardRepo: boardRepo,
+	}
+}
+
+// CycleTime reports how long cards take to travel from a start list to an
+// end list. Moving into any later list counts as reaching a boundary. Without
+// start_list_id the clock starts at creation (lead time); end_list_id
+// defaults to the board's last list.
+func (h *MetricsHandler) CycleTime(c *gin.Context) {
+	boardID, lists, ok := h.boardLists(c)
+	if !ok {
+		return
+	}
+	if len(lists) == 0 {
+		middleware.HandleError(c, http.StatusBadRequest, "Board has no lists")
+		return
+	}
+
+	from, to, ok := metricsRange(c)
+	if !ok {
+		return
+	}
+
+	var startList *models.List
+	if v := c.Query("start_list_id"); v != "" {
+		if startList = findList(lists, v); startList == nil {
+			middleware.HandleError(c, http.StatusBadRequest, "start_list_id is not a list on this board")
+			return
+		}
+	}
+
+	endList := &lists[len(lists)-1]
+	if v := c.Query("end_list_id"); v != "" {
+		if endList = findList(lists, v); endList == nil {
+			middleware.HandleError(c, http.StatusBadRequest, "end_list_id is not a list on this board")
+			return
+		}
+	}
+
+	if startList != nil && startList.Position > endList.Position {
+		middleware.HandleError(c, http.StatusBadRequest, "Start list must come before the end list")
+		return
+	}
+
+	var start map[int]bool
+	if startList != nil {
+		start = listsFrom(lists, startList.Position)
+	}
+	end := listsFrom(lists, endList.Position)
+
+	moves, err := h.cardRepo.GetMovesByBoard(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card history")
+		return
+	}
+
+	samples := metrics.CycleTimes(moves, start, end, from, to)
+	report := models.CycleTimeReport{
+		BoardID:   boardID,
+		EndListID: endList.ID,
+		From:      from.Format("2006-01-02"),
+		To:        to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Stats:     metrics.Summarize(samples),
+		Cards:     samples,
+	}
+	if startList != nil {
+		report.StartListID = &startList.ID
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Throughput reports how many cards, and how many points, a board finished
+// per interval. By default a card is finished when it is marked completed;
+// with list_id it is finished when it first reaches that list or a later one.
+func (h *MetricsHandler) Throughput(c *gin.Context) {
+	boardID, lists, ok := h.boardLists(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := metricsRange(c)
+	if !ok {
+		return
+	}
+
+	interval := c.DefaultQuery("interval", models.IntervalWeek)
+	switch interval {
+	case models.IntervalDay, models.IntervalWeek, models.IntervalMonth:
+	default:
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid interval; use day, week or month")
+		return
+	}
+
+	report := models.ThroughputReport{
+		BoardID:  boardID,
+		Interval: interval,
+		From:     from.Format("2006-01-02"),
+		To:       to.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+
+	var labelID int
+	if v := c.Query("label_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid label ID")
+			return
+		}
+		labelID = id
+		report.LabelID = &labelID
+	}
+
+	var doneList *models.List
+	if v := c.Query("list_id"); v != "" {
+		if doneList = findList(lists, v); doneList == nil {
+			middleware.HandleError(c, http.StatusBadRequest, "list_id is not a list on this board")
+			return
+		}
+		report.ListID = &doneList.ID
+	}
+
+	completions, err := h.cardRepo.GetCompletions(boardID, labelID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve completed cards")
+		return
+	}
+
+	if doneList != nil {
+		moves, err := h.cardRepo.GetMovesByBoard(boardID)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card history")
+			return
+		}
+		arrivals := metrics.FirstArrivals(moves, listsFrom(lists, doneList.Position))
+		for i := range completions {
+			completions[i].CompletedAt = nil
+			if at, ok := arrivals[completions[i].CardID]; ok {
+				completions[i].CompletedAt = &at
+			}
+		}
+	}
+
+	report.Intervals = metrics.Throughput(completions, interval, from, to)
+	for _, bucket := range report.Intervals {
+		report.TotalCards += bucket.Cards
+		report.TotalPoints += bucket.Points
+	}
+	if n := len(report.Intervals); n > 0 {
+		report.AverageCards = math.Round(float64(report.TotalCards)/float64(n)*100) / 100
+		report.AveragePoints = math.Round(float64(report.TotalPoints)/float64(n)*100) / 100
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// TimeInLists reports how long cards spend in each of a board's lists, to
+// find the columns where work waits. Stays are counted when they end within
+// the range; cards in a list now are reported separately.
+func (h *MetricsHandler) TimeInLists(c *gin.Context) {
+	boardID, lists, ok := h.boardLists(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := metricsRange(c)
+	if !ok {
+		return
+	}
+
+	moves, err := h.cardRepo.GetMovesByBoard(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card history")
+		return
+	}
+	current, err := h.cardRepo.GetCurrentStays(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card history")
+		return
+	}
+
+	now := time.Now()
+	report := models.TimeInListsReport{
+		BoardID: boardID,
+		From:    from.Format("2006-01-02"),
+		To:      to.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+	report.Lists, report.BottleneckListID = metrics.ListTimes(lists, metrics.ListStays(moves, now), current, from, to, now)
+
+	c.JSON(http.StatusOK, report)
+}
+
+// CardTimeInLists reports how long a card has spent in each list it has
+// been in. The current stay runs until now, or until the card was archived.
+func (h *MetricsHandler) CardTimeInLists(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	card, err := h.cardRepo.GetByID(id)
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) {
+		return
+	}
+
+	moves, err := h.cardRepo.GetMovesByCard(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card history")
+		return
+	}
+
+	until := time.Now()
+	if card.Archived && card.ArchivedAt != nil {
+		until = *card.ArchivedAt
+	}
+	report := metrics.TimeInLists(id, metrics.ListStays(moves, until))
+
+	// Name the lists; ones since deleted are left unnamed
+	for i := range report.Lists {
+		if list, err := h.listRepo.GetByID(report.Lists[i].ListID); err == nil {
+			report.Lists[i].ListName = list.Name
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// boardLists resolves the board in the path and returns its lists in order
+func (h *MetricsHandler) boardLists(c *gin.Context) (int, []models.List, bool) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return 0, nil, false
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return 0, nil, false
+	}
+
+	lists, err := h.listRepo.GetByBoardID(boardID, nil)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve lists")
+		return 0, nil, false
+	}
+
+	return boardID, lists, true
+}
+
+// metricsRange reads from/to (YYYY-MM-DD, inclusive) and returns the
+// half-open range [from, to+1 day). The default is the last 90 days.
+func metricsRange(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid to date; use YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -defaultMetricsDays)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid from date; use YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+
+	if to.Before(from) {
+		middleware.HandleError(c, http.StatusBadRequest, "to must not be before from")
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to.AddDate(0, 0, 1), true
+}
+
+// findList returns the list whose ID is the string id, or nil
+func findList(lists []models.List, id string) *models.List {
+	listID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil
+	}
+	for i := range lists {
+		if lists[i].ID == listID {
+			return &lists[i]
+		}
+	}
+	return nil
+}
+
+// listsFrom returns the IDs of lists at or after position
+func listsFrom(lists []models.List, position float64) map[int]bool {
+	ids := make(map[int]bool)
+	for _, list := range lists {
+		if list.Position >= position {
+			ids[list.ID] = true
+		}
+	}
+	return ids
+}
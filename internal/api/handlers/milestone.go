@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// MilestoneHandler handles milestone-related HTTP requests
+type MilestoneHandler struct {
+	repo      *repository.MilestoneRepository
+	boardRepo *repository.BoardRepository
+}
+
+// NewMilestoneHandler creates a new milestone handler
+func NewMilestoneHandler(repo *repository.MilestoneRepository, boardRepo *repository.BoardRepository) *MilestoneHandler {
+	return &MilestoneHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+	}
+}
+
+// GetByBoardID retrieves a board's milestones with their progress
+func (h *MilestoneHandler) GetByBoardID(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	milestones, err := h.repo.GetByBoardID(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve milestones")
+		return
+	}
+
+	c.JSON(http.StatusOK, milestones)
+}
+
+// Create creates a milestone on a board
+func (h *MilestoneHandler) Create(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	var req models.CreateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	milestone := &models.Milestone{
+		BoardID:     boardID,
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+	}
+
+	if err := h.repo.Create(milestone); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create milestone")
+		return
+	}
+
+	c.JSON(http.StatusCreated, milestone)
+}
+
+// GetByID retrieves a milestone with its progress
+func (h *MilestoneHandler) GetByID(c *gin.Context) {
+	milestone, ok := h.loadMilestone(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, milestone)
+}
+
+// Update updates a milestone's title, description or due date
+func (h *MilestoneHandler) Update(c *gin.Context) {
+	milestone, ok := h.loadMilestone(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMilestoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.Title != "" {
+		milestone.Title = req.Title
+	}
+	if req.Description != nil {
+		milestone.Description = *req.Description
+	}
+	if req.DueDate != nil {
+		milestone.DueDate = req.DueDate
+	}
+
+	if err := h.repo.Update(milestone); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update milestone")
+		return
+	}
+
+	c.JSON(http.StatusOK, milestone)
+}
+
+// Delete deletes a milestone, leaving its cards unassigned
+func (h *MilestoneHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid milestone ID")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if err.Error() == "milestone not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Milestone not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete milestone")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Milestone deleted successfully"))
+}
+
+// loadMilestone resolves the milestone in the path, writing an error response if missing
+func (h *MilestoneHandler) loadMilestone(c *gin.Context) (*models.Milestone, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid milestone ID")
+		return nil, false
+	}
+
+	milestone, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Milestone not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve milestone")
+		}
+		return nil, false
+	}
+
+	return milestone, true
+}
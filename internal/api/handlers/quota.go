@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// withinQuota reports whether adding to count stays within max, responding
+// with 422 when it does not. A zero max is no limit.
+func withinQuota(c *gin.Context, max, count, adding int, message string) bool {
+	return checkQuota(c, models.CheckQuota(max, count, adding, message))
+}
+
+// checkQuota responds with 422 to a quota error, which it reports false for
+func checkQuota(c *gin.Context, err error) bool {
+	quota, ok := err.(*models.QuotaError)
+	if !ok {
+		return true
+	}
+	middleware.HandleErrorf(c, http.StatusUnprocessableEntity, quota.Message, quota.Max)
+	return false
+}
+
+// withinBoardQuota reports whether one more board is allowed
+func withinBoardQuota(c *gin.Context, quotas models.Quotas, boardRepo *repository.BoardRepository) bool {
+	if quotas.MaxBoards == 0 {
+		return true
+	}
+	count, err := boardRepo.Count()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve boards")
+		return false
+	}
+	return withinQuota(c, quotas.MaxBoards, count, 1, models.BoardQuotaMessage)
+}
+
+// withinListQuota reports whether adding lists to an existing board is allowed
+func withinListQuota(c *gin.Context, quotas models.Quotas, listRepo *repository.ListRepository, boardID, adding int) bool {
+	if quotas.MaxListsPerBoard == 0 {
+		return true
+	}
+	count, err := listRepo.CountByBoard(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve lists")
+		return false
+	}
+	return withinQuota(c, quotas.MaxListsPerBoard, count, adding, models.ListQuotaMessage)
+}
+
+// withinCommentQuota reports whether a comment is short enough
+func withinCommentQuota(c *gin.Context, quotas models.Quotas, content string) bool {
+	return checkQuota(c, quotas.CheckComment(content))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/markdown"
+	"github.com/kanban-simple/internal/models"
+)
+
+// wantsHTML reports whether the client asked for rendered Markdown via ?render=html
+func wantsHTML(c *gin.Context) bool {
+	return c.Query("render") == "html"
+}
+
+// renderBoard fills the rendered HTML field of a board
+func renderBoard(board *models.Board) {
+	board.DescriptionHTML = markdown.Render(board.Description)
+}
+
+// renderBoards fills the rendered HTML field of each board
+func renderBoards(boards []models.Board) {
+	for i := range boards {
+		renderBoard(&boards[i])
+	}
+}
+
+// renderCard fills the rendered HTML fields of a card and its comments
+func renderCard(card *models.Card) {
+	card.DescriptionHTML = markdown.Render(card.Description)
+	renderComments(card.Comments)
+}
+
+// renderCards fills the rendered HTML fields of each card
+func renderCards(cards []models.Card) {
+	for i := range cards {
+		renderCard(&cards[i])
+	}
+}
+
+// renderComments fills the rendered HTML field of each comment
+func renderComments(comments []models.Comment) {
+	for i := range comments {
+		renderComment(&comments[i])
+	}
+}
+
+// renderComment fills the rendered HTML field of a comment
+func renderComment(comment *models.Comment) {
+	comment.ContentHTML = markdown.Render(comment.Content)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// SnippetHandler handles description snippet HTTP requests
+type SnippetHandler struct {
+	repo      *repository.SnippetRepository
+	boardRepo *repository.BoardRepository
+}
+
+// NewSnippetHandler creates a new snippet handler
+func NewSnippetHandler(repo *repository.SnippetRepository, boardRepo *repository.BoardRepository) *SnippetHandler {
+	return &SnippetHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+	}
+}
+
+// GetByBoardID retrieves a board's snippets
+func (h *SnippetHandler) GetByBoardID(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	snippets, err := h.repo.GetByBoardID(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve snippets")
+		return
+	}
+
+	c.JSON(http.StatusOK, snippets)
+}
+
+// Create creates a snippet on a board
+func (h *SnippetHandler) Create(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateSnippetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	snippet := &models.Snippet{
+		BoardID: boardID,
+		Name:    req.Name,
+		Content: req.Content,
+	}
+
+	if err := h.repo.Create(snippet); err != nil {
+		if err.Error() == "snippet name already exists" {
+			middleware.HandleError(c, http.StatusConflict, "Snippet name already exists on this board")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to create snippet")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, snippet)
+}
+
+// Expand previews the description a card created with the given snippets
+// would get
+func (h *SnippetHandler) Expand(c *gin.Context) {
+	boardID, ok := h.boardID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ExpandSnippetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	description, ok := expandSnippets(c, h.repo, boardID, req.Description, req.Snippets)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ExpandSnippetsResponse{Description: description})
+}
+
+// GetByID retrieves a snippet
+func (h *SnippetHandler) GetByID(c *gin.Context) {
+	snippet, ok := h.loadSnippet(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, snippet)
+}
+
+// Update renames a snippet or replaces its content
+func (h *SnippetHandler) Update(c *gin.Context) {
+	snippet, ok := h.loadSnippet(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSnippetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		snippet.Name = *req.Name
+	}
+	if req.Content != nil {
+		snippet.Content = *req.Content
+	}
+
+	if err := h.repo.Update(snippet); err != nil {
+		switch err.Error() {
+		case "snippet name already exists":
+			middleware.HandleError(c, http.StatusConflict, "Snippet name already exists on this board")
+		case "snippet not found":
+			middleware.HandleError(c, http.StatusNotFound, "Snippet not found")
+		default:
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update snippet")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, snippet)
+}
+
+// Delete deletes a snippet; cards created with it keep their descriptions
+func (h *SnippetHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid snippet ID")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if err.Error() == "snippet not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Snippet not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete snippet")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Snippet deleted successfully"))
+}
+
+// loadSnippet resolves the snippet in the path, writing an error response if missing
+func (h *SnippetHandler) loadSnippet(c *gin.Context) (*models.Snippet, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid snippet ID")
+		return nil, false
+	}
+
+	snippet, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "snippet not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Snippet not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve snippet")
+		}
+		return nil, false
+	}
+
+	return snippet, true
+}
+
+// boardID resolves the :id parameter to an existing board, writing an error
+// response when it cannot
+func (h *SnippetHandler) boardID(c *gin.Context) (int, bool) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return 0, false
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return 0, false
+	}
+
+	return boardID, true
+}
+
+// expandSnippets appends the board's snippets with the given names to
+// description, writing an error response when one is missing or the result
+// is longer than a card description may be
+func expandSnippets(c *gin.Context, repo *repository.SnippetRepository, boardID int, description string, names []string) (string, bool) {
+	snippets, missing, err := repo.GetByNames(boardID, names)
+	if err != nil {
+		if err.Error() == "snippet not found" {
+			middleware.HandleErrorf(c, http.StatusBadRequest, "Snippet not found: %s", missing)
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve snippets")
+		}
+		return "", false
+	}
+
+	expanded := models.ExpandSnippets(description, snippets)
+	if tooLong(expanded, models.MaxTextLength) {
+		middleware.HandleErrorf(c, http.StatusBadRequest, "Description with snippets is longer than %d characters", models.MaxTextLength)
+		return "", false
+	}
+
+	return expanded, true
+}
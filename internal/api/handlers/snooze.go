@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+)
+
+// Snooze pushes a card's due date back and records the postponement
+func (h *CardHandler) Snooze(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	var req models.SnoozeCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+	if (req.Duration == "") == (req.DueDate == nil) {
+		middleware.HandleError(c, http.StatusBadRequest, "Send either duration or due_date")
+		return
+	}
+
+	card, err := h.cardRepo.GetByID(id)
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) {
+		return
+	}
+
+	var until time.Time
+	if req.DueDate != nil {
+		until = *req.DueDate
+	} else {
+		d, err := models.ParseRelativeDuration(req.Duration)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid duration; use e.g. 3d, 2w or 4h")
+			return
+		}
+		// Overdue and undated cards are snoozed from now
+		base := time.Now()
+		if card.DueDate != nil && card.DueDate.After(base) {
+			base = *card.DueDate
+		}
+		until = base.Add(d)
+	}
+
+	if card.DueDate != nil && !until.After(*card.DueDate) {
+		middleware.HandleError(c, http.StatusBadRequest, "Snoozed due date must be after the current due date")
+		return
+	}
+
+	var userID *int
+	if user := middleware.GetCurrentUser(c); user != nil {
+		userID = &user.ID
+	}
+
+	if _, err := h.cardRepo.Snooze(id, until, userID); err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to snooze card")
+		}
+		return
+	}
+
+	card, err = h.cardRepo.GetByID(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// GetSnoozes lists a card's snooze history
+func (h *CardHandler) GetSnoozes(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid card ID")
+		return
+	}
+
+	card, err := h.cardRepo.GetByID(id)
+	if err != nil {
+		if err.Error() == "card not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Card not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve card")
+		}
+		return
+	}
+	if cardHidden(c, card) {
+		return
+	}
+
+	snoozes, err := h.cardRepo.GetSnoozes(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve snoozes")
+		return
+	}
+
+	c.JSON(http.StatusOK, snoozes)
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// SprintHandler handles sprint-related HTTP requests
+type SprintHandler struct {
+	repo      *repository.SprintRepository
+	boardRepo *repository.BoardRepository
+}
+
+// NewSprintHandler creates a new sprint handler
+func NewSprintHandler(repo *repository.SprintRepository, boardRepo *repository.BoardRepository) *SprintHandler {
+	return &SprintHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+	}
+}
+
+// GetByBoardID retrieves a board's sprints, optionally filtered by ?status=
+func (h *SprintHandler) GetByBoardID(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.SprintPlanned, models.SprintActive, models.SprintClosed:
+	default:
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid status; use planned, active or closed")
+		return
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	sprints, err := h.repo.GetByBoardID(boardID, status)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve sprints")
+		return
+	}
+
+	c.JSON(http.StatusOK, sprints)
+}
+
+// Create creates a sprint on a board
+func (h *SprintHandler) Create(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	var req models.CreateSprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	sprint := &models.Sprint{
+		BoardID:   boardID,
+		Name:      req.Name,
+		Goal:      req.Goal,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+	if !validSprintDates(c, sprint) {
+		return
+	}
+
+	if err := h.repo.Create(sprint); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create sprint")
+		return
+	}
+
+	c.JSON(http.StatusCreated, sprint)
+}
+
+// GetByID retrieves a sprint
+func (h *SprintHandler) GetByID(c *gin.Context) {
+	sprint, ok := h.loadSprint(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+// Update updates a sprint's name, goal or dates
+func (h *SprintHandler) Update(c *gin.Context) {
+	sprint, ok := h.loadSprint(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.Name != "" {
+		sprint.Name = req.Name
+	}
+	if req.Goal != nil {
+		sprint.Goal = *req.Goal
+	}
+	if req.StartDate != nil {
+		sprint.StartDate = req.StartDate
+	}
+	if req.EndDate != nil {
+		sprint.EndDate = req.EndDate
+	}
+	if !validSprintDates(c, sprint) {
+		return
+	}
+
+	if err := h.repo.Update(sprint); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+// Delete deletes a sprint, returning its cards to the backlog
+func (h *SprintHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid sprint ID")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if err.Error() == "sprint not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Sprint not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete sprint")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Sprint deleted successfully"))
+}
+
+// Start makes a planned sprint the board's active sprint
+func (h *SprintHandler) Start(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid sprint ID")
+		return
+	}
+
+	if err := h.repo.Start(id); err != nil {
+		switch err.Error() {
+		case "sprint not found":
+			middleware.HandleError(c, http.StatusNotFound, "Sprint not found")
+		case "sprint is not planned":
+			middleware.HandleError(c, http.StatusConflict, "Only planned sprints can be started")
+		case "board already has an active sprint":
+			middleware.HandleError(c, http.StatusConflict, "Board already has an active sprint")
+		default:
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to start sprint")
+		}
+		return
+	}
+
+	sprint, err := h.repo.GetByID(id)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, sprint)
+}
+
+// Close closes the active sprint, carrying unfinished cards over to the next
+// sprint (the given one, else the next planned sprint, else the backlog)
+func (h *SprintHandler) Close(c *gin.Context) {
+	sprint, ok := h.loadSprint(c)
+	if !ok {
+		return
+	}
+
+	var req models.CloseSprintRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			middleware.HandleBindError(c, err)
+			return
+		}
+	}
+
+	nextID := req.NextSprintID
+	if nextID != nil {
+		next, err := h.repo.GetByID(*nextID)
+		if err != nil || next.BoardID != sprint.BoardID {
+			middleware.HandleError(c, http.StatusBadRequest, "Next sprint not found on this board")
+			return
+		}
+		if next.Status == models.SprintClosed || next.ID == sprint.ID {
+			middleware.HandleError(c, http.StatusBadRequest, "Next sprint must be open")
+			return
+		}
+	} else {
+		planned, err := h.repo.GetByBoardID(sprint.BoardID, models.SprintPlanned)
+		if err != nil {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve sprints")
+			return
+		}
+		if len(planned) > 0 {
+			nextID = &planned[0].ID
+		}
+	}
+
+	moved, err := h.repo.Close(sprint.ID, nextID)
+	if err != nil {
+		if err.Error() == "sprint is not active" {
+			middleware.HandleError(c, http.StatusConflict, "Only the active sprint can be closed")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to close sprint")
+		}
+		return
+	}
+
+	sprint, err = h.repo.GetByID(sprint.ID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CloseSprintResult{
+		Sprint:       sprint,
+		NextSprintID: nextID,
+		MovedCards:   moved,
+	})
+}
+
+// loadSprint resolves the sprint in the path, writing an error response if missing
+func (h *SprintHandler) loadSprint(c *gin.Context) (*models.Sprint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid sprint ID")
+		return nil, false
+	}
+
+	sprint, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "sprint not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Sprint not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve sprint")
+		}
+		return nil, false
+	}
+
+	return sprint, true
+}
+
+// validSprintDates rejects a sprint ending before it starts
+func validSprintDates(c *gin.Context, sprint *models.Sprint) bool {
+	if sprint.StartDate != nil && sprint.EndDate != nil && sprint.EndDate.Before(*sprint.StartDate) {
+		middleware.HandleError(c, http.StatusBadRequest, "end_date must not be before start_date")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+)
+
+// defaultStaleDays is the window used when no days are given
+const defaultStaleDays = 30
+
+// Stale returns a board's open cards that have not been updated or moved in
+// the last ?days= days (default 30), least recently touched first
+func (h *CardHandler) Stale(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	days := defaultStaleDays
+	if v := c.Query("days"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil || days < 1 {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid days; use a whole number of at least 1")
+			return
+		}
+	}
+
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return
+	}
+
+	before := time.Now().AddDate(0, 0, -days)
+	cards, err := h.cardRepo.GetStale(boardID, before, actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve cards")
+		return
+	}
+
+	if err := h.cardRepo.LoadSummaries(cards); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve cards")
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCards(cards)
+	}
+
+	c.JSON(http.StatusOK, models.StaleCards{
+		BoardID: boardID,
+		Days:    days,
+		Before:  before,
+		Cards:   cards,
+	})
+}
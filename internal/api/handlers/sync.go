@@ -0,0 +1,683 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/i18n"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// cardUpdateFields are the sync fields saved by CardRepository.Update
+var cardUpdateFields = []string{"title", "description", "color", "priority", "start_date", "due_date", "assignee_id", "points"}
+
+// SyncHandler applies card operations queued by offline clients
+type SyncHandler struct {
+	repo  *repository.SyncRepository
+	cards *CardHandler
+}
+
+// NewSyncHandler creates a new sync handler; operations change cards the
+// way the card handler does
+func NewSyncHandler(repo *repository.SyncRepository, cards *CardHandler) *SyncHandler {
+	return &SyncHandler{
+		repo:  repo,
+		cards: cards,
+	}
+}
+
+// syncBase records that a client's operations based on revision from have
+// brought a card to revision to, so later operations in the batch based on
+// the same revision are not taken for conflicts with the client's own edits
+type syncBase struct {
+	from, to int
+}
+
+// Sync applies a batch of card operations in order and reports what became
+// of each. An update based on an older revision is merged when the server
+// changed other fields since, and reported as a conflict without being
+// applied when the server changed the same fields to something else.
+func (h *SyncHandler) Sync(c *gin.Context) {
+	var req models.SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	bases := make(map[int]syncBase)
+	results := make([]models.SyncResult, 0, len(req.Ops))
+	for _, op := range req.Ops {
+		result, ok := h.apply(c, op, bases)
+		if !ok {
+			return
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, models.SyncResponse{Results: results})
+}
+
+// apply applies one operation, or replays it when the acting user applied
+// it before under the same ID. It writes an error response and returns false
+// on failure; the client resends the batch, and operations applied so far
+// are replayed.
+func (h *SyncHandler) apply(c *gin.Context, op models.SyncOp, bases map[int]syncBase) (models.SyncResult, bool) {
+	done, err := h.repo.GetOp(actingUserID(c), op.OpID)
+	if err == nil {
+		if done.PayloadHash != "" && done.PayloadHash != opHash(op) {
+			return rejected(op, syncMessage(c, "op_id %s was already used for a different operation", op.OpID)), true
+		}
+		return h.replay(c, done, bases)
+	}
+	if err.Error() != "sync op not found" {
+		return syncFailed(c)
+	}
+
+	switch op.Type {
+	case models.SyncCreate:
+		return h.create(c, op, bases)
+	case models.SyncUpdate:
+		return h.update(c, op, bases)
+	default:
+		return h.remove(c, op, bases)
+	}
+}
+
+// replay reports an operation applied by an earlier request with the card
+// as it is now. The card goes through the checks a new operation on it
+// would, as access may have changed since.
+func (h *SyncHandler) replay(c *gin.Context, done *models.SyncedOp, bases map[int]syncBase) (models.SyncResult, bool) {
+	result := models.SyncResult{OpID: done.OpID, Status: done.Status, Replayed: true}
+	if done.Type == models.SyncDelete {
+		return result, true
+	}
+
+	card, err := h.cards.cardRepo.GetByID(done.CardID)
+	if err != nil {
+		if err.Error() != "card not found" {
+			return syncFailed(c)
+		}
+		return result, true
+	}
+	visible, message, ok := h.access(c, card)
+	if !ok {
+		return models.SyncResult{}, false
+	}
+	if message != "" {
+		return models.SyncResult{OpID: done.OpID, Status: models.SyncRejected, Message: message}, true
+	}
+	if visible {
+		result.Card = card
+		bases[card.ID] = syncBase{from: done.BaseRevision, to: done.Revision}
+	}
+	return result, true
+}
+
+// create creates a card on the list given by the list_id field
+func (h *SyncHandler) create(c *gin.Context, op models.SyncOp, bases map[int]syncBase) (models.SyncResult, bool) {
+	card := &models.Card{}
+	message, err := h.decodeFields(c, op.Fields, card)
+	if err != nil {
+		return syncFailed(c)
+	}
+	if message != "" {
+		return rejected(op, message), true
+	}
+	if card.Title == "" {
+		return rejected(op, syncMessage(c, "%s is required", "title")), true
+	}
+	if card.ListID == 0 {
+		return rejected(op, syncMessage(c, "%s is required", "list_id")), true
+	}
+
+	list, err := h.cards.listRepo.GetByID(card.ListID)
+	if err != nil {
+		if err.Error() == "list not found" {
+			return rejected(op, syncMessage(c, "List not found")), true
+		}
+		return syncFailed(c)
+	}
+	if allowed, err := middleware.BoardAllows(c, h.cards.memberRepo, list.BoardID, models.MemberRoleEditor); err != nil {
+		return syncFailed(c)
+	} else if !allowed {
+		return rejected(op, syncMessage(c, "Changing this board needs the editor role")), true
+	}
+	if max := h.cards.quotas.MaxCardsPerList; max != 0 && list.CardCount+1 > max {
+		return rejected(op, syncMessage(c, models.CardQuotaMessage, max)), true
+	}
+
+	// Completion and archiving are applied after creation, as they would be
+	// through their own endpoints
+	completed, archived := card.Completed, card.Archived
+	card.Completed, card.Archived = false, false
+	if !h.cards.insertCard(c, list, card, card.DueDate != nil) {
+		return models.SyncResult{}, false
+	}
+	if !h.saveFlags(c, card, completed, archived) {
+		return models.SyncResult{}, false
+	}
+
+	return h.applied(c, op, card.ID, 0, models.SyncApplied, bases)
+}
+
+// update changes the fields given, unless the server changed any of them
+// differently since the base revision
+func (h *SyncHandler) update(c *gin.Context, op models.SyncOp, bases map[int]syncBase) (models.SyncResult, bool) {
+	card, base, message, ok := h.resolveCard(c, op, bases)
+	if !ok {
+		return models.SyncResult{}, false
+	}
+	if message != "" {
+		return rejected(op, message), true
+	}
+	if card == nil {
+		return models.SyncResult{OpID: op.OpID, Status: models.SyncConflict, Message: syncMessage(c, "Card has been deleted")}, true
+	}
+
+	next := *card
+	message, err := h.decodeFields(c, op.Fields, &next)
+	if err != nil {
+		return syncFailed(c)
+	}
+	if message != "" {
+		return rejected(op, message), true
+	}
+
+	conflicts, ok := h.conflicts(c, card, &next, op.Fields, base)
+	if !ok {
+		return models.SyncResult{}, false
+	}
+	if len(conflicts) > 0 {
+		return models.SyncResult{
+			OpID:      op.OpID,
+			Status:    models.SyncConflict,
+			Card:      card,
+			Conflicts: conflicts,
+			Message:   syncMessage(c, "The card was changed on the server; resolve the conflicts and resend with the current revision"),
+		}, true
+	}
+
+	var target *models.List
+	if next.ListID != card.ListID {
+		if target, message, ok = h.checkMove(c, card, &next, op.Fields); !ok {
+			return models.SyncResult{}, false
+		}
+		if message != "" {
+			return rejected(op, message), true
+		}
+	}
+
+	if !h.save(c, card, &next, op.Fields, target) {
+		return models.SyncResult{}, false
+	}
+
+	status := models.SyncApplied
+	if base < card.Revision {
+		status = models.SyncMerged
+	}
+	return h.applied(c, op, card.ID, op.BaseRevision, status, bases)
+}
+
+// remove deletes a card, unless the server changed it since the base
+// revision. A card that is already gone counts as deleted.
+func (h *SyncHandler) remove(c *gin.Context, op models.SyncOp, bases map[int]syncBase) (models.SyncResult, bool) {
+	card, base, message, ok := h.resolveCard(c, op, bases)
+	if !ok {
+		return models.SyncResult{}, false
+	}
+	if message != "" {
+		return rejected(op, message), true
+	}
+	if card == nil {
+		return models.SyncResult{OpID: op.OpID, Status: models.SyncApplied, Message: syncMessage(c, "Card was already deleted")}, true
+	}
+
+	if base < card.Revision {
+		changed, complete, err := h.repo.ChangedFields(card.ID, base, card.Revision)
+		if err != nil {
+			return syncFailed(c)
+		}
+		conflicts := []string{}
+		for _, field := range changed {
+			if field != "position" {
+				conflicts = append(conflicts, field)
+			}
+		}
+		if len(conflicts) > 0 || !complete {
+			return models.SyncResult{
+				OpID:      op.OpID,
+				Status:    models.SyncConflict,
+				Card:      card,
+				Conflicts: conflicts,
+				Message:   syncMessage(c, "The card was changed on the server; resend with the current revision to delete it anyway"),
+			}, true
+		}
+	}
+
+	if err := h.cards.cardRepo.Delete(card.ID); err != nil && err.Error() != "card not found" {
+		return syncFailed(c)
+	}
+	h.cards.publish(c, events.Event{Type: events.CardDeleted, Card: card})
+
+	if err := h.repo.SaveOp(actingUserID(c), &models.SyncedOp{
+		OpID: op.OpID, Type: op.Type, Status: models.SyncApplied,
+		CardID: card.ID, BaseRevision: op.BaseRevision, Revision: card.Revision,
+		PayloadHash: opHash(op),
+	}); err != nil {
+		return syncFailed(c)
+	}
+	delete(bases, card.ID)
+
+	return models.SyncResult{OpID: op.OpID, Status: models.SyncApplied}, true
+}
+
+// resolveCard loads the card an update or delete names and the revision to
+// compare against. A nil card means it has been deleted; a message means
+// the operation is rejected. It writes an error response and returns false
+// on failure.
+func (h *SyncHandler) resolveCard(c *gin.Context, op models.SyncOp, bases map[int]syncBase) (*models.Card, int, string, bool) {
+	cardID, base := op.CardID, op.BaseRevision
+	if op.CardRef != "" {
+		created, err := h.repo.GetOp(actingUserID(c), op.CardRef)
+		if err != nil {
+			if err.Error() != "sync op not found" {
+				syncFailed(c)
+				return nil, 0, "", false
+			}
+			return nil, 0, syncMessage(c, "card_ref must be the op_id of an applied create operation"), true
+		}
+		if created.Type != models.SyncCreate {
+			return nil, 0, syncMessage(c, "card_ref must be the op_id of an applied create operation"), true
+		}
+		cardID = created.CardID
+		if base == 0 {
+			base = created.Revision
+		}
+	}
+	if cardID == 0 {
+		return nil, 0, syncMessage(c, "card_id or card_ref is required"), true
+	}
+
+	// The client's own earlier operations are not changes it missed
+	if applied, ok := bases[cardID]; ok && applied.from == op.BaseRevision {
+		base = applied.to
+	}
+	if base == 0 {
+		return nil, 0, syncMessage(c, "%s is required", "base_revision"), true
+	}
+
+	card, err := h.cards.cardRepo.GetByID(cardID)
+	if err != nil {
+		if err.Error() == "card not found" {
+			return nil, base, "", true
+		}
+		syncFailed(c)
+		return nil, 0, "", false
+	}
+	visible, message, ok := h.access(c, card)
+	if !ok {
+		return nil, 0, "", false
+	}
+	if message != "" {
+		return nil, 0, message, true
+	}
+	if !visible {
+		return nil, base, "", true
+	}
+	if base > card.Revision {
+		return nil, 0, syncMessage(c, "base_revision is ahead of the card's revision %d", card.Revision), true
+	}
+
+	return card, base, "", true
+}
+
+// access checks the acting user may change a card: restricted cards they
+// may not see are treated as missing, and a message means the operation is
+// rejected. It writes an error response and returns false on failure.
+func (h *SyncHandler) access(c *gin.Context, card *models.Card) (bool, string, bool) {
+	if !card.VisibleTo(actingUserID(c)) {
+		return false, "", true
+	}
+	boardID, err := h.cards.memberRepo.BoardOf("cards", card.ID)
+	if err != nil {
+		syncFailed(c)
+		return false, "", false
+	}
+	if allowed, err := middleware.BoardAllows(c, h.cards.memberRepo, boardID, models.MemberRoleEditor); err != nil {
+		syncFailed(c)
+		return false, "", false
+	} else if !allowed {
+		return false, syncMessage(c, "Changing this board needs the editor role"), true
+	}
+	return true, "", true
+}
+
+// conflicts lists the fields an update sets that the server changed since
+// base to a different value. Without the complete history every field the
+// update sets counts as changed. Positions never conflict; the last move
+// wins. It writes an error response and returns false on failure.
+func (h *SyncHandler) conflicts(c *gin.Context, card, next *models.Card, fields map[string]json.RawMessage, base int) ([]string, bool) {
+	conflicts := []string{}
+	if base == card.Revision {
+		return conflicts, true
+	}
+
+	changed, complete, err := h.repo.ChangedFields(card.ID, base, card.Revision)
+	if err != nil {
+		syncFailed(c)
+		return nil, false
+	}
+	serverChanged := make(map[string]bool, len(changed))
+	for _, field := range changed {
+		serverChanged[field] = true
+	}
+
+	for _, field := range sortedFields(fields) {
+		if field == "position" || (complete && !serverChanged[field]) {
+			continue
+		}
+		if !sameField(card, next, field) {
+			conflicts = append(conflicts, field)
+		}
+	}
+	return conflicts, true
+}
+
+// checkMove checks that an update moving a card to another list keeps it
+// on its board, within the list's quota and with a position. A message means
+// the operation is rejected. It writes an error response and returns false
+// on failure.
+func (h *SyncHandler) checkMove(c *gin.Context, card, next *models.Card, fields map[string]json.RawMessage) (*models.List, string, bool) {
+	if _, ok := fields["position"]; !ok {
+		return nil, syncMessage(c, "position is required when list_id changes"), true
+	}
+
+	target, err := h.cards.listRepo.GetByID(next.ListID)
+	if err != nil {
+		if err.Error() == "list not found" {
+			return nil, syncMessage(c, "List not found"), true
+		}
+		syncFailed(c)
+		return nil, "", false
+	}
+	current, err := h.cards.listRepo.GetByID(card.ListID)
+	if err != nil {
+		syncFailed(c)
+		return nil, "", false
+	}
+	if target.BoardID != current.BoardID {
+		return nil, syncMessage(c, "Sync moves cards within their board only"), true
+	}
+	if max := h.cards.quotas.MaxCardsPerList; max != 0 && !next.Archived && target.CardCount+1 > max {
+		return nil, syncMessage(c, models.CardQuotaMessage, max), true
+	}
+
+	return target, "", true
+}
+
+// save writes an update's changes the way the card endpoints do, publishing
+// the same events: fields first, then completion, archiving and the move,
+// applying the rules of a list the card enters. It writes an error response
+// and returns false on failure.
+func (h *SyncHandler) save(c *gin.Context, card, next *models.Card, fields map[string]json.RawMessage, target *models.List) bool {
+	for _, field := range cardUpdateFields {
+		if _, ok := fields[field]; ok {
+			if err := h.cards.cardRepo.Update(next); err != nil {
+				syncFailed(c)
+				return false
+			}
+			h.cards.publishCard(c, events.CardUpdated, card.ID)
+			break
+		}
+	}
+
+	if next.Completed != card.Completed || next.Archived != card.Archived {
+		if !h.saveFlags(c, card, next.Completed, next.Archived) {
+			return false
+		}
+	}
+
+	if next.ListID == card.ListID && next.Position == card.Position {
+		return true
+	}
+	if err := h.cards.cardRepo.Move(card.ID, next.ListID, next.Position, models.CardMoveOptions{UserID: actingUserID(c)}); err != nil {
+		syncFailed(c)
+		return false
+	}
+	moved, err := h.cards.cardRepo.GetByID(card.ID)
+	if err != nil {
+		syncFailed(c)
+		return false
+	}
+	if target != nil && !h.cards.enterList(c, moved, target) {
+		return false
+	}
+	h.cards.publish(c, events.Event{Type: events.CardMoved, Card: moved, FromListID: card.ListID})
+	return true
+}
+
+// saveFlags completes or archives a card, or undoes either, when that
+// changes it. It writes an error response and returns false on failure.
+func (h *SyncHandler) saveFlags(c *gin.Context, card *models.Card, completed, archived bool) bool {
+	if completed != card.Completed {
+		unblocked, err := h.cards.cardRepo.Complete(card.ID, completed)
+		if err != nil {
+			syncFailed(c)
+			return false
+		}
+		eventType := events.CardCompleted
+		if !completed {
+			eventType = events.CardUncompleted
+		}
+		h.cards.publishCard(c, eventType, card.ID)
+		h.cards.publishUnblocked(c, unblocked)
+	}
+
+	if archived != card.Archived {
+		if err := h.cards.cardRepo.Archive(card.ID, archived); err != nil {
+			syncFailed(c)
+			return false
+		}
+		eventType := events.CardArchived
+		if !archived {
+			eventType = events.CardUnarchived
+		}
+		h.cards.publishCard(c, eventType, card.ID)
+	}
+	return true
+}
+
+// applied remembers an applied operation for replays and reports it with
+// the card as it is now
+func (h *SyncHandler) applied(c *gin.Context, op models.SyncOp, cardID, baseRevision int, status string, bases map[int]syncBase) (models.SyncResult, bool) {
+	card, err := h.cards.cardRepo.GetByID(cardID)
+	if err != nil {
+		return syncFailed(c)
+	}
+
+	if err := h.repo.SaveOp(actingUserID(c), &models.SyncedOp{
+		OpID: op.OpID, Type: op.Type, Status: status,
+		CardID: cardID, BaseRevision: baseRevision, Revision: card.Revision,
+		PayloadHash: opHash(op),
+	}); err != nil {
+		return syncFailed(c)
+	}
+	bases[cardID] = syncBase{from: baseRevision, to: card.Revision}
+
+	return models.SyncResult{OpID: op.OpID, Status: status, Card: card}, true
+}
+
+// decodeFields sets the fields of an operation on card, checking their
+// values as the card endpoints do; null clears a field that can be empty.
+// Optional fields are decoded into new values, as card may be a copy
+// sharing them with the stored card. The message returned, translated,
+// says why the fields are rejected.
+func (h *SyncHandler) decodeFields(c *gin.Context, fields map[string]json.RawMessage, card *models.Card) (string, error) {
+	for _, field := range sortedFields(fields) {
+		if !models.SyncFields[field] {
+			return syncMessage(c, "Unknown field: %s", field), nil
+		}
+
+		raw := fields[field]
+		null := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		valid := true
+		switch field {
+		case "title":
+			valid = !null && json.Unmarshal(raw, &card.Title) == nil && models.IsValidCardTitle(card.Title)
+		case "description":
+			valid = decodeText(raw, &card.Description) && models.FitsText(card.Description)
+		case "color":
+			valid = decodeText(raw, &card.Color) && (card.Color == "" || models.IsValidColor(card.Color))
+		case "priority":
+			valid = decodeText(raw, &card.Priority)
+			switch card.Priority {
+			case "", models.PriorityLow, models.PriorityMedium, models.PriorityHigh, models.PriorityUrgent:
+			default:
+				valid = false
+			}
+		case "start_date":
+			card.StartDate = nil
+			valid = json.Unmarshal(raw, &card.StartDate) == nil
+		case "due_date":
+			card.DueDate = nil
+			valid = json.Unmarshal(raw, &card.DueDate) == nil
+		case "assignee_id":
+			card.AssigneeID = nil
+			valid = json.Unmarshal(raw, &card.AssigneeID) == nil
+			if valid && card.AssigneeID != nil && *card.AssigneeID == 0 {
+				card.AssigneeID = nil
+			}
+			if valid && card.AssigneeID != nil {
+				if _, err := h.cards.userRepo.GetByID(*card.AssigneeID); err != nil {
+					if err.Error() != "user not found" {
+						return "", err
+					}
+					return syncMessage(c, "Assignee not found"), nil
+				}
+			}
+		case "points":
+			card.Points = nil
+			valid = json.Unmarshal(raw, &card.Points) == nil && (card.Points == nil || (*card.Points >= 0 && *card.Points <= 1000))
+		case "completed":
+			valid = !null && json.Unmarshal(raw, &card.Completed) == nil
+		case "archived":
+			valid = !null && json.Unmarshal(raw, &card.Archived) == nil
+		case "list_id":
+			valid = !null && json.Unmarshal(raw, &card.ListID) == nil && card.ListID > 0
+		case "position":
+			valid = !null && json.Unmarshal(raw, &card.Position) == nil
+		}
+		if !valid {
+			return syncMessage(c, "Invalid value for %s", field), nil
+		}
+	}
+
+	if card.StartDate != nil && card.DueDate != nil && card.StartDate.After(*card.DueDate) {
+		return syncMessage(c, "start_date must not be after due_date"), nil
+	}
+	return "", nil
+}
+
+// decodeText decodes a string field, null decoding as empty
+func decodeText(raw json.RawMessage, s *string) bool {
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return false
+	}
+	*s = ""
+	if value != nil {
+		*s = *value
+	}
+	return true
+}
+
+// sameField reports whether a sync field has the same value on both cards
+func sameField(a, b *models.Card, field string) bool {
+	switch field {
+	case "title":
+		return a.Title == b.Title
+	case "description":
+		return a.Description == b.Description
+	case "color":
+		return a.Color == b.Color
+	case "priority":
+		return a.Priority == b.Priority
+	case "start_date":
+		return sameTime(a.StartDate, b.StartDate)
+	case "due_date":
+		return sameTime(a.DueDate, b.DueDate)
+	case "assignee_id":
+		return sameInt(a.AssigneeID, b.AssigneeID)
+	case "points":
+		return sameInt(a.Points, b.Points)
+	case "completed":
+		return a.Completed == b.Completed
+	case "archived":
+		return a.Archived == b.Archived
+	case "list_id":
+		return a.ListID == b.ListID
+	case "position":
+		return a.Position == b.Position
+	}
+	return false
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// sameInt reports whether two optional numbers are both unset or equal
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// sortedFields returns the names of an operation's fields in order
+func sortedFields(fields map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// opHash fingerprints an operation, so a resend can be told from another
+// operation reusing its op_id. Field values are compacted by json.Marshal
+// and map keys sorted, so formatting does not change the hash.
+func opHash(op models.SyncOp) string {
+	data, _ := json.Marshal(op)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// rejected reports an operation that was not applied because it is invalid
+func rejected(op models.SyncOp, message string) models.SyncResult {
+	return models.SyncResult{OpID: op.OpID, Status: models.SyncRejected, Message: message}
+}
+
+// syncMessage translates a message reported for one operation
+func syncMessage(c *gin.Context, format string, args ...interface{}) string {
+	return i18n.Sprintf(middleware.GetLocale(c), format, args...)
+}
+
+// syncFailed responds with 500 when an operation could not be applied
+func syncFailed(c *gin.Context) (models.SyncResult, bool) {
+	middleware.HandleError(c, http.StatusInternalServerError, "Failed to apply sync operations")
+	return models.SyncResult{}, false
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+)
+
+// Theme returns the theme the web UI is drawn with: the configured one,
+// with any fields set through the admin API in their place. It needs no
+// admin token, since the UI loads it before anyone signs in.
+func (h *AdminHandler) Theme(c *gin.Context) {
+	theme, err := h.theme()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve theme")
+		return
+	}
+
+	c.JSON(http.StatusOK, theme)
+}
+
+// UpdateTheme sets parts of the theme over the configured one; fields given
+// as empty strings go back to the configured value
+func (h *AdminHandler) UpdateTheme(c *gin.Context) {
+	var req models.UpdateThemeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if _, err := h.instanceRepo.SetTheme(&req); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update theme")
+		return
+	}
+
+	h.Theme(c)
+}
+
+// ResetTheme clears the theme set through the admin API, going back to the
+// configured one
+func (h *AdminHandler) ResetTheme(c *gin.Context) {
+	if err := h.instanceRepo.ResetTheme(); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update theme")
+		return
+	}
+
+	h.Theme(c)
+}
+
+// theme merges the default, configured and admin-set themes
+func (h *AdminHandler) theme() (models.Theme, error) {
+	set, err := h.instanceRepo.GetTheme()
+	if err != nil {
+		return models.Theme{}, err
+	}
+	return models.DefaultTheme.Merge(h.runtime.Get().Theme).Merge(set), nil
+}
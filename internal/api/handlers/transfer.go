@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// TransferHandler handles moving boards between instances
+type TransferHandler struct {
+	repo      *repository.TransferRepository
+	boardRepo *repository.BoardRepository
+	changes   *repository.ChangeRepository
+	quotas    models.Quotas
+	bus       *events.Bus
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(repo *repository.TransferRepository, boardRepo *repository.BoardRepository, changes *repository.ChangeRepository, quotas models.Quotas, bus *events.Bus) *TransferHandler {
+	return &TransferHandler{repo: repo, boardRepo: boardRepo, changes: changes, quotas: quotas, bus: bus}
+}
+
+// Export returns a board in the native JSON format accepted by ImportNative.
+// Every export carries seq, the change log position it was taken at; with
+// ?since= set to the seq of an earlier export, only what changed after it is
+// exported, with tombstones for what was removed.
+func (h *TransferHandler) Export(c *gin.Context) {
+	boardID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	// Read before exporting, so changes made while exporting are exported
+	// again next time rather than missed
+	latest, err := h.changes.Latest()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to export board")
+		return
+	}
+
+	var since *int
+	if v := c.Query("since"); v != "" {
+		parsed, ok := parseSince(c, h.changes, v, latest)
+		if !ok {
+			return
+		}
+		since = &parsed
+	}
+
+	export, err := h.repo.Export(boardID, since, actingUserID(c))
+	if err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to export board")
+		}
+		return
+	}
+
+	export.Seq = latest
+	c.JSON(http.StatusOK, export)
+}
+
+// ImportNative recreates a board from a native export with new IDs. With
+// ?dry_run=true nothing is written; the response reports validation errors
+// or what the import would create, conflict with and skip.
+func (h *TransferHandler) ImportNative(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	var export models.BoardExport
+	if err := c.ShouldBindJSON(&export); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if problems := validateExport(&export); len(problems) > 0 {
+		if dryRun {
+			c.JSON(http.StatusOK, models.ImportResult{
+				DryRun:    true,
+				Errors:    problems,
+				Conflicts: []string{},
+				Skipped:   []string{},
+			})
+			return
+		}
+		middleware.HandleError(c, http.StatusBadRequest, problems[0])
+		return
+	}
+
+	if !h.withinImportQuotas(c, &export) {
+		return
+	}
+
+	result, err := h.repo.Import(&export, dryRun, actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to import board")
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	if board, err := h.boardRepo.GetByID(result.BoardID); err == nil {
+		publish(c, h.bus, events.Event{Type: events.BoardCreated, BoardID: board.ID, Board: board})
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// validateExport checks an export document, returning every problem that
+// prevents it from being imported
+func validateExport(export *models.BoardExport) []string {
+	var problems []string
+	if export.Format != models.ExportFormat {
+		// Nothing else can be checked against an unknown format
+		return []string{fmt.Sprintf("Unsupported format; expected %q", models.ExportFormat)}
+	}
+	if export.Version < 1 || export.Version > models.ExportVersion {
+		return []string{fmt.Sprintf("Unsupported export version %d", export.Version)}
+	}
+	if export.Since != nil {
+		// Incremental exports lack everything that did not change
+		return []string{"Incremental exports cannot be imported; import a full export"}
+	}
+	if export.Board.Name == "" {
+		problems = append(problems, "Board name is required")
+	}
+	if tooLong(export.Board.Name, models.MaxNameLength) || tooLong(export.Board.Description, models.MaxBoardDescriptionLength) {
+		problems = append(problems, "Board name or description is too long")
+	}
+	if export.Board.Icon != "" && !models.IsValidIcon(export.Board.Icon) {
+		problems = append(problems, fmt.Sprintf("Board icon %q is neither an emoji nor an icon name", export.Board.Icon))
+	}
+	if export.Board.KeyPrefix != "" && !models.IsValidKeyPrefix(export.Board.KeyPrefix) {
+		problems = append(problems, fmt.Sprintf("Board key prefix %q is invalid", export.Board.KeyPrefix))
+	}
+
+	active := 0
+	for i, sprint := range export.Sprints {
+		if sprint.Status == models.SprintActive {
+			active++
+		}
+		if sprint.Name == "" {
+			problems = append(problems, fmt.Sprintf("Sprint %d has no name", i+1))
+		}
+		switch sprint.Status {
+		case models.SprintPlanned, models.SprintActive, models.SprintClosed:
+		default:
+			problems = append(problems, fmt.Sprintf("Sprint %q has invalid status %q", sprint.Name, sprint.Status))
+		}
+	}
+	if active > 1 {
+		problems = append(problems, "A board can have only one active sprint")
+	}
+	for i, milestone := range export.Milestones {
+		if milestone.Title == "" {
+			problems = append(problems, fmt.Sprintf("Milestone %d has no title", i+1))
+		}
+	}
+
+	for i, list := range export.Lists {
+		if list.Name == "" {
+			problems = append(problems, fmt.Sprintf("List %d has no name", i+1))
+		}
+		if tooLong(list.Name, models.MaxNameLength) {
+			problems = append(problems, fmt.Sprintf("List %d has a name longer than %d characters", i+1, models.MaxNameLength))
+		}
+		if rules := list.Settings; rules != nil {
+			if rules.DefaultCardColor != "" && !models.IsValidColor(rules.DefaultCardColor) {
+				problems = append(problems, fmt.Sprintf("List %q has invalid default card color %q", list.Name, rules.DefaultCardColor))
+			}
+			if _, err := models.ParseRelativeDuration(rules.DueIn); rules.DueIn != "" && err != nil {
+				problems = append(problems, fmt.Sprintf("List %q has invalid due_in %q", list.Name, rules.DueIn))
+			}
+			if _, err := models.ParseRelativeDuration(rules.SLA); rules.SLA != "" && err != nil {
+				problems = append(problems, fmt.Sprintf("List %q has invalid sla %q", list.Name, rules.SLA))
+			}
+			if rules.Capacity < 0 {
+				problems = append(problems, fmt.Sprintf("List %q has negative capacity %d", list.Name, rules.Capacity))
+			}
+		}
+		for j, card := range list.Cards {
+			if card.Title == "" {
+				problems = append(problems, fmt.Sprintf("Card %d in list %q has no title", j+1, list.Name))
+			}
+			if tooLong(card.Title, models.MaxNameLength) || tooLong(card.Description, models.MaxTextLength) {
+				problems = append(problems, fmt.Sprintf("Card %d in list %q has a title or description that is too long", j+1, list.Name))
+			}
+			for _, comment := range card.Comments {
+				if tooLong(comment.Content, models.MaxTextLength) {
+					problems = append(problems, fmt.Sprintf("Card %d in list %q has a comment longer than %d characters", j+1, list.Name, models.MaxTextLength))
+					break
+				}
+			}
+			switch card.Visibility {
+			case "", models.CardVisibilityBoard, models.CardVisibilityRestricted:
+			default:
+				problems = append(problems, fmt.Sprintf("Card %q has invalid visibility %q", card.Title, card.Visibility))
+			}
+			switch card.Priority {
+			case "", models.PriorityLow, models.PriorityMedium, models.PriorityHigh, models.PriorityUrgent:
+			default:
+				problems = append(problems, fmt.Sprintf("Card %q has invalid priority %q", card.Title, card.Priority))
+			}
+			if card.StartDate != nil && card.DueDate != nil && card.StartDate.After(*card.DueDate) {
+				problems = append(problems, fmt.Sprintf("Card %q starts after it is due", card.Title))
+			}
+		}
+	}
+
+	return problems
+}
+
+// withinImportQuotas reports whether the board an export would create fits
+// the instance's quotas, responding with 422 when it does not. Comments
+// over the length limit are rejected rather than truncated.
+func (h *TransferHandler) withinImportQuotas(c *gin.Context, export *models.BoardExport) bool {
+	if !withinBoardQuota(c, h.quotas, h.boardRepo) ||
+		!withinQuota(c, h.quotas.MaxListsPerBoard, 0, len(export.Lists), models.ListQuotaMessage) {
+		return false
+	}
+
+	for _, list := range export.Lists {
+		unarchived := 0
+		for _, card := range list.Cards {
+			if !card.Archived {
+				unarchived++
+			}
+			for _, comment := range card.Comments {
+				if !withinCommentQuota(c, h.quotas, comment.Content) {
+					return false
+				}
+			}
+		}
+		if !checkQuota(c, h.quotas.CheckCards(0, unarchived)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tooLong reports whether s has more than max characters
+func tooLong(s string, max int) bool {
+	return utf8.RuneCountInString(s) > max
+}
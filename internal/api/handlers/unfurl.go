@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/markdown"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/unfurl"
+)
+
+// linkText is a card description or comment whose link previews are
+// loaded into unfurls
+type linkText struct {
+	text    string
+	unfurls *[]models.Unfurl
+}
+
+// cardLinkTexts returns the description of a card and its loaded comments
+func cardLinkTexts(card *models.Card) []linkText {
+	return append([]linkText{{card.Description, &card.Unfurls}}, commentLinkTexts(card.Comments)...)
+}
+
+// commentLinkTexts returns the contents of comments
+func commentLinkTexts(comments []models.Comment) []linkText {
+	texts := make([]linkText, len(comments))
+	for i := range comments {
+		texts[i] = linkText{comments[i].Content, &comments[i].Unfurls}
+	}
+	return texts
+}
+
+// loadUnfurls fills in the previews of the links in texts with one query.
+// Links not fetched yet, or due to be fetched again, are queued for the
+// unfurler and show up on a later read; links that failed are left out.
+func (h *CardHandler) loadUnfurls(texts []linkText) error {
+	links := make([][]string, len(texts))
+	var all []string
+	for i, t := range texts {
+		links[i] = unfurl.Find(t.text)
+		all = append(all, links[i]...)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+
+	previews, err := h.cardRepo.GetLinkPreviews(all)
+	if err != nil {
+		return err
+	}
+
+	var due []string
+	for i, t := range texts {
+		for _, link := range links[i] {
+			preview, ok := previews[link]
+			if !ok || unfurl.Stale(preview) {
+				due = append(due, link)
+			}
+			if !ok || preview.Failed {
+				continue
+			}
+			if preview.FaviconURL != "" {
+				preview.FaviconURL = markdown.ImageURL(preview.FaviconURL)
+			}
+			if preview.ImageURL != "" {
+				preview.ImageURL = markdown.ImageURL(preview.ImageURL)
+			}
+			*t.unfurls = append(*t.unfurls, preview.Unfurl)
+		}
+	}
+	h.queueUnfurls(due)
+
+	return nil
+}
+
+// queueUnfurls has the unfurler fetch previews of links, if it is enabled
+func (h *CardHandler) queueUnfurls(links []string) {
+	if h.unfurler != nil && len(links) > 0 {
+		h.unfurler.Queue(links...)
+	}
+}
+
+// onLinking fetches previews of the links in a card description or
+// comment as it is written, so they are ready by the time it is read
+func (h *CardHandler) onLinking(event events.Event) {
+	if h.unfurler == nil {
+		return
+	}
+
+	text := event.Card.Description
+	if event.Comment != nil {
+		text = event.Comment.Content
+	}
+	var unfurls []models.Unfurl
+	if err := h.loadUnfurls([]linkText{{text, &unfurls}}); err != nil {
+		log.Printf("Failed to check link previews of card %d: %v", event.Card.ID, err)
+	}
+}
+
+// CreateFromURL creates a card for a web page on a list, the backend for
+// bookmarklets and browser extensions: titled after the page, quoting its
+// description and linking back to it, with its preview image embedded on
+// request. Pages that cannot be fetched, such as those on internal
+// addresses, still get a card titled with the given title or the URL.
+func (h *CardHandler) CreateFromURL(c *gin.Context) {
+	var req models.CreateCardFromURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		middleware.HandleError(c, http.StatusBadRequest, "URL must be http or https")
+		return
+	}
+
+	list, err := h.listRepo.GetByID(req.ListID)
+	if err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to verify list")
+		}
+		return
+	}
+	if !middleware.RequireBoardRole(c, h.memberRepo, list.BoardID, models.MemberRoleEditor) {
+		return
+	}
+	if !checkQuota(c, h.quotas.CheckCards(list.CardCount, 1)) {
+		return
+	}
+
+	page := h.fetchPage(c, req.URL)
+	title := req.Title
+	if title == "" && page != nil {
+		title = page.Title
+	}
+	if title == "" {
+		title = req.URL
+	}
+
+	var parts []string
+	if note := strings.TrimSpace(req.Note); note != "" {
+		parts = append(parts, note)
+	}
+	if page != nil && page.Description != "" {
+		parts = append(parts, "> "+page.Description)
+	}
+	// The image URL comes from the page, so it is escaped before it goes into
+	// Markdown; rendering shows it through the image proxy when that is on
+	if image := markdownURL(pageImage(req.Screenshot, page)); image != "" {
+		parts = append(parts, "![Screenshot](<"+image+">)")
+	}
+	parts = append(parts, "Source: <"+markdownURL(req.URL)+">")
+
+	card := &models.Card{
+		ListID:      list.ID,
+		Title:       truncateRunes(strings.TrimSpace(title), 255),
+		Description: truncateRunes(strings.Join(parts, "\n\n"), 5000),
+	}
+	if !h.insertCard(c, list, card, false) {
+		return
+	}
+
+	if wantsHTML(c) {
+		renderCard(card)
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+// pageImage returns the preview image of a page when a screenshot was asked
+// for, or ""
+func pageImage(screenshot bool, page *models.Unfurl) string {
+	if !screenshot || page == nil {
+		return ""
+	}
+	return page.ImageURL
+}
+
+// markdownURL returns an http or https URL ready to go between < and > in
+// Markdown, or "" for any other. Whitespace, control and non-ASCII bytes
+// and the characters that could end the link or escape one that does are
+// percent-encoded.
+func markdownURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range []byte(u.String()) {
+		if c <= ' ' || c >= 0x7f || c == '<' || c == '>' || c == '\\' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// fetchPage returns the preview of the page at a link, fetched now and
+// cached for unfurling, or nil when it cannot be fetched or unfurling is
+// disabled
+func (h *CardHandler) fetchPage(c *gin.Context, link string) *models.Unfurl {
+	if h.unfurler == nil {
+		return nil
+	}
+
+	page, err := h.unfurler.Fetch(c.Request.Context(), link)
+	preview := &models.LinkPreview{Failed: err != nil}
+	if err != nil {
+		preview.Unfurl = models.Unfurl{URL: link, FetchedAt: time.Now()}
+	} else {
+		preview.Unfurl = *page
+	}
+	if err := h.cardRepo.SaveLinkPreview(preview); err != nil {
+		log.Printf("Failed to save link preview of %s: %v", link, err)
+	}
+
+	if err != nil {
+		return nil
+	}
+	return page
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/i18n"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// UserHandler handles user-related HTTP requests
+type UserHandler struct {
+	repo             *repository.UserRepository
+	notificationRepo *repository.NotificationRepository
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(repo *repository.UserRepository, notificationRepo *repository.NotificationRepository) *UserHandler {
+	return &UserHandler{
+		repo:             repo,
+		notificationRepo: notificationRepo,
+	}
+}
+
+// GetAll retrieves all users
+func (h *UserHandler) GetAll(c *gin.Context) {
+	users, err := h.repo.GetAll()
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve users")
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetByID retrieves a user by ID
+func (h *UserHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusNotFound, "User not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Create creates a new user
+func (h *UserHandler) Create(c *gin.Context) {
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	// Usernames must be unique
+	if _, err := h.repo.GetByUsername(req.Username); err == nil {
+		middleware.HandleError(c, http.StatusConflict, "Username already exists")
+		return
+	}
+
+	if !validLocale(c, req.Locale) {
+		return
+	}
+
+	user := &models.User{
+		Username:    req.Username,
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+		Locale:      i18n.Normalize(req.Locale),
+	}
+
+	if err := h.repo.Create(user); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// Me retrieves the acting user
+func (h *UserHandler) Me(c *gin.Context) {
+	user := requireCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateMe updates the acting user's profile
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	user := requireCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.DisplayName != nil {
+		user.DisplayName = *req.DisplayName
+	}
+	if req.AvatarURL != nil {
+		user.AvatarURL = *req.AvatarURL
+	}
+	if req.Locale != nil {
+		if !validLocale(c, *req.Locale) {
+			return
+		}
+		user.Locale = i18n.Normalize(*req.Locale)
+	}
+
+	if err := h.repo.Update(user); err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusNotFound, "User not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update user")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Activity summarizes a user's recent actions and the cards they touched
+func (h *UserHandler) Activity(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "user not found" {
+			middleware.HandleError(c, http.StatusNotFound, "User not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		}
+		return
+	}
+
+	h.respondActivity(c, user)
+}
+
+// MyActivity summarizes the acting user's recent actions and the cards they
+// touched
+func (h *UserHandler) MyActivity(c *gin.Context) {
+	user := requireCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	h.respondActivity(c, user)
+}
+
+// respondActivity writes a user's activity over the last ?days= days
+// (default 7, at most 90), on the cards the acting user may see
+func (h *UserHandler) respondActivity(c *gin.Context, user *models.User) {
+	days := models.DefaultActivityDays
+	if v := c.Query("days"); v != "" {
+		var err error
+		if days, err = strconv.Atoi(v); err != nil || days < 1 || days > models.MaxActivityDays {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid days; use a whole number from 1 to 90")
+			return
+		}
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	activity, err := h.repo.GetActivity(user.ID, since, queryLimit(c, 50), actingUserID(c))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve user activity")
+		return
+	}
+	activity.Username = user.Username
+	activity.Days = days
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// Mentions retrieves the most recent mentions of the acting user
+func (h *UserHandler) Mentions(c *gin.Context) {
+	user := requireCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	mentions, err := h.notificationRepo.GetMentionsByUser(user.ID, queryLimit(c, 50))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve mentions")
+		return
+	}
+
+	c.JSON(http.StatusOK, mentions)
+}
+
+// Notifications retrieves the notifications of the acting user
+func (h *UserHandler) Notifications(c *gin.Context) {
+	user := requireCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+	notifications, err := h.notificationRepo.GetByUser(user.ID, unreadOnly, queryLimit(c, 50))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead marks one of the acting user's notifications as read
+func (h *UserHandler) MarkNotificationRead(c *gin.Context) {
+	user := requireCurrentUser(c)
+	if user == nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+
+	if err := h.notificationRepo.MarkRead(id, user.ID); err != nil {
+		if err.Error() == "notification not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Notification not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to update notification")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Notification marked as read"))
+}
+
+// requireCurrentUser returns the acting user or responds with 401
+func requireCurrentUser(c *gin.Context) *models.User {
+	user := middleware.GetCurrentUser(c)
+	if user == nil {
+		middleware.HandleError(c, http.StatusUnauthorized, "No current user; send an API token or the X-Kanban-User header")
+		return nil
+	}
+	return user
+}
+
+// successMessage is the body of a successful request that returns no
+// entity, in the request's locale
+func successMessage(c *gin.Context, message string) gin.H {
+	return gin.H{"message": localize(c, message)}
+}
+
+// localize translates a message into the request's locale
+func localize(c *gin.Context, message string) string {
+	return i18n.Translate(middleware.GetLocale(c), message)
+}
+
+// validLocale checks that an optional locale preference is supported,
+// responding with 400 if not
+func validLocale(c *gin.Context, locale string) bool {
+	if locale != "" && !i18n.Supported(locale) {
+		middleware.HandleErrorf(c, http.StatusBadRequest, "Unsupported locale; use one of: %s", strings.Join(i18n.Locales(), ", "))
+		return false
+	}
+	return true
+}
+
+// queryLimit parses the ?limit= query parameter, bounded to 1..500
+func queryLimit(c *gin.Context, fallback int) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		return fallback
+	}
+	if limit > 500 {
+		return 500
+	}
+	return limit
+}
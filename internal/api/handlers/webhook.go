@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// WebhookHandler handles webhook-related HTTP requests
+type WebhookHandler struct {
+	repo      *repository.WebhookRepository
+	boardRepo *repository.BoardRepository
+	listRepo  *repository.ListRepository
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(repo *repository.WebhookRepository, boardRepo *repository.BoardRepository, listRepo *repository.ListRepository) *WebhookHandler {
+	return &WebhookHandler{
+		repo:      repo,
+		boardRepo: boardRepo,
+		listRepo:  listRepo,
+	}
+}
+
+// GetAll retrieves every webhook, or those of the board given by ?board_id
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	var boardID *int
+	if v := c.Query("board_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.HandleError(c, http.StatusBadRequest, "Invalid board ID")
+			return
+		}
+		boardID = &id
+	}
+
+	webhooks, err := h.repo.GetAll(boardID)
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// Create registers a webhook. The response carries the signing secret,
+// which is not shown again.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.ListID != nil {
+		list, ok := h.loadList(c, *req.ListID)
+		if !ok {
+			return
+		}
+		if req.BoardID != nil && *req.BoardID != list.BoardID {
+			middleware.HandleError(c, http.StatusBadRequest, "List belongs to another board")
+			return
+		}
+		req.BoardID = &list.BoardID
+	} else if req.BoardID != nil && !h.checkBoard(c, *req.BoardID) {
+		return
+	}
+
+	webhook := &models.Webhook{
+		URL:     req.URL,
+		BoardID: req.BoardID,
+		ListID:  req.ListID,
+		Events:  req.Events,
+		Active:  req.Active == nil || *req.Active,
+		Secret:  req.Secret,
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+
+	if err := h.repo.Create(webhook); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// GetByID retrieves a webhook
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Update changes a webhook's URL, board, list, events or active flag
+func (h *WebhookHandler) Update(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.HandleBindError(c, err)
+		return
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.BoardID != nil {
+		if *req.BoardID == 0 {
+			webhook.BoardID = nil
+		} else {
+			if !h.checkBoard(c, *req.BoardID) {
+				return
+			}
+			webhook.BoardID = req.BoardID
+		}
+		webhook.ListID = nil
+	}
+	if req.ListID != nil {
+		if *req.ListID == 0 {
+			webhook.ListID = nil
+		} else {
+			list, ok := h.loadList(c, *req.ListID)
+			if !ok {
+				return
+			}
+			if req.BoardID != nil && *req.BoardID != list.BoardID {
+				middleware.HandleError(c, http.StatusBadRequest, "List belongs to another board")
+				return
+			}
+			webhook.BoardID = &list.BoardID
+			webhook.ListID = &list.ID
+		}
+	}
+	if req.Events != nil {
+		webhook.Events = req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := h.repo.Update(webhook); err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Delete removes a webhook
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.repo.Delete(id); err != nil {
+		if err.Error() == "webhook not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Webhook not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to delete webhook")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, successMessage(c, "Webhook deleted successfully"))
+}
+
+// RotateSecret replaces a webhook's signing secret; deliveries are signed
+// with the new one from then on
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	webhook, err := h.repo.RotateSecret(id)
+	if err != nil {
+		if err.Error() == "webhook not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to rotate webhook secret")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// GetDeliveries retrieves a webhook's most recent delivery attempts with
+// their status codes, latencies and payloads, optionally only those whose
+// delivery is in the ?state= given
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	state := c.Query("state")
+	if state != "" && !models.IsValidDeliveryState(state) {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid delivery state")
+		return
+	}
+
+	attempts, err := h.repo.GetAttempts(webhook.ID, state, queryLimit(c, 50))
+	if err != nil {
+		middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
+
+// loadWebhook resolves the :id parameter, writing an error response when the
+// webhook cannot be loaded
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*models.Webhook, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		middleware.HandleError(c, http.StatusBadRequest, "Invalid webhook ID")
+		return nil, false
+	}
+
+	webhook, err := h.repo.GetByID(id)
+	if err != nil {
+		if err.Error() == "webhook not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Webhook not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve webhook")
+		}
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// checkBoard makes sure a webhook's board exists, writing an error response
+// when it does not
+func (h *WebhookHandler) checkBoard(c *gin.Context, boardID int) bool {
+	if _, err := h.boardRepo.GetByID(boardID); err != nil {
+		if err.Error() == "board not found" {
+			middleware.HandleError(c, http.StatusNotFound, "Board not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve board")
+		}
+		return false
+	}
+	return true
+}
+
+// loadList loads the list a webhook is scoped to, writing an error response
+// when it cannot
+func (h *WebhookHandler) loadList(c *gin.Context, listID int) (*models.List, bool) {
+	list, err := h.listRepo.GetByID(listID)
+	if err != nil {
+		if err.Error() == "list not found" {
+			middleware.HandleError(c, http.StatusNotFound, "List not found")
+		} else {
+			middleware.HandleError(c, http.StatusInternalServerError, "Failed to retrieve list")
+		}
+		return nil, false
+	}
+	return list, true
+}
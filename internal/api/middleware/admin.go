@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/models"
+)
+
+// AdminToken middleware guards admin endpoints with a shared bearer token,
+// or an API token granted the admin scope. With no shared token configured
+// the endpoints are disabled entirely. The shared token is read from the
+// runtime settings on every request, so a reload takes effect immediately.
+func AdminToken(runtime *Runtime) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := runtime.Get().AdminToken
+		if token == "" {
+			HandleError(c, http.StatusForbidden, "Admin endpoints are disabled")
+			return
+		}
+
+		if apiToken := GetCurrentToken(c); apiToken != nil && apiToken.HasScope(models.TokenScopeAdmin) {
+			c.Next()
+			return
+		}
+
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			HandleError(c, http.StatusUnauthorized, "Invalid admin token")
+			return
+		}
+
+		c.Next()
+	}
+}
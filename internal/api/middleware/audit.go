@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// handlerNamePattern picks the handler type and method out of a route's
+// handler name, e.g. handlers.(*CardHandler).Archive-fm
+var handlerNamePattern = regexp.MustCompile(`\(\*(\w+)Handler\)\.(\w+)-fm$`)
+
+// Audit middleware records every successful mutating API request in the
+// audit log. The board is resolved before the handler runs so deletes are
+// attributed too. Failures to record are logged, not returned.
+func Audit(audit *repository.AuditRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		var boardID *int
+		if id, err := strconv.Atoi(c.Param("id")); err == nil {
+			segments := strings.Split(strings.TrimPrefix(route, "/api/"), "/")
+			boardID = audit.BoardOf(segments[0], id)
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusBadRequest {
+			return
+		}
+
+		entry := &models.AuditEntry{
+			Action:  auditAction(c.Request.Method, route, c.HandlerName()),
+			Route:   c.Request.Method + " " + route,
+			Path:    c.Request.URL.Path,
+			Status:  status,
+			BoardID: boardID,
+		}
+		if user := GetCurrentUser(c); user != nil {
+			entry.ActorID = &user.ID
+			entry.Actor = user.Username
+		}
+		if err := audit.Record(entry); err != nil {
+			log.Printf("Failed to record audit entry for %s: %v", entry.Route, err)
+		}
+	}
+}
+
+// auditAction names a request after the handler serving it, e.g.
+// card.archive for CardHandler.Archive, falling back to the route
+func auditAction(method, route, handlerName string) string {
+	m := handlerNamePattern.FindStringSubmatch(handlerName)
+	if m == nil {
+		return method + " " + route
+	}
+	return snakeCase(m[1]) + "." + snakeCase(m[2])
+}
+
+// snakeCase turns UpdateSettings into update_settings
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
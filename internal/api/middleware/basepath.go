@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// basePathKey is the context key holding the path the server is mounted under
+const basePathKey = "base_path"
+
+// CleanBasePath turns a base path setting such as kanban/ into the form
+// /kanban; the root is ""
+func CleanBasePath(basePath string) (string, error) {
+	trimmed := strings.Trim(basePath, "/")
+	if trimmed == "" {
+		return "", nil
+	}
+	cleaned := path.Clean("/" + trimmed)
+	if cleaned != "/"+trimmed || strings.ContainsAny(trimmed, "?#%:*{} ") {
+		return "", fmt.Errorf("invalid base path %q", basePath)
+	}
+	return cleaned, nil
+}
+
+// Mount serves handler under basePath, for running behind a reverse proxy
+// that forwards a subdirectory such as /kanban/ without rewriting paths.
+// The base path is stripped before handler sees the request, so routes,
+// audit entries and token scopes keep their usual /api form, and it is
+// passed on as X-Forwarded-Prefix for gin's trailing slash redirects. The
+// base path without its slash redirects to it; anything outside it is not
+// found.
+func Mount(basePath string, handler http.Handler) http.Handler {
+	if basePath == "" {
+		return handler
+	}
+
+	stripped := http.StripPrefix(basePath, handler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == basePath {
+			target := basePath + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, basePath+"/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		r.Header.Set("X-Forwarded-Prefix", basePath)
+		stripped.ServeHTTP(w, r)
+	})
+}
+
+// BasePath middleware records the path the server is mounted under, for
+// building links
+func BasePath(basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(basePathKey, basePath)
+		c.Next()
+	}
+}
+
+// GetBasePath returns the path the server is mounted under, such as
+// /kanban; "" at the root
+func GetBasePath(c *gin.Context) string {
+	return c.GetString(basePathKey)
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kanban-simple/internal/i18n"
+)
+
+// BindErrorDetails translates an error from binding a request body into
+// per-field details. Errors that do not concern a particular field, such as
+// malformed JSON, are reported against the field "body". Messages are
+// translated into locale.
+func BindErrorDetails(err error, locale string) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		details := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			details = append(details, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: ruleMessage(fe, locale),
+			})
+		}
+		return details
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		field := typeError.Field
+		if field == "" {
+			field = "body"
+		}
+		return []FieldError{{
+			Field:   field,
+			Rule:    "type",
+			Message: i18n.Sprintf(locale, "must be %s, not %s", i18n.Translate(locale, typeName(typeError.Type)), typeError.Value),
+		}}
+	}
+
+	var timeError *time.ParseError
+	if errors.As(err, &timeError) {
+		return []FieldError{{
+			Field:   "body",
+			Rule:    "type",
+			Message: i18n.Sprintf(locale, "%s is not an RFC 3339 timestamp", timeError.Value),
+		}}
+	}
+
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		return []FieldError{{
+			Field:   "body",
+			Rule:    "json",
+			Message: i18n.Sprintf(locale, "malformed JSON at offset %d: %s", syntaxError.Offset, syntaxError.Error()),
+		}}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return []FieldError{{Field: "body", Rule: "required", Message: i18n.Translate(locale, "request body is empty")}}
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return []FieldError{{Field: "body", Rule: "json", Message: i18n.Translate(locale, "request body is truncated")}}
+	}
+
+	return []FieldError{{Field: "body", Rule: "json", Message: err.Error()}}
+}
+
+// fieldPath is the JSON path of the failing field, e.g. "settings.background_color"
+func fieldPath(fe validator.FieldError) string {
+	// The namespace starts with the Go struct name, which clients never see
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+// ruleMessage describes a failed validation rule in plain words
+func ruleMessage(fe validator.FieldError, locale string) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return i18n.Translate(locale, "is required")
+	case "min", "max", "len":
+		bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fe.Tag()]
+		switch fe.Kind() {
+		case reflect.String:
+			return i18n.Sprintf(locale, "must be "+bound+" %s characters long", param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return i18n.Sprintf(locale, "must contain "+bound+" %s items", param)
+		default:
+			return i18n.Sprintf(locale, "must be "+bound+" %s", param)
+		}
+	case "gt":
+		return i18n.Sprintf(locale, "must be greater than %s", param)
+	case "gte":
+		return i18n.Sprintf(locale, "must be at least %s", param)
+	case "lt":
+		return i18n.Sprintf(locale, "must be less than %s", param)
+	case "lte":
+		return i18n.Sprintf(locale, "must be at most %s", param)
+	case "oneof":
+		return i18n.Sprintf(locale, "must be one of: %s", strings.Join(strings.Fields(param), ", "))
+	case "url":
+		return i18n.Translate(locale, "must be a valid URL")
+	case "email":
+		return i18n.Translate(locale, "must be a valid email address")
+	case "color":
+		return i18n.Translate(locale, "must be a hex color code (#RGB or #RRGGBB) or a palette color name")
+	case "logourl":
+		return i18n.Translate(locale, "must be an http or https URL or a path starting with /")
+	case "publicurl":
+		return i18n.Translate(locale, "must be an http or https URL on a public address")
+	case "username":
+		return i18n.Translate(locale, "may contain only letters, digits, dots, dashes and underscores")
+	case "event":
+		return i18n.Translate(locale, "must be a known event type such as card.created")
+	case "template":
+		return i18n.Translate(locale, "must use placeholders like {{$.path}} with valid paths")
+	default:
+		return i18n.Sprintf(locale, "failed the %q rule", fe.Tag())
+	}
+}
+
+// typeName names a Go type the way a JSON client thinks of it
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// BoardRole middleware enforces board member roles on routes whose :id
+// names an entity of kind on a board, kind being the route segment such as
+// lists or cards: on a board restricted to its members GET and HEAD need
+// the viewer role and any other request needs editor. Routes whose entity
+// does not exist are left to the handler to report. It must run after
+// CurrentUser.
+func BoardRole(members *repository.MemberRepository, kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		boardID, err := members.BoardOf(kind, id)
+		if err != nil {
+			if strings.HasSuffix(err.Error(), "not found") {
+				c.Next()
+				return
+			}
+			HandleError(c, http.StatusInternalServerError, "Failed to check board access")
+			return
+		}
+
+		role := models.MemberRoleEditor
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			role = models.MemberRoleViewer
+		}
+		if !RequireBoardRole(c, members, boardID, role) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// BoardOwner middleware limits a route under /boards/:id to the board's
+// owners while the board is restricted to its members. It must run after
+// CurrentUser.
+func BoardOwner(members *repository.MemberRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		boardID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Next()
+			return
+		}
+		if !RequireBoardRole(c, members, boardID, models.MemberRoleOwner) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// BoardAllows reports whether the acting user has at least role on a
+// board. Boards that are not restricted to their members allow everyone.
+func BoardAllows(c *gin.Context, members *repository.MemberRepository, boardID int, role string) (bool, error) {
+	var userID *int
+	if user := GetCurrentUser(c); user != nil {
+		userID = &user.ID
+	}
+
+	actual, restricted, err := members.Role(boardID, userID)
+	if err != nil {
+		return false, err
+	}
+	return !restricted || models.RoleRank(actual) >= models.RoleRank(role), nil
+}
+
+// RequireBoardRole checks that the acting user has at least role on a
+// board, for handlers acting on a board named in the request body. It
+// writes a 403 and returns false when they do not.
+func RequireBoardRole(c *gin.Context, members *repository.MemberRepository, boardID int, role string) bool {
+	allowed, err := BoardAllows(c, members, boardID, role)
+	if err != nil {
+		HandleError(c, http.StatusInternalServerError, "Failed to check board access")
+		return false
+	}
+	if allowed {
+		return true
+	}
+
+	switch role {
+	case models.MemberRoleOwner:
+		HandleError(c, http.StatusForbidden, "Only the board's owners can do this")
+	case models.MemberRoleEditor:
+		HandleError(c, http.StatusForbidden, "Changing this board needs the editor role")
+	default:
+		HandleError(c, http.StatusForbidden, "This board is shared with its members only")
+	}
+	return false
+}
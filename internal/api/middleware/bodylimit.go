@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit middleware caps request bodies at limit bytes, or at the
+// override for routes listed in overrides. A declared Content-Length over
+// the limit is rejected with 413 before the handler runs; a body that turns
+// out larger fails to bind, which HandleBindError also reports as 413.
+// A zero limit disables the check.
+func BodyLimit(limit int64, overrides map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		max := limit
+		if override, ok := overrides[c.FullPath()]; ok {
+			max = override
+		}
+		if max <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > max {
+			bodyTooLarge(c, max)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+		c.Next()
+	}
+}
+
+// isBodyTooLarge reports whether a bind error came from BodyLimit cutting
+// the body off, responding with 413 if so
+func isBodyTooLarge(c *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	bodyTooLarge(c, tooLarge.Limit)
+	return true
+}
+
+// bodyTooLarge responds with 413 naming the limit
+func bodyTooLarge(c *gin.Context, limit int64) {
+	HandleErrorf(c, http.StatusRequestEntityTooLarge, "Request body too large; the limit is %d bytes", limit)
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// envelopeKey is the context key marking requests whose collections are
+// wrapped in an envelope
+const envelopeKey = "envelope"
+
+// Envelope middleware marks the requests of a route group, /api/v1, whose
+// collections are returned as {data, meta} pages instead of bare arrays
+func Envelope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(envelopeKey, true)
+		c.Next()
+	}
+}
+
+// Enveloped reports whether the request's collections are returned in an
+// envelope
+func Enveloped(c *gin.Context) bool {
+	return c.GetBool(envelopeKey)
+}
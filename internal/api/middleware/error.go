@@ -5,12 +5,21 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/i18n"
 )
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+	Error   string       `json:"error"`
+	Message string       `json:"message,omitempty"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError describes a validation failure on a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 // ErrorHandler middleware handles errors consistently
@@ -38,10 +47,53 @@ func ErrorHandler() gin.HandlerFunc {
 	}
 }
 
-// HandleError is a helper function to handle errors in handlers
+// HandleError is a helper function to handle errors in handlers. The message
+// is translated into the request's locale.
 func HandleError(c *gin.Context, status int, message string) {
 	c.AbortWithStatusJSON(status, ErrorResponse{
 		Error:   http.StatusText(status),
-		Message: message,
+		Message: i18n.Translate(GetLocale(c), message),
 	})
-}
\ No newline at end of file
+}
+
+// HandleErrorf is HandleError for messages with variable parts; the format
+// string is what gets translated
+func HandleErrorf(c *gin.Context, status int, format string, args ...interface{}) {
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Error:   http.StatusText(status),
+		Message: i18n.Sprintf(GetLocale(c), format, args...),
+	})
+}
+
+// HandleBindError responds to a request body that failed to bind, listing
+// each problem in details. Invalid colors are reported as 422 for backward
+// compatibility, and bodies over the BodyLimit as 413; everything else is a
+// 400.
+func HandleBindError(c *gin.Context, err error) {
+	if isBodyTooLarge(c, err) {
+		return
+	}
+
+	locale := GetLocale(c)
+	details := BindErrorDetails(err, locale)
+
+	status, message := http.StatusBadRequest, "Invalid request body"
+	colors := 0
+	for _, detail := range details {
+		if detail.Rule == "color" {
+			colors++
+		}
+	}
+	if colors > 0 {
+		status = http.StatusUnprocessableEntity
+		if colors == len(details) {
+			message = "Invalid color"
+		}
+	}
+
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Error:   http.StatusText(status),
+		Message: i18n.Translate(locale, message),
+		Details: details,
+	})
+}
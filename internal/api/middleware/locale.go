@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/i18n"
+)
+
+// localeKey is the context key holding the response locale
+const localeKey = "locale"
+
+// Locale middleware picks the language for user-facing messages: the acting
+// user's saved locale, otherwise the best match for Accept-Language, otherwise
+// English. It must run after CurrentUser.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := ""
+		if user := GetCurrentUser(c); user != nil && user.Locale != "" && i18n.Supported(user.Locale) {
+			locale = user.Locale
+		}
+		if locale == "" {
+			locale = i18n.Match(c.GetHeader("Accept-Language"))
+		}
+
+		c.Set(localeKey, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
+
+// GetLocale returns the response locale. Before Locale has run it falls back
+// to Accept-Language.
+func GetLocale(c *gin.Context) string {
+	if value, exists := c.Get(localeKey); exists {
+		if locale, ok := value.(string); ok {
+			return locale
+		}
+	}
+	return i18n.Match(c.GetHeader("Accept-Language"))
+}
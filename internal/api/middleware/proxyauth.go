@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// TrustedHeader authenticates requests forwarded by a reverse proxy that has
+// already signed the user in, such as Authelia or oauth2-proxy, and names
+// them in a header. The header is only believed on connections from the
+// proxy's own addresses.
+type TrustedHeader struct {
+	Header  string
+	proxies []*net.IPNet
+}
+
+// NewTrustedHeader creates trusted-header authentication for the named
+// header, accepted from the comma-separated CIDRs or plain IP addresses
+func NewTrustedHeader(header, proxies string) (*TrustedHeader, error) {
+	trusted := &TrustedHeader{Header: header}
+
+	for _, entry := range strings.Split(proxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", entry)
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy CIDR %q", entry)
+		}
+		trusted.proxies = append(trusted.proxies, network)
+	}
+
+	if len(trusted.proxies) == 0 {
+		return nil, fmt.Errorf("trusted header %s needs at least one proxy CIDR", header)
+	}
+
+	return trusted, nil
+}
+
+// Trusts reports whether remoteIP, the address the connection came from,
+// belongs to a trusted proxy. Forwarded-for headers are deliberately not
+// consulted since any client can send them.
+func (t *TrustedHeader) Trusts(remoteIP string) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+	for _, network := range t.proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyUser returns the user a trusted proxy named, creating them the first
+// time they are seen
+func proxyUser(users *repository.UserRepository, username string) (*models.User, error) {
+	user, err := users.GetByUsername(username)
+	if err == nil || err.Error() != "user not found" {
+		return user, err
+	}
+
+	if len(username) > 50 || !models.IsValidUsername(username) {
+		return nil, fmt.Errorf("invalid username %q", username)
+	}
+
+	user = &models.User{Username: username}
+	if err := users.Create(user); err != nil {
+		// Another request may have created them first
+		if existing, lookupErr := users.GetByUsername(username); lookupErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyRoute is the admin route that switches read-only mode; it stays
+// writable so the mode can be turned off again
+const ReadOnlyRoute = "/api/admin/read-only"
+
+// ReadOnlyMode holds whether the server is read-only. It can be switched
+// while the server runs.
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+// NewReadOnlyMode creates a read-only mode starting in the given state
+func NewReadOnlyMode(enabled bool) *ReadOnlyMode {
+	mode := &ReadOnlyMode{}
+	mode.enabled.Store(enabled)
+	return mode
+}
+
+// Enabled reports whether mutating requests are being rejected
+func (m *ReadOnlyMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Set switches read-only mode on or off
+func (m *ReadOnlyMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// ReadOnly middleware rejects mutating requests while read-only mode is enabled
+func ReadOnly(mode *ReadOnlyMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !mode.Enabled() || c.FullPath() == ReadOnlyRoute {
+			c.Next()
+			return
+		}
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+		default:
+			HandleError(c, http.StatusForbidden, "Server is in read-only mode")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// RuntimeSettings is the configuration that can be reloaded without a
+// restart
+type RuntimeSettings struct {
+	AdminToken  string       // Bearer token for /api/admin; empty disables it
+	CORSOrigins []string     // Origins allowed to make cross-origin requests; "*" allows any
+	Theme       models.Theme // Configured theme, under the one set through the admin API
+}
+
+// Runtime holds the current runtime settings. They can be replaced while
+// the server runs; requests see either the old or the new settings, never a
+// mixture.
+type Runtime struct {
+	current atomic.Pointer[RuntimeSettings]
+}
+
+// NewRuntime creates a runtime holding the given settings
+func NewRuntime(settings RuntimeSettings) *Runtime {
+	runtime := &Runtime{}
+	runtime.Set(settings)
+	return runtime
+}
+
+// Get returns the current settings
+func (r *Runtime) Get() RuntimeSettings {
+	return *r.current.Load()
+}
+
+// Set replaces the current settings
+func (r *Runtime) Set(settings RuntimeSettings) {
+	r.current.Store(&settings)
+}
+
+// AllowsOrigin reports whether the CORS origins allow origin
+func (r *Runtime) AllowsOrigin(origin string) bool {
+	for _, allowed := range r.Get().CORSOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
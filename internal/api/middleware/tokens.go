@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/models"
+)
+
+// TokenScope middleware rejects API token requests outside the token's
+// scopes: GET, HEAD and OPTIONS need read, /api/admin needs admin and any
+// other request needs write. Requests without a token are not affected.
+// It must run after CurrentUser.
+func TokenScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := GetCurrentToken(c)
+		if token == nil {
+			c.Next()
+			return
+		}
+
+		scope := models.TokenScopeWrite
+		switch {
+		case strings.HasPrefix(c.Request.URL.Path, "/api/admin/"):
+			scope = models.TokenScopeAdmin
+		case c.Request.Method == http.MethodGet, c.Request.Method == http.MethodHead, c.Request.Method == http.MethodOptions:
+			scope = models.TokenScopeRead
+		}
+
+		if !token.HasScope(scope) {
+			HandleErrorf(c, http.StatusForbidden, "API token lacks the %s scope", scope)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// tokenBucket allows limit requests a minute, refilled continuously
+type tokenBucket struct {
+	limit   int
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimiter throttles API tokens to their own rate limits. Buckets live in
+// memory, so limits reset when the server restarts.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[int]*tokenBucket
+}
+
+// NewRateLimiter creates an empty rate limiter
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{buckets: make(map[int]*tokenBucket)}
+}
+
+// take spends one request from the token's bucket. It returns the requests
+// left and, when none were, how long until the next is allowed.
+func (l *RateLimiter) take(token *models.APIToken) (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := l.buckets[token.ID]
+	if !ok || bucket.limit != token.RateLimit {
+		bucket = &tokenBucket{limit: token.RateLimit, tokens: float64(token.RateLimit), updated: now}
+		l.buckets[token.ID] = bucket
+	}
+
+	perSecond := float64(bucket.limit) / 60
+	bucket.tokens = math.Min(float64(bucket.limit), bucket.tokens+now.Sub(bucket.updated).Seconds()*perSecond)
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+		return 0, wait
+	}
+	bucket.tokens--
+	return int(bucket.tokens), 0
+}
+
+// RateLimit middleware enforces each API token's rate limit, answering 429
+// with Retry-After once it is used up. Tokens without a limit, and requests
+// without a token, are not throttled. It must run after CurrentUser.
+func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := GetCurrentToken(c)
+		if token == nil || token.RateLimit == 0 {
+			c.Next()
+			return
+		}
+
+		remaining, wait := limiter.take(token)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(token.RateLimit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			HandleErrorf(c, http.StatusTooManyRequests, "Rate limit of %d requests per minute exceeded", token.RateLimit)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// UserHeader is the request header naming the acting user
+const UserHeader = "X-Kanban-User"
+
+// currentUserKey is the context key holding the acting user
+const currentUserKey = "current_user"
+
+// currentTokenKey is the context key holding the API token a request used
+const currentTokenKey = "current_token"
+
+// UserHeaderPolicy says when X-Kanban-User may name the acting user. Anyone
+// can send the header, so once clients authenticate it would let them act
+// as someone else, past their token's scopes and rate limit.
+type UserHeaderPolicy string
+
+const (
+	// UserHeaderAuto accepts the header until an API token is issued; from
+	// then on only a token names the acting user
+	UserHeaderAuto UserHeaderPolicy = "auto"
+	// UserHeaderOn always accepts the header
+	UserHeaderOn UserHeaderPolicy = "on"
+	// UserHeaderOff never accepts the header
+	UserHeaderOff UserHeaderPolicy = "off"
+)
+
+// ParseUserHeaderPolicy reads auto, on or off
+func ParseUserHeaderPolicy(s string) (UserHeaderPolicy, error) {
+	switch policy := UserHeaderPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case UserHeaderAuto, UserHeaderOn, UserHeaderOff:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown user header policy %q; use auto, on or off", s)
+	}
+}
+
+// CurrentUser middleware resolves the acting user from an API token sent as
+// "Authorization: Bearer kb_...", or else from the X-Kanban-User header when
+// policy allows it. Requests without either, or naming an unknown user, stay
+// anonymous. An unknown or revoked token is rejected, as is any request by a
+// disabled user.
+//
+// With a trusted header configured, the user a trusted proxy names replaces
+// X-Kanban-User, which is then ignored whatever the policy, and unknown
+// usernames are created on first sight. The header is ignored on requests
+// from anywhere else.
+func CurrentUser(users *repository.UserRepository, tokens *repository.APITokenRepository, proxy *TrustedHeader, policy UserHeaderPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user *models.User
+
+		if credential := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); repository.IsAPIToken(credential) {
+			token, err := tokens.Authenticate(credential)
+			if err != nil {
+				HandleError(c, http.StatusUnauthorized, "Invalid API token")
+				return
+			}
+			if user, err = users.GetByID(token.UserID); err != nil {
+				HandleError(c, http.StatusUnauthorized, "Invalid API token")
+				return
+			}
+			c.Set(currentTokenKey, token)
+		} else if proxy != nil {
+			if username := c.GetHeader(proxy.Header); username != "" && proxy.Trusts(c.RemoteIP()) {
+				var err error
+				if user, err = proxyUser(users, username); err != nil {
+					HandleError(c, http.StatusUnauthorized, "Invalid user from authenticating proxy")
+					return
+				}
+			}
+		} else if username := c.GetHeader(UserHeader); username != "" && userHeaderAllowed(policy, tokens) {
+			user, _ = users.GetByUsername(username)
+		}
+
+		if user != nil {
+			if user.Disabled {
+				HandleError(c, http.StatusForbidden, "User is disabled")
+				return
+			}
+			c.Set(currentUserKey, user)
+		}
+
+		c.Next()
+	}
+}
+
+// userHeaderAllowed reports whether X-Kanban-User is accepted under policy.
+// The automatic policy refuses it when tokens cannot be checked.
+func userHeaderAllowed(policy UserHeaderPolicy, tokens *repository.APITokenRepository) bool {
+	switch policy {
+	case UserHeaderOn:
+		return true
+	case UserHeaderOff:
+		return false
+	}
+	issued, err := tokens.AnyActive()
+	if err != nil {
+		log.Printf("Ignoring %s: %v", UserHeader, err)
+		return false
+	}
+	return !issued
+}
+
+// GetCurrentUser returns the acting user, or nil for anonymous requests
+func GetCurrentUser(c *gin.Context) *models.User {
+	if value, exists := c.Get(currentUserKey); exists {
+		if user, ok := value.(*models.User); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// GetCurrentToken returns the API token the request was made with, or nil
+// when it used the header or no credentials
+func GetCurrentToken(c *gin.Context) *models.APIToken {
+	if value, exists := c.Get(currentTokenKey); exists {
+		if token, ok := value.(*models.APIToken); ok {
+			return token
+		}
+	}
+	return nil
+}
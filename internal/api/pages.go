@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// staticDir holds the web UI's pages and assets
+const staticDir = "./web/static"
+
+// Cache-Control values: fingerprinted assets never change, so browsers keep
+// them for a year without asking; pages and plain asset paths are checked
+// with the server on every use, so a new release shows up on the next load
+const (
+	cacheForever    = "public, max-age=31536000, immutable"
+	cacheRevalidate = "no-cache"
+)
+
+// basePathMeta is the tag the web UI's pages read the base path from, so
+// their scripts can reach the API under it
+const basePathMeta = `<meta name="base-path" content="">`
+
+// assetLink matches a page's links to assets, such as
+// href="/static/css/styles.css"
+var assetLink = regexp.MustCompile(`="/static/([^"?#]+)"`)
+
+// assets fingerprints the web UI's assets: each is also served under a
+// name carrying a hash of its content, such as css/styles.3f2a9c1b0d.css,
+// which pages link to and browsers may cache for good. The hashes are taken
+// at startup, so changed assets need a restart.
+type assets struct {
+	dir     string
+	hashed  map[string]string // Asset path, such as css/styles.css, to its fingerprinted path
+	sources map[string]string // Fingerprinted path back to the asset path
+}
+
+// loadAssets fingerprints every file under dir but the HTML pages with the
+// first 10 hex digits of its SHA-256. Files that cannot be read are left
+// out and served under their plain paths only.
+func loadAssets(dir string) *assets {
+	a := &assets{dir: dir, hashed: make(map[string]string), sources: make(map[string]string)}
+
+	filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(file) == ".html" {
+			return nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil
+		}
+
+		rel = filepath.ToSlash(rel)
+		sum := sha256.Sum256(data)
+		ext := path.Ext(rel)
+		name := strings.TrimSuffix(rel, ext) + "." + hex.EncodeToString(sum[:])[:10] + ext
+		a.hashed[rel] = name
+		a.sources[name] = rel
+		return nil
+	})
+
+	return a
+}
+
+// serve serves /static/*filepath: fingerprinted paths for good, plain ones
+// revalidated on every use. Directories are not listed.
+func (a *assets) serve(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("filepath"), "/")
+	if source, ok := a.sources[name]; ok {
+		c.Header("Cache-Control", cacheForever)
+		c.FileFromFS(source, gin.Dir(a.dir, false))
+		return
+	}
+
+	c.Header("Cache-Control", cacheRevalidate)
+	c.FileFromFS(name, gin.Dir(a.dir, false))
+}
+
+// page serves an HTML page of the web UI, always revalidated. Its links to
+// /static/ assets are rewritten to their fingerprinted paths, under the
+// base path along with its base-path meta tag.
+func (a *assets) page(name, basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := os.ReadFile(filepath.Join(a.dir, name))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		html := assetLink.ReplaceAllStringFunc(string(data), func(link string) string {
+			asset := assetLink.FindStringSubmatch(link)[1]
+			if hashed, ok := a.hashed[asset]; ok {
+				asset = hashed
+			}
+			return `="` + basePath + `/static/` + asset + `"`
+		})
+		html = strings.Replace(html, basePathMeta, `<meta name="base-path" content="`+basePath+`">`, 1)
+
+		c.Header("Cache-Control", cacheRevalidate)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+	}
+}
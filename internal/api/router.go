@@ -1,59 +1,234 @@
 package api
 
 import (
+	"log"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-yaml"
 	"github.com/kanban-simple/internal/api/handlers"
 	"github.com/kanban-simple/internal/api/middleware"
+	"github.com/kanban-simple/internal/apispec"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/imageproxy"
+	"github.com/kanban-simple/internal/markdown"
+	"github.com/kanban-simple/internal/models"
 	"github.com/kanban-simple/internal/repository"
+	"github.com/kanban-simple/internal/unfurl"
 )
 
 // Repositories holds all repository instances
 type Repositories struct {
-	Board *repository.BoardRepository
-	List  *repository.ListRepository
-	Card  *repository.CardRepository
-	Label *repository.LabelRepository
+	Board        *repository.BoardRepository
+	List         *repository.ListRepository
+	Card         *repository.CardRepository
+	Label        *repository.LabelRepository
+	Sprint       *repository.SprintRepository
+	Milestone    *repository.MilestoneRepository
+	Transfer     *repository.TransferRepository
+	User         *repository.UserRepository
+	Notification *repository.NotificationRepository
+	Consistency  *repository.ConsistencyRepository
+	Dashboard    *repository.DashboardRepository
+	Retention    *repository.RetentionRepository
+	Audit        *repository.AuditRepository
+	APIToken     *repository.APITokenRepository
+	Instance     *repository.InstanceRepository
+	Webhook      *repository.WebhookRepository
+	InboundHook  *repository.InboundHookRepository
+	Change       *repository.ChangeRepository
+	Sync         *repository.SyncRepository
+	Member       *repository.MemberRepository
+	Group        *repository.GroupRepository
+	Snippet      *repository.SnippetRepository
+	Digest       *repository.DigestRepository
+}
+
+// Config holds router-level settings
+type Config struct {
+	ReadOnly      *middleware.ReadOnlyMode    // Reject mutating requests with 403 while enabled
+	Spec          *apispec.Spec               // Describes the routes in the spec generated for /openapi.json and /openapi.yaml
+	Runtime       *middleware.Runtime         // Admin token and CORS origins; replaced on reload
+	Reload        func() error                // Reloads the runtime settings for POST /api/admin/reload
+	DBMetrics     func() models.DBMetrics     // Database use for GET /api/admin/metrics
+	RetentionDays int                         // Default retention for archived cards; zero keeps them
+	Quotas        models.Quotas               // Limits on what clients can create
+	MaxBodySize   int64                       // Largest request body in bytes; zero is unlimited
+	MaxImportSize int64                       // Largest body for /api/import/native
+	TrustedHeader *middleware.TrustedHeader   // Reverse-proxy authentication; nil disables it
+	UserHeader    middleware.UserHeaderPolicy // When X-Kanban-User names the acting user; "" is auto
+	Events        *events.Bus                 // Receives every change made through the API; created when nil
+	BasePath      string                      // Path the server is mounted under with middleware.Mount, such as /kanban; "" at the root
+	ImageProxy    *imageproxy.Proxy           // Shows images in rendered Markdown through /api/image-proxy; nil strips them
+	UnfurlLinks   bool                        // Fetch previews of links in descriptions and comments for ?expand=unfurls
 }
 
 // NewRouter creates and configures the Gin router
-func NewRouter(repos *Repositories) *gin.Engine {
+func NewRouter(repos *Repositories, cfg Config) *gin.Engine {
+	registerValidators()
+
 	router := gin.New()
 
 	// Middleware
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowOriginFunc:  cfg.Runtime.AllowsOrigin,
+		AllowMethods:     []string{"GET", "HEAD", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", middleware.UserHeader},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-Total-Count"},
 		AllowCredentials: true,
 	}))
 	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.BasePath(cfg.BasePath))
+	router.Use(middleware.BodyLimit(cfg.MaxBodySize, map[string]int64{"/api/import/native": cfg.MaxImportSize}))
+	router.Use(middleware.ReadOnly(cfg.ReadOnly))
+	userHeader := cfg.UserHeader
+	if userHeader == "" {
+		userHeader = middleware.UserHeaderAuto
+	}
+	router.Use(middleware.CurrentUser(repos.User, repos.APIToken, cfg.TrustedHeader, userHeader))
+	router.Use(middleware.RateLimit(middleware.NewRateLimiter()))
+	router.Use(middleware.TokenScope())
+	router.Use(middleware.Locale())
+	router.Use(middleware.Audit(repos.Audit))
+
+	bus := cfg.Events
+	if bus == nil {
+		bus = events.NewBus()
+	}
+
+	// Link previews are fetched in the background and cached in the
+	// database, which is left alone while the server is read-only
+	var unfurler *unfurl.Unfurler
+	if cfg.UnfurlLinks {
+		unfurler = unfurl.New(func(preview *models.LinkPreview) error {
+			if cfg.ReadOnly.Enabled() {
+				return nil
+			}
+			return repos.Card.SaveLinkPreview(preview)
+		})
+	}
 
 	// Initialize handlers
-	boardHandler := handlers.NewBoardHandler(repos.Board)
-	listHandler := handlers.NewListHandler(repos.List, repos.Board)
-	cardHandler := handlers.NewCardHandler(repos.Card, repos.List, repos.Board)
-	labelHandler := handlers.NewLabelHandler(repos.Label, repos.Card)
+	boardHandler := handlers.NewBoardHandler(repos.Board, repos.List, repos.Label, cfg.Quotas, bus)
+	listHandler := handlers.NewListHandler(repos.List, repos.Board, repos.Label, repos.User, cfg.Quotas, bus)
+	cardHandler := handlers.NewCardHandler(repos.Card, repos.List, repos.Board, repos.Label, repos.Sprint, repos.Milestone, repos.Snippet, repos.User, repos.Notification, repos.Webhook, repos.Member, unfurler, cfg.Quotas, bus)
+	labelHandler := handlers.NewLabelHandler(repos.Label, repos.Card, repos.Member)
+	userHandler := handlers.NewUserHandler(repos.User, repos.Notification)
+	metricsHandler := handlers.NewMetricsHandler(repos.Card, repos.List, repos.Board)
+	sprintHandler := handlers.NewSprintHandler(repos.Sprint, repos.Board)
+	milestoneHandler := handlers.NewMilestoneHandler(repos.Milestone, repos.Board)
+	snippetHandler := handlers.NewSnippetHandler(repos.Snippet, repos.Board)
+	transferHandler := handlers.NewTransferHandler(repos.Transfer, repos.Board, repos.Change, cfg.Quotas, bus)
+	adminHandler := handlers.NewAdminHandler(repos.Consistency, repos.Retention, repos.Audit, repos.User, repos.APIToken, repos.Instance, cfg.ReadOnly, cfg.Runtime, cfg.Reload, cfg.DBMetrics, cfg.RetentionDays)
+	dashboardHandler := handlers.NewDashboardHandler(repos.Dashboard)
+	webhookHandler := handlers.NewWebhookHandler(repos.Webhook, repos.Board, repos.List)
+	digestHandler := handlers.NewDigestHandler(repos.Digest, repos.Board, repos.User)
+	inboundHookHandler := handlers.NewInboundHookHandler(repos.InboundHook, repos.List, cardHandler)
+	changeHandler := handlers.NewChangeHandler(repos.Change, repos.Board, bus)
+	syncHandler := handlers.NewSyncHandler(repos.Sync, cardHandler)
+	memberHandler := handlers.NewMemberHandler(repos.Member, repos.Board, repos.User)
+	groupHandler := handlers.NewGroupHandler(repos.Group, repos.Board, repos.User)
+
+	// Web UI pages and assets
+	ui := loadAssets(staticDir)
+	imageHandler := handlers.NewImageHandler(cfg.ImageProxy)
+
+	// Rendered Markdown links external images through the proxy, under the
+	// base path like every other link
+	if cfg.ImageProxy != nil {
+		markdown.ProxyImages(func(src string) string {
+			if proxied := cfg.ImageProxy.URL(src); proxied != "" {
+				return cfg.BasePath + proxied
+			}
+			return ""
+		})
+	}
+
+	// Notifications and alerts react to changes rather than being called
+	// from each handler
+	cardHandler.Subscribe(bus)
+
+	// Routes on a board, and on the lists, cards and other entities on it,
+	// check the acting user's role on the board once it is restricted to its
+	// members
+	boardOwner := middleware.BoardOwner(repos.Member)
+	listRole := middleware.BoardRole(repos.Member, "lists")
+	cardRole := middleware.BoardRole(repos.Member, "cards")
 
 	// API routes
 	api := router.Group("/api")
 	{
 		// Health check
 		api.GET("/health", func(c *gin.Context) {
-			c.JSON(200, gin.H{"status": "healthy"})
+			c.JSON(200, gin.H{"status": "healthy", "read_only": cfg.ReadOnly.Enabled()})
 		})
 
+		// Branding for the web UI
+		api.GET("/theme", adminHandler.Theme)
+
+		// External images in rendered Markdown
+		api.GET("/image-proxy", imageHandler.Get)
+
+		// Interactive API explorer backed by the OpenAPI spec
+		api.GET("/docs", ui.page("docs.html", cfg.BasePath))
+
 		// Board endpoints
-		boards := api.Group("/boards")
+		boards := api.Group("/boards", middleware.BoardRole(repos.Member, "boards"))
 		{
 			boards.GET("", boardHandler.GetAll)
+			boards.HEAD("", boardHandler.GetAll)
 			boards.POST("", boardHandler.Create)
+			boards.PUT("/order", boardHandler.Reorder)
 			boards.GET("/:id", boardHandler.GetByID)
 			boards.PUT("/:id", boardHandler.Update)
-			boards.DELETE("/:id", boardHandler.Delete)
+			boards.DELETE("/:id", boardOwner, boardHandler.Delete)
+			boards.PATCH("/:id/star", boardHandler.Star)
+			boards.GET("/:id/settings", boardHandler.GetSettings)
+			boards.PUT("/:id/settings", boardHandler.UpdateSettings)
+			boards.GET("/:id/calendar", cardHandler.Calendar)
+			boards.GET("/:id/timeline", cardHandler.Timeline)
+			boards.GET("/:id/stale", cardHandler.Stale)
+			boards.GET("/:id/poll", changeHandler.Poll)
+			boards.GET("/:id/delete-preview", boardHandler.DeletePreview)
+			boards.GET("/:id/export.md", cardHandler.ExportMarkdown)
+			boards.GET("/:id/report", digestHandler.BoardReport)
+			boards.GET("/:id/report.md", digestHandler.BoardReportMarkdown)
+			boards.GET("/:id/export.json", transferHandler.Export)
+			boards.GET("/:id/cards/by-number/:number", cardHandler.GetByNumber)
+			boards.GET("/:id/cards/count", cardHandler.Count)
+			boards.HEAD("/:id/cards/count", cardHandler.Count)
+			boards.GET("/:id/metrics/cycle-time", metricsHandler.CycleTime)
+			boards.GET("/:id/metrics/throughput", metricsHandler.Throughput)
+			boards.GET("/:id/metrics/time-in-lists", metricsHandler.TimeInLists)
+
+			// Sprints endpoints (nested under boards)
+			boards.GET("/:id/sprints", sprintHandler.GetByBoardID)
+			boards.POST("/:id/sprints", sprintHandler.Create)
+
+			// Milestones endpoints (nested under boards)
+			boards.GET("/:id/milestones", milestoneHandler.GetByBoardID)
+			boards.POST("/:id/milestones", milestoneHandler.Create)
+
+			// Description snippets (nested under boards)
+			boards.GET("/:id/snippets", snippetHandler.GetByBoardID)
+			boards.POST("/:id/snippets", snippetHandler.Create)
+			boards.POST("/:id/snippets/expand", snippetHandler.Expand)
+
+			// Members and invitations (nested under boards)
+			boards.GET("/:id/members", memberHandler.GetByBoardID)
+			boards.POST("/:id/members", boardOwner, memberHandler.Add)
+			boards.PUT("/:id/members/:user_id", boardOwner, memberHandler.Update)
+			boards.DELETE("/:id/members/:user_id", boardOwner, memberHandler.Remove)
+			boards.GET("/:id/invitations", boardOwner, memberHandler.GetInvitations)
+			boards.POST("/:id/invitations", boardOwner, memberHandler.Invite)
+			boards.DELETE("/:id/invitations/:invitation_id", boardOwner, memberHandler.RevokeInvitation)
+			boards.GET("/:id/groups", groupHandler.GetBoardGrants)
+			boards.POST("/:id/groups", boardOwner, groupHandler.GrantBoard)
+			boards.PUT("/:id/groups/:group_id", boardOwner, groupHandler.UpdateBoardGrant)
+			boards.DELETE("/:id/groups/:group_id", boardOwner, groupHandler.RevokeBoard)
+			boards.GET("/:id/access", memberHandler.Access)
 
 			// Lists endpoints (nested under boards)
 			boards.GET("/:id/lists", listHandler.GetByBoardID)
@@ -61,39 +236,72 @@ func NewRouter(repos *Repositories) *gin.Engine {
 		}
 
 		// List endpoints
-		lists := api.Group("/lists")
+		lists := api.Group("/lists", listRole)
 		{
 			lists.GET("/:id", listHandler.GetByID)
 			lists.PUT("/:id", listHandler.Update)
 			lists.PATCH("/:id/move", listHandler.Move)
 			lists.DELETE("/:id", listHandler.Delete)
+			lists.GET("/:id/delete-preview", listHandler.DeletePreview)
+			lists.GET("/:id/settings", listHandler.GetSettings)
+			lists.PUT("/:id/settings", listHandler.UpdateSettings)
+			lists.POST("/:id/archive-cards", cardHandler.ArchiveList)
 
 			// Cards endpoints (nested under lists)
 			lists.GET("/:id/cards", cardHandler.GetByListID)
+			lists.HEAD("/:id/cards", cardHandler.GetByListID)
 			lists.POST("/:id/cards", cardHandler.Create)
 		}
 
 		// Card endpoints
-		cards := api.Group("/cards")
+		// Card routes take a reference such as OPS-214 in place of an ID
+		cards := api.Group("/cards", cardHandler.ResolveReference, cardRole)
 		{
 			cards.GET("", cardHandler.Search)
+			cards.HEAD("", cardHandler.Search)
 			cards.GET("/:id", cardHandler.GetByID)
+			cards.GET("/by-key/:key", cardHandler.GetByKey)
+			cards.POST("/from-url", cardHandler.CreateFromURL)
 			cards.PUT("/:id", cardHandler.Update)
 			cards.PATCH("/:id/move", cardHandler.Move)
+			cards.GET("/:id/moves", cardHandler.GetMoves)
+			cards.GET("/:id/backlinks", cardHandler.GetBacklinks)
 			cards.POST("/:id/archive", cardHandler.Archive)
 			cards.POST("/:id/unarchive", cardHandler.Unarchive)
+			cards.POST("/:id/complete", cardHandler.Complete)
+			cards.POST("/:id/uncomplete", cardHandler.Uncomplete)
+			cards.POST("/:id/block", cardHandler.Block)
+			cards.POST("/:id/unblock", cardHandler.Unblock)
+			cards.POST("/:id/snooze", cardHandler.Snooze)
+			cards.GET("/:id/snoozes", cardHandler.GetSnoozes)
+			cards.GET("/:id/time-in-lists", metricsHandler.CardTimeInLists)
 			cards.DELETE("/:id", cardHandler.Delete)
 
 			// Comments
 			cards.GET("/:id/comments", cardHandler.GetComments)
+			cards.HEAD("/:id/comments", cardHandler.GetComments)
 			cards.POST("/:id/comments", cardHandler.AddComment)
 		}
 
+		// Comment endpoints
+		comments := api.Group("/comments", middleware.BoardRole(repos.Member, "comments"))
+		{
+			comments.PUT("/:id", cardHandler.UpdateComment)
+			comments.DELETE("/:id", cardHandler.DeleteComment)
+		}
+
 		// Quick card creation for bots
 		api.POST("/cards/quick", cardHandler.QuickCreate)
 
+		// Import endpoint
+		api.POST("/import/native", transferHandler.ImportNative)
+
 		// Search endpoint
 		api.GET("/search", cardHandler.Search)
+		api.HEAD("/search", cardHandler.Search)
+
+		// Cross-board landing page
+		api.GET("/dashboard", dashboardHandler.Get)
 
 		// Label endpoints
 		labels := api.Group("/labels")
@@ -103,22 +311,193 @@ func NewRouter(repos *Repositories) *gin.Engine {
 			labels.GET("/:id", labelHandler.GetByID)
 			labels.PUT("/:id", labelHandler.Update)
 			labels.DELETE("/:id", labelHandler.Delete)
+			labels.POST("/:id/cards", labelHandler.AssignToCards)
+			labels.DELETE("/:id/cards", labelHandler.RemoveFromCards)
+		}
+
+		// User endpoints
+		users := api.Group("/users")
+		{
+			users.GET("", userHandler.GetAll)
+			users.POST("", userHandler.Create)
+			users.GET("/:id", userHandler.GetByID)
+			users.GET("/:id/activity", userHandler.Activity)
+		}
+
+		// Group endpoints
+		groups := api.Group("/groups")
+		{
+			groups.GET("", groupHandler.GetAll)
+			groups.POST("", groupHandler.Create)
+			groups.GET("/:id", groupHandler.GetByID)
+			groups.PUT("/:id", groupHandler.Update)
+			groups.DELETE("/:id", groupHandler.Delete)
+			groups.GET("/:id/members", groupHandler.GetMembers)
+			groups.POST("/:id/members", groupHandler.AddMember)
+			groups.DELETE("/:id/members/:user_id", groupHandler.RemoveMember)
+		}
+
+		// Endpoints for the acting user
+		me := api.Group("/me")
+		{
+			me.GET("", userHandler.Me)
+			me.PUT("", userHandler.UpdateMe)
+			me.GET("/activity", userHandler.MyActivity)
+			me.GET("/mentions", userHandler.Mentions)
+			me.GET("/notifications", userHandler.Notifications)
+			me.POST("/notifications/:id/read", userHandler.MarkNotificationRead)
 		}
 
+		// Board invitations are accepted by the acting user
+		api.POST("/invitations/accept", memberHandler.AcceptInvitation)
+
+		// Sprints endpoints
+		sprints := api.Group("/sprints", middleware.BoardRole(repos.Member, "sprints"))
+		{
+			sprints.GET("/:id", sprintHandler.GetByID)
+			sprints.PUT("/:id", sprintHandler.Update)
+			sprints.DELETE("/:id", sprintHandler.Delete)
+			sprints.POST("/:id/start", sprintHandler.Start)
+			sprints.POST("/:id/close", sprintHandler.Close)
+		}
+
+		// Milestones endpoints
+		milestones := api.Group("/milestones", middleware.BoardRole(repos.Member, "milestones"))
+		{
+			milestones.GET("/:id", milestoneHandler.GetByID)
+			milestones.PUT("/:id", milestoneHandler.Update)
+			milestones.DELETE("/:id", milestoneHandler.Delete)
+		}
+
+		// Snippets endpoints
+		snippets := api.Group("/snippets", middleware.BoardRole(repos.Member, "snippets"))
+		{
+			snippets.GET("/:id", snippetHandler.GetByID)
+			snippets.PUT("/:id", snippetHandler.Update)
+			snippets.DELETE("/:id", snippetHandler.Delete)
+		}
+
+		// Webhook endpoints
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.GET("", webhookHandler.GetAll)
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("/:id", webhookHandler.GetByID)
+			webhooks.PUT("/:id", webhookHandler.Update)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+			webhooks.POST("/:id/rotate-secret", webhookHandler.RotateSecret)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+		}
+
+		// Digest endpoints; due digests are sent by the scheduler
+		digests := api.Group("/digests")
+		{
+			digests.GET("", digestHandler.GetAll)
+			digests.POST("", digestHandler.Create)
+			digests.GET("/:id", digestHandler.GetByID)
+			digests.PUT("/:id", digestHandler.Update)
+			digests.DELETE("/:id", digestHandler.Delete)
+			digests.GET("/:id/preview", digestHandler.Preview)
+		}
+
+		// Inbound hook endpoints; payloads are posted to /hooks/:token
+		inboundHooks := api.Group("/inbound-hooks")
+		{
+			inboundHooks.GET("", inboundHookHandler.GetAll)
+			inboundHooks.POST("", inboundHookHandler.Create)
+			inboundHooks.GET("/:id", inboundHookHandler.GetByID)
+			inboundHooks.PUT("/:id", inboundHookHandler.Update)
+			inboundHooks.DELETE("/:id", inboundHookHandler.Delete)
+			inboundHooks.POST("/:id/rotate-token", inboundHookHandler.Rotate)
+		}
+		api.POST("/hooks/:token", inboundHookHandler.Receive)
+
+		// Change log for incremental sync
+		api.GET("/changes", changeHandler.GetChanges)
+
+		// Card operations queued by offline clients
+		api.POST("/sync", syncHandler.Sync)
+
 		// Card-Label associations
-		api.POST("/cards/:id/labels/:label_id", labelHandler.AssignToCard)
-		api.DELETE("/cards/:id/labels/:label_id", labelHandler.RemoveFromCard)
-		api.GET("/cards/:id/labels", labelHandler.GetCardLabels)
+		api.POST("/cards/:id/labels/:label_id", cardHandler.ResolveReference, cardRole, labelHandler.AssignToCard)
+		api.DELETE("/cards/:id/labels/:label_id", cardHandler.ResolveReference, cardRole, labelHandler.RemoveFromCard)
+		api.GET("/cards/:id/labels", cardHandler.ResolveReference, cardRole, labelHandler.GetCardLabels)
+
+		// Maintenance endpoints
+		admin := api.Group("/admin", middleware.AdminToken(cfg.Runtime))
+		{
+			admin.GET("/consistency", adminHandler.Consistency)
+			admin.POST("/consistency/repair", adminHandler.RepairConsistency)
+			admin.POST("/purge", adminHandler.Purge)
+			admin.GET("/audit/export", adminHandler.AuditExport)
+			admin.GET("/stats", adminHandler.Stats)
+			admin.GET("/metrics", adminHandler.Metrics)
+			admin.POST("/checkpoint", adminHandler.Checkpoint)
+			admin.PUT("/read-only", adminHandler.SetReadOnly)
+			admin.POST("/reload", adminHandler.Reload)
+			admin.PUT("/theme", adminHandler.UpdateTheme)
+			admin.DELETE("/theme", adminHandler.ResetTheme)
+			admin.GET("/users", adminHandler.GetUsers)
+			admin.POST("/users/:id/disable", adminHandler.DisableUser)
+			admin.POST("/users/:id/enable", adminHandler.EnableUser)
+			admin.POST("/users/:id/tokens", adminHandler.CreateToken)
+			admin.GET("/tokens", adminHandler.GetTokens)
+			admin.PUT("/tokens/:id", adminHandler.UpdateToken)
+			admin.POST("/tokens/:id/rotate", adminHandler.RotateToken)
+			admin.DELETE("/tokens/:id", adminHandler.RevokeToken)
+		}
+	}
+
+	// Version 1 returns collections as {data, meta} pages; the handlers and
+	// board role checks are the ones above
+	v1 := router.Group("/api/v1", middleware.Envelope())
+	{
+		v1.GET("/boards", boardHandler.GetAll)
+		v1.GET("/lists/:id/cards", listRole, cardHandler.GetByListID)
+		v1.GET("/cards", cardHandler.Search)
+		v1.GET("/search", cardHandler.Search)
+		v1.GET("/cards/:id/comments", cardHandler.ResolveReference, cardRole, cardHandler.GetComments)
+		v1.GET("/changes", changeHandler.GetChanges)
+	}
+
+	// Serve OpenAPI specification. A loaded spec is served as generated
+	// from the route table once every route is registered, below.
+	var specJSON, specYAML []byte
+	if cfg.Spec != nil {
+		router.GET("/openapi.json", func(c *gin.Context) {
+			c.Data(200, "application/json", specJSON)
+		})
+		router.GET("/openapi.yaml", func(c *gin.Context) {
+			c.Data(200, "application/yaml", specYAML)
+		})
+	} else {
+		router.StaticFile("/openapi.yaml", "./openapi.yaml")
 	}
 
-	// Serve OpenAPI specification
-	router.StaticFile("/openapi.yaml", "./openapi.yaml")
+	// Static files (web UI), fingerprinted so they can be cached for good
+	router.GET("/static/*filepath", ui.serve)
+	router.HEAD("/static/*filepath", ui.serve)
+	router.GET("/", ui.page("index.html", cfg.BasePath))
+	router.GET("/c/:key", cardHandler.Permalink)
 
-	// Static files (web UI)
-	router.Static("/static", "./web/static")
-	router.GET("/", func(c *gin.Context) {
-		c.File("./web/static/index.html")
-	})
+	if cfg.Spec != nil {
+		specJSON, specYAML = generateSpec(cfg.Spec, router.Routes())
+	}
 
 	return router
-}
\ No newline at end of file
+}
+
+// generateSpec builds the served spec from the route table, falling back to
+// the loaded document as it is if that fails
+func generateSpec(spec *apispec.Spec, routes gin.RoutesInfo) ([]byte, []byte) {
+	generated, err := spec.Generate(routes)
+	if err != nil {
+		log.Printf("Failed to generate OpenAPI spec: %v", err)
+		generated = spec.JSON()
+	}
+	asYAML, err := yaml.JSONToYAML(generated)
+	if err != nil {
+		log.Printf("Failed to convert OpenAPI spec to YAML: %v", err)
+	}
+	return generated, asYAML
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kanban-simple/internal/apispec"
+)
+
+// TestSpecMatchesRoutes keeps the hand-maintained openapi.yaml in step with
+// the routes the router registers
+func TestSpecMatchesRoutes(t *testing.T) {
+	spec, err := apispec.Load("../../openapi.yaml")
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+
+	routes := newTestRouter(t).Routes()
+	for _, route := range spec.Undocumented(routes) {
+		t.Errorf("route missing from openapi.yaml: %s", route)
+	}
+	for _, op := range spec.Unrouted(routes) {
+		t.Errorf("openapi.yaml operation has no route: %s", op)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kanban-simple/internal/models"
+)
+
+// syncOp posts one sync operation as user and returns its result
+func syncOp(t *testing.T, router *gin.Engine, user string, op map[string]interface{}) models.SyncResult {
+	t.Helper()
+	w := call(router, user, http.MethodPost, "/api/sync", map[string]interface{}{"ops": []interface{}{op}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("%q sync: %d %s", user, w.Code, w.Body.String())
+	}
+	var resp models.SyncResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Results) != 1 {
+		t.Fatalf("%q sync: %s", user, w.Body.String())
+	}
+	return resp.Results[0]
+}
+
+func TestSyncOpIDsBelongToTheirUser(t *testing.T) {
+	router := newTestRouter(t)
+
+	create(t, router, "", "/api/users", map[string]string{"username": "alice"})
+	create(t, router, "", "/api/users", map[string]string{"username": "olivia"})
+	board := create(t, router, "", "/api/boards", map[string]string{"name": "Trips"})
+	list := create(t, router, "", fmt.Sprintf("/api/boards/%d/lists", board), map[string]string{"name": "Todo"})
+	createOp := func(title string) map[string]interface{} {
+		return map[string]interface{}{
+			"op_id": "q2", "type": "create",
+			"fields": map[string]interface{}{"list_id": list, "title": title, "description": "Private notes"},
+		}
+	}
+
+	first := syncOp(t, router, "alice", createOp("Book hotel"))
+	if first.Status != models.SyncApplied || first.Card == nil {
+		t.Fatalf("alice create: %+v", first)
+	}
+
+	// Resending the same operation replays it
+	again := syncOp(t, router, "alice", createOp("Book hotel"))
+	if !again.Replayed || again.Card == nil || again.Card.ID != first.Card.ID {
+		t.Errorf("alice resend: %+v, want replay of card %d", again, first.Card.ID)
+	}
+
+	// Another client's op_id of the same name is its own operation
+	other := syncOp(t, router, "", createOp("Buy tickets"))
+	if other.Replayed || other.Card == nil || other.Card.ID == first.Card.ID || other.Card.Title != "Buy tickets" {
+		t.Errorf("anonymous create reusing q2: %+v, want a new card", other)
+	}
+
+	// Reusing an op_id for a different operation is rejected
+	reused := syncOp(t, router, "alice", createOp("Rent car"))
+	if reused.Status != models.SyncRejected || reused.Card != nil {
+		t.Errorf("alice reuses q2: %+v, want rejected", reused)
+	}
+
+	// Replays check access as a new operation would
+	card := first.Card.ID
+	if w := call(router, "alice", http.MethodPut, fmt.Sprintf("/api/cards/%d", card), map[string]string{"visibility": "restricted"}); w.Code != http.StatusOK {
+		t.Fatalf("restrict card: %d %s", w.Code, w.Body.String())
+	}
+	create(t, router, "", fmt.Sprintf("/api/boards/%d/members", board), map[string]string{"username": "olivia", "role": "owner"})
+	w := call(router, "alice", http.MethodPost, "/api/sync", map[string]interface{}{"ops": []interface{}{createOp("Book hotel")}})
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Private notes") || !strings.Contains(w.Body.String(), models.SyncRejected) {
+		t.Errorf("alice replay after losing access: %d %s, want rejected without the card", w.Code, w.Body.String())
+	}
+}
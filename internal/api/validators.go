@@ -0,0 +1,55 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/mapping"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/netguard"
+)
+
+// registerValidators installs the custom binding validators used by request models
+func registerValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Report fields by their JSON names so error details match the request body
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("color", func(fl validator.FieldLevel) bool {
+		return models.IsValidColor(fl.Field().String())
+	})
+	v.RegisterValidation("icon", func(fl validator.FieldLevel) bool {
+		return models.IsValidIcon(fl.Field().String())
+	})
+	v.RegisterValidation("logourl", func(fl validator.FieldLevel) bool {
+		return models.IsValidLogoURL(fl.Field().String())
+	})
+	v.RegisterValidation("publicurl", func(fl validator.FieldLevel) bool {
+		return netguard.PublicURL(fl.Field().String())
+	})
+	v.RegisterValidation("keyprefix", func(fl validator.FieldLevel) bool {
+		return models.IsValidKeyPrefix(fl.Field().String())
+	})
+	v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return models.IsValidUsername(fl.Field().String())
+	})
+	v.RegisterValidation("event", func(fl validator.FieldLevel) bool {
+		return events.IsType(fl.Field().String())
+	})
+	v.RegisterValidation("template", func(fl validator.FieldLevel) bool {
+		return mapping.Validate(fl.Field().String()) == nil
+	})
+}
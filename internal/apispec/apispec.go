@@ -0,0 +1,119 @@
+package apispec
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-yaml"
+)
+
+// methods are the OpenAPI operation keys that correspond to HTTP methods
+var methods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// Spec is a loaded OpenAPI document
+type Spec struct {
+	json       []byte
+	basePath   string          // Path of the first server URL, e.g. /api
+	operations map[string]bool // "METHOD /normalized/path"
+}
+
+// Load reads an OpenAPI YAML document
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	doc, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	var parsed struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(doc, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode spec: %w", err)
+	}
+
+	spec := &Spec{json: doc, operations: make(map[string]bool)}
+	if len(parsed.Servers) > 0 {
+		if u, err := url.Parse(parsed.Servers[0].URL); err == nil {
+			spec.basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+	for path, item := range parsed.Paths {
+		for method := range item {
+			if methods[method] {
+				spec.operations[operation(strings.ToUpper(method), path)] = true
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// JSON returns the document as JSON
+func (s *Spec) JSON() []byte {
+	return s.json
+}
+
+// Undocumented lists registered routes under the spec's base path that have
+// no matching operation, as "METHOD /path"
+func (s *Spec) Undocumented(routes gin.RoutesInfo) []string {
+	missing := []string{}
+	for _, route := range routes {
+		path, ok := strings.CutPrefix(route.Path, s.basePath)
+		if !ok || path == "" || path[0] != '/' {
+			continue
+		}
+		if !s.operations[operation(route.Method, path)] {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Unrouted lists documented operations that no registered route serves
+func (s *Spec) Unrouted(routes gin.RoutesInfo) []string {
+	registered := make(map[string]bool)
+	for _, route := range routes {
+		if path, ok := strings.CutPrefix(route.Path, s.basePath); ok {
+			registered[operation(route.Method, path)] = true
+		}
+	}
+
+	stale := []string{}
+	for op := range s.operations {
+		if !registered[op] {
+			stale = append(stale, op)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// operation builds a lookup key with path parameters normalized, so gin's
+// /boards/:id and OpenAPI's /boards/{boardId} match
+func operation(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") ||
+			(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
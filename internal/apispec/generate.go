@@ -0,0 +1,120 @@
+package apispec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Generate builds the document served to clients from the route table: it
+// has an operation for every registered route under the base path and no
+// others. Operations the loaded document describes keep their description;
+// routes it lacks get a stub naming their path parameters and marked
+// x-undocumented, and operations without a route are left out.
+func (s *Spec) Generate(routes gin.RoutesInfo) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(s.json, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode spec: %w", err)
+	}
+	documented, _ := doc["paths"].(map[string]interface{})
+
+	// Documented operations by lookup key, with the path they are under
+	type entry struct {
+		path string
+		op   interface{}
+	}
+	described := make(map[string]entry)
+	for path, item := range documented {
+		operations, _ := item.(map[string]interface{})
+		for method, op := range operations {
+			if methods[method] {
+				described[operation(strings.ToUpper(method), path)] = entry{path, op}
+			}
+		}
+	}
+
+	sorted := append(gin.RoutesInfo(nil), routes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Method < sorted[j].Method
+	})
+
+	paths := make(map[string]interface{})
+	for _, route := range sorted {
+		path, ok := strings.CutPrefix(route.Path, s.basePath)
+		if !ok || path == "" || path[0] != '/' {
+			continue
+		}
+
+		e, ok := described[operation(route.Method, path)]
+		if !ok {
+			e = entry{openAPIPath(path), stub(route.Method, route.Path, path)}
+		}
+		item, ok := paths[e.path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			// Keep parameters and summaries shared by a documented path's operations
+			if original, ok := documented[e.path].(map[string]interface{}); ok {
+				for key, value := range original {
+					if !methods[key] {
+						item[key] = value
+					}
+				}
+			}
+			paths[e.path] = item
+		}
+		item[strings.ToLower(route.Method)] = e.op
+	}
+	doc["paths"] = paths
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode spec: %w", err)
+	}
+	return data, nil
+}
+
+// openAPIPath turns gin's /boards/:id into OpenAPI's /boards/{id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		} else if name, ok := strings.CutPrefix(segment, "*"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// stub describes a route the loaded document lacks by its path parameters
+func stub(method, fullPath, path string) map[string]interface{} {
+	parameters := []interface{}{}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     segment[1:],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+
+	op := map[string]interface{}{
+		"summary":        method + " " + fullPath,
+		"x-undocumented": true,
+		"responses": map[string]interface{}{
+			"default": map[string]interface{}{"description": "Not yet documented"},
+		},
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+	return op
+}
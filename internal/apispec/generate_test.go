@@ -0,0 +1,53 @@
+package apispec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGenerateFollowsRoutes(t *testing.T) {
+	spec, err := Load("../../openapi.yaml")
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+
+	routes := gin.RoutesInfo{
+		{Method: "GET", Path: "/api/boards"},
+		{Method: "GET", Path: "/api/boards/:id"},
+		{Method: "POST", Path: "/api/extra/:id/things"},
+		{Method: "GET", Path: "/static/*filepath"},
+	}
+	data, err := spec.Generate(routes)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Summary      string        `json:"summary"`
+			Undocumented bool          `json:"x-undocumented"`
+			Parameters   []interface{} `json:"parameters"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode generated spec: %v", err)
+	}
+
+	if len(doc.Paths) != 3 {
+		t.Errorf("generated %d paths, want 3: %v", len(doc.Paths), doc.Paths)
+	}
+	if op, ok := doc.Paths["/boards"]["get"]; !ok || op.Undocumented || op.Summary != "List all boards" {
+		t.Errorf("GET /boards = %+v, want the documented operation", op)
+	}
+	if _, ok := doc.Paths["/boards/{boardId}"]["get"]; !ok {
+		t.Errorf("GET /boards/:id is not under its documented path")
+	}
+	if _, ok := doc.Paths["/boards/{boardId}"]["delete"]; ok {
+		t.Errorf("DELETE /boards/{boardId} has no route but was kept")
+	}
+	if op, ok := doc.Paths["/extra/{id}/things"]["post"]; !ok || !op.Undocumented || len(op.Parameters) != 1 {
+		t.Errorf("POST /extra/:id/things = %+v, want an undocumented stub with its path parameter", op)
+	}
+}
@@ -1,50 +1,116 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/kanban-simple/internal/models"
 )
 
+// Memory is the database path for an in-memory database, which lives until
+// the server stops
+const Memory = ":memory:"
+
 // DB holds the database connection
 type DB struct {
 	*sql.DB
+	connector *connector
+	pinned    *sql.Conn // Keeps an in-memory database alive
 }
 
-// NewConnection creates a new database connection
+// NewConnection creates a new database connection. With Memory as the path
+// the database is held in memory, shared by every pooled connection.
 func NewConnection(dbPath string) (*DB, error) {
-	// Create database file if it doesn't exist
-	db, err := sql.Open("sqlite", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+	// Every connection to ":memory:" would get a database of its own
+	dsn := dbPath
+	if dbPath == Memory {
+		dsn = "file:kanban?mode=memory&cache=shared"
 	}
 
-	// Set optimal SQLite settings for web application
+	// Store times in SQLite's own format so datetime() can compare them. The
+	// pragmas go in the DSN too, as the driver runs them on every connection
+	// it opens; most only last for the connection that set them.
 	pragmas := []string{
-		"PRAGMA journal_mode=WAL",           // Write-Ahead Logging for concurrency
-		"PRAGMA synchronous=NORMAL",         // Balance between safety and performance
-		"PRAGMA cache_size=-64000",          // 64MB cache
-		"PRAGMA foreign_keys=ON",            // Enable foreign key constraints
-		"PRAGMA temp_store=MEMORY",          // Store temp tables in memory
-		"PRAGMA busy_timeout=5000",          // 5 second timeout for locks
+		"busy_timeout(5000)",  // 5 second timeout for locks
+		"foreign_keys(1)",     // Deletes rely on ON DELETE CASCADE and SET NULL
+		"journal_mode(WAL)",   // Write-Ahead Logging for concurrency
+		"synchronous(NORMAL)", // Balance between safety and performance
+		"cache_size(-64000)",  // 64MB cache
+		"temp_store(MEMORY)",  // Store temp tables in memory
 	}
-
+	if strings.Contains(dsn, "?") {
+		dsn += "&"
+	} else {
+		dsn += "?"
+	}
+	dsn += "_time_format=sqlite"
 	for _, pragma := range pragmas {
-		if _, err := db.Exec(pragma); err != nil {
-			return nil, fmt.Errorf("failed to set pragma %s: %w", pragma, err)
-		}
+		dsn += "&_pragma=" + pragma
+	}
+
+	// Create database file if it doesn't exist
+	c := &connector{dsn: dsn}
+	db := sql.OpenDB(c)
+
+	// Deletes rely on ON DELETE CASCADE and SET NULL, so refuse to run without them
+	var foreignKeys bool
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return nil, fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	if !foreignKeys {
+		return nil, fmt.Errorf("foreign key enforcement is not enabled")
 	}
 
 	// Set connection pool settings
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 
-	return &DB{db}, nil
+	// An in-memory database is dropped when its last connection closes
+	var pinned *sql.Conn
+	if dbPath == Memory {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to hold in-memory database: %w", err)
+		}
+		pinned = conn
+	}
+
+	return &DB{DB: db, connector: c, pinned: pinned}, nil
+}
+
+// SetQueryTimeout limits how long each statement may run from now on; 0
+// removes the limit. Statements past it fail with ErrQueryTimeout.
+func (db *DB) SetQueryTimeout(timeout time.Duration) {
+	db.connector.timeout.Store(int64(timeout))
+}
+
+// Instrument starts recording how long statements take, for Metrics, and
+// logging those that take at least slowThreshold, naming their arguments'
+// types but not their values; 0 logs none. Recording starts over.
+func (db *DB) Instrument(slowThreshold time.Duration) {
+	db.connector.stats.Store(newQueryStats(slowThreshold))
+}
+
+// Metrics reports statement durations since Instrument and connection pool
+// use
+func (db *DB) Metrics() models.DBMetrics {
+	pool := db.Stats()
+	metrics := models.DBMetrics{
+		OpenConnections:  pool.OpenConnections,
+		InUseConnections: pool.InUse,
+		IdleConnections:  pool.Idle,
+		WaitCount:        pool.WaitCount,
+		WaitDuration:     pool.WaitDuration,
+	}
+	metrics.Statements, metrics.SlowStatements, metrics.TimedOut = db.connector.stats.Load().snapshot()
+	return metrics
 }
 
 // RunMigrations executes all SQL migration files
@@ -118,7 +184,27 @@ func (db *DB) RunMigrations(migrationsPath string) error {
 	return nil
 }
 
+// Snapshot writes a consistent copy of the database to path, replacing any
+// file there, e.g. to keep an in-memory database. The copy is written
+// beside path first, so a failed snapshot leaves an earlier one intact.
+func (db *DB) Snapshot(path string) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old snapshot: %w", err)
+	}
+	if _, err := db.ExecContext(WithoutTimeout(context.Background()), "VACUUM INTO ?", tmp); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
+	if db.pinned != nil {
+		db.pinned.Close()
+	}
 	return db.DB.Close()
 }
\ No newline at end of file
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// ErrQueryTimeout is returned by statements that ran past the query timeout
+var ErrQueryTimeout = errors.New("query timed out")
+
+// connector opens SQLite connections whose statements are timed, for the
+// metrics and slow query log, and run under a deadline, so one slow query
+// cannot hold the database, and its write lock, while every other request
+// waits behind busy_timeout
+type connector struct {
+	dsn     string
+	driver  sqlite.Driver
+	timeout atomic.Int64               // Nanoseconds; 0 means no deadline
+	stats   atomic.Pointer[queryStats] // nil until Instrument
+}
+
+// Connect implements driver.Connector
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	inner, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: inner, connector: c}, nil
+}
+
+// Driver implements driver.Connector
+func (c *connector) Driver() driver.Driver {
+	return &c.driver
+}
+
+// conn times each statement and runs it under the connector's deadline.
+// SQLite interrupts a statement still running when its deadline passes; a
+// query whose rows are still being read stops at the next row.
+type conn struct {
+	driver.Conn
+	connector *connector
+}
+
+// noTimeout marks contexts whose statements run without the query timeout
+type noTimeout struct{}
+
+// WithoutTimeout returns a context whose statements are not stopped at the
+// query timeout, for maintenance that is slow by nature
+func WithoutTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeout{}, true)
+}
+
+// deadline derives the context a statement runs under
+func (c *conn) deadline(ctx context.Context) (context.Context, context.CancelFunc, time.Duration) {
+	timeout := time.Duration(c.connector.timeout.Load())
+	if timeout <= 0 || ctx.Value(noTimeout{}) != nil {
+		return ctx, func() {}, 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, timeout
+}
+
+// ExecContext implements driver.ExecerContext
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ctx, cancel, timeout := c.deadline(ctx)
+	defer cancel()
+
+	start := time.Now()
+	result, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	c.connector.stats.Load().observe(query, args, time.Since(start))
+	return result, c.timedOut(ctx, err, timeout, query)
+}
+
+// QueryContext implements driver.QueryerContext
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, cancel, timeout := c.deadline(ctx)
+	defer cancel()
+
+	start := time.Now()
+	inner, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	took := time.Since(start)
+	if err != nil {
+		c.connector.stats.Load().observe(query, args, took)
+		return nil, c.timedOut(ctx, err, timeout, query)
+	}
+	r := &rows{Rows: inner, connector: c.connector, query: query, args: args, took: took, timeout: timeout}
+	if timeout > 0 {
+		r.deadline, _ = ctx.Deadline()
+	}
+	return r, nil
+}
+
+// PrepareContext implements driver.ConnPrepareContext
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+// BeginTx implements driver.ConnBeginTx. Transactions are not limited as a
+// whole; each statement in them is.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+// Ping implements driver.Pinger
+func (c *conn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+// ResetSession implements driver.SessionResetter
+func (c *conn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+// IsValid implements driver.Validator
+func (c *conn) IsValid() bool {
+	return c.Conn.(driver.Validator).IsValid()
+}
+
+// timedOut turns the error of a statement interrupted at its deadline into
+// ErrQueryTimeout, logging the statement
+func (c *conn) timedOut(ctx context.Context, err error, timeout time.Duration, query string) error {
+	if err == nil || timeout == 0 || ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	c.connector.logTimeout(timeout, query)
+	return fmt.Errorf("%w after %s: %v", ErrQueryTimeout, timeout, err)
+}
+
+// rows adds the time spent reading a query's rows to the query's, and stops
+// reading once its deadline has passed
+type rows struct {
+	driver.Rows
+	connector *connector
+	query     string
+	args      []driver.NamedValue
+	took      time.Duration // Spent in SQLite so far
+	timeout   time.Duration
+	deadline  time.Time // Zero without a timeout
+}
+
+// Next implements driver.Rows
+func (r *rows) Next(dest []driver.Value) error {
+	if !r.deadline.IsZero() && time.Now().After(r.deadline) {
+		r.connector.logTimeout(r.timeout, r.query)
+		return fmt.Errorf("%w after %s", ErrQueryTimeout, r.timeout)
+	}
+	start := time.Now()
+	err := r.Rows.Next(dest)
+	r.took += time.Since(start)
+	return err
+}
+
+// Close implements driver.Rows, recording the query
+func (r *rows) Close() error {
+	r.connector.stats.Load().observe(r.query, r.args, r.took)
+	return r.Rows.Close()
+}
+
+// logTimeout counts and logs a statement that ran past the query timeout,
+// without its arguments, which may hold user content
+func (c *connector) logTimeout(timeout time.Duration, query string) {
+	c.stats.Load().timeout()
+	log.Printf("Query exceeded the %s query timeout and was stopped: %s", timeout, oneLine(query))
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the statement
+// duration histograms
+var durationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// maxClassified caps how many distinct statements have their kind
+// remembered; searches build their SQL, so there is no fixed set
+const maxClassified = 1000
+
+// statementTable finds the table a statement names first after FROM, INTO
+// or UPDATE
+var statementTable = regexp.MustCompile(`(?i)\b(?:from|into|update)\s+"?(\w+)`)
+
+// statementKind labels a histogram
+type statementKind struct {
+	operation string
+	table     string
+}
+
+// histogram counts durations per bucket; counts are not cumulative
+type histogram struct {
+	counts []int64
+	count  int64
+	sum    time.Duration
+}
+
+// queryStats records how long statements take and logs the slow ones
+type queryStats struct {
+	mu         sync.Mutex
+	histograms map[statementKind]*histogram
+	kinds      map[string]statementKind
+	slow       int64
+	timedOut   int64
+	threshold  time.Duration // 0 disables the slow query log
+}
+
+// newQueryStats creates statistics with nothing recorded
+func newQueryStats(threshold time.Duration) *queryStats {
+	return &queryStats{
+		histograms: make(map[statementKind]*histogram),
+		kinds:      make(map[string]statementKind),
+		threshold:  threshold,
+	}
+}
+
+// observe records one statement, logging it when it was slow. Nothing is
+// recorded on nil statistics.
+func (s *queryStats) observe(query string, args []driver.NamedValue, took time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	kind, ok := s.kinds[query]
+	if !ok {
+		kind = classify(query)
+		if len(s.kinds) < maxClassified {
+			s.kinds[query] = kind
+		}
+	}
+	h := s.histograms[kind]
+	if h == nil {
+		h = &histogram{counts: make([]int64, len(durationBuckets))}
+		s.histograms[kind] = h
+	}
+	seconds := took.Seconds()
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += took
+	slow := s.threshold > 0 && took >= s.threshold
+	if slow {
+		s.slow++
+	}
+	s.mu.Unlock()
+
+	if slow {
+		log.Printf("Slow query took %s: %s [args: %s]", took.Round(time.Microsecond), oneLine(query), redact(args))
+	}
+}
+
+// timeout counts a statement stopped at the query timeout
+func (s *queryStats) timeout() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.timedOut++
+	s.mu.Unlock()
+}
+
+// snapshot copies the histograms, making counts cumulative, ordered by
+// total time so the hotspots come first
+func (s *queryStats) snapshot() ([]models.StatementMetrics, int64, int64) {
+	if s == nil {
+		return []models.StatementMetrics{}, 0, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statements := make([]models.StatementMetrics, 0, len(s.histograms))
+	for kind, h := range s.histograms {
+		counts := make([]int64, len(h.counts))
+		var total int64
+		for i, n := range h.counts {
+			total += n
+			counts[i] = total
+		}
+		statements = append(statements, models.StatementMetrics{
+			Operation: kind.operation,
+			Table:     kind.table,
+			Buckets:   durationBuckets,
+			Counts:    counts,
+			Count:     h.count,
+			Sum:       h.sum,
+		})
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		if statements[i].Sum != statements[j].Sum {
+			return statements[i].Sum > statements[j].Sum
+		}
+		if statements[i].Table != statements[j].Table {
+			return statements[i].Table < statements[j].Table
+		}
+		return statements[i].Operation < statements[j].Operation
+	})
+
+	return statements, s.slow, s.timedOut
+}
+
+// classify names a statement's operation, its first keyword after any
+// WITH clause, and its first table
+func classify(query string) statementKind {
+	kind := statementKind{operation: "other", table: "none"}
+
+	fields := strings.Fields(strings.ToLower(query))
+	if len(fields) > 0 {
+		kind.operation = fields[0]
+		if kind.operation == "with" {
+			// The statement after the common table expressions; they only select
+			kind.operation = "select"
+			for _, field := range fields[1:] {
+				if field == "insert" || field == "update" || field == "delete" {
+					kind.operation = field
+					break
+				}
+			}
+		}
+	}
+	switch kind.operation {
+	case "select", "insert", "update", "delete", "pragma", "begin", "commit", "rollback", "create", "alter", "drop":
+	default:
+		kind.operation = "other"
+	}
+
+	if match := statementTable.FindStringSubmatch(query); match != nil {
+		kind.table = strings.ToLower(match[1])
+	}
+	return kind
+}
+
+// redact describes arguments by type and size only, as they may hold user
+// content
+func redact(args []driver.NamedValue) string {
+	if len(args) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.Value.(type) {
+		case nil:
+			parts[i] = "null"
+		case string:
+			parts[i] = fmt.Sprintf("text(%d)", len(v))
+		case []byte:
+			parts[i] = fmt.Sprintf("blob(%d)", len(v))
+		case int64:
+			parts[i] = "int"
+		case float64:
+			parts[i] = "real"
+		case bool:
+			parts[i] = "bool"
+		case time.Time:
+			parts[i] = "time"
+		default:
+			parts[i] = fmt.Sprintf("%T", v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// oneLine collapses a statement's whitespace for the log
+func oneLine(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
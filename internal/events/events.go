@@ -0,0 +1,140 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// Type names a kind of change
+type Type string
+
+// Event types, named <entity>.<change>
+const (
+	BoardCreated Type = "board.created"
+	BoardUpdated Type = "board.updated"
+	BoardDeleted Type = "board.deleted"
+
+	ListCreated Type = "list.created"
+	ListUpdated Type = "list.updated"
+	ListDeleted Type = "list.deleted"
+
+	CardCreated     Type = "card.created"
+	CardUpdated     Type = "card.updated"
+	CardMoved       Type = "card.moved"
+	CardArchived    Type = "card.archived"
+	CardUnarchived  Type = "card.unarchived"
+	CardCompleted   Type = "card.completed"
+	CardUncompleted Type = "card.uncompleted"
+	CardDeleted     Type = "card.deleted"
+
+	CommentCreated Type = "comment.created"
+	CommentUpdated Type = "comment.updated"
+	CommentDeleted Type = "comment.deleted"
+)
+
+// Types lists every event type
+var Types = []Type{
+	BoardCreated, BoardUpdated, BoardDeleted,
+	ListCreated, ListUpdated, ListDeleted,
+	CardCreated, CardUpdated, CardMoved, CardArchived, CardUnarchived, CardCompleted, CardUncompleted, CardDeleted,
+	CommentCreated, CommentUpdated, CommentDeleted,
+}
+
+// IsType reports whether name is a known event type
+func IsType(name string) bool {
+	for _, t := range Types {
+		if string(t) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Event describes a change that has been committed. Only the fields that
+// apply to its type are set: board events carry the board, list events the
+// list, card events the card, and comment events the comment and its card.
+// Deleted events carry the entity as it was just before it was deleted.
+type Event struct {
+	Type       Type
+	BoardID    int // The board the change happened on
+	Board      *models.Board
+	List       *models.List
+	Card       *models.Card
+	Comment    *models.Comment
+	FromListID int          // For card.moved, the list the card left
+	Reason     string       // For card.moved by automation, why it moved
+	Actor      *models.User // nil for anonymous requests and background jobs
+	OccurredAt time.Time
+}
+
+// Handler reacts to an event
+type Handler func(Event)
+
+// subscription is a handler and the event types it wants; no types means all
+type subscription struct {
+	handler Handler
+	types   map[Type]bool
+}
+
+// Bus delivers published events to its subscribers. Delivery is
+// synchronous and in subscription order, so a subscriber sees the events of
+// one publisher in the order they happened; subscribers doing slow work
+// should hand it off to a goroutine of their own.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+// NewBus creates a bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers handler for the given event types, or for every event
+// when none are given
+func (b *Bus) Subscribe(handler Handler, types ...Type) {
+	sub := subscription{handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, sub)
+}
+
+// Publish delivers event to every subscriber that wants it. A subscriber
+// that panics is logged and skipped; it does not stop the others or fail
+// the change that was published.
+func (b *Bus) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		if sub.types != nil && !sub.types[event.Type] {
+			continue
+		}
+		deliver(sub.handler, event)
+	}
+}
+
+// deliver runs one handler, recovering from a panic
+func deliver(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event subscriber for %s panicked: %v", event.Type, r)
+		}
+	}()
+	handler(event)
+}
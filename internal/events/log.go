@@ -0,0 +1,30 @@
+package events
+
+import (
+	"fmt"
+	"log"
+)
+
+// Log writes an event to the server log; subscribe it to every event for an
+// activity trail alongside the request log
+func Log(event Event) {
+	actor := "-"
+	if event.Actor != nil {
+		actor = event.Actor.Username
+	}
+
+	var subject string
+	switch {
+	case event.Comment != nil:
+		subject = fmt.Sprintf(" comment=%d card=%d", event.Comment.ID, event.Card.ID)
+	case event.Card != nil:
+		subject = fmt.Sprintf(" card=%d list=%d", event.Card.ID, event.Card.ListID)
+	case event.List != nil:
+		subject = fmt.Sprintf(" list=%d", event.List.ID)
+	}
+	if event.Type == CardMoved {
+		subject += fmt.Sprintf(" from_list=%d", event.FromListID)
+	}
+
+	log.Printf("Event %s board=%d%s actor=%s", event.Type, event.BoardID, subject, actor)
+}
@@ -0,0 +1,32 @@
+package events
+
+import "sync"
+
+// Signal wakes everyone waiting on it whenever an event is published, for
+// long polls that recheck for changes rather than receive them
+type Signal struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// NewSignal creates a signal fired by every event published on bus
+func NewSignal(bus *Bus) *Signal {
+	s := &Signal{ch: make(chan struct{})}
+	bus.Subscribe(func(Event) { s.fire() })
+	return s
+}
+
+// Wait returns a channel that is closed by the next event
+func (s *Signal) Wait() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ch
+}
+
+// fire closes the current channel and starts a new one
+func (s *Signal) fire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.ch)
+	s.ch = make(chan struct{})
+}
@@ -0,0 +1,140 @@
+// Package fuzzy scores how well cards match a search despite typos, by the
+// trigrams the words of the search share with the words of a card, in the
+// manner of PostgreSQL's pg_trgm.
+package fuzzy
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MinScore is the lowest score of a match. A typo or two in a word scores
+// well above it; a search sharing one short word with a card does not.
+const MinScore = 0.4
+
+// minWordScore is the lowest score each word of a search must reach for a
+// card to match at all, so one word found exactly cannot carry the others
+const minWordScore = 0.3
+
+// descriptionWeight scales down matches found only in the description, so
+// cards named after the search rank above cards that mention it
+const descriptionWeight = 0.8
+
+// Score returns how well query matches a card's title and description,
+// from 0 to 1: the mean over the query's words of the best match each
+// finds, or 0 when one finds none. A word matches another containing it
+// with 1, and otherwise with their trigram similarity.
+func Score(query, title, description string) float64 {
+	terms := words(query)
+	if len(terms) == 0 {
+		return 0
+	}
+	titleWords := words(title)
+	descriptionWords := words(description)
+
+	total := 0.0
+	for _, term := range terms {
+		best := bestMatch(term, titleWords)
+		if d := descriptionWeight * bestMatch(term, descriptionWords); d > best {
+			best = d
+		}
+		if best < minWordScore {
+			return 0
+		}
+		total += best
+	}
+
+	return math.Round(total/float64(len(terms))*1000) / 1000
+}
+
+// bestMatch returns how well term matches the closest of words
+func bestMatch(term string, words []string) float64 {
+	termGrams := trigrams(term)
+	best := 0.0
+	for _, word := range words {
+		if strings.Contains(word, term) {
+			return 1
+		}
+		if s := similarity(termGrams, trigrams(word)); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// similarity is the share of the trigrams of two words that they have in
+// common
+func similarity(a, b map[string]bool) float64 {
+	shared := 0
+	for gram := range a {
+		if b[gram] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// trigrams returns the runs of three characters in a word padded with two
+// spaces in front and one behind, so its start counts for more than its
+// middle: "bug" has "  b", " bu", "bug" and "ug "
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	grams := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])] = true
+	}
+	return grams
+}
+
+// words splits text into lowercase words of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Prefilter returns, for each word of query, the substrings of which a
+// card's title or description must contain at least one for the word to
+// match it, so the database can narrow down the cards to score. A word
+// can only reach minWordScore by containing the search word or sharing
+// trigrams with it besides the first, whose single letter says too little
+// to be worth filtering on. Words with letters outside ASCII, which SQLite
+// does not compare case-insensitively, are left out.
+func Prefilter(query string) [][]string {
+	var filters [][]string
+	for _, term := range words(query) {
+		if !isASCII(term) {
+			continue
+		}
+		seen := make(map[string]bool)
+		var parts []string
+		for gram := range trigrams(term) {
+			part := strings.TrimSpace(gram)
+			if (strings.HasPrefix(gram, "  ") && len(term) > 1) || seen[part] {
+				continue
+			}
+			seen[part] = true
+			parts = append(parts, part)
+		}
+		sort.Strings(parts)
+		filters = append(filters, parts)
+	}
+	return filters
+}
+
+// isASCII reports whether s has only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package fuzzy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrefilterKeepsMatches(t *testing.T) {
+	titles := []string{
+		"Fix authentication bug",
+		"Write documentation",
+		"Deploy to production",
+		"A b c",
+		"Q3 OKRs",
+		"x",
+	}
+	queries := []string{
+		"autentication", "authetication bugs", "documentaton", "deploy prodution",
+		"bug", "bgu", "a", "b c", "okr", "q3", "x", "xy", "wirte docs",
+	}
+
+	for _, query := range queries {
+		for _, title := range titles {
+			if Score(query, title, "") < MinScore {
+				continue
+			}
+			text := strings.ToLower(title)
+			for _, parts := range Prefilter(query) {
+				found := false
+				for _, part := range parts {
+					if strings.Contains(text, part) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Prefilter(%q) = %v excludes %q, which scores %v", query, parts, title, Score(query, title, ""))
+				}
+			}
+		}
+	}
+}
+
+func TestPrefilterSkipsNonASCII(t *testing.T) {
+	if got := Prefilter("Äpfel bug"); len(got) != 1 {
+		t.Errorf("Prefilter(\"Äpfel bug\") = %v, want only the filter for bug", got)
+	}
+}
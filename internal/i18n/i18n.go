@@ -0,0 +1,213 @@
+// Package i18n translates user-facing messages. Messages are keyed by their
+// English text, so code keeps writing plain English and a message without a
+// translation falls back to it. Messages with variable parts are keyed by
+// their fmt format string and rendered with Sprintf.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the language messages are written in
+const DefaultLocale = "en"
+
+// Catalog supplies translations for one or more locales
+type Catalog interface {
+	// Message returns the translation of an English message or format string
+	Message(locale, message string) (string, bool)
+	// Locales lists the locales the catalog translates into
+	Locales() []string
+}
+
+// MapCatalog is a Catalog of message maps keyed by locale
+type MapCatalog map[string]map[string]string
+
+// Message implements Catalog
+func (m MapCatalog) Message(locale, message string) (string, bool) {
+	translated, ok := m[locale][message]
+	return translated, ok
+}
+
+// Locales implements Catalog
+func (m MapCatalog) Locales() []string {
+	locales := make([]string, 0, len(m))
+	for locale := range m {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Merge adds other's translations, replacing existing ones
+func (m MapCatalog) Merge(other MapCatalog) {
+	for locale, messages := range other {
+		if m[locale] == nil {
+			m[locale] = make(map[string]string)
+		}
+		for message, translated := range messages {
+			m[locale][message] = translated
+		}
+	}
+}
+
+//go:embed locales/*.json
+var bundled embed.FS
+
+var (
+	mu      sync.RWMutex
+	catalog Catalog = mustLoadBundled()
+)
+
+// SetCatalog replaces the catalog used for translation
+func SetCatalog(c Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+	catalog = c
+}
+
+// Bundled returns a fresh copy of the translations shipped with the server
+func Bundled() MapCatalog {
+	return mustLoadBundled()
+}
+
+// LoadDir reads <locale>.json files, each a JSON object mapping English
+// messages to translations
+func LoadDir(dir string) (MapCatalog, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locale files: %w", err)
+	}
+
+	m := MapCatalog{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := m.add(filepath.Base(path), data); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m MapCatalog) add(name string, data []byte) error {
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	m.Merge(MapCatalog{Normalize(strings.TrimSuffix(name, ".json")): messages})
+	return nil
+}
+
+func mustLoadBundled() MapCatalog {
+	m := MapCatalog{}
+	entries, err := bundled.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := bundled.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		if err := m.add(entry.Name(), data); err != nil {
+			panic(err)
+		}
+	}
+	return m
+}
+
+// Locales lists every supported locale, the default first
+func Locales() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	locales := []string{DefaultLocale}
+	for _, locale := range catalog.Locales() {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// Supported reports whether locale can be selected
+func Supported(locale string) bool {
+	locale = Normalize(locale)
+	for _, l := range Locales() {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Translate returns message in locale, or message itself if untranslated
+func Translate(locale, message string) string {
+	if locale == "" || locale == DefaultLocale {
+		return message
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if translated, ok := catalog.Message(locale, message); ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf translates format and then formats it with args
+func Sprintf(locale, format string, args ...interface{}) string {
+	return fmt.Sprintf(Translate(locale, format), args...)
+}
+
+// Match picks the best supported locale for an Accept-Language header,
+// falling back to the default. "de-AT" matches "de" when only that exists.
+func Match(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{Normalize(tag), q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if Supported(c.tag) {
+			return c.tag
+		}
+		if primary, _, found := strings.Cut(c.tag, "-"); found && Supported(primary) {
+			return primary
+		}
+	}
+	return DefaultLocale
+}
+
+// Normalize lowercases a language tag and uses "-" as the separator
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
@@ -0,0 +1,76 @@
+package imageproxy
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// cache holds recently fetched images up to a total size, dropping the
+// least recently used first. Entries expire after ttl so changed images are
+// eventually fetched again.
+type cache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	ttl      time.Duration
+	size     int64
+	order    *list.List // Most recently used first; values are *entry
+	entries  map[string]*list.Element
+}
+
+// entry is a cached image and its URL
+type entry struct {
+	url   string
+	image *Image
+}
+
+// newCache creates a cache of up to maxBytes of images; zero disables it
+func newCache(maxBytes int64, ttl time.Duration) *cache {
+	return &cache{maxBytes: maxBytes, ttl: ttl, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the cached image for a URL, or nil
+func (c *cache) get(url string) *Image {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[url]
+	if !ok {
+		return nil
+	}
+	image := element.Value.(*entry).image
+	if time.Since(image.FetchedAt) > c.ttl {
+		c.remove(element)
+		return nil
+	}
+	c.order.MoveToFront(element)
+	return image
+}
+
+// put caches the image for a URL, making room for it. Images larger than
+// the whole cache are not kept.
+func (c *cache) put(url string, image *Image) {
+	size := int64(len(image.Data))
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[url]; ok {
+		c.remove(element)
+	}
+	for c.size+size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+	c.entries[url] = c.order.PushFront(&entry{url: url, image: image})
+	c.size += size
+}
+
+// remove drops a cached image; the lock must be held
+func (c *cache) remove(element *list.Element) {
+	e := c.order.Remove(element).(*entry)
+	delete(c.entries, e.url)
+	c.size -= int64(len(e.image.Data))
+}
@@ -0,0 +1,153 @@
+package imageproxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/netguard"
+)
+
+// Path is where the proxy is served, under the API
+const Path = "/api/image-proxy"
+
+var (
+	// ErrForbiddenAddress is returned for images on loopback, private and
+	// other internal addresses, which the proxy must not be used to reach
+	ErrForbiddenAddress = netguard.ErrForbiddenAddress
+
+	// ErrTooLarge is returned for images over the size limit
+	ErrTooLarge = errors.New("image too large")
+
+	// ErrNotImage is returned when the URL serves something other than a
+	// raster image. SVG is refused too, since it can carry scripts.
+	ErrNotImage = errors.New("not an image")
+)
+
+// imageTypes are the content types served, by the type sniffed from the
+// first bytes. AVIF is not sniffed, so it is taken from the response's
+// Content-Type when nothing else is detected.
+var imageTypes = map[string]bool{
+	"image/png":                true,
+	"image/jpeg":               true,
+	"image/gif":                true,
+	"image/webp":               true,
+	"image/bmp":                true,
+	"image/x-icon":             true,
+	"image/vnd.microsoft.icon": true,
+	"image/avif":               true,
+}
+
+// Image is an image fetched through the proxy
+type Image struct {
+	ContentType string
+	Data        []byte
+	FetchedAt   time.Time
+}
+
+// Proxy fetches external images referenced in Markdown on behalf of
+// browsers, so boards embedding screenshots hosted elsewhere do not leak
+// their viewers' addresses or mix HTTP content into HTTPS pages. Only URLs
+// signed with its key are fetched, which keeps it from being used as an
+// open proxy.
+type Proxy struct {
+	key      []byte
+	maxBytes int64
+	client   *http.Client
+	cache    *cache
+}
+
+// New creates an image proxy signing URLs with key, fetching images of up
+// to maxBytes and caching up to cacheBytes of them for ttl
+func New(key []byte, maxBytes, cacheBytes int64, ttl time.Duration) *Proxy {
+	return &Proxy{
+		key:      key,
+		maxBytes: maxBytes,
+		client:   netguard.NewClient(10*time.Second, 3),
+		cache:    newCache(cacheBytes, ttl),
+	}
+}
+
+// Sign returns the signature of an image URL
+func (p *Proxy) Sign(rawURL string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(rawURL))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// Verify reports whether sig is the signature of an image URL
+func (p *Proxy) Verify(rawURL, sig string) bool {
+	return hmac.Equal([]byte(p.Sign(rawURL)), []byte(sig))
+}
+
+// URL returns the signed proxy path for an http or https image URL, or ""
+// for any other
+func (p *Proxy) URL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return Path + "?url=" + url.QueryEscape(rawURL) + "&sig=" + p.Sign(rawURL)
+}
+
+// Fetch returns the image at an http or https URL, from the cache when it
+// was fetched recently
+func (p *Proxy) Fetch(ctx context.Context, rawURL string) (*Image, error) {
+	if image := p.cache.get(rawURL); image != nil {
+		return image, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil || (req.URL.Scheme != "http" && req.URL.Scheme != "https") {
+		return nil, fmt.Errorf("invalid image URL")
+	}
+	req.Header.Set("Accept", "image/*")
+	req.Header.Set("User-Agent", "kanban-simple-image-proxy")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrForbiddenAddress) {
+			return nil, ErrForbiddenAddress
+		}
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: %s", resp.Status)
+	}
+	if resp.ContentLength > p.maxBytes {
+		return nil, ErrTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > p.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType == "application/octet-stream" {
+		contentType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if contentType != "image/avif" {
+			return nil, ErrNotImage
+		}
+	}
+	if !imageTypes[strings.ToLower(contentType)] {
+		return nil, ErrNotImage
+	}
+
+	image := &Image{ContentType: contentType, Data: data, FetchedAt: time.Now()}
+	p.cache.put(rawURL, image)
+	return image, nil
+}
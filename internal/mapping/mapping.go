@@ -0,0 +1,153 @@
+// Package mapping turns arbitrary JSON payloads into text through templates
+// with JSONPath-style placeholders, such as
+// "{{$.commonLabels.alertname}} on {{$.alerts[0].labels.instance}}".
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// placeholder matches {{$...}} in a template
+var placeholder = regexp.MustCompile(`\{\{\s*(\$[^{}]*?)\s*\}\}`)
+
+// segment is one step of a path: a key, an index, or every element
+type segment struct {
+	key   string
+	index int
+	all   bool
+	isKey bool
+}
+
+// Validate checks that every placeholder in a template is a valid path and
+// that no {{ is left unmatched
+func Validate(template string) error {
+	for _, match := range placeholder.FindAllStringSubmatch(template, -1) {
+		if _, err := parsePath(match[1]); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(placeholder.ReplaceAllString(template, ""), "{{") {
+		return fmt.Errorf("placeholders must look like {{$.path}}")
+	}
+	return nil
+}
+
+// Render replaces every placeholder in a template with the value its path
+// selects from payload. Missing values render as nothing, strings as they
+// are, numbers and booleans in their JSON form, objects and arrays as JSON,
+// and the values selected through [*] joined with ", ".
+func Render(template string, payload interface{}) string {
+	return placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		path, err := parsePath(placeholder.FindStringSubmatch(match)[1])
+		if err != nil {
+			return ""
+		}
+		values := lookup(payload, path)
+		texts := make([]string, 0, len(values))
+		for _, value := range values {
+			if text := format(value); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, ", ")
+	})
+}
+
+// parsePath parses $ followed by .key, ["key"], [n] or [*] segments
+func parsePath(path string) ([]segment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+
+	var segments []segment
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := 1
+			for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+				end++
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			segments = append(segments, segment{key: rest[1:end], isKey: true})
+			rest = rest[end:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unclosed [", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			switch {
+			case inner == "*":
+				segments = append(segments, segment{all: true})
+			case len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, segment{key: inner[1 : len(inner)-1], isKey: true})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("path %q has an invalid index %q", path, inner)
+				}
+				segments = append(segments, segment{index: index})
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path %q has unexpected %q", path, rest[:1])
+		}
+	}
+	return segments, nil
+}
+
+// lookup follows a path through decoded JSON, returning every value it
+// reaches
+func lookup(value interface{}, path []segment) []interface{} {
+	values := []interface{}{value}
+	for _, seg := range path {
+		var next []interface{}
+		for _, v := range values {
+			switch {
+			case seg.isKey:
+				if object, ok := v.(map[string]interface{}); ok {
+					if child, exists := object[seg.key]; exists {
+						next = append(next, child)
+					}
+				}
+			case seg.all:
+				if array, ok := v.([]interface{}); ok {
+					next = append(next, array...)
+				}
+			default:
+				if array, ok := v.([]interface{}); ok && seg.index < len(array) {
+					next = append(next, array[seg.index])
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// format renders one value as text
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
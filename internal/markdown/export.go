@@ -0,0 +1,88 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// ExportBoard renders a board as Markdown: the board name as the title, each
+// list as a second-level heading and its cards as checklist items, checked
+// when completed. Descriptions are indented beneath their card so they stay
+// part of the list item. Cards must be ordered by list then position.
+func ExportBoard(board *models.Board, lists []models.List, cards []models.Card) string {
+	byList := make(map[int][]models.Card)
+	for _, card := range cards {
+		byList[card.ListID] = append(byList[card.ListID], card)
+	}
+
+	var b strings.Builder
+	b.WriteString("# " + singleLine(board.Name) + "\n")
+	if board.Description != "" {
+		b.WriteString("\n" + strings.TrimSpace(board.Description) + "\n")
+	}
+
+	for _, list := range lists {
+		b.WriteString("\n## " + singleLine(list.Name) + "\n\n")
+
+		listCards := byList[list.ID]
+		if len(listCards) == 0 {
+			b.WriteString("_No cards_\n")
+			continue
+		}
+		for _, card := range listCards {
+			writeCard(&b, card)
+		}
+	}
+
+	return b.String()
+}
+
+// writeCard writes one checklist item with its details and description
+func writeCard(b *strings.Builder, card models.Card) {
+	check := "[ ]"
+	if card.Completed {
+		check = "[x]"
+	}
+	b.WriteString("- " + check + " " + singleLine(card.Title))
+
+	var details []string
+	if card.StartDate != nil {
+		details = append(details, "starts "+card.StartDate.Format("2006-01-02"))
+	}
+	if card.DueDate != nil {
+		details = append(details, "due "+card.DueDate.Format("2006-01-02"))
+	}
+	if card.Priority != "" {
+		details = append(details, card.Priority)
+	}
+	if len(card.Labels) > 0 {
+		names := make([]string, len(card.Labels))
+		for i, label := range card.Labels {
+			names[i] = label.Name
+		}
+		details = append(details, strings.Join(names, ", "))
+	}
+	if card.Archived {
+		details = append(details, "archived")
+	}
+	if len(details) > 0 {
+		b.WriteString(" (" + strings.Join(details, "; ") + ")")
+	}
+	b.WriteString("\n")
+
+	if description := strings.TrimSpace(card.Description); description != "" {
+		for _, line := range strings.Split(description, "\n") {
+			if line = strings.TrimRight(line, " \t\r"); line == "" {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("  " + line + "\n")
+			}
+		}
+	}
+}
+
+// singleLine collapses whitespace so text fits on a heading or item line
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
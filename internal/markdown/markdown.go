@@ -0,0 +1,119 @@
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	// renderer converts GitHub-flavored Markdown to HTML. Raw HTML in the
+	// source is dropped rather than passed through.
+	renderer = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+	)
+
+	// policy is the allowlist applied to every rendered document
+	policy = newPolicy()
+
+	// imagePolicy is policy with images allowed, used once ProxyImages has
+	// been called
+	imagePolicy = newImagePolicy()
+
+	// imageURL maps image sources to the URLs they are shown from; images
+	// are stripped while it is nil
+	imageURL func(src string) string
+
+	// imageTag and imageSource find images in sanitized HTML, which always
+	// has double-quoted, escaped attributes
+	imageTag    = regexp.MustCompile(`<img[^>]*>`)
+	imageSource = regexp.MustCompile(` src="([^"]*)"`)
+)
+
+// newPolicy builds the strict allowlist for rendered Markdown: the elements
+// goldmark's GFM output uses and nothing else. Links may only point at http,
+// https and mailto URLs; images, classes, inline styles and event handlers
+// are all stripped. Images are added back by newImagePolicy.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+
+	p.AllowElements(
+		"p", "br", "hr", "blockquote", "pre", "code",
+		"strong", "em", "del",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+		"ul", "ol", "li",
+		"table", "thead", "tbody", "tr", "th", "td",
+	)
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+
+	// GFM task list items
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+
+	return p
+}
+
+// newImagePolicy builds the allowlist for rendered Markdown showing images:
+// newPolicy plus img elements with http and https sources, alt text and
+// titles
+func newImagePolicy() *bluemonday.Policy {
+	p := newPolicy()
+	p.AllowAttrs("src", "alt", "title").OnElements("img")
+	return p
+}
+
+// ProxyImages shows images in rendered Markdown from the URLs proxy maps
+// their sources to, such as those of an image proxy; images it maps to ""
+// are dropped. It must be called before anything is rendered.
+func ProxyImages(proxy func(src string) string) {
+	imageURL = proxy
+}
+
+// ImageURL returns the URL an image outside Markdown, such as a link
+// preview's icon, is shown from: the one ProxyImages maps it to, or src
+// itself when images are not proxied
+func ImageURL(src string) string {
+	if imageURL == nil {
+		return src
+	}
+	return imageURL(src)
+}
+
+// Render converts Markdown to sanitized HTML
+func Render(source string) string {
+	if source == "" {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		// Fall back to the escaped source rather than failing the request
+		return html.EscapeString(source)
+	}
+
+	if imageURL == nil {
+		return policy.Sanitize(buf.String())
+	}
+	return imageTag.ReplaceAllStringFunc(imagePolicy.Sanitize(buf.String()), func(tag string) string {
+		m := imageSource.FindStringSubmatch(tag)
+		if m == nil {
+			return ""
+		}
+		src := imageURL(html.UnescapeString(m[1]))
+		if src == "" {
+			return ""
+		}
+		return strings.Replace(tag, m[0], ` src="`+html.EscapeString(src)+`"`, 1)
+	})
+}
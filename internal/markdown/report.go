@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// Report renders a board report as Markdown for status emails and chat:
+// the counts up front, then the completed, created and overdue cards, the
+// top labels and the cycle time, each section left out when empty
+func Report(report *models.BoardReport) string {
+	var b strings.Builder
+	b.WriteString("# Status report: " + singleLine(report.BoardName) + "\n\n")
+	b.WriteString("_" + report.From.Format("2006-01-02") + " to " + report.To.Add(-1).Format("2006-01-02") + "_\n\n")
+
+	completed := strconv.Itoa(report.CompletedCount)
+	if report.CompletedPoints > 0 {
+		completed += fmt.Sprintf(" (%d points)", report.CompletedPoints)
+	}
+	fmt.Fprintf(&b, "**Created:** %d · **Completed:** %s · **Overdue:** %d\n",
+		report.CreatedCount, completed, report.OverdueCount)
+
+	writeReportCards(&b, "Completed", report.Completed, report.CompletedCount, func(card models.DashboardCard) string {
+		return card.ListName
+	})
+	writeReportCards(&b, "Created", report.Created, report.CreatedCount, func(card models.DashboardCard) string {
+		return card.ListName
+	})
+	writeReportCards(&b, "Overdue", report.Overdue, report.OverdueCount, func(card models.DashboardCard) string {
+		return "due " + card.DueDate.Format("2006-01-02") + ", " + card.ListName
+	})
+
+	if len(report.TopLabels) > 0 {
+		b.WriteString("\n## Top labels\n\n")
+		for _, label := range report.TopLabels {
+			fmt.Fprintf(&b, "- %s: %d\n", singleLine(label.Name), label.Cards)
+		}
+	}
+
+	if stats := report.CycleTime; stats.Count > 0 {
+		b.WriteString("\n## Cycle time\n\n")
+		cards := "cards"
+		if stats.Count == 1 {
+			cards = "card"
+		}
+		fmt.Fprintf(&b, "Created to completed: median %s h, 85th percentile %s h, over %d %s\n",
+			formatHours(stats.Median), formatHours(stats.P85), stats.Count, cards)
+	}
+
+	return b.String()
+}
+
+// writeReportCards writes a section listing cards with their details,
+// noting how many more there are than were listed
+func writeReportCards(b *strings.Builder, heading string, cards []models.DashboardCard, total int, details func(models.DashboardCard) string) {
+	if len(cards) == 0 {
+		return
+	}
+
+	b.WriteString("\n## " + heading + "\n\n")
+	for _, card := range cards {
+		b.WriteString("- ")
+		if card.Reference != "" {
+			b.WriteString(card.Reference + " ")
+		}
+		b.WriteString(singleLine(card.Title) + " (" + singleLine(details(card)) + ")\n")
+	}
+	if more := total - len(cards); more > 0 {
+		fmt.Fprintf(b, "- _and %d more_\n", more)
+	}
+}
+
+// formatHours writes hours with at most one decimal place
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(math.Round(hours*10)/10, 'f', -1, 64)
+}
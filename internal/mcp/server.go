@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+)
+
+// ProtocolVersion is the newest Model Context Protocol revision supported
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions lists the protocol revisions the server can speak
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Tool describes an operation exposed to the client
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+
+	// Handler runs the tool with the raw JSON arguments
+	Handler func(args json.RawMessage) (interface{}, error) `json:"-"`
+}
+
+// request is an incoming JSON-RPC message
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is an outgoing JSON-RPC message
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server is a Model Context Protocol server speaking newline-delimited JSON-RPC
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+	byName  map[string]*Tool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewServer creates a server exposing the given tools
+func NewServer(name, version string, tools []Tool) *Server {
+	s := &Server{
+		name:    name,
+		version: version,
+		tools:   tools,
+		byName:  make(map[string]*Tool),
+	}
+	for i := range s.tools {
+		s.byName[s.tools[i].Name] = &s.tools[i]
+	}
+	return s
+}
+
+// Serve reads requests from in and writes responses to out until in is closed
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "Parse error"}})
+			continue
+		}
+
+		// Notifications carry no ID and expect no response
+		if len(req.ID) == 0 {
+			continue
+		}
+
+		result, rerr := s.handle(&req)
+		s.write(response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr})
+	}
+
+	return scanner.Err()
+}
+
+// handle dispatches a request to its method
+func (s *Server) handle(req *request) (interface{}, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "Invalid JSON-RPC version"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params), nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools}, nil
+	case "tools/call":
+		return s.callTool(req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("Method not found: %s", req.Method)}
+	}
+}
+
+// initialize negotiates the protocol version and advertises capabilities
+func (s *Server) initialize(params json.RawMessage) interface{} {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	_ = json.Unmarshal(params, &p)
+
+	version := ProtocolVersion
+	if supportedVersions[p.ProtocolVersion] {
+		version = p.ProtocolVersion
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities": map[string]interface{}{
+			"tools": map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{
+			"name":    s.name,
+			"version": s.version,
+		},
+	}
+}
+
+// callTool runs a tool. Tool failures are reported in the result so the
+// model can see them, while unknown tools are protocol errors.
+func (s *Server) callTool(params json.RawMessage) (interface{}, *rpcError) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "Invalid params"}
+	}
+
+	tool, ok := s.byName[p.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("Unknown tool: %s", p.Name)}
+	}
+
+	if len(p.Arguments) == 0 {
+		p.Arguments = json.RawMessage("{}")
+	}
+
+	value, err := tool.Handler(p.Arguments)
+	if err != nil {
+		return toolResult(err.Error(), true), nil
+	}
+
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return toolResult(fmt.Sprintf("failed to encode result: %v", err), true), nil
+	}
+
+	return toolResult(string(text), false), nil
+}
+
+// toolResult wraps text in a tools/call result
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": text},
+		},
+		"isError": isError,
+	}
+}
+
+// write sends a response as a single line
+func (s *Server) write(resp response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Failed to encode MCP response: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.out.Write(append(data, '\n')); err != nil {
+		log.Printf("Failed to write MCP response: %v", err)
+	}
+}
@@ -0,0 +1,499 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// Kanban holds the repositories backing the kanban tools
+type Kanban struct {
+	Boards  *repository.BoardRepository
+	Lists   *repository.ListRepository
+	Cards   *repository.CardRepository
+	Labels  *repository.LabelRepository
+	Members *repository.MemberRepository
+	Audit   *repository.AuditRepository // Records each change, as the API's audit log does; may be nil
+	Events  *events.Bus                 // Receives each change, so mentions and references are processed; may be nil
+	Quotas  models.Quotas               // Enforced as by the API
+	// ReadOnly rejects every tool that changes data. It is fixed at startup:
+	// switching the API server to read-only does not reach this process.
+	ReadOnly bool
+}
+
+// Tools returns the kanban operations exposed over MCP
+func (k *Kanban) Tools() []Tool {
+	return []Tool{
+		{
+			Name:        "list_boards",
+			Description: "List all kanban boards.",
+			InputSchema: objectSchema(nil),
+			Handler:     k.listBoards,
+		},
+		{
+			Name:        "get_board",
+			Description: "Get a board with its lists and their non-archived cards.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"board_id": intProp("Board ID"),
+			}, "board_id"),
+			Handler: k.getBoard,
+		},
+		{
+			Name:        "search_cards",
+			Description: "Search cards by text in title or description, optionally filtered by board, list, label or archive state.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"query":    stringProp("Text to search for"),
+				"board_id": intProp("Only cards on this board"),
+				"list_id":  intProp("Only cards in this list"),
+				"label_id": intProp("Only cards with this label"),
+				"archived": boolProp("Filter by archive state"),
+			}),
+			Handler: k.searchCards,
+		},
+		{
+			Name:        "create_card",
+			Description: "Create a card at the end of a list.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"list_id":     intProp("List to create the card in"),
+				"title":       textProp("Card title", models.MaxNameLength),
+				"description": textProp("Card description (Markdown)", models.MaxTextLength),
+				"color":       stringProp("Hex color code or palette name"),
+				"due_date":    stringProp("Due date in RFC 3339 format"),
+			}, "list_id", "title"),
+			Handler: k.write("create_card", k.createCard),
+		},
+		{
+			Name:        "move_card",
+			Description: "Move a card to another list. Without a position the card is placed at the end.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"card_id":  intProp("Card to move"),
+				"list_id":  intProp("Target list"),
+				"position": map[string]interface{}{"type": "number", "description": "Position within the target list"},
+			}, "card_id", "list_id"),
+			Handler: k.write("move_card", k.moveCard),
+		},
+		{
+			Name:        "archive_card",
+			Description: "Archive a card, or restore it with archived=false.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"card_id":  intProp("Card to archive"),
+				"archived": boolProp("Defaults to true"),
+			}, "card_id"),
+			Handler: k.write("archive_card", k.archiveCard),
+		},
+		{
+			Name:        "add_comment",
+			Description: "Add a comment to a card.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"card_id": intProp("Card to comment on"),
+				"content": textProp("Comment text (Markdown)", models.MaxTextLength),
+			}, "card_id", "content"),
+			Handler: k.write("add_comment", k.addComment),
+		},
+	}
+}
+
+func (k *Kanban) listBoards(json.RawMessage) (interface{}, error) {
+	return k.Boards.GetAll(nil, nil)
+}
+
+func (k *Kanban) getBoard(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		BoardID int `json:"board_id"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	if err := k.visibleBoard(args.BoardID); err != nil {
+		return nil, err
+	}
+	board, err := k.Boards.GetByID(args.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := k.Lists.GetByBoardID(board.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lists {
+		cards, err := k.Cards.GetByListID(lists[i].ID, models.CardListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		lists[i].Cards = cards
+	}
+	board.Lists = lists
+
+	return board, nil
+}
+
+func (k *Kanban) searchCards(raw json.RawMessage) (interface{}, error) {
+	var params models.SearchCardsRequest
+	if err := decodeArgs(raw, &params); err != nil {
+		return nil, err
+	}
+
+	return k.Cards.Search(params)
+}
+
+func (k *Kanban) createCard(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		ListID      int        `json:"list_id"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Color       string     `json:"color"`
+		DueDate     *time.Time `json:"due_date"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	if !models.IsValidCardTitle(args.Title) {
+		return nil, fmt.Errorf("title must be 1 to %d characters", models.MaxNameLength)
+	}
+	if !models.FitsText(args.Description) {
+		return nil, fmt.Errorf("description must be at most %d characters", models.MaxTextLength)
+	}
+	if args.Color != "" && !models.IsValidColor(args.Color) {
+		return nil, fmt.Errorf("invalid color %q", args.Color)
+	}
+	list, err := k.visibleList(args.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.Quotas.CheckCards(list.CardCount, 1); err != nil {
+		return nil, err
+	}
+
+	card := &models.Card{
+		ListID:      args.ListID,
+		Title:       args.Title,
+		Description: args.Description,
+		Color:       args.Color,
+		DueDate:     args.DueDate,
+	}
+
+	// The list's rules come first, then the board's default card color, as
+	// for cards created through the API
+	list.Settings.Apply(card, time.Now(), args.DueDate != nil)
+	if card.Color == "" {
+		if board, err := k.Boards.GetByID(list.BoardID); err == nil {
+			card.Color = board.Settings.DefaultCardColor
+		}
+	}
+	if err := k.Cards.Create(card); err != nil {
+		return nil, err
+	}
+	if err := k.assignRuleLabels(card, list); err != nil {
+		return nil, err
+	}
+	k.publish(events.Event{Type: events.CardCreated, BoardID: list.BoardID, Card: card})
+
+	return card, nil
+}
+
+func (k *Kanban) moveCard(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		CardID   int      `json:"card_id"`
+		ListID   int      `json:"list_id"`
+		Position *float64 `json:"position"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	card, err := k.visibleCard(args.CardID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := k.visibleList(args.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if target.ID != card.ListID && !card.Archived {
+		if err := k.Quotas.CheckCards(target.CardCount, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	position := 1.0
+	if args.Position != nil {
+		position = *args.Position
+	} else {
+		cards, err := k.Cards.GetByListID(args.ListID, models.CardListOptions{IncludeArchived: true})
+		if err != nil {
+			return nil, err
+		}
+		if len(cards) > 0 {
+			position = cards[len(cards)-1].Position + 1
+		}
+	}
+
+	if err := k.Cards.Move(card.ID, args.ListID, position, models.CardMoveOptions{}); err != nil {
+		return nil, err
+	}
+
+	moved, err := k.Cards.GetByID(card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entering a list applies its rules; reordering within one does not
+	if card.ListID != target.ID {
+		target.Settings.Apply(moved, time.Now(), false)
+		if err := k.Cards.Update(moved); err != nil {
+			return nil, err
+		}
+		if err := k.assignRuleLabels(moved, target); err != nil {
+			return nil, err
+		}
+	}
+	k.publish(events.Event{Type: events.CardMoved, BoardID: target.BoardID, Card: moved, FromListID: card.ListID})
+
+	return moved, nil
+}
+
+func (k *Kanban) archiveCard(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		CardID   int   `json:"card_id"`
+		Archived *bool `json:"archived"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	archived := true
+	if args.Archived != nil {
+		archived = *args.Archived
+	}
+
+	card, err := k.visibleCard(args.CardID)
+	if err != nil {
+		return nil, err
+	}
+	if card.Archived && !archived {
+		list, err := k.Lists.GetByID(card.ListID)
+		if err != nil {
+			return nil, err
+		}
+		if err := k.Quotas.CheckCards(list.CardCount, 1); err != nil {
+			return nil, err
+		}
+	}
+	if err := k.Cards.Archive(args.CardID, archived); err != nil {
+		return nil, err
+	}
+
+	updated, err := k.Cards.GetByID(args.CardID)
+	if err != nil {
+		return nil, err
+	}
+	if archived {
+		k.publish(events.Event{Type: events.CardArchived, Card: updated})
+	} else {
+		k.publish(events.Event{Type: events.CardUnarchived, Card: updated})
+	}
+
+	return updated, nil
+}
+
+func (k *Kanban) addComment(raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		CardID  int    `json:"card_id"`
+		Content string `json:"content"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+
+	if args.Content == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	if !models.FitsText(args.Content) {
+		return nil, fmt.Errorf("content must be at most %d characters", models.MaxTextLength)
+	}
+	if err := k.Quotas.CheckComment(args.Content); err != nil {
+		return nil, err
+	}
+	card, err := k.visibleCard(args.CardID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		CardID:  args.CardID,
+		Content: args.Content,
+	}
+	if err := k.Cards.AddComment(comment); err != nil {
+		return nil, err
+	}
+	k.publish(events.Event{Type: events.CommentCreated, Card: card, Comment: comment})
+
+	return comment, nil
+}
+
+// write wraps a tool that changes data: it fails while the server is
+// read-only, and each change it makes is recorded in the audit log
+func (k *Kanban) write(name string, handler func(json.RawMessage) (interface{}, error)) func(json.RawMessage) (interface{}, error) {
+	return func(raw json.RawMessage) (interface{}, error) {
+		if k.ReadOnly {
+			return nil, fmt.Errorf("server is in read-only mode")
+		}
+
+		result, err := handler(raw)
+		if err != nil {
+			return nil, err
+		}
+		k.audit(name, result)
+		return result, nil
+	}
+}
+
+// audit records a tool's change against the board of the card it changed
+func (k *Kanban) audit(name string, result interface{}) {
+	if k.Audit == nil {
+		return
+	}
+
+	var cardID int
+	switch result := result.(type) {
+	case *models.Card:
+		cardID = result.ID
+	case *models.Comment:
+		cardID = result.CardID
+	}
+	entry := &models.AuditEntry{
+		Action:  "mcp." + name,
+		Route:   "MCP " + name,
+		Status:  200,
+		BoardID: k.Audit.BoardOf("cards", cardID),
+	}
+	if err := k.Audit.Record(entry); err != nil {
+		log.Printf("Failed to record audit entry for %s: %v", name, err)
+	}
+}
+
+// publish sends an event for a change, filling in the board from the card's
+// list. Tools have no acting user, so the event has no actor.
+func (k *Kanban) publish(event events.Event) {
+	if k.Events == nil {
+		return
+	}
+	if event.BoardID == 0 {
+		if list, err := k.Lists.GetByID(event.Card.ListID); err == nil {
+			event.BoardID = list.BoardID
+		}
+	}
+	k.Events.Publish(event)
+}
+
+// assignRuleLabels adds the labels a list's rules give entering cards
+func (k *Kanban) assignRuleLabels(card *models.Card, list *models.List) error {
+	labels, err := k.Labels.GetByIDs(list.Settings.LabelIDs)
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if err := k.Labels.AssignToCard(card.ID, label.ID); err != nil {
+			return err
+		}
+	}
+	if len(labels) > 0 {
+		cardLabels, err := k.Labels.GetCardLabels(card.ID)
+		if err != nil {
+			return err
+		}
+		card.Labels = cardLabels
+	}
+	return nil
+}
+
+// visibleBoard checks a board is open to everyone. Tools run without an
+// acting user, so boards restricted to their members are "board not found"
+// to them, as they are left out for anonymous API callers.
+func (k *Kanban) visibleBoard(id int) error {
+	_, restricted, err := k.Members.Role(id, nil)
+	if err != nil {
+		return err
+	}
+	if restricted {
+		return fmt.Errorf("board not found")
+	}
+	return nil
+}
+
+// visibleList loads a list by ID, on a board visibleBoard allows
+func (k *Kanban) visibleList(id int) (*models.List, error) {
+	list, err := k.Lists.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.visibleBoard(list.BoardID); err != nil {
+		return nil, fmt.Errorf("list not found")
+	}
+	return list, nil
+}
+
+// visibleCard loads a card by ID. Tools run without an acting user, so
+// restricted cards, and cards on restricted boards, are "card not found" to
+// them, as to anonymous API callers.
+func (k *Kanban) visibleCard(id int) (*models.Card, error) {
+	card, err := k.Cards.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !card.VisibleTo(nil) {
+		return nil, fmt.Errorf("card not found")
+	}
+	if _, err := k.visibleList(card.ListID); err != nil {
+		return nil, fmt.Errorf("card not found")
+	}
+	return card, nil
+}
+
+// decodeArgs unmarshals tool arguments
+func decodeArgs(raw json.RawMessage, dest interface{}) error {
+	if err := json.Unmarshal(raw, dest); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// objectSchema builds a JSON Schema object with the given properties
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func intProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "integer", "description": description}
+}
+
+func stringProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+// textProp is a string property of at most max characters
+func textProp(description string, max int) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description, "maxLength": max}
+}
+
+func boolProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "boolean", "description": description}
+}
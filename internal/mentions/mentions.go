@@ -0,0 +1,28 @@
+package mentions
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches @username not preceded by a word character, so
+// email addresses like bob@example.com are not treated as mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.-]+)`)
+
+// Parse returns the unique usernames mentioned in text, in order of appearance
+func Parse(text string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// Trailing dots are sentence punctuation, not part of the name
+		username := strings.TrimRight(match[1], ".")
+		if username == "" || seen[strings.ToLower(username)] {
+			continue
+		}
+		seen[strings.ToLower(username)] = true
+		usernames = append(usernames, username)
+	}
+
+	return usernames
+}
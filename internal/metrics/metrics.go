@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// CycleTimes measures each card from when it first reached a start list to
+// when it first reached an end list afterwards. Reaching any list in the set
+// counts, so cards that skip a column are still measured. A nil start set
+// measures from creation (lead time). Only cards finishing within
+// [from, to) are returned; moves must be ordered by card then time.
+func CycleTimes(moves []models.CardMove, start, end map[int]bool, from, to time.Time) []models.CycleTimeSample {
+	samples := []models.CycleTimeSample{}
+
+	for i := 0; i < len(moves); {
+		j := i
+		for j < len(moves) && moves[j].CardID == moves[i].CardID {
+			j++
+		}
+		if sample, ok := measure(moves[i:j], start, end); ok &&
+			!sample.FinishedAt.Before(from) && sample.FinishedAt.Before(to) {
+			samples = append(samples, sample)
+		}
+		i = j
+	}
+
+	return samples
+}
+
+// measure finds the first start and the following end in one card's history
+func measure(history []models.CardMove, start, end map[int]bool) (models.CycleTimeSample, bool) {
+	var started *time.Time
+	for k := range history {
+		move := history[k]
+		if started == nil && (start == nil || start[move.ToListID]) {
+			started = &history[k].MovedAt
+		}
+		if started != nil && end[move.ToListID] {
+			return models.CycleTimeSample{
+				CardID:     move.CardID,
+				StartedAt:  *started,
+				FinishedAt: move.MovedAt,
+				Hours:      round(move.MovedAt.Sub(*started).Hours()),
+			}, true
+		}
+	}
+	return models.CycleTimeSample{}, false
+}
+
+// Summarize computes distribution statistics over sample durations
+func Summarize(samples []models.CycleTimeSample) models.DurationStats {
+	hours := make([]float64, len(samples))
+	for i, s := range samples {
+		hours[i] = s.Hours
+	}
+	return SummarizeHours(hours)
+}
+
+// SummarizeHours computes distribution statistics over durations in hours
+func SummarizeHours(hours []float64) models.DurationStats {
+	if len(hours) == 0 {
+		return models.DurationStats{}
+	}
+
+	hours = append([]float64{}, hours...)
+	var total float64
+	for _, h := range hours {
+		total += h
+	}
+	sort.Float64s(hours)
+
+	return models.DurationStats{
+		Count:  len(hours),
+		Mean:   round(total / float64(len(hours))),
+		Median: Percentile(hours, 0.5),
+		P85:    Percentile(hours, 0.85),
+		P95:    Percentile(hours, 0.95),
+		Min:    hours[0],
+		Max:    hours[len(hours)-1],
+	}
+}
+
+// Percentile returns the nearest-rank percentile p (0..1) of sorted values
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// round keeps two decimal places
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
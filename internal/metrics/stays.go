@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// ListStays splits card histories into visits to lists. Each move starts a
+// stay that the card's next move ends; a card's last stay is still open and
+// is measured up to until. Moves must be ordered by card then time.
+func ListStays(moves []models.CardMove, until time.Time) []models.ListStay {
+	stays := []models.ListStay{}
+	for i, move := range moves {
+		stay := models.ListStay{CardID: move.CardID, ListID: move.ToListID, EnteredAt: move.MovedAt}
+		end := until
+		if i+1 < len(moves) && moves[i+1].CardID == move.CardID {
+			end = moves[i+1].MovedAt
+			stay.LeftAt = &end
+		}
+		stay.Hours = hoursBetween(stay.EnteredAt, end)
+		stays = append(stays, stay)
+	}
+	return stays
+}
+
+// TimeInLists totals one card's stays per list, in the order it first
+// entered them
+func TimeInLists(cardID int, stays []models.ListStay) models.CardTimeInLists {
+	result := models.CardTimeInLists{CardID: cardID, Lists: []models.CardListTime{}, Stays: stays}
+	index := make(map[int]int)
+	for _, stay := range stays {
+		i, ok := index[stay.ListID]
+		if !ok {
+			i = len(result.Lists)
+			index[stay.ListID] = i
+			result.Lists = append(result.Lists, models.CardListTime{ListID: stay.ListID})
+		}
+		list := &result.Lists[i]
+		list.Visits++
+		list.Hours = round(list.Hours + stay.Hours)
+		list.Current = stay.LeftAt == nil
+		result.TotalHours = round(result.TotalHours + stay.Hours)
+	}
+	return result
+}
+
+// hoursBetween is the time from start to end in hours, never negative
+func hoursBetween(start, end time.Time) float64 {
+	if end.Before(start) {
+		return 0
+	}
+	return round(end.Sub(start).Hours())
+}
+
+// ListTimes reports how long cards spend in each of lists: the stays that
+// ended within [from, to), and the open stays in current measured up to now.
+// The bottleneck is the list with the longest median ended stay.
+func ListTimes(lists []models.List, stays, current []models.ListStay, from, to, now time.Time) ([]models.ListTime, *int) {
+	ended := make(map[int][]float64)
+	for _, stay := range stays {
+		if stay.LeftAt != nil && !stay.LeftAt.Before(from) && stay.LeftAt.Before(to) {
+			ended[stay.ListID] = append(ended[stay.ListID], stay.Hours)
+		}
+	}
+	open := make(map[int][]float64)
+	for _, stay := range current {
+		open[stay.ListID] = append(open[stay.ListID], hoursBetween(stay.EnteredAt, now))
+	}
+
+	times := []models.ListTime{}
+	var bottleneck *int
+	var longest float64
+	for _, list := range lists {
+		lt := models.ListTime{
+			ListID:       list.ID,
+			ListName:     list.Name,
+			Stats:        SummarizeHours(ended[list.ID]),
+			CurrentCards: len(open[list.ID]),
+			CurrentStats: SummarizeHours(open[list.ID]),
+		}
+		if lt.Stats.Count > 0 && (bottleneck == nil || lt.Stats.Median > longest) {
+			id := list.ID
+			bottleneck, longest = &id, lt.Stats.Median
+		}
+		times = append(times, lt)
+	}
+	return times, bottleneck
+}
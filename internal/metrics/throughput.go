@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// FirstArrivals returns when each card first entered any list in set; moves
+// must be ordered by card then time
+func FirstArrivals(moves []models.CardMove, set map[int]bool) map[int]time.Time {
+	arrivals := make(map[int]time.Time)
+	for _, move := range moves {
+		if _, seen := arrivals[move.CardID]; !seen && set[move.ToListID] {
+			arrivals[move.CardID] = move.MovedAt
+		}
+	}
+	return arrivals
+}
+
+// Throughput counts completions in [from, to) per day, week (starting
+// Monday) or month, in UTC. Every interval overlapping the range is returned,
+// including empty ones; the first and last may be partial.
+func Throughput(completions []models.CardCompletion, interval string, from, to time.Time) []models.ThroughputInterval {
+	intervals := []models.ThroughputInterval{}
+	var starts []time.Time
+	for start := intervalStart(from, interval); start.Before(to); start = nextInterval(start, interval) {
+		starts = append(starts, start)
+		intervals = append(intervals, models.ThroughputInterval{
+			Start: start.Format("2006-01-02"),
+			End:   nextInterval(start, interval).AddDate(0, 0, -1).Format("2006-01-02"),
+		})
+	}
+
+	for _, completion := range completions {
+		if completion.CompletedAt == nil {
+			continue
+		}
+		at := completion.CompletedAt.UTC()
+		if at.Before(from) || !at.Before(to) {
+			continue
+		}
+		// Intervals are few, so a linear scan from the end is plenty
+		for i := len(starts) - 1; i >= 0; i-- {
+			if !at.Before(starts[i]) {
+				intervals[i].Cards++
+				intervals[i].Points += completion.Points
+				break
+			}
+		}
+	}
+
+	return intervals
+}
+
+// intervalStart truncates t to the start of its interval
+func intervalStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case models.IntervalWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextInterval returns the start of the interval after the one beginning at start
+func nextInterval(start time.Time, interval string) time.Time {
+	switch interval {
+	case models.IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case models.IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Default and largest activity windows, in days
+const (
+	DefaultActivityDays = 7
+	MaxActivityDays     = 90
+)
+
+// User actions shown in activity feeds
+const (
+	ActivityCreated   = "created"
+	ActivityMoved     = "moved"
+	ActivityCommented = "commented"
+	ActivitySnoozed   = "snoozed"
+)
+
+// UserActivity summarizes what a user did in the last Days days: how many
+// of each action, the most recent ones, and the cards they touched
+type UserActivity struct {
+	UserID   int            `json:"user_id"`
+	Username string         `json:"username"`
+	Days     int            `json:"days"`
+	Since    time.Time      `json:"since"`
+	Counts   map[string]int `json:"counts"`  // Actions by kind, over the whole window
+	Actions  []UserAction   `json:"actions"` // Newest first
+	Cards    []TouchedCard  `json:"cards"`   // Most recently touched first
+}
+
+// UserAction is one thing a user did to a card
+type UserAction struct {
+	Action     string    `json:"action"` // created, moved, commented or snoozed
+	CardID     int       `json:"card_id"`
+	CardTitle  string    `json:"card_title"`
+	BoardID    int       `json:"board_id"`
+	ListName   string    `json:"list_name,omitempty"`  // The list a card was created in or moved to
+	CommentID  *int      `json:"comment_id,omitempty"` // Set for comments
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// TouchedCard is a card a user acted on, as it is now
+type TouchedCard struct {
+	CardID        int       `json:"card_id"`
+	Title         string    `json:"title"`
+	BoardID       int       `json:"board_id"`
+	ListID        int       `json:"list_id"`
+	ListName      string    `json:"list_name"`
+	Completed     bool      `json:"completed"`
+	Archived      bool      `json:"archived"`
+	Actions       int       `json:"actions"`
+	LastTouchedAt time.Time `json:"last_touched_at"`
+}
@@ -0,0 +1,134 @@
+package models
+
+import "time"
+
+// API token scopes: read allows GET requests, write allows changes outside
+// /api/admin, and admin allows the /api/admin endpoints
+const (
+	TokenScopeRead  = "read"
+	TokenScopeWrite = "write"
+	TokenScopeAdmin = "admin"
+)
+
+// DefaultTokenScopes are given to tokens issued without scopes
+var DefaultTokenScopes = []string{TokenScopeRead, TokenScopeWrite}
+
+// APIToken is a bearer credential acting as one user. The secret itself is
+// only returned, in Token, when the token is issued or rotated.
+type APIToken struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Username   string     `json:"username"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Leading characters of the secret, to tell tokens apart
+	Token      string     `json:"token,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"` // Requests per minute; zero means unlimited
+	CreatedAt  time.Time  `json:"created_at"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the token was granted scope
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAPITokenRequest represents the request to issue a token for a user.
+// Without scopes the token gets DefaultTokenScopes.
+type CreateAPITokenRequest struct {
+	Name      string   `json:"name" binding:"required,min=1,max=100"`
+	Scopes    []string `json:"scopes,omitempty" binding:"omitempty,dive,oneof=read write admin"`
+	RateLimit int      `json:"rate_limit,omitempty" binding:"min=0"`
+}
+
+// UpdateAPITokenRequest changes a token's name, scopes or rate limit;
+// omitted fields are left alone
+type UpdateAPITokenRequest struct {
+	Name      *string  `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Scopes    []string `json:"scopes,omitempty" binding:"omitempty,min=1,dive,oneof=read write admin"`
+	RateLimit *int     `json:"rate_limit,omitempty" binding:"omitempty,min=0"`
+}
+
+// ReadOnlyRequest switches the instance's read-only mode
+type ReadOnlyRequest struct {
+	ReadOnly *bool `json:"read_only" binding:"required"`
+}
+
+// InstanceStats summarizes what the instance holds
+type InstanceStats struct {
+	Boards        int       `json:"boards"`
+	Lists         int       `json:"lists"`
+	Cards         int       `json:"cards"`
+	ArchivedCards int       `json:"archived_cards"`
+	Comments      int       `json:"comments"`
+	Users         int       `json:"users"`
+	DisabledUsers int       `json:"disabled_users"`
+	APITokens     int       `json:"api_tokens"` // Not revoked
+	DBSizeBytes   int64     `json:"db_size_bytes"`
+	WALSizeBytes  int64     `json:"wal_size_bytes"` // Zero when there is no write-ahead log file
+	PageSize      int64     `json:"page_size"`
+	PageCount     int64     `json:"page_count"`
+	FreelistCount int64     `json:"freelist_count"` // Unused pages, which VACUUM would give back
+	FreelistBytes int64     `json:"freelist_bytes"`
+	ReadOnly      bool      `json:"read_only"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+// Write-ahead log checkpoint modes, from least to most thorough
+const (
+	CheckpointPassive  = "passive"  // Copy what can be copied without waiting
+	CheckpointFull     = "full"     // Wait for writers, then copy everything
+	CheckpointRestart  = "restart"  // As full, then wait for readers so the log starts over
+	CheckpointTruncate = "truncate" // As restart, then empty the log file
+)
+
+// CheckpointModes lists the valid checkpoint modes
+var CheckpointModes = map[string]bool{
+	CheckpointPassive:  true,
+	CheckpointFull:     true,
+	CheckpointRestart:  true,
+	CheckpointTruncate: true,
+}
+
+// CheckpointResult reports a write-ahead log checkpoint. Busy means it
+// could not finish because of other connections; what it copied is kept.
+type CheckpointResult struct {
+	Mode               string    `json:"mode"`
+	Busy               bool      `json:"busy"`
+	LogFrames          int       `json:"log_frames"`          // Frames in the log afterwards, none after truncate; -1 when not in WAL mode
+	CheckpointedFrames int       `json:"checkpointed_frames"` // Of those, the ones copied into the database file
+	WALSizeBefore      int64     `json:"wal_size_before"`
+	WALSizeAfter       int64     `json:"wal_size_after"`
+	CheckpointedAt     time.Time `json:"checkpointed_at"`
+}
+
+// DBMetrics describes how the database has been used since the server
+// started, for GET /api/admin/metrics
+type DBMetrics struct {
+	Statements       []StatementMetrics
+	SlowStatements   int64 // Statements that took longer than the slow query threshold
+	TimedOut         int64 // Statements stopped at the query timeout
+	OpenConnections  int
+	InUseConnections int
+	IdleConnections  int
+	WaitCount        int64         // Times a statement waited for a free connection
+	WaitDuration     time.Duration // Total time spent waiting
+}
+
+// StatementMetrics is the duration histogram of one kind of statement:
+// an operation such as select or update on the table it names first
+type StatementMetrics struct {
+	Operation string
+	Table     string
+	Buckets   []float64 // Upper bounds in seconds, ascending
+	Counts    []int64   // Statements at or below each bound, so cumulative
+	Count     int64
+	Sum       time.Duration
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// AuditEntry records one successful change made through the API
+type AuditEntry struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ActorID   *int      `json:"actor_id,omitempty"` // Nil for anonymous requests
+	Actor     string    `json:"actor,omitempty"`    // Username at the time of the request
+	Action    string    `json:"action"`             // e.g. card.archive or list.update_settings
+	Route     string    `json:"route"`              // Method and route template, e.g. POST /api/cards/:id/archive
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	BoardID   *int      `json:"board_id,omitempty"` // Nil when the request did not act on an existing board
+}
+
+// AuditFilter narrows an audit log export
+type AuditFilter struct {
+	From    *time.Time
+	To      *time.Time // Exclusive
+	Actor   string     // Username
+	BoardID *int
+	Action  string // Exact action, or a prefix ending in a dot such as card.
+}
@@ -6,22 +6,79 @@ import (
 
 // Board represents a kanban board
 type Board struct {
-	ID          int       `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description,omitempty" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-	Lists       []List    `json:"lists,omitempty"` // Populated when needed
+	ID              int           `json:"id" db:"id"`
+	Name            string        `json:"name" db:"name"`
+	Description     string        `json:"description,omitempty" db:"description"`
+	DescriptionHTML string        `json:"description_html,omitempty"`           // Rendered with ?render=html
+	Icon            string        `json:"icon,omitempty" db:"icon"`             // An emoji or an icon name, e.g. rocket
+	KeyPrefix       string        `json:"key_prefix,omitempty" db:"key_prefix"` // Gives cards references like OPS-214
+	Starred         bool          `json:"starred" db:"starred"`
+	Position        float64       `json:"position" db:"position"`
+	Settings        BoardSettings `json:"settings" db:"settings"`
+	CreatedAt       time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at" db:"updated_at"`
+	Lists           []List        `json:"lists,omitempty"` // Populated when needed
 }
 
-// CreateBoardRequest represents the request to create a new board
+// BoardSettings holds appearance and behavior settings for a board
+type BoardSettings struct {
+	BackgroundColor   string `json:"background_color,omitempty" binding:"omitempty,color"`
+	BackgroundImage   string `json:"background_image,omitempty"`
+	DefaultCardColor  string `json:"default_card_color,omitempty" binding:"omitempty,color"`
+	QuickCreateListID *int   `json:"quick_create_list_id,omitempty"` // List receiving quick-created cards
+	CardAging         bool   `json:"card_aging"`
+	OverdueListID     *int   `json:"overdue_list_id,omitempty"`                         // The scheduler moves cards here once their due date passes
+	CompletedListID   *int   `json:"completed_list_id,omitempty"`                       // The scheduler moves completed cards here
+	OverdueLabelID    *int   `json:"overdue_label_id,omitempty"`                        // The scheduler labels cards with this once their due date passes
+	OverdueColor      string `json:"overdue_color,omitempty" binding:"omitempty,color"` // Color the scheduler gives cards once they are overdue
+	OverdueNotify     bool   `json:"overdue_notify,omitempty"`                          // Whether the scheduler notifies the assignee of a card once it is overdue
+	RetentionDays     int    `json:"retention_days,omitempty" binding:"min=0"`          // Purge cards archived this long ago; zero uses the server default
+}
+
+// DefaultBoardLists are the lists a new board starts with when the request
+// does not name any
+var DefaultBoardLists = []List{
+	{Name: "To Do", Color: "#60a5fa"},
+	{Name: "In Progress", Color: "#fbbf24"},
+	{Name: "Done", Color: "#10b981"},
+}
+
+// CreateBoardRequest represents the request to create a new board. Omitting
+// lists creates DefaultBoardLists; an empty array creates none.
 type CreateBoardRequest struct {
-	Name        string `json:"name" binding:"required,min=1,max=255"`
-	Description string `json:"description,omitempty"`
+	Name        string   `json:"name" binding:"required,min=1,max=255"`
+	Description string   `json:"description,omitempty" binding:"max=1000"`
+	Icon        string   `json:"icon,omitempty" binding:"omitempty,icon"`
+	KeyPrefix   string   `json:"key_prefix,omitempty" binding:"omitempty,keyprefix"`
+	Lists       []string `json:"lists" binding:"max=50,dive,required,max=255"`
 }
 
 // UpdateBoardRequest represents the request to update a board
 type UpdateBoardRequest struct {
-	Name        string `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
-	Description string `json:"description,omitempty"`
-}
\ No newline at end of file
+	Name        string  `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Description string  `json:"description,omitempty" binding:"max=1000"`
+	Icon        *string `json:"icon,omitempty" binding:"omitempty,eq=|icon"`            // "" removes the icon
+	KeyPrefix   *string `json:"key_prefix,omitempty" binding:"omitempty,eq=|keyprefix"` // "" removes the prefix
+}
+
+// StarBoardRequest represents the request to star or unstar a board
+type StarBoardRequest struct {
+	Starred *bool `json:"starred" binding:"required"`
+}
+
+// ReorderBoardsRequest represents the request to set the board display order
+type ReorderBoardsRequest struct {
+	BoardIDs []int `json:"board_ids" binding:"required,min=1"`
+}
+
+// DeletePreview counts what deleting a board or list would remove. Large
+// deletes must be confirmed by sending ConfirmToken back with the DELETE.
+type DeletePreview struct {
+	Lists                int    `json:"lists"`
+	Cards                int    `json:"cards"`
+	Comments             int    `json:"comments"`
+	Sprints              int    `json:"sprints"`
+	Milestones           int    `json:"milestones"`
+	ConfirmationRequired bool   `json:"confirmation_required"`
+	ConfirmToken         string `json:"confirm_token"` // Changes whenever the counts do
+}
@@ -2,30 +2,136 @@ package models
 
 import (
 	"time"
+	"unicode/utf8"
 )
 
+// Longest names and titles, board descriptions, and card descriptions and
+// comments, in characters. Request bindings spell out the same numbers.
+const (
+	MaxNameLength             = 255
+	MaxBoardDescriptionLength = 1000
+	MaxTextLength             = 5000
+)
+
+// IsValidCardTitle reports whether s can be a card's title: not empty and
+// at most MaxNameLength characters
+func IsValidCardTitle(s string) bool {
+	return s != "" && utf8.RuneCountInString(s) <= MaxNameLength
+}
+
+// FitsText reports whether s fits in a card description or a comment
+func FitsText(s string) bool {
+	return utf8.RuneCountInString(s) <= MaxTextLength
+}
+
 // Card represents a task/ticket in a kanban list
 type Card struct {
-	ID          int       `json:"id" db:"id"`
-	ListID      int       `json:"list_id" db:"list_id"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description,omitempty" db:"description"`
-	Position    float64   `json:"position" db:"position"`
-	Color       string    `json:"color,omitempty" db:"color"`
-	DueDate     *time.Time `json:"due_date,omitempty" db:"due_date"`
-	Archived    bool      `json:"archived" db:"archived"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-	Comments    []Comment `json:"comments,omitempty"` // Populated when needed
-	Labels      []Label   `json:"labels,omitempty"`   // Populated when needed
+	ID              int             `json:"id" db:"id"`
+	Number          int             `json:"number" db:"number"`  // Sequential per board
+	Key             string          `json:"key" db:"key"`        // Stable public identifier, e.g. c_8fk2la0q
+	Reference       string          `json:"reference,omitempty"` // Board key prefix and number, e.g. OPS-214; empty on boards without a prefix
+	ListID          int             `json:"list_id" db:"list_id"`
+	Title           string          `json:"title" db:"title"`
+	Description     string          `json:"description,omitempty" db:"description"`
+	DescriptionHTML string          `json:"description_html,omitempty"` // Rendered with ?render=html
+	Position        float64         `json:"position" db:"position"`
+	Color           string          `json:"color,omitempty" db:"color"`
+	StartDate       *time.Time      `json:"start_date,omitempty" db:"start_date"`
+	DueDate         *time.Time      `json:"due_date,omitempty" db:"due_date"`
+	Archived        bool            `json:"archived" db:"archived"`
+	Completed       bool            `json:"completed" db:"completed"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+	Blocked         bool            `json:"blocked" db:"blocked"`
+	BlockedReason   string          `json:"blocked_reason,omitempty" db:"blocked_reason"`
+	BlockedAt       *time.Time      `json:"blocked_at,omitempty" db:"blocked_at"`
+	BlockedBy       []int           `json:"blocked_by,omitempty"` // Open blocking cards; populated on single-card reads
+	Priority        string          `json:"priority,omitempty" db:"priority"`
+	AssigneeID      *int            `json:"assignee_id,omitempty" db:"assignee_id"`
+	SprintID        *int            `json:"sprint_id,omitempty" db:"sprint_id"`
+	MilestoneID     *int            `json:"milestone_id,omitempty" db:"milestone_id"`
+	Points          *int            `json:"points,omitempty" db:"points"` // Story point estimate
+	SnoozeCount     int             `json:"snooze_count" db:"snooze_count"`
+	Visibility      string          `json:"visibility" db:"visibility"`                     // board or restricted
+	CreatedBy       *int            `json:"created_by,omitempty" db:"created_by"`           // Nil for cards made anonymously or before creators were recorded
+	ListEnteredAt   *time.Time      `json:"list_entered_at,omitempty" db:"list_entered_at"` // When the card entered its current list
+	ArchivedAt      *time.Time      `json:"archived_at,omitempty" db:"archived_at"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+	Revision        int             `json:"revision" db:"revision"`  // Raised by every change to a field clients sync
+	AgeDays         int             `json:"age_days"`                // Whole days since the card was last updated or moved
+	DaysInList      int             `json:"days_in_list"`            // Whole days since the card entered its current list
+	SLABreached     bool            `json:"sla_breached"`            // Stayed in its current list longer than the list's SLA
+	Comments        []Comment       `json:"comments,omitempty"`      // Populated when needed
+	Labels          []Label         `json:"labels,omitempty"`        // Populated when needed
+	References      []CardReference `json:"references,omitempty"`    // Cards referred to in the description and comments; populated on single-card reads
+	Unfurls         []Unfurl        `json:"unfurls,omitempty"`       // Previews of links in the description; populated with ?expand=unfurls
+	CommentCount    *int            `json:"comment_count,omitempty"` // Populated on listings
+	Score           float64         `json:"score,omitempty"`         // How well the card matched a fuzzy search, from 0 to 1
+}
+
+// Card priorities, lowest to highest
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// Card visibilities. Restricted cards are seen only by their assignee and
+// creator.
+const (
+	CardVisibilityBoard      = "board"
+	CardVisibilityRestricted = "restricted"
+)
+
+// VisibleTo reports whether a user, nil for anonymous callers, may see the
+// card. Repository listings apply the same rule in SQL.
+func (c *Card) VisibleTo(userID *int) bool {
+	if c.Visibility != CardVisibilityRestricted {
+		return true
+	}
+	if userID == nil {
+		return false
+	}
+	return (c.AssigneeID != nil && *c.AssigneeID == *userID) || (c.CreatedBy != nil && *c.CreatedBy == *userID)
+}
+
+// CardSort orders a card listing; an empty Field means position
+type CardSort struct {
+	Field string
+	Desc  bool
+}
+
+// CardListOptions filters and orders a list's cards
+type CardListOptions struct {
+	IncludeArchived bool
+	SprintID        *int       // 0 selects cards in no sprint (the backlog)
+	UpdatedAfter    *time.Time // Only cards updated at or after this second
+	Sort            CardSort
+	ViewerID        *int // Restricted cards are left out unless assigned to or created by this user
+}
+
+// CardSortFields are the fields card listings can be sorted by
+var CardSortFields = map[string]bool{
+	"position":     true,
+	"due_date":     true,
+	"created_at":   true,
+	"priority":     true,
+	"title":        true,
+	"snooze_count": true,
 }
 
 // Comment represents a comment on a card
 type Comment struct {
-	ID        int       `json:"id" db:"id"`
-	CardID    int       `json:"card_id" db:"card_id"`
-	Content   string    `json:"content" db:"content"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID          int        `json:"id" db:"id"`
+	CardID      int        `json:"card_id" db:"card_id"`
+	Content     string     `json:"content" db:"content"`
+	ContentHTML string     `json:"content_html,omitempty"` // Rendered with ?render=html
+	AuthorID    *int       `json:"author_id,omitempty" db:"author_id"`
+	Author      *User      `json:"author,omitempty"`  // Populated when the comment has an author
+	Unfurls     []Unfurl   `json:"unfurls,omitempty"` // Previews of links in the content; populated with ?expand=unfurls
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty" db:"edited_at"`
 }
 
 // Label represents a label for categorization
@@ -39,42 +145,257 @@ type Label struct {
 // CreateCardRequest represents the request to create a new card
 type CreateCardRequest struct {
 	Title       string     `json:"title" binding:"required,min=1,max=255"`
-	Description string     `json:"description,omitempty"`
+	Description string     `json:"description,omitempty" binding:"max=5000"`
 	Position    float64    `json:"position,omitempty"`
-	Color       string     `json:"color,omitempty"`
+	Color       string     `json:"color,omitempty" binding:"omitempty,color"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
+	Priority    string     `json:"priority,omitempty" binding:"omitempty,oneof=low medium high urgent"`
+	AssigneeID  *int       `json:"assignee_id,omitempty"`
+	SprintID    *int       `json:"sprint_id,omitempty"`
+	MilestoneID *int       `json:"milestone_id,omitempty"`
+	Points      *int       `json:"points,omitempty" binding:"omitempty,min=0,max=1000"`
+	Visibility  string     `json:"visibility,omitempty" binding:"omitempty,oneof=board restricted"` // Default board
+	Snippets    []string   `json:"snippets,omitempty"`                                              // Names of board snippets appended to the description
+}
+
+// CreateCardFromURLRequest creates a card for a web page, such as one a
+// bookmarklet or browser extension is showing. The title defaults to the
+// page's; the description quotes the page's and links back to it.
+type CreateCardFromURLRequest struct {
+	URL        string `json:"url" binding:"required,url,max=2048"`
+	ListID     int    `json:"list_id" binding:"required"`
+	Title      string `json:"title,omitempty" binding:"max=255"` // In place of the page's title
+	Note       string `json:"note,omitempty" binding:"max=2000"` // Put above the page's description
+	Screenshot bool   `json:"screenshot,omitempty"`              // Embed the page's preview image in the description
 }
 
 // UpdateCardRequest represents the request to update a card
 type UpdateCardRequest struct {
-	Title       string     `json:"title,omitempty" binding:"omitempty,min=1,max=255"`
-	Description string     `json:"description,omitempty"`
-	Color       string     `json:"color,omitempty"`
-	DueDate     *time.Time `json:"due_date,omitempty"`
+	Title              string     `json:"title,omitempty" binding:"omitempty,min=1,max=255"`
+	Description        string     `json:"description,omitempty" binding:"max=5000"`
+	Color              string     `json:"color,omitempty" binding:"omitempty,color"`
+	StartDate          *time.Time `json:"start_date,omitempty"`
+	DueDate            *time.Time `json:"due_date,omitempty"`
+	Priority           string     `json:"priority,omitempty" binding:"omitempty,oneof=low medium high urgent"`
+	AssigneeID         *int       `json:"assignee_id,omitempty"`
+	SprintID           *int       `json:"sprint_id,omitempty"`
+	MilestoneID        *int       `json:"milestone_id,omitempty"`
+	Points             *int       `json:"points,omitempty" binding:"omitempty,min=0,max=1000"`
+	Visibility         string     `json:"visibility,omitempty" binding:"omitempty,oneof=board restricted"`
+	Revision           *int       `json:"revision,omitempty" binding:"omitempty,min=1"` // The revision the client last saw; a card changed since gets 409
+	LastKnownUpdatedAt *time.Time `json:"last_known_updated_at,omitempty"`              // The updated_at the client last saw; likewise, to the second
+}
+
+// CardConflictResponse is the 409 for an update based on an outdated copy
+// of a card, carrying the card as it is now so the client can merge
+type CardConflictResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Card    *Card  `json:"card"`
 }
 
-// MoveCardRequest represents the request to move a card
+// MoveCardRequest represents the request to move a card. The label and
+// numbering options matter mostly when moving to another board.
 type MoveCardRequest struct {
-	ListID   int     `json:"list_id" binding:"required"`
-	Position float64 `json:"position" binding:"required"`
+	ListID   int         `json:"list_id" binding:"required"`
+	Position float64     `json:"position" binding:"required"`
+	Labels   string      `json:"labels,omitempty" binding:"omitempty,oneof=keep detach"` // Default keep
+	LabelMap map[int]int `json:"label_map,omitempty"`                                    // Replace label IDs with others; 0 removes
+	Renumber *bool       `json:"renumber,omitempty"`                                     // Cross-board only; default true
+}
+
+// CardMoveOptions adjusts what happens to a card's labels and number when it moves
+type CardMoveOptions struct {
+	DetachLabels bool        // Remove every label
+	LabelMap     map[int]int // Replace label IDs with others; 0 removes
+	KeepNumber   bool        // Keep the card number on another board instead of taking the next one
+	UserID       *int        // Who moved the card, recorded in its history
+	Reason       string      // Why automation moved the card, recorded in its history
+}
+
+// SnoozeCardRequest pushes a card's due date, either by a duration such as
+// "3d", "2w" or "4h", or to a new date. Exactly one must be given.
+type SnoozeCardRequest struct {
+	Duration string     `json:"duration,omitempty"`
+	DueDate  *time.Time `json:"due_date,omitempty"`
+}
+
+// BlockCardRequest flags a card blocked. BlockedBy names the cards that
+// block it, replacing any named before; completing the last open one
+// unblocks the card.
+type BlockCardRequest struct {
+	Reason    string `json:"reason,omitempty" binding:"max=255"`
+	BlockedBy []int  `json:"blocked_by,omitempty"`
+}
+
+// CardSnooze records one postponement of a card's due date
+type CardSnooze struct {
+	ID          int        `json:"id" db:"id"`
+	CardID      int        `json:"card_id" db:"card_id"`
+	FromDueDate *time.Time `json:"from_due_date,omitempty" db:"from_due_date"` // Nil when the card had no due date
+	ToDueDate   time.Time  `json:"to_due_date" db:"to_due_date"`
+	UserID      *int       `json:"user_id,omitempty" db:"user_id"`
+	SnoozedAt   time.Time  `json:"snoozed_at" db:"snoozed_at"`
 }
 
 // CreateCommentRequest represents the request to create a comment
 type CreateCommentRequest struct {
-	Content string `json:"content" binding:"required,min=1"`
+	Content  string `json:"content" binding:"required,min=1,max=5000"`
+	AuthorID *int   `json:"author_id,omitempty"`
+}
+
+// UpdateCommentRequest represents the request to edit a comment
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required,min=1,max=5000"`
 }
 
 // CreateLabelRequest represents the request to create a label
 type CreateLabelRequest struct {
 	Name  string `json:"name" binding:"required,min=1,max=50"`
-	Color string `json:"color" binding:"required"`
+	Color string `json:"color" binding:"required,color"`
+}
+
+// BulkLabelRequest represents a request to assign or remove a label on many cards
+type BulkLabelRequest struct {
+	CardIDs []int `json:"card_ids" binding:"required,min=1"`
+}
+
+// CardCount is the number of cards matching a count request
+type CardCount struct {
+	Count int `json:"count"`
 }
 
 // SearchCardsRequest represents card search parameters
 type SearchCardsRequest struct {
-	Query    string `json:"query,omitempty" form:"query"`
-	BoardID  int    `json:"board_id,omitempty" form:"board_id"`
-	ListID   int    `json:"list_id,omitempty" form:"list_id"`
-	Archived *bool  `json:"archived,omitempty" form:"archived"`
-	LabelID  int    `json:"label_id,omitempty" form:"label_id"`
-}
\ No newline at end of file
+	Query       string `json:"query,omitempty" form:"query"`
+	Fuzzy       bool   `json:"fuzzy,omitempty" form:"fuzzy"` // Match the query's words despite typos, ranking cards by score
+	BoardID     int    `json:"board_id,omitempty" form:"board_id"`
+	ListID      int    `json:"list_id,omitempty" form:"list_id"`
+	Archived    *bool  `json:"archived,omitempty" form:"archived"`
+	LabelID     int    `json:"label_id,omitempty" form:"label_id"`
+	Completed   *bool  `json:"completed,omitempty" form:"completed"`
+	Blocked     *bool  `json:"blocked,omitempty" form:"blocked"`
+	SprintID    *int   `json:"sprint_id,omitempty" form:"sprint_id"`       // 0 selects the backlog
+	MilestoneID *int   `json:"milestone_id,omitempty" form:"milestone_id"` // 0 selects cards in no milestone
+
+	// Date filters; *Before is exclusive and *After inclusive
+	StartBefore     *time.Time `json:"start_before,omitempty" form:"start_before"`
+	StartAfter      *time.Time `json:"start_after,omitempty" form:"start_after"`
+	ActiveOn        *time.Time `json:"active_on,omitempty" form:"active_on"` // Started by the end of this day and not due before it
+	DueBefore       *time.Time `json:"due_before,omitempty" form:"due_before"`
+	DueAfter        *time.Time `json:"due_after,omitempty" form:"due_after"`
+	CreatedBefore   *time.Time `json:"created_before,omitempty" form:"created_before"`
+	CreatedAfter    *time.Time `json:"created_after,omitempty" form:"created_after"`
+	UpdatedAfter    *time.Time `json:"updated_after,omitempty" form:"updated_after"`
+	CompletedBefore *time.Time `json:"completed_before,omitempty" form:"completed_before"`
+	CompletedAfter  *time.Time `json:"completed_after,omitempty" form:"completed_after"`
+	HasDueDate      *bool      `json:"has_due_date,omitempty" form:"has_due_date"`
+	MinSnoozes      int        `json:"min_snoozes,omitempty" form:"min_snoozes"` // Cards snoozed at least this many times
+
+	ViewerID *int `json:"-" form:"-"` // Set by the handler; see CardListOptions
+}
+
+// CalendarDay groups the cards due or starting on one date
+type CalendarDay struct {
+	Date     string `json:"date"` // YYYY-MM-DD
+	Cards    []Card `json:"cards"`
+	Starting []Card `json:"starting,omitempty"` // Cards whose start date falls on this day
+}
+
+// StaleCards lists a board's open cards untouched for at least Days days
+type StaleCards struct {
+	BoardID int       `json:"board_id"`
+	Days    int       `json:"days"`
+	Before  time.Time `json:"before"` // Cards last updated or moved before this time
+	Cards   []Card    `json:"cards"`
+}
+
+// ArchiveCardsOptions narrows a bulk archive of a list's cards
+type ArchiveCardsOptions struct {
+	Completed     *bool      // Only completed, or only open, cards
+	UpdatedBefore *time.Time // Only cards last updated or moved before this time
+	ViewerID      *int       // Restricted cards are left alone unless this user may see them
+}
+
+// ArchiveCardsResult reports a bulk archive of a list's cards
+type ArchiveCardsResult struct {
+	ListID   int   `json:"list_id"`
+	Archived int   `json:"archived"`
+	CardIDs  []int `json:"card_ids"`
+}
+
+// BoardCalendar is a board's cards grouped by due date within a range
+type BoardCalendar struct {
+	BoardID int           `json:"board_id"`
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Days    []CalendarDay `json:"days"`
+	Undated []Card        `json:"undated"` // Cards without a due date
+}
+
+// TimelineItem places a card on a board timeline. Dates are YYYY-MM-DD in
+// the requested time zone; a card with only one of start_date and due_date
+// spans that single day.
+type TimelineItem struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Days  int    `json:"days"` // Inclusive length of the span
+	Card  Card   `json:"card"`
+}
+
+// BoardTimeline is a Gantt-style view of a board's scheduled cards
+type BoardTimeline struct {
+	BoardID     int            `json:"board_id"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Items       []TimelineItem `json:"items"`       // Ordered by start, then end
+	Unscheduled []Card         `json:"unscheduled"` // Cards with neither date
+}
+
+// Reasons for an automatic move
+const (
+	AutoMoveOverdue   = "overdue"
+	AutoMoveCompleted = "completed"
+)
+
+// AutoMove is a card the scheduler moved by board automation
+type AutoMove struct {
+	CardID     int    `json:"card_id"`
+	BoardID    int    `json:"board_id"`
+	FromListID int    `json:"from_list_id"`
+	ToListID   int    `json:"to_list_id"`
+	Reason     string `json:"reason"`
+}
+
+// OverdueEscalation is a card the scheduler escalated by its board's overdue
+// settings, once for each due date it missed
+type OverdueEscalation struct {
+	CardID     int  `json:"card_id"`
+	BoardID    int  `json:"board_id"`
+	AssigneeID *int `json:"assignee_id,omitempty"`
+	Changed    bool `json:"changed"` // Whether the card was labeled or recolored
+	Notify     bool `json:"notify"`  // Whether the assignee is to be notified
+}
+
+// SLABreach is a card the scheduler found to have stayed in its list longer
+// than the list's SLA, reported once for each stay
+type SLABreach struct {
+	CardID    int       `json:"card_id"`
+	ListID    int       `json:"list_id"`
+	EnteredAt time.Time `json:"entered_at"`
+}
+
+// CardMove records a card entering a list
+type CardMove struct {
+	ID           int       `json:"id" db:"id"`
+	CardID       int       `json:"card_id" db:"card_id"`
+	FromListID   *int      `json:"from_list_id,omitempty" db:"from_list_id"` // Nil when the card was created
+	FromListName string    `json:"from_list_name,omitempty"`                 // Empty for lists since deleted
+	ToListID     int       `json:"to_list_id" db:"to_list_id"`
+	ToListName   string    `json:"to_list_name,omitempty"`
+	UserID       *int      `json:"user_id,omitempty" db:"user_id"` // Nil for anonymous callers, automation and older moves
+	Username     string    `json:"username,omitempty"`
+	Reason       string    `json:"reason,omitempty" db:"reason"` // Set for automatic moves: overdue or completed
+	MovedAt      time.Time `json:"moved_at" db:"moved_at"`
+}
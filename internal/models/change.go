@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Change is one entry of the change log: an entity that was created,
+// updated or deleted. Seq increases with every change across all entities.
+type Change struct {
+	Seq       int       `json:"seq"`
+	Entity    string    `json:"entity"` // board, list, card, comment or label
+	EntityID  int       `json:"entity_id"`
+	Operation string    `json:"operation"`          // created, updated or deleted
+	BoardID   *int      `json:"board_id,omitempty"` // Omitted for labels, which belong to no board
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ChangeFeed is a page of the change log. Next is the since to pass for the
+// following page, and HasMore reports whether that page already has changes.
+type ChangeFeed struct {
+	Changes []Change `json:"changes"`
+	Next    int      `json:"next"`
+	HasMore bool     `json:"has_more"`
+}
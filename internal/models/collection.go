@@ -0,0 +1,18 @@
+package models
+
+// DefaultPageSize is the page size of enveloped collections without ?limit=
+const DefaultPageSize = 50
+
+// Collection is a page of a collection returned under /api/v1
+type Collection struct {
+	Data interface{}    `json:"data"`
+	Meta CollectionMeta `json:"meta"`
+}
+
+// CollectionMeta describes a page and how to get the next one
+type CollectionMeta struct {
+	Total      int     `json:"total"`       // Items in the whole collection
+	Limit      int     `json:"limit"`       // Largest page size
+	Offset     int     `json:"offset"`      // Items before this page
+	NextCursor *string `json:"next_cursor"` // Pass as ?cursor= for the next page; null on the last
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hexColorPattern matches #RGB and #RRGGBB color codes
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// ColorPalette maps the named colors accepted in place of hex codes.
+// Names are valid CSS color keywords so the UI can render them directly.
+var ColorPalette = map[string]string{
+	"red":    "#ef4444",
+	"orange": "#f97316",
+	"yellow": "#eab308",
+	"green":  "#22c55e",
+	"teal":   "#14b8a6",
+	"cyan":   "#06b6d4",
+	"blue":   "#3b82f6",
+	"purple": "#8b5cf6",
+	"pink":   "#ec4899",
+	"gray":   "#6b7280",
+	"black":  "#000000",
+	"white":  "#ffffff",
+}
+
+// IsValidColor reports whether s is a hex color code or a palette name
+func IsValidColor(s string) bool {
+	if hexColorPattern.MatchString(s) {
+		return true
+	}
+	_, ok := ColorPalette[strings.ToLower(s)]
+	return ok
+}
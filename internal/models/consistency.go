@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ConsistencyCheck is the outcome of one integrity check
+type ConsistencyCheck struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`    // Rows found in violation
+	Repaired    int    `json:"repaired"` // Rows deleted, cleared or renumbered
+}
+
+// ConsistencyReport lists every integrity check run against the database
+type ConsistencyReport struct {
+	CheckedAt time.Time          `json:"checked_at"`
+	Repair    bool               `json:"repair"`
+	Problems  int                `json:"problems"` // Sum of all check counts
+	Checks    []ConsistencyCheck `json:"checks"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// DashboardCard is a card shown on the dashboard with the board and list it
+// belongs to
+type DashboardCard struct {
+	Card
+	BoardID   int    `json:"board_id"`
+	BoardName string `json:"board_name"`
+	ListName  string `json:"list_name"`
+}
+
+// BoardSummary counts a board's cards for the dashboard. Open cards are
+// neither archived nor completed; overdue and due-soon counts only include
+// open cards.
+type BoardSummary struct {
+	BoardID     int    `json:"board_id"`
+	Name        string `json:"name"`
+	Starred     bool   `json:"starred"`
+	Lists       int    `json:"lists"`
+	OpenCards   int    `json:"open_cards"`
+	Completed   int    `json:"completed"`
+	Overdue     int    `json:"overdue"`
+	DueThisWeek int    `json:"due_this_week"`
+}
+
+// DashboardOptions sets the windows a dashboard is built for
+type DashboardOptions struct {
+	Now          time.Time
+	DueBy        time.Time // End of the due-this-week window, exclusive
+	UpdatedSince time.Time // Start of the recently-updated window
+	Limit        int       // Maximum cards per list
+	ViewerID     *int      // Restricted cards are left out, and not counted, unless this user may see them
+}
+
+// Dashboard summarizes every board for a landing page
+type Dashboard struct {
+	GeneratedAt     time.Time       `json:"generated_at"`
+	DueThisWeek     []DashboardCard `json:"due_this_week"`    // Open cards due within the next seven days, soonest first
+	Overdue         []DashboardCard `json:"overdue"`          // Open cards past their due date, oldest first
+	RecentlyUpdated []DashboardCard `json:"recently_updated"` // Unarchived cards, most recently updated first
+	Boards          []BoardSummary  `json:"boards"`
+}
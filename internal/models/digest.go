@@ -0,0 +1,163 @@
+package models
+
+import "time"
+
+// Digest cadences
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// Digest kinds: lists of cards to look at, or a status report of a board
+const (
+	DigestKindCards  = "cards"
+	DigestKindReport = "report"
+)
+
+// Digest defaults
+const (
+	DefaultDigestHour      = 8
+	DefaultDigestWeekday   = 1 // Monday
+	DefaultDigestStaleDays = 14
+	DigestCardLimit        = 50 // Most cards listed in each section of a report
+	ReportDays             = 7  // Span of a board report when none is given
+	ReportLabelLimit       = 5  // Most labels listed in a board report's top labels
+)
+
+// Digest is a subscription to a daily or weekly summary of one board, or of
+// every board. With UserID set the user is notified in the app and sees
+// their restricted cards; with URL set the report is posted there through
+// the webhook outbox. At least one of them is set. Report digests send a
+// BoardReport of their board instead of a DigestReport.
+type Digest struct {
+	ID         int        `json:"id"`
+	UserID     *int       `json:"user_id,omitempty"`
+	BoardID    *int       `json:"board_id,omitempty"` // Nil for every board; required for report digests
+	URL        string     `json:"url,omitempty"`
+	Kind       string     `json:"kind"` // cards or report
+	Cadence    string     `json:"cadence"`
+	Hour       int        `json:"hour"`    // UTC hour it is sent in
+	Weekday    int        `json:"weekday"` // Day weekly digests are sent on, 0 for Sunday
+	StaleDays  int        `json:"stale_days"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	NextSendAt time.Time  `json:"next_send_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Period is the time between two of the digest's reports
+func (d *Digest) Period() time.Duration {
+	if d.Cadence == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// ScheduleNext sets NextSendAt to the first time the digest is due after it
+// was last sent, or after it was created when it never has been
+func (d *Digest) ScheduleNext() {
+	from := d.CreatedAt
+	if d.LastSentAt != nil {
+		from = *d.LastSentAt
+	}
+	d.NextSendAt = d.slot(from).Add(d.Period())
+}
+
+// slot is the latest time at or before t the digest is scheduled for
+func (d *Digest) slot(t time.Time) time.Time {
+	t = t.UTC()
+	slot := time.Date(t.Year(), t.Month(), t.Day(), d.Hour, 0, 0, 0, time.UTC)
+	if d.Cadence == DigestWeekly {
+		slot = slot.AddDate(0, 0, -((int(t.Weekday()) - d.Weekday + 7) % 7))
+	}
+	if slot.After(t) {
+		slot = slot.Add(-d.Period())
+	}
+	return slot
+}
+
+// CreateDigestRequest represents the request to subscribe to a digest
+type CreateDigestRequest struct {
+	UserID    *int   `json:"user_id,omitempty"`
+	BoardID   *int   `json:"board_id,omitempty"`
+	URL       string `json:"url,omitempty" binding:"omitempty,url,publicurl,max=2048"`
+	Kind      string `json:"kind,omitempty" binding:"omitempty,oneof=cards report"` // Defaults to cards
+	Cadence   string `json:"cadence" binding:"required,oneof=daily weekly"`
+	Hour      *int   `json:"hour,omitempty" binding:"omitempty,min=0,max=23"`
+	Weekday   *int   `json:"weekday,omitempty" binding:"omitempty,min=0,max=6"`
+	StaleDays int    `json:"stale_days,omitempty" binding:"omitempty,min=1,max=365"`
+}
+
+// UpdateDigestRequest changes a digest; omitted fields are left alone. A
+// user_id or board_id of 0 and an empty url clear them.
+type UpdateDigestRequest struct {
+	UserID    *int    `json:"user_id,omitempty"`
+	BoardID   *int    `json:"board_id,omitempty"`
+	URL       *string `json:"url,omitempty" binding:"omitempty,url,publicurl,max=2048"`
+	Kind      *string `json:"kind,omitempty" binding:"omitempty,oneof=cards report"`
+	Cadence   *string `json:"cadence,omitempty" binding:"omitempty,oneof=daily weekly"`
+	Hour      *int    `json:"hour,omitempty" binding:"omitempty,min=0,max=23"`
+	Weekday   *int    `json:"weekday,omitempty" binding:"omitempty,min=0,max=6"`
+	StaleDays *int    `json:"stale_days,omitempty" binding:"omitempty,min=1,max=365"`
+}
+
+// DigestReport is what a digest sends: the cards due, created, completed and
+// gone stale, at most DigestCardLimit of each
+type DigestReport struct {
+	DigestID    int             `json:"digest_id"`
+	BoardID     *int            `json:"board_id,omitempty"`
+	Cadence     string          `json:"cadence"`
+	Since       time.Time       `json:"since"` // Start of the created and completed window: the last report, or one period ago
+	GeneratedAt time.Time       `json:"generated_at"`
+	DueThisWeek []DashboardCard `json:"due_this_week"` // Open cards due within the next seven days, overdue ones included, soonest first
+	Created     []DashboardCard `json:"created"`       // Cards created since, newest first
+	Completed   []DashboardCard `json:"completed"`     // Cards completed since, most recent first
+	Stale       []DashboardCard `json:"stale"`         // Open cards untouched for the digest's stale_days, least recently touched first
+}
+
+// Empty reports whether the report has no cards to tell about
+func (r *DigestReport) Empty() bool {
+	return len(r.DueThisWeek)+len(r.Created)+len(r.Completed)+len(r.Stale) == 0
+}
+
+// BoardReport is a status report of a board over a span of time, such as
+// the week before a Friday status update: the cards created and completed
+// in it, the open ones overdue at its end, the labels most used on the
+// cards created or completed, and how long the completed cards took from
+// creation. Counts cover every card; the lists hold at most DigestCardLimit.
+type BoardReport struct {
+	BoardID         int             `json:"board_id"`
+	BoardName       string          `json:"board_name"`
+	From            time.Time       `json:"from"`
+	To              time.Time       `json:"to"` // Exclusive
+	GeneratedAt     time.Time       `json:"generated_at"`
+	CreatedCount    int             `json:"created_count"`
+	CompletedCount  int             `json:"completed_count"`
+	CompletedPoints int             `json:"completed_points"`
+	OverdueCount    int             `json:"overdue_count"`
+	Created         []DashboardCard `json:"created"`        // Newest first
+	Completed       []DashboardCard `json:"completed"`      // Most recent first
+	Overdue         []DashboardCard `json:"overdue"`        // Most overdue first
+	TopLabels       []LabelCount    `json:"top_labels"`     // Most used first, at most ReportLabelLimit
+	CycleTime       DurationStats   `json:"cycle_time"`     // Creation to completion of the cards completed
+	HTML            string          `json:"html,omitempty"` // The Markdown report rendered with ?render=html
+}
+
+// LabelCount is a label and how many cards in a report carry it
+type LabelCount struct {
+	LabelID int    `json:"label_id"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+	Cards   int    `json:"cards"`
+}
+
+// DigestPayload is the body posted to a digest's URL. Text is a one-line
+// summary chat services such as Slack show as the message. Report digests
+// carry the board report and its Markdown rendering instead of a report.
+type DigestPayload struct {
+	Event       string        `json:"event"` // Always digest
+	Text        string        `json:"text"`
+	Report      *DigestReport `json:"report,omitempty"`
+	BoardReport *BoardReport  `json:"board_report,omitempty"`
+	Markdown    string        `json:"markdown,omitempty"`
+}
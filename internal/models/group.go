@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Group is a named set of users that boards can be shared with at once
+type Group struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateGroupRequest represents the request to create a group
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	Description string `json:"description,omitempty" binding:"max=1000"`
+}
+
+// UpdateGroupRequest represents the request to update a group; omitted
+// fields are left alone
+type UpdateGroupRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=1000"`
+}
+
+// GroupMember is a user in a group
+type GroupMember struct {
+	GroupID     int       `json:"group_id"`
+	UserID      int       `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name,omitempty"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// AddGroupMemberRequest adds an existing user to a group by ID or username
+type AddGroupMemberRequest struct {
+	UserID   *int   `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty" binding:"max=50"`
+}
+
+// BoardGroupGrant gives every member of a group a role on a board
+type BoardGroupGrant struct {
+	BoardID   int       `json:"board_id"`
+	GroupID   int       `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Role      string    `json:"role"`
+	GrantedBy *int      `json:"granted_by,omitempty"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+// GrantBoardGroupRequest shares a board with a group. The role defaults to
+// editor.
+type GrantBoardGroupRequest struct {
+	GroupID int    `json:"group_id" binding:"required,min=1"`
+	Role    string `json:"role,omitempty" binding:"omitempty,oneof=owner editor viewer"`
+}
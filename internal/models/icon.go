@@ -0,0 +1,45 @@
+package models
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// iconNamePattern matches the names of icons from the UI's icon set, such
+// as rocket or bar-chart
+var iconNamePattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// Longest icon name, and longest emoji in runes. Emoji built from several
+// code points, such as families joined with zero-width joiners, stay well
+// under it.
+const (
+	maxIconNameLength = 32
+	maxEmojiLength    = 16
+)
+
+// IsValidIcon reports whether s is an icon name or a single emoji: symbols
+// optionally combined with joiners, variation selectors, skin tone
+// modifiers, keycaps and tags, but no letters, digits or spaces.
+func IsValidIcon(s string) bool {
+	if len(s) <= maxIconNameLength && iconNamePattern.MatchString(s) {
+		return true
+	}
+	if s == "" || !utf8.ValidString(s) || utf8.RuneCountInString(s) > maxEmojiLength {
+		return false
+	}
+
+	symbols := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r):
+			symbols++
+		case r == '\u200d' || r == '\u20e3' || r == '\ufe0f' || r == '\ufe0e': // Joiner, keycap, variation selectors
+		case r >= 0x1f3fb && r <= 0x1f3ff: // Skin tone modifiers
+		case r >= 0xe0020 && r <= 0xe007f: // Tags, for subdivision flags
+		default:
+			return false
+		}
+	}
+	return symbols > 0
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// InboundHook creates a card on its list for every payload posted to its
+// URL, with the title and description rendered from the payload by
+// templates such as "{{$.commonLabels.alertname}} firing"
+type InboundHook struct {
+	ID                  int        `json:"id"`
+	Name                string     `json:"name"`
+	ListID              int        `json:"list_id"`
+	Prefix              string     `json:"prefix"`          // Leading characters of the token, to tell hooks apart
+	Token               string     `json:"token,omitempty"` // Only returned when created or rotated
+	URL                 string     `json:"url,omitempty"`   // Path to post payloads to; returned with the token
+	TitleTemplate       string     `json:"title_template"`
+	DescriptionTemplate string     `json:"description_template"`
+	CreatedAt           time.Time  `json:"created_at"`
+	LastUsedAt          *time.Time `json:"last_used_at,omitempty"`
+}
+
+// CreateInboundHookRequest represents the request to create an inbound hook
+type CreateInboundHookRequest struct {
+	Name                string `json:"name" binding:"required,min=1,max=100"`
+	ListID              int    `json:"list_id" binding:"required"`
+	TitleTemplate       string `json:"title_template" binding:"required,max=1000,template"`
+	DescriptionTemplate string `json:"description_template,omitempty" binding:"max=5000,template"`
+}
+
+// UpdateInboundHookRequest changes an inbound hook; omitted fields are left
+// alone
+type UpdateInboundHookRequest struct {
+	Name                *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	ListID              *int    `json:"list_id,omitempty" binding:"omitempty,min=1"`
+	TitleTemplate       *string `json:"title_template,omitempty" binding:"omitempty,min=1,max=1000,template"`
+	DescriptionTemplate *string `json:"description_template,omitempty" binding:"omitempty,max=5000,template"`
+}
+
+// InboundPreview is what a payload would become, returned by dry runs
+type InboundPreview struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
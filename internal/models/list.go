@@ -6,31 +6,110 @@ import (
 
 // List represents a column in a kanban board
 type List struct {
-	ID        int       `json:"id" db:"id"`
-	BoardID   int       `json:"board_id" db:"board_id"`
-	Name      string    `json:"name" db:"name"`
-	Position  float64   `json:"position" db:"position"`
-	Color     string    `json:"color" db:"color"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	Cards     []Card    `json:"cards,omitempty"` // Populated when needed
+	ID           int          `json:"id" db:"id"`
+	BoardID      int          `json:"board_id" db:"board_id"`
+	Name         string       `json:"name" db:"name"`
+	Position     float64      `json:"position" db:"position"`
+	Color        string       `json:"color" db:"color"`
+	Settings     ListSettings `json:"settings" db:"settings"`
+	CardCount    int          `json:"card_count"`    // Unarchived cards
+	OverCapacity bool         `json:"over_capacity"` // More cards than settings.capacity allows
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+	Cards        []Card       `json:"cards,omitempty"` // Populated when needed
+}
+
+// ListSettings holds rules applied to cards created in or moved into a list.
+// A default color only fills in cards without one; labels are added to the
+// card; due_in sets the due date relative to when the card enters the list.
+// Capacity is a soft limit: going over it alerts but never blocks a card.
+// SLA is the longest a card should stay in the list; the scheduler flags and
+// alerts on cards that stay longer. Sort keeps the list's cards ordered by a
+// field instead of by hand.
+type ListSettings struct {
+	DefaultCardColor      string `json:"default_card_color,omitempty" binding:"omitempty,color"`
+	LabelIDs              []int  `json:"label_ids,omitempty"`
+	DueIn                 string `json:"due_in,omitempty"`                   // e.g. 2d, 1w or 36h
+	Capacity              int    `json:"capacity,omitempty" binding:"min=0"` // Unarchived cards; zero means no limit
+	CapacityNotifyUserIDs []int  `json:"capacity_notify_user_ids,omitempty"`
+	CapacityWebhookURL    string `json:"capacity_webhook_url,omitempty" binding:"omitempty,url,publicurl"`
+	SLA                   string `json:"sla,omitempty"` // e.g. 3d, 1w or 36h
+	SLANotifyUserIDs      []int  `json:"sla_notify_user_ids,omitempty"`
+	SLAWebhookURL         string `json:"sla_webhook_url,omitempty" binding:"omitempty,url,publicurl"`
+	Sort                  string `json:"sort,omitempty" binding:"omitempty,oneof=manual due_date priority created_at"` // Empty means manual
+}
+
+// Apply fills in the list's default color and due date on a card entering
+// it at now. A due date the caller set explicitly is kept, as is one the
+// rule would put before the card's start date. The rule labels are assigned
+// separately, as they are stored apart from the card.
+func (s ListSettings) Apply(card *Card, now time.Time, explicitDue bool) {
+	if card.Color == "" {
+		card.Color = s.DefaultCardColor
+	}
+
+	if s.DueIn != "" && !explicitDue {
+		if d, err := ParseRelativeDuration(s.DueIn); err == nil {
+			due := now.Add(d)
+			if card.StartDate == nil || !card.StartDate.After(due) {
+				card.DueDate = &due
+			}
+		}
+	}
+}
+
+// List sort modes. Cards in a list sorted by a field are renumbered whenever
+// one is added, moved or changed, so their positions follow the field and
+// drags within the list do not stick.
+const (
+	ListSortManual    = "manual"
+	ListSortDueDate   = "due_date"   // Soonest first, undated cards last
+	ListSortPriority  = "priority"   // Most urgent first
+	ListSortCreatedAt = "created_at" // Oldest first
+)
+
+// CapacityAlert is posted to a list's capacity webhook when a card entering
+// the list takes it over capacity
+type CapacityAlert struct {
+	Event     string    `json:"event"` // Always list.over_capacity
+	BoardID   int       `json:"board_id"`
+	ListID    int       `json:"list_id"`
+	ListName  string    `json:"list_name"`
+	Capacity  int       `json:"capacity"`
+	CardCount int       `json:"card_count"`
+	CardID    int       `json:"card_id"` // The card that went over
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SLAAlert is posted to a list's SLA webhook when a card has stayed in the
+// list longer than its SLA
+type SLAAlert struct {
+	Event     string    `json:"event"` // Always card.sla_breached
+	BoardID   int       `json:"board_id"`
+	ListID    int       `json:"list_id"`
+	ListName  string    `json:"list_name"`
+	SLA       string    `json:"sla"`
+	CardID    int       `json:"card_id"`
+	CardTitle string    `json:"card_title"`
+	EnteredAt time.Time `json:"entered_at"` // When the card entered the list
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // CreateListRequest represents the request to create a new list
 type CreateListRequest struct {
 	Name     string  `json:"name" binding:"required,min=1,max=255"`
 	Position float64 `json:"position,omitempty"`
-	Color    string  `json:"color,omitempty"`
+	Color    string  `json:"color,omitempty" binding:"omitempty,color"`
 }
 
 // UpdateListRequest represents the request to update a list
 type UpdateListRequest struct {
 	Name     string  `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
 	Position float64 `json:"position,omitempty"`
-	Color    string  `json:"color,omitempty"`
+	Color    string  `json:"color,omitempty" binding:"omitempty,color"`
 }
 
 // MoveListRequest represents the request to move a list
 type MoveListRequest struct {
 	Position float64 `json:"position" binding:"required"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// Board member roles: owners manage the board and its members, editors
+// change its lists and cards, and viewers only read it
+const (
+	MemberRoleOwner  = "owner"
+	MemberRoleEditor = "editor"
+	MemberRoleViewer = "viewer"
+)
+
+// RoleRank orders member roles from weakest to strongest; unknown roles
+// rank below viewer
+func RoleRank(role string) int {
+	switch role {
+	case MemberRoleOwner:
+		return 3
+	case MemberRoleEditor:
+		return 2
+	case MemberRoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// DefaultInvitationDays is how long an invitation can be accepted when no
+// expiry is given
+const DefaultInvitationDays = 7
+
+// BoardMember is a user a board is shared with
+type BoardMember struct {
+	BoardID     int       `json:"board_id"`
+	UserID      int       `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	Role        string    `json:"role"`
+	AddedBy     *int      `json:"added_by,omitempty"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// AddBoardMemberRequest adds an existing user to a board by ID or username.
+// The role defaults to editor.
+type AddBoardMemberRequest struct {
+	UserID   *int   `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty" binding:"max=50"`
+	Role     string `json:"role,omitempty" binding:"omitempty,oneof=owner editor viewer"`
+}
+
+// UpdateBoardMemberRequest changes a member's role, or a group grant's
+type UpdateBoardMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// BoardAccess is a user's effective role on a board: the strongest of their
+// direct membership and the grants to the groups they are in
+type BoardAccess struct {
+	UserID      int      `json:"user_id"`
+	Username    string   `json:"username"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Role        string   `json:"role"`
+	Direct      bool     `json:"direct"` // A member in their own right
+	Groups      []string `json:"groups"` // Groups with a grant on the board the user is in
+}
+
+// BoardInvitation invites whoever holds its token to join a board. The token
+// itself is only returned, in Token, when the invitation is created.
+type BoardInvitation struct {
+	ID        int       `json:"id"`
+	BoardID   int       `json:"board_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Token     string    `json:"token,omitempty"`
+	InvitedBy *int      `json:"invited_by,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateBoardInvitationRequest represents the request to create an invite
+// link to a board. Email only records who it is meant for; nothing is sent.
+// The role defaults to editor and the invitation expires after
+// DefaultInvitationDays.
+type CreateBoardInvitationRequest struct {
+	Email         string `json:"email" binding:"required,email,max=255"`
+	Role          string `json:"role,omitempty" binding:"omitempty,oneof=owner editor viewer"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty" binding:"omitempty,min=1,max=90"`
+}
+
+// AcceptInvitationRequest represents the request to accept an invitation as
+// the acting user
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required,max=100"`
+}
@@ -0,0 +1,115 @@
+package models
+
+import "time"
+
+// DurationStats summarizes a distribution of durations, in hours
+type DurationStats struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean_hours"`
+	Median float64 `json:"median_hours"`
+	P85    float64 `json:"p85_hours"`
+	P95    float64 `json:"p95_hours"`
+	Min    float64 `json:"min_hours"`
+	Max    float64 `json:"max_hours"`
+}
+
+// CycleTimeSample is one card's trip from the start to the end boundary
+type CycleTimeSample struct {
+	CardID     int       `json:"card_id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Hours      float64   `json:"hours"`
+}
+
+// CycleTimeReport is the cycle (or lead) time distribution for a board
+type CycleTimeReport struct {
+	BoardID     int               `json:"board_id"`
+	StartListID *int              `json:"start_list_id,omitempty"` // Nil measures lead time from creation
+	EndListID   int               `json:"end_list_id"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Stats       DurationStats     `json:"stats"`
+	Cards       []CycleTimeSample `json:"cards"`
+}
+
+// Throughput intervals
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// CardCompletion is when a card was finished and the points it carried
+type CardCompletion struct {
+	CardID      int
+	CompletedAt *time.Time // Nil when the card has not been completed
+	Points      int
+}
+
+// ThroughputInterval counts the cards and points finished in one interval
+type ThroughputInterval struct {
+	Start  string `json:"start"` // First day of the interval
+	End    string `json:"end"`   // Last day of the interval, inclusive
+	Cards  int    `json:"cards"`
+	Points int    `json:"points"`
+}
+
+// ThroughputReport is how much a board finished per interval
+type ThroughputReport struct {
+	BoardID       int                  `json:"board_id"`
+	Interval      string               `json:"interval"`
+	ListID        *int                 `json:"list_id,omitempty"` // Nil counts completed cards rather than arrivals in a list
+	LabelID       *int                 `json:"label_id,omitempty"`
+	From          string               `json:"from"`
+	To            string               `json:"to"`
+	TotalCards    int                  `json:"total_cards"`
+	TotalPoints   int                  `json:"total_points"`
+	AverageCards  float64              `json:"average_cards"`
+	AveragePoints float64              `json:"average_points"`
+	Intervals     []ThroughputInterval `json:"intervals"`
+}
+
+// ListStay is one visit of a card to a list
+type ListStay struct {
+	CardID    int        `json:"card_id"`
+	ListID    int        `json:"list_id"`
+	EnteredAt time.Time  `json:"entered_at"`
+	LeftAt    *time.Time `json:"left_at,omitempty"` // Nil while the card is still there
+	Hours     float64    `json:"hours"`             // Up to now, or to archiving, for the current stay
+}
+
+// CardListTime is the total time a card has spent in one list
+type CardListTime struct {
+	ListID   int     `json:"list_id"`
+	ListName string  `json:"list_name,omitempty"` // Empty for lists since deleted
+	Visits   int     `json:"visits"`
+	Hours    float64 `json:"hours"`
+	Current  bool    `json:"current"` // The card is in this list now
+}
+
+// CardTimeInLists is how long a card has spent in each list it has been in,
+// in the order it first entered them
+type CardTimeInLists struct {
+	CardID     int            `json:"card_id"`
+	TotalHours float64        `json:"total_hours"`
+	Lists      []CardListTime `json:"lists"`
+	Stays      []ListStay     `json:"stays"`
+}
+
+// ListTime is how long cards spend in one of a board's lists
+type ListTime struct {
+	ListID       int           `json:"list_id"`
+	ListName     string        `json:"list_name"`
+	Stats        DurationStats `json:"stats"` // Stays that ended in the range
+	CurrentCards int           `json:"current_cards"`
+	CurrentStats DurationStats `json:"current_stats"` // Time so far of the unarchived cards in the list now
+}
+
+// TimeInListsReport is how long cards spend in each of a board's lists
+type TimeInListsReport struct {
+	BoardID          int        `json:"board_id"`
+	From             string     `json:"from"`
+	To               string     `json:"to"`
+	BottleneckListID *int       `json:"bottleneck_list_id,omitempty"` // The list with the longest median stay in the range
+	Lists            []ListTime `json:"lists"`                        // In board order
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Milestone is a release or goal on a board that cards roll up into
+type Milestone struct {
+	ID              int        `json:"id" db:"id"`
+	BoardID         int        `json:"board_id" db:"board_id"`
+	Title           string     `json:"title" db:"title"`
+	Description     string     `json:"description,omitempty" db:"description"`
+	DueDate         *time.Time `json:"due_date,omitempty" db:"due_date"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	CardCount       int        `json:"card_count"`
+	CompletedCount  int        `json:"completed_count"`
+	PercentComplete float64    `json:"percent_complete"` // 0-100, 0 when there are no cards
+}
+
+// CreateMilestoneRequest represents the request to create a milestone
+type CreateMilestoneRequest struct {
+	Title       string     `json:"title" binding:"required,min=1,max=255"`
+	Description string     `json:"description,omitempty" binding:"max=5000"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+}
+
+// UpdateMilestoneRequest represents the request to update a milestone
+type UpdateMilestoneRequest struct {
+	Title       string     `json:"title,omitempty" binding:"omitempty,min=1,max=255"`
+	Description *string    `json:"description,omitempty" binding:"omitempty,max=5000"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification types
+const (
+	NotificationMention      = "mention"
+	NotificationListCapacity = "list_capacity"
+	NotificationDigest       = "digest"
+	NotificationOverdue      = "overdue"
+	NotificationSLABreach    = "sla_breach"
+)
+
+// Mention records a user being @mentioned in a card description or comment
+type Mention struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	CardID    int       `json:"card_id" db:"card_id"`
+	CommentID *int      `json:"comment_id,omitempty" db:"comment_id"` // Nil for card descriptions
+	CardTitle string    `json:"card_title"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Notification represents an in-app notification for a user
+type Notification struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	Type      string     `json:"type" db:"type"`
+	CardID    *int       `json:"card_id,omitempty" db:"card_id"`
+	CommentID *int       `json:"comment_id,omitempty" db:"comment_id"`
+	Message   string     `json:"message" db:"message"`
+	ReadAt    *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
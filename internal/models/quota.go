@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Quotas cap how much the API lets clients create, protecting small
+// instances from runaway automation. Zero means no limit.
+type Quotas struct {
+	MaxBoards        int `json:"max_boards"`
+	MaxListsPerBoard int `json:"max_lists_per_board"`
+	MaxCardsPerList  int `json:"max_cards_per_list"` // Unarchived cards, as in List.CardCount
+	MaxCommentLength int `json:"max_comment_length"` // In characters
+}
+
+// Quota messages; each takes the limit
+const (
+	BoardQuotaMessage   = "Board limit reached: at most %d boards are allowed"
+	ListQuotaMessage    = "List limit reached: a board can have at most %d lists"
+	CardQuotaMessage    = "Card limit reached: a list can have at most %d unarchived cards"
+	CommentQuotaMessage = "Comment is too long: at most %d characters are allowed"
+)
+
+// QuotaError reports a change that would pass a quota. Message is one of
+// the quota messages, which take Max.
+type QuotaError struct {
+	Message string
+	Max     int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf(e.Message, e.Max)
+}
+
+// CheckQuota returns a *QuotaError when adding to count passes max. A zero
+// max is no limit.
+func CheckQuota(max, count, adding int, message string) error {
+	if max == 0 || count+adding <= max {
+		return nil
+	}
+	return &QuotaError{Message: message, Max: max}
+}
+
+// CheckCards checks adding cards to a list holding count unarchived cards
+func (q Quotas) CheckCards(count, adding int) error {
+	return CheckQuota(q.MaxCardsPerList, count, adding, CardQuotaMessage)
+}
+
+// CheckComment checks a comment's length
+func (q Quotas) CheckComment(content string) error {
+	return CheckQuota(q.MaxCommentLength, utf8.RuneCountInString(content), 0, CommentQuotaMessage)
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keyPrefixPattern matches board key prefixes: an uppercase letter followed
+// by one to nine uppercase letters or digits, such as OPS or WEB2
+var keyPrefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+// IsValidKeyPrefix reports whether s can be used as a board key prefix
+func IsValidKeyPrefix(s string) bool {
+	return keyPrefixPattern.MatchString(s)
+}
+
+// ParseCardReference splits a card reference such as OPS-214, ignoring case,
+// into its board key prefix, uppercased, and card number
+func ParseCardReference(s string) (prefix string, number int, ok bool) {
+	i := strings.LastIndex(s, "-")
+	if i < 0 {
+		return "", 0, false
+	}
+	prefix = strings.ToUpper(s[:i])
+	number, err := strconv.Atoi(s[i+1:])
+	if err != nil || number < 1 || !IsValidKeyPrefix(prefix) {
+		return "", 0, false
+	}
+	return prefix, number, true
+}
+
+// CardReference is a reference such as #123 or OPS-214 in a card's
+// description or comments, resolved to the card it names when written
+type CardReference struct {
+	Text      string `json:"text"`                 // As written, e.g. #123
+	CommentID *int   `json:"comment_id,omitempty"` // Nil for card descriptions
+	CardID    int    `json:"card_id"`              // The card referred to
+	BoardID   int    `json:"board_id"`
+	Title     string `json:"title"`
+	Key       string `json:"key"`
+	Reference string `json:"reference,omitempty"`
+	Completed bool   `json:"completed"`
+}
+
+// CardBacklink is a reference to a card from another card's description or
+// comments
+type CardBacklink struct {
+	CardID    int       `json:"card_id"`              // The card whose text holds the reference
+	CommentID *int      `json:"comment_id,omitempty"` // Nil for card descriptions
+	Text      string    `json:"text"`                 // As written, e.g. #123
+	BoardID   int       `json:"board_id"`
+	Title     string    `json:"title"`
+	Key       string    `json:"key"`
+	Reference string    `json:"reference,omitempty"`
+	Completed bool      `json:"completed"`
+	CreatedAt time.Time `json:"created_at"` // When the reference was recorded
+}
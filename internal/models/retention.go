@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// PurgeOptions controls a retention purge
+type PurgeOptions struct {
+	Now         time.Time
+	DefaultDays int  // Retention for boards without their own; zero keeps their cards
+	DryRun      bool // Report what would be purged without deleting it
+}
+
+// BoardPurge reports the archived cards purged from one board
+type BoardPurge struct {
+	BoardID       int       `json:"board_id"`
+	BoardName     string    `json:"board_name"`
+	RetentionDays int       `json:"retention_days"`
+	Before        time.Time `json:"before"` // Cards archived before this time are purged
+	Cards         int       `json:"cards"`
+	CardIDs       []int     `json:"card_ids"`
+}
+
+// PurgeReport lists what a retention purge deleted, or for a dry run what it
+// would delete. Boards without a retention policy are left out.
+type PurgeReport struct {
+	RanAt                time.Time    `json:"ran_at"`
+	DryRun               bool         `json:"dry_run"`
+	DefaultRetentionDays int          `json:"default_retention_days"`
+	Cards                int          `json:"cards"`    // Archived cards purged
+	Comments             int          `json:"comments"` // Removed along with their cards
+	Boards               []BoardPurge `json:"boards"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Snippet is a reusable block of description text on a board, appended to
+// new cards by name
+type Snippet struct {
+	ID        int       `json:"id" db:"id"`
+	BoardID   int       `json:"board_id" db:"board_id"`
+	Name      string    `json:"name" db:"name"` // Unique on the board, ignoring case
+	Content   string    `json:"content" db:"content"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateSnippetRequest represents the request to create a snippet
+type CreateSnippetRequest struct {
+	Name    string `json:"name" binding:"required,min=1,max=100"`
+	Content string `json:"content" binding:"required,min=1,max=5000"`
+}
+
+// UpdateSnippetRequest represents the request to rename or rewrite a snippet
+type UpdateSnippetRequest struct {
+	Name    *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Content *string `json:"content,omitempty" binding:"omitempty,min=1,max=5000"`
+}
+
+// ExpandSnippetsRequest previews the description a card created with these
+// snippets would get
+type ExpandSnippetsRequest struct {
+	Description string   `json:"description,omitempty" binding:"max=5000"`
+	Snippets    []string `json:"snippets" binding:"required,min=1"` // Names, in order
+}
+
+// ExpandSnippetsResponse is the expanded description
+type ExpandSnippetsResponse struct {
+	Description string `json:"description"`
+}
+
+// ExpandSnippets appends each snippet's content to description, in order,
+// separated by blank lines
+func ExpandSnippets(description string, snippets []Snippet) string {
+	parts := []string{}
+	if strings.TrimSpace(description) != "" {
+		parts = append(parts, strings.TrimRight(description, "\n"))
+	}
+	for _, snippet := range snippets {
+		parts = append(parts, strings.TrimRight(snippet.Content, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Sprint statuses
+const (
+	SprintPlanned = "planned"
+	SprintActive  = "active"
+	SprintClosed  = "closed"
+)
+
+// Sprint is a time-boxed iteration on a board
+type Sprint struct {
+	ID        int        `json:"id" db:"id"`
+	BoardID   int        `json:"board_id" db:"board_id"`
+	Name      string     `json:"name" db:"name"`
+	Goal      string     `json:"goal,omitempty" db:"goal"`
+	StartDate *time.Time `json:"start_date,omitempty" db:"start_date"`
+	EndDate   *time.Time `json:"end_date,omitempty" db:"end_date"`
+	Status    string     `json:"status" db:"status"`
+	StartedAt *time.Time `json:"started_at,omitempty" db:"started_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	CardCount int        `json:"card_count"`
+	DoneCount int        `json:"completed_count"`
+}
+
+// CreateSprintRequest represents the request to create a sprint
+type CreateSprintRequest struct {
+	Name      string     `json:"name" binding:"required,min=1,max=255"`
+	Goal      string     `json:"goal,omitempty" binding:"max=1000"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// UpdateSprintRequest represents the request to update a sprint
+type UpdateSprintRequest struct {
+	Name      string     `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	Goal      *string    `json:"goal,omitempty" binding:"omitempty,max=1000"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// CloseSprintRequest chooses where unfinished cards go when a sprint closes.
+// Without NextSprintID they move to the board's next planned sprint, or back
+// to the backlog if there is none.
+type CloseSprintRequest struct {
+	NextSprintID *int `json:"next_sprint_id,omitempty"`
+}
+
+// CloseSprintResult reports what closing a sprint did
+type CloseSprintResult struct {
+	Sprint       *Sprint `json:"sprint"`
+	NextSprintID *int    `json:"next_sprint_id,omitempty"` // Nil when unfinished cards went to the backlog
+	MovedCards   int     `json:"moved_cards"`
+}
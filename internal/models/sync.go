@@ -0,0 +1,81 @@
+package models
+
+import "encoding/json"
+
+// Sync operation types
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// Sync results
+const (
+	SyncApplied  = "applied"  // Nothing changed on the server since the base revision
+	SyncMerged   = "merged"   // Applied on top of server changes to other fields
+	SyncConflict = "conflict" // Not applied; the server changed the same fields
+	SyncRejected = "rejected" // Not applied; the operation is invalid
+)
+
+// SyncFields are the card fields sync operations can set
+var SyncFields = map[string]bool{
+	"title":       true,
+	"description": true,
+	"color":       true,
+	"priority":    true,
+	"start_date":  true,
+	"due_date":    true,
+	"assignee_id": true,
+	"points":      true,
+	"completed":   true,
+	"archived":    true,
+	"list_id":     true,
+	"position":    true,
+}
+
+// SyncRequest is a batch of card operations a client queued while offline,
+// applied in order
+type SyncRequest struct {
+	Ops []SyncOp `json:"ops" binding:"required,min=1,max=100,dive"`
+}
+
+// SyncOp is one queued card operation. OpID is chosen by the client and
+// makes resending the operation safe. Updates and deletes name the card by
+// CardID, or by CardRef, the OpID of the create that made a card the client
+// has no ID for yet, and carry the revision the client last saw.
+type SyncOp struct {
+	OpID         string                     `json:"op_id" binding:"required,max=100"`
+	Type         string                     `json:"type" binding:"required,oneof=create update delete"`
+	CardID       int                        `json:"card_id,omitempty"`
+	CardRef      string                     `json:"card_ref,omitempty" binding:"max=100"`
+	BaseRevision int                        `json:"base_revision,omitempty" binding:"min=0"`
+	Fields       map[string]json.RawMessage `json:"fields,omitempty"`
+}
+
+// SyncResult reports what became of one operation. Card is the card as it
+// is now, so the client can replace its copy; a conflict names the fields
+// the server changed differently.
+type SyncResult struct {
+	OpID      string   `json:"op_id"`
+	Status    string   `json:"status"`
+	Card      *Card    `json:"card,omitempty"`
+	Conflicts []string `json:"conflicts,omitempty"`
+	Message   string   `json:"message,omitempty"`
+	Replayed  bool     `json:"replayed,omitempty"` // Applied by an earlier request with the same op_id
+}
+
+// SyncResponse lists the result of every operation in request order
+type SyncResponse struct {
+	Results []SyncResult `json:"results"`
+}
+
+// SyncedOp is an applied sync operation as remembered for replays
+type SyncedOp struct {
+	OpID         string
+	Type         string
+	Status       string
+	CardID       int
+	BaseRevision int
+	Revision     int
+	PayloadHash  string // Empty for operations remembered before hashes were kept
+}
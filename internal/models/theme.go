@@ -0,0 +1,60 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Theme is the branding the web UI is drawn with, so an instance can be
+// white-labelled without rebuilding its assets. Colors are hex codes or
+// palette names.
+type Theme struct {
+	InstanceName string `json:"instance_name"`
+	PrimaryColor string `json:"primary_color"`
+	AccentColor  string `json:"accent_color"`
+	LogoURL      string `json:"logo_url"` // Empty for the built-in icon
+}
+
+// DefaultTheme is the theme of an instance that configures none
+var DefaultTheme = Theme{
+	InstanceName: "Kanban Board",
+	PrimaryColor: "#667eea",
+	AccentColor:  "#764ba2",
+}
+
+// Merge returns the theme with the fields set in over in place of its own
+func (t Theme) Merge(over Theme) Theme {
+	if over.InstanceName != "" {
+		t.InstanceName = over.InstanceName
+	}
+	if over.PrimaryColor != "" {
+		t.PrimaryColor = over.PrimaryColor
+	}
+	if over.AccentColor != "" {
+		t.AccentColor = over.AccentColor
+	}
+	if over.LogoURL != "" {
+		t.LogoURL = over.LogoURL
+	}
+	return t
+}
+
+// UpdateThemeRequest sets parts of the theme over the configured one.
+// Omitted fields are left alone; an empty string goes back to the
+// configured value.
+type UpdateThemeRequest struct {
+	InstanceName *string `json:"instance_name,omitempty" binding:"omitempty,max=100"`
+	PrimaryColor *string `json:"primary_color,omitempty" binding:"omitempty,eq=|color"`
+	AccentColor  *string `json:"accent_color,omitempty" binding:"omitempty,eq=|color"`
+	LogoURL      *string `json:"logo_url,omitempty" binding:"omitempty,max=2048,eq=|logourl"`
+}
+
+// IsValidLogoURL reports whether s is an http or https URL, or a path on
+// this server such as /static/logo.svg
+func IsValidLogoURL(s string) bool {
+	if strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//") {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
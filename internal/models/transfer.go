@@ -0,0 +1,165 @@
+package models
+
+import "time"
+
+// Native export format identifiers
+const (
+	ExportFormat  = "simple-kanban"
+	ExportVersion = 1
+)
+
+// BoardExport is a self-contained copy of a board for moving it between
+// instances. IDs are not carried over; sprints and milestones keep their
+// original ID only so cards can refer to them within the document, and
+// lists, cards and comments so incremental exports can be matched to them.
+//
+// An incremental export holds only the lists, cards and comments changed
+// after a change log sequence number, along with tombstones for those
+// removed since; it cannot be imported.
+type BoardExport struct {
+	Format     string              `json:"format"`
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Seq        int                 `json:"seq"`             // Change log position the export is current to; the since of the next incremental export
+	Since      *int                `json:"since,omitempty"` // Set on incremental exports
+	Board      ExportedBoard       `json:"board"`
+	Labels     []ExportedLabel     `json:"labels"`
+	Sprints    []ExportedSprint    `json:"sprints"`
+	Milestones []ExportedMilestone `json:"milestones"`
+	Lists      []ExportedList      `json:"lists"`
+	Tombstones []ExportTombstone   `json:"tombstones,omitempty"`
+}
+
+// ExportTombstone marks a list, card or comment that was deleted or moved to
+// another board after an incremental export's since. A list's tombstone
+// stands for its cards as well.
+type ExportTombstone struct {
+	Entity    string    `json:"entity"` // list, card or comment
+	ID        int       `json:"id"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// ExportedBoard holds the board's own fields
+type ExportedBoard struct {
+	Name            string        `json:"name"`
+	Description     string        `json:"description,omitempty"`
+	Icon            string        `json:"icon,omitempty"`
+	KeyPrefix       string        `json:"key_prefix,omitempty"` // Dropped on import when another board uses it
+	Settings        BoardSettings `json:"settings"`
+	QuickCreateList string        `json:"quick_create_list,omitempty"` // Name of the quick-create list; replaces settings.quick_create_list_id
+	OverdueList     string        `json:"overdue_list,omitempty"`      // Replaces settings.overdue_list_id
+	CompletedList   string        `json:"completed_list,omitempty"`    // Replaces settings.completed_list_id
+	OverdueLabel    string        `json:"overdue_label,omitempty"`     // Replaces settings.overdue_label_id
+}
+
+// ExportedLabel is a label used by the board's cards
+type ExportedLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// ExportedSprint is a sprint on the exported board
+type ExportedSprint struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Goal      string     `json:"goal,omitempty"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	Status    string     `json:"status"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ExportedMilestone is a milestone on the exported board
+type ExportedMilestone struct {
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ExportedList is a list with its cards in order; in incremental exports,
+// only those that changed
+type ExportedList struct {
+	ID       int                   `json:"id,omitempty"`
+	Name     string                `json:"name"`
+	Position float64               `json:"position"`
+	Color    string                `json:"color,omitempty"`
+	Settings *ExportedListSettings `json:"settings,omitempty"`
+	Cards    []ExportedCard        `json:"cards"`
+}
+
+// ExportedListSettings holds a list's card rules, naming labels instead of
+// referring to them by ID. Capacity and SLA alert recipients are not
+// exported.
+type ExportedListSettings struct {
+	DefaultCardColor string   `json:"default_card_color,omitempty"`
+	Labels           []string `json:"labels,omitempty"`
+	DueIn            string   `json:"due_in,omitempty"`
+	Capacity         int      `json:"capacity,omitempty"`
+	SLA              string   `json:"sla,omitempty"`
+}
+
+// ExportedCard is a card with its labels and comments
+type ExportedCard struct {
+	ID            int               `json:"id,omitempty"`
+	Key           string            `json:"key,omitempty"`
+	Number        int               `json:"number,omitempty"` // Kept on import unless another imported card has it
+	Title         string            `json:"title"`
+	Description   string            `json:"description,omitempty"`
+	Position      float64           `json:"position"`
+	Color         string            `json:"color,omitempty"`
+	StartDate     *time.Time        `json:"start_date,omitempty"`
+	DueDate       *time.Time        `json:"due_date,omitempty"`
+	Archived      bool              `json:"archived"`
+	ArchivedAt    *time.Time        `json:"archived_at,omitempty"`
+	Completed     bool              `json:"completed"`
+	CompletedAt   *time.Time        `json:"completed_at,omitempty"`
+	Blocked       bool              `json:"blocked,omitempty"`
+	BlockedReason string            `json:"blocked_reason,omitempty"` // Blockers are not exported
+	BlockedAt     *time.Time        `json:"blocked_at,omitempty"`
+	Priority      string            `json:"priority,omitempty"`
+	Points        *int              `json:"points,omitempty"`
+	Assignee      string            `json:"assignee,omitempty"`   // Username, matched on import
+	Visibility    string            `json:"visibility,omitempty"` // Omitted for board-visible cards
+	CreatedBy     string            `json:"created_by,omitempty"` // Username, matched on import
+	SprintID      *int              `json:"sprint_id,omitempty"`
+	MilestoneID   *int              `json:"milestone_id,omitempty"`
+	Labels        []string          `json:"labels,omitempty"`
+	Comments      []ExportedComment `json:"comments,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// ExportedComment is a comment on an exported card
+type ExportedComment struct {
+	ID        int        `json:"id,omitempty"`
+	Content   string     `json:"content"`
+	Author    string     `json:"author,omitempty"` // Username, matched on import
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// ImportCounts tallies the entities an import creates
+type ImportCounts struct {
+	Boards     int `json:"boards"`
+	Lists      int `json:"lists"`
+	Cards      int `json:"cards"`
+	Comments   int `json:"comments"`
+	Labels     int `json:"labels"`
+	Sprints    int `json:"sprints"`
+	Milestones int `json:"milestones"`
+}
+
+// ImportResult reports what an import did, or for a dry run what it would do
+type ImportResult struct {
+	DryRun    bool         `json:"dry_run"`
+	Valid     bool         `json:"valid"`
+	Errors    []string     `json:"errors,omitempty"`   // Validation problems; a payload with errors is not imported
+	BoardID   int          `json:"board_id,omitempty"` // Omitted on dry runs
+	Created   ImportCounts `json:"created"`
+	Conflicts []string     `json:"conflicts"` // Resolved automatically, e.g. an existing label was reused
+	Skipped   []string     `json:"skipped"`   // Data that could not be carried over
+}
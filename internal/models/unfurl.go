@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Unfurl is a preview of a web page linked from a card description or
+// comment, taken from the page's title, description and icon so clients
+// can show pasted links as rich previews
+type Unfurl struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	FaviconURL  string    `json:"favicon_url,omitempty"` // Through the image proxy when it is enabled
+	ImageURL    string    `json:"image_url,omitempty"`   // The page's preview image, likewise
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// LinkPreview is a fetched page as cached for unfurling. Failed fetches
+// are cached too, so unreachable links are not retried on every read.
+type LinkPreview struct {
+	Unfurl
+	Failed bool
+}
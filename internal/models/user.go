@@ -0,0 +1,42 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// usernamePattern restricts usernames to characters that are safe in @mentions
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// User represents a person who can author comments and own work
+type User struct {
+	ID          int        `json:"id" db:"id"`
+	Username    string     `json:"username" db:"username"`
+	DisplayName string     `json:"display_name,omitempty" db:"display_name"`
+	AvatarURL   string     `json:"avatar_url,omitempty" db:"avatar_url"`
+	Locale      string     `json:"locale,omitempty" db:"locale"` // Preferred message language; empty means Accept-Language decides
+	Disabled    bool       `json:"disabled"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateUserRequest represents the request to create a user
+type CreateUserRequest struct {
+	Username    string `json:"username" binding:"required,min=1,max=50,username"`
+	DisplayName string `json:"display_name,omitempty" binding:"max=255"`
+	AvatarURL   string `json:"avatar_url,omitempty" binding:"omitempty,url"`
+	Locale      string `json:"locale,omitempty" binding:"max=35"`
+}
+
+// UpdateUserRequest represents the request to update the acting user's
+// profile. An empty locale clears the preference.
+type UpdateUserRequest struct {
+	DisplayName *string `json:"display_name,omitempty" binding:"omitempty,max=255"`
+	AvatarURL   *string `json:"avatar_url,omitempty" binding:"omitempty,url"`
+	Locale      *string `json:"locale,omitempty" binding:"omitempty,max=35"`
+}
+
+// IsValidUsername reports whether s can be used as a username
+func IsValidUsername(s string) bool {
+	return usernamePattern.MatchString(s)
+}
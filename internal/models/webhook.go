@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook receives a POST for every change on its board, or on every board
+// when BoardID is nil, optionally narrowed to one of the board's lists and
+// to some event types
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	BoardID   *int      `json:"board_id,omitempty"`
+	ListID    *int      `json:"list_id,omitempty"` // Only list, card and comment events in this list
+	Events    []string  `json:"events"`            // Empty means every event
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"` // Signs deliveries; only returned when created or rotated
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Wants reports whether the webhook should receive an outbox entry: one of
+// its event types, on its board, and in its list, which for card.moved
+// includes the list the card left
+func (w *Webhook) Wants(entry OutboxEntry) bool {
+	if !w.Active {
+		return false
+	}
+	if w.BoardID != nil && (entry.BoardID == nil || *entry.BoardID != *w.BoardID) {
+		return false
+	}
+	if w.ListID != nil && !sameID(entry.ListID, *w.ListID) && !sameID(entry.FromListID, *w.ListID) {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == entry.Event {
+			return true
+		}
+	}
+	return false
+}
+
+// sameID reports whether an optional ID is set to id
+func sameID(optional *int, id int) bool {
+	return optional != nil && *optional == id
+}
+
+// CreateWebhookRequest represents the request to register a webhook
+type CreateWebhookRequest struct {
+	URL     string   `json:"url" binding:"required,url,publicurl,max=2048"`
+	BoardID *int     `json:"board_id,omitempty"`
+	ListID  *int     `json:"list_id,omitempty"` // Scopes the webhook to a list, and so to its board
+	Events  []string `json:"events,omitempty" binding:"omitempty,dive,event"`
+	Active  *bool    `json:"active,omitempty"`                                    // Defaults to true
+	Secret  string   `json:"secret,omitempty" binding:"omitempty,min=16,max=256"` // Generated when omitted
+}
+
+// UpdateWebhookRequest changes a webhook; omitted fields are left alone. A
+// board_id of 0 makes the webhook receive every board's events, and a
+// list_id of 0 every list's on its board. Changing the board without a list
+// drops the list.
+type UpdateWebhookRequest struct {
+	URL     *string  `json:"url,omitempty" binding:"omitempty,url,publicurl,max=2048"`
+	BoardID *int     `json:"board_id,omitempty"`
+	ListID  *int     `json:"list_id,omitempty"`
+	Events  []string `json:"events,omitempty" binding:"omitempty,dive,event"`
+	Active  *bool    `json:"active,omitempty"`
+}
+
+// OutboxEntry is a change waiting to be delivered. Entries with a URL are
+// delivered to it with their own payload; the rest go to matching webhooks.
+type OutboxEntry struct {
+	ID         int
+	Event      string
+	EntityID   int
+	BoardID    *int
+	ListID     *int // The list a list, card or comment event happened in
+	FromListID *int // The list a moved card left
+	URL        string
+	Payload    string
+	CreatedAt  time.Time
+}
+
+// WebhookPayload is the body posted to a webhook. ID is the outbox sequence
+// number and stays the same when a delivery is retried, so receivers can
+// discard duplicates.
+type WebhookPayload struct {
+	ID         int         `json:"id"`
+	Event      string      `json:"event"`
+	BoardID    *int        `json:"board_id,omitempty"`
+	EntityID   int         `json:"entity_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data,omitempty"` // The entity as of delivery; omitted once it is deleted
+}
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"   // Not yet accepted; retried after NextAttemptAt
+	DeliveryDelivered = "delivered" // Accepted with a 2xx response
+	DeliveryDead      = "dead"      // Given up on after the last attempt failed
+)
+
+// IsValidDeliveryState reports whether state is a known delivery state
+func IsValidDeliveryState(state string) bool {
+	return state == DeliveryPending || state == DeliveryDelivered || state == DeliveryDead
+}
+
+// WebhookDelivery is the state of one outbox entry for one recipient.
+// WebhookID is nil for direct deliveries.
+type WebhookDelivery struct {
+	OutboxID      int
+	WebhookID     *int
+	State         string
+	Attempts      int
+	NextAttemptAt *time.Time
+}
+
+// WebhookAttempt is one request made to deliver an outbox entry, with the
+// current state of that delivery
+type WebhookAttempt struct {
+	ID            int             `json:"id"`
+	EventID       int             `json:"event_id"` // The payload id
+	Event         string          `json:"event"`
+	WebhookID     *int            `json:"webhook_id,omitempty"`
+	Attempt       int             `json:"attempt"`
+	StatusCode    int             `json:"status_code"` // 0 when no response arrived
+	LatencyMS     int64           `json:"latency_ms"`
+	Error         string          `json:"error,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	State         string          `json:"state"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	AttemptedAt   time.Time       `json:"attempted_at"`
+}
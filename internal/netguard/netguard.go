@@ -0,0 +1,133 @@
+// Package netguard makes outgoing HTTP requests to URLs taken from users,
+// such as images and links in Markdown, without letting them reach the
+// server's own network.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for loopback, private and other internal
+// addresses, which user-supplied URLs must not be used to reach
+var ErrForbiddenAddress = errors.New("address not allowed")
+
+// NewClient creates an HTTP client that only connects to public addresses
+// and follows up to maxRedirects http and https redirects. Addresses are
+// checked on every connection, after DNS resolution, so host names and
+// redirects pointing inside the network are refused too. No outgoing proxy
+// is used, since it would be the only address checked.
+func NewClient(timeout time.Duration, maxRedirects int) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+}
+
+// PublicURL reports whether raw is an http or https URL that may name a
+// public host. Only addresses and localhost can be told apart without
+// resolving the name, so NewClient still checks where it connects.
+func PublicURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return PublicIP(ip)
+	}
+	return true
+}
+
+// reserved holds ranges PublicIP refuses besides those net.IP classifies:
+// addresses that are not routed on the internet, or that carriers and
+// networks use internally
+var reserved = parseCIDRs(
+	"0.0.0.0/8",      // "This network"
+	"100.64.0.0/10",  // Carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // Benchmarking
+	"240.0.0.0/4",    // Reserved, and broadcast
+	"64:ff9b:1::/48", // Local-use NAT64
+	"2001::/32",      // Teredo, whose IPv4 address can't be checked
+)
+
+// NAT64 and 6to4 addresses reach the IPv4 address they embed
+var (
+	nat64     = parseCIDRs("64:ff9b::/96")[0]
+	sixToFour = parseCIDRs("2002::/16")[0]
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
+
+// PublicIP reports whether ip is an address on the public internet
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return false
+	}
+	for _, n := range reserved {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	if ip.To4() == nil {
+		switch {
+		case nat64.Contains(ip):
+			return PublicIP(net.IP(ip[12:16]))
+		case sixToFour.Contains(ip):
+			return PublicIP(net.IP(ip[2:6]))
+		}
+	}
+	return true
+}
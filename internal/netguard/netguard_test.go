@@ -0,0 +1,106 @@
+package netguard
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"::ffff:93.184.216.34", true},
+
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"224.0.0.1", false},
+		{"ff02::1", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+
+		// "This network"
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		// Carrier-grade NAT
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		// IETF protocol assignments
+		{"192.0.0.8", false},
+		// Benchmarking
+		{"198.18.0.1", false},
+		{"198.19.255.254", false},
+		{"198.20.0.1", true},
+		// Reserved and broadcast
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+
+		// NAT64 follows the embedded IPv4 address
+		{"64:ff9b::7f00:1", false},
+		{"64:ff9b::a00:1", false},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"64:ff9b::5db8:d822", true},
+		{"64:ff9b:1::5db8:d822", false},
+		// So does 6to4
+		{"2002:7f00:1::", false},
+		{"2002:a00:1::1", false},
+		{"2002:a9fe:a9fe::", false},
+		{"2002:5db8:d822::1", true},
+		// Teredo
+		{"2001:0:4136:e378:8000:63bf:3fff:fdd2", false},
+	}
+
+	for _, tt := range tests {
+		ip := net.ParseIP(tt.ip)
+		if ip == nil {
+			t.Fatalf("invalid test address %s", tt.ip)
+		}
+		if got := PublicIP(ip); got != tt.public {
+			t.Errorf("PublicIP(%s) = %v, want %v", tt.ip, got, tt.public)
+		}
+		if v4 := ip.To4(); v4 != nil {
+			if got := PublicIP(v4); got != tt.public {
+				t.Errorf("PublicIP(%s as 4 bytes) = %v, want %v", tt.ip, got, tt.public)
+			}
+		}
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		public bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://93.184.216.34:8080/hook", true},
+		{"https://[2606:2800:220:1:248:1893:25c8:1946]/hook", true},
+
+		{"ftp://example.com/hook", false},
+		{"https:///hook", false},
+		{"http://localhost:8080/hook", false},
+		{"http://LOCALHOST./hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://100.64.1.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://[64:ff9b::a00:1]/hook", false},
+	}
+
+	for _, tt := range tests {
+		if got := PublicURL(tt.url); got != tt.public {
+			t.Errorf("PublicURL(%s) = %v, want %v", tt.url, got, tt.public)
+		}
+	}
+}
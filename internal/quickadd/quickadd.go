@@ -0,0 +1,219 @@
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is the interpretation of a quick-add line
+type Result struct {
+	Title    string     `json:"title"`
+	DueDate  *time.Time `json:"due_date,omitempty"`
+	Labels   []string   `json:"labels,omitempty"`
+	Priority string     `json:"priority,omitempty"`
+	Assignee string     `json:"assignee,omitempty"`
+}
+
+var (
+	labelPattern    = regexp.MustCompile(`^#([\w-]+)$`)
+	assigneePattern = regexp.MustCompile(`^@([A-Za-z0-9_.-]+)$`)
+	isoDatePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	numberPattern   = regexp.MustCompile(`^\d+$`)
+)
+
+var priorities = map[string]string{
+	"!low":    "low",
+	"!medium": "medium",
+	"!high":   "high",
+	"!urgent": "urgent",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// weekdayAbbreviations are only read as dates after "on", "next" or "due",
+// as words such as "sun" or "wed" are common in titles
+var weekdayAbbreviations = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Parse extracts a due date, #labels, a !priority and an @assignee from text.
+// Recognised tokens are removed from the title; only the first date, priority
+// and assignee are used, later ones are left in place. Relative dates are
+// resolved against now and due at the end of that day.
+func Parse(text string, now time.Time) Result {
+	var result Result
+	var title []string
+
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		token := strings.TrimRight(word, ",.;:")
+
+		if m := labelPattern.FindStringSubmatch(token); m != nil && !numberPattern.MatchString(m[1]) {
+			result.Labels = appendUnique(result.Labels, m[1])
+			continue
+		}
+
+		if p, ok := priorities[strings.ToLower(token)]; ok && result.Priority == "" {
+			result.Priority = p
+			continue
+		}
+
+		if m := assigneePattern.FindStringSubmatch(token); m != nil && result.Assignee == "" {
+			if name := strings.TrimRight(m[1], "."); name != "" {
+				result.Assignee = name
+				continue
+			}
+		}
+
+		if result.DueDate == nil {
+			if due, n := parseDate(words[i:], now, false); n > 0 {
+				result.DueDate = &due
+				i += n - 1
+				continue
+			}
+		}
+
+		title = append(title, word)
+	}
+
+	result.Title = strings.Join(title, " ")
+	return result
+}
+
+// parseDate reads a date phrase at the start of words, returning the date
+// and the number of words consumed, or zero if there is no date. Weekday
+// abbreviations count only when prefixed, as after "due".
+func parseDate(words []string, now time.Time, prefixed bool) (time.Time, int) {
+	lower := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = strings.ToLower(strings.TrimRight(w, ",.;:"))
+	}
+
+	// "due" is only consumed together with a date
+	if lower[0] == "due" && len(words) > 1 {
+		if due, n := parseDate(words[1:], now, true); n > 0 {
+			return due, n + 1
+		}
+		return time.Time{}, 0
+	}
+
+	today := endOfDay(now)
+
+	// "on" is only consumed together with a weekday or an ISO date, so
+	// titles such as "Work on today's plan" keep it
+	if lower[0] == "on" && len(lower) > 1 {
+		if wd, ok := weekday(lower[1], true); ok {
+			return nextWeekday(today, wd), 2
+		}
+		if isoDatePattern.MatchString(lower[1]) {
+			if d, err := time.ParseInLocation("2006-01-02", lower[1], now.Location()); err == nil {
+				return endOfDay(d), 2
+			}
+		}
+		return time.Time{}, 0
+	}
+
+	switch lower[0] {
+	case "today", "tonight":
+		return today, 1
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), 1
+	}
+
+	if wd, ok := weekday(lower[0], prefixed); ok {
+		return nextWeekday(today, wd), 1
+	}
+
+	if isoDatePattern.MatchString(lower[0]) {
+		if d, err := time.ParseInLocation("2006-01-02", lower[0], now.Location()); err == nil {
+			return endOfDay(d), 1
+		}
+	}
+
+	if lower[0] == "next" && len(lower) > 1 {
+		switch lower[1] {
+		case "week":
+			return today.AddDate(0, 0, 7), 2
+		case "month":
+			return today.AddDate(0, 1, 0), 2
+		}
+		if wd, ok := weekday(lower[1], true); ok {
+			return weekdayNextWeek(today, wd), 2
+		}
+	}
+
+	if lower[0] == "in" && len(lower) > 2 {
+		n, err := strconv.Atoi(lower[1])
+		if err != nil || n < 0 {
+			return time.Time{}, 0
+		}
+		switch strings.TrimSuffix(lower[2], "s") {
+		case "day":
+			return today.AddDate(0, 0, n), 3
+		case "week":
+			return today.AddDate(0, 0, 7*n), 3
+		case "month":
+			return today.AddDate(0, n, 0), 3
+		}
+	}
+
+	return time.Time{}, 0
+}
+
+// weekday looks up a weekday name, and its abbreviation when abbreviated
+func weekday(word string, abbreviated bool) (time.Weekday, bool) {
+	if wd, ok := weekdays[word]; ok {
+		return wd, true
+	}
+	if abbreviated {
+		wd, ok := weekdayAbbreviations[word]
+		return wd, ok
+	}
+	return 0, false
+}
+
+// weekdayNextWeek returns the day falling on wd in the week after from's,
+// weeks starting on Monday, so "next friday" on a Thursday is eight days on
+func weekdayNextWeek(from time.Time, wd time.Weekday) time.Time {
+	monday := from.AddDate(0, 0, 7-(int(from.Weekday())+6)%7)
+	return monday.AddDate(0, 0, (int(wd)+6)%7)
+}
+
+// nextWeekday returns the first day after from that falls on wd
+func nextWeekday(from time.Time, wd time.Weekday) time.Time {
+	days := (int(wd) - int(from.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return from.AddDate(0, 0, days)
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,63 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDates(t *testing.T) {
+	// A Thursday
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		due := time.Date(2026, 10, d, 23, 59, 59, 0, time.UTC)
+		return &due
+	}
+
+	tests := []struct {
+		text  string
+		title string
+		due   *time.Time
+	}{
+		{"Update sun icon", "Update sun icon", nil},
+		{"Sat down with the team", "Sat down with the team", nil},
+		{"Wed the configs", "Wed the configs", nil},
+		{"Fix mon dashboard", "Fix mon dashboard", nil},
+		{"Work on today's plan", "Work on today's plan", nil},
+		{"Call Bob friday", "Call Bob", day(16)},
+		{"Call Bob thursday", "Call Bob", day(22)},
+		{"Call Bob on fri", "Call Bob", day(16)},
+		{"Call Bob due sat", "Call Bob", day(17)},
+		{"Call Bob on 2026-10-20", "Call Bob", day(20)},
+		{"Call Bob next fri", "Call Bob", day(23)},
+		{"Call Bob next friday", "Call Bob", day(23)},
+		{"Call Bob next monday", "Call Bob", day(19)},
+		{"Call Bob next sunday", "Call Bob", day(25)},
+		{"Call Bob due next wednesday", "Call Bob", day(21)},
+		{"Ship it tomorrow", "Ship it", day(16)},
+		{"Ship it in 3 days", "Ship it", day(18)},
+		{"Ship it next week", "Ship it", day(22)},
+	}
+
+	for _, tt := range tests {
+		got := Parse(tt.text, now)
+		if got.Title != tt.title {
+			t.Errorf("Parse(%q).Title = %q, want %q", tt.text, got.Title, tt.title)
+		}
+		switch {
+		case tt.due == nil && got.DueDate != nil:
+			t.Errorf("Parse(%q).DueDate = %v, want none", tt.text, got.DueDate)
+		case tt.due != nil && (got.DueDate == nil || !got.DueDate.Equal(*tt.due)):
+			t.Errorf("Parse(%q).DueDate = %v, want %v", tt.text, got.DueDate, tt.due)
+		}
+	}
+}
+
+func TestParseNextWeekdayFromSunday(t *testing.T) {
+	// On a Sunday the next week starts tomorrow
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	got := Parse("Plan next monday", now)
+	want := time.Date(2026, 10, 19, 23, 59, 59, 0, time.UTC)
+	if got.DueDate == nil || !got.DueDate.Equal(want) {
+		t.Errorf("Parse(next monday) on Sunday = %v, want %v", got.DueDate, want)
+	}
+}
@@ -0,0 +1,83 @@
+package references
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// referencePattern matches #123, a card number on the same board, and
+// OPS-12, a card reference on the board with that key prefix. Neither may
+// follow a word character, so HTML entities like &#123; and words like
+// PRE-OPS-12 are not treated as references.
+var referencePattern = regexp.MustCompile(`(?:^|[^\w&#-])(#([0-9]+)|([A-Z][A-Z0-9]{1,9})-([0-9]+))\b`)
+
+// Reference is a card reference as written in text. Prefix is empty for
+// #123 references.
+type Reference struct {
+	Text   string
+	Prefix string
+	Number int
+}
+
+// Parse returns the unique card references in text, in order of appearance
+func Parse(text string) []Reference {
+	var refs []Reference
+	seen := make(map[string]bool)
+
+	for _, m := range find(text) {
+		if m.ref.Number == 0 || seen[m.ref.Text] {
+			continue
+		}
+		seen[m.ref.Text] = true
+		refs = append(refs, m.ref)
+	}
+
+	return refs
+}
+
+// Unprefix rewrites the references in text with the given key prefix, such
+// as OPS-12, to #12 references
+func Unprefix(text, prefix string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range find(text) {
+		if m.ref.Prefix != prefix {
+			continue
+		}
+		b.WriteString(text[last:m.start])
+		b.WriteString("#" + strconv.Itoa(m.ref.Number))
+		last = m.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// match is a reference and where it was found in the text
+type match struct {
+	ref        Reference
+	start, end int
+}
+
+// find returns every reference in text, duplicates included
+func find(text string) []match {
+	var matches []match
+	for _, loc := range referencePattern.FindAllStringSubmatchIndex(text, -1) {
+		m := match{ref: Reference{Text: text[loc[2]:loc[3]]}, start: loc[2], end: loc[3]}
+		digits := ""
+		if loc[4] >= 0 {
+			digits = text[loc[4]:loc[5]]
+		} else {
+			m.ref.Prefix = text[loc[6]:loc[7]]
+			digits = text[loc[8]:loc[9]]
+		}
+		number, err := strconv.Atoi(digits)
+		if err != nil {
+			// Too long to be a card number
+			continue
+		}
+		m.ref.Number = number
+		matches = append(matches, m)
+	}
+	return matches
+}
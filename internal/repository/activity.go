@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// userActions selects a user's actions since a time as action, card_id,
+// comment_id, list_name and occurred_at. Its arguments are the user ID and
+// the time, three times over.
+const userActions = `
+	SELECT CASE WHEN m.from_list_id IS NULL THEN 'created' ELSE 'moved' END AS action,
+		m.card_id, NULL AS comment_id, COALESCE(tl.name, '') AS list_name, m.moved_at AS occurred_at
+	FROM card_moves m
+	LEFT JOIN lists tl ON tl.id = m.to_list_id
+	WHERE m.user_id = ? AND datetime(m.moved_at) >= datetime(?)
+	UNION ALL
+	SELECT 'commented', cm.card_id, cm.id, '', cm.created_at
+	FROM comments cm
+	WHERE cm.author_id = ? AND datetime(cm.created_at) >= datetime(?)
+	UNION ALL
+	SELECT 'snoozed', s.card_id, NULL, '', s.snoozed_at
+	FROM card_snoozes s
+	WHERE s.user_id = ? AND datetime(s.snoozed_at) >= datetime(?)
+`
+
+// GetActivity summarizes a user's card creations, moves, comments and
+// snoozes since a time, on the cards viewer may see. Actions and cards are
+// each limited to limit entries; counts cover every action.
+func (r *UserRepository) GetActivity(userID int, since time.Time, limit int, viewer *int) (*models.UserActivity, error) {
+	visible, visibleArgs := readableBy(viewer)
+	args := append([]interface{}{userID, since, userID, since, userID, since}, visibleArgs...)
+	from := `
+		FROM (` + userActions + `) a
+		JOIN cards c ON c.id = a.card_id
+		JOIN lists l ON l.id = c.list_id
+		WHERE ` + visible
+
+	activity := &models.UserActivity{
+		UserID:  userID,
+		Since:   since,
+		Counts:  map[string]int{},
+		Actions: []models.UserAction{},
+		Cards:   []models.TouchedCard{},
+	}
+	for _, action := range []string{models.ActivityCreated, models.ActivityMoved, models.ActivityCommented, models.ActivitySnoozed} {
+		activity.Counts[action] = 0
+	}
+
+	rows, err := r.db.Query("SELECT a.action, COUNT(*)"+from+" GROUP BY a.action", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count user activity: %w", err)
+	}
+	for rows.Next() {
+		var action string
+		var count int
+		if err := rows.Scan(&action, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan user activity: %w", err)
+		}
+		activity.Counts[action] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count user activity: %w", err)
+	}
+
+	rows, err = r.db.Query(`
+		SELECT a.action, c.id, c.title, l.board_id, a.list_name, a.comment_id, a.occurred_at`+from+`
+		ORDER BY julianday(a.occurred_at) DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user activity: %w", err)
+	}
+	for rows.Next() {
+		var action models.UserAction
+		if err := rows.Scan(&action.Action, &action.CardID, &action.CardTitle, &action.BoardID,
+			&action.ListName, &action.CommentID, &action.OccurredAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan user activity: %w", err)
+		}
+		activity.Actions = append(activity.Actions, action)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get user activity: %w", err)
+	}
+
+	rows, err = r.db.Query(`
+		SELECT c.id, c.title, l.board_id, l.id, l.name, COALESCE(c.completed, 0), c.archived, COUNT(*),
+			strftime('%Y-%m-%d %H:%M:%f', MAX(julianday(a.occurred_at)))`+from+`
+		GROUP BY c.id
+		ORDER BY MAX(julianday(a.occurred_at)) DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get touched cards: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var card models.TouchedCard
+		var lastTouched string
+		if err := rows.Scan(&card.CardID, &card.Title, &card.BoardID, &card.ListID, &card.ListName,
+			&card.Completed, &card.Archived, &card.Actions, &lastTouched); err != nil {
+			return nil, fmt.Errorf("failed to scan touched card: %w", err)
+		}
+		if card.LastTouchedAt, err = time.Parse("2006-01-02 15:04:05.999", lastTouched); err != nil {
+			return nil, fmt.Errorf("failed to parse touched card time: %w", err)
+		}
+		activity.Cards = append(activity.Cards, card)
+	}
+
+	return activity, rows.Err()
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// AuditRepository handles database operations for the audit log
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// boardQueries find the board of an entity by the route segment naming it
+var boardQueries = map[string]string{
+	"boards":     "SELECT id FROM boards WHERE id = ?",
+	"lists":      "SELECT board_id FROM lists WHERE id = ?",
+	"cards":      "SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = ?",
+	"comments":   "SELECT l.board_id FROM comments cm JOIN cards c ON c.id = cm.card_id JOIN lists l ON l.id = c.list_id WHERE cm.id = ?",
+	"sprints":    "SELECT board_id FROM sprints WHERE id = ?",
+	"milestones": "SELECT board_id FROM milestones WHERE id = ?",
+	"snippets":   "SELECT board_id FROM snippets WHERE id = ?",
+}
+
+// boardOf finds the board an entity belongs to, by the route segment naming
+// its kind, such as cards. A missing entity is "card not found" and so on.
+func boardOf(db *sql.DB, kind string, id int) (int, error) {
+	query, ok := boardQueries[kind]
+	if !ok {
+		return 0, fmt.Errorf("unknown board entity %q", kind)
+	}
+
+	var boardID int
+	err := db.QueryRow(query, id).Scan(&boardID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%s not found", strings.TrimSuffix(kind, "s"))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get board of %s: %w", kind, err)
+	}
+	return boardID, nil
+}
+
+// BoardOf returns the board an entity belongs to, or nil if the kind has no
+// board or the entity does not exist
+func (r *AuditRepository) BoardOf(kind string, id int) *int {
+	boardID, err := boardOf(r.db, kind, id)
+	if err != nil {
+		return nil
+	}
+	return &boardID
+}
+
+// Record adds an entry to the audit log
+func (r *AuditRepository) Record(entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor_id, actor, action, route, path, status, board_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	entry.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		query, entry.ActorID, nullString(entry.Actor), entry.Action, entry.Route,
+		entry.Path, entry.Status, entry.BoardID, entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// Each calls fn for every entry matching filter, oldest first, without
+// loading the whole log into memory. It stops at the first error from fn.
+func (r *AuditRepository) Each(filter models.AuditFilter, fn func(models.AuditEntry) error) error {
+	query := `
+		SELECT id, created_at, actor_id, COALESCE(actor, ''), action, route, path, status, board_id
+		FROM audit_log
+	`
+	var conditions []string
+	var args []interface{}
+
+	if filter.From != nil {
+		conditions = append(conditions, "datetime(created_at) >= datetime(?)")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "datetime(created_at) < datetime(?)")
+		args = append(args, *filter.To)
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.BoardID != nil {
+		conditions = append(conditions, "board_id = ?")
+		args = append(args, *filter.BoardID)
+	}
+	if strings.HasSuffix(filter.Action, ".") {
+		conditions = append(conditions, "substr(action, 1, ?) = ?")
+		args = append(args, len(filter.Action), filter.Action)
+	} else if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		err := rows.Scan(
+			&entry.ID, &entry.CreatedAt, &entry.ActorID, &entry.Actor, &entry.Action,
+			&entry.Route, &entry.Path, &entry.Status, &entry.BoardID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kanban-simple/internal/models"
@@ -21,34 +23,93 @@ func NewBoardRepository(db *sql.DB) *BoardRepository {
 // Create creates a new board
 func (r *BoardRepository) Create(board *models.Board) error {
 	query := `
-		INSERT INTO boards (name, description, created_at, updated_at)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO boards (name, description, icon, key_prefix, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 		RETURNING id
 	`
 	now := time.Now()
 	board.CreatedAt = now
 	board.UpdatedAt = now
 
-	err := r.db.QueryRow(query, board.Name, board.Description, board.CreatedAt, board.UpdatedAt).Scan(&board.ID)
+	err := r.db.QueryRow(
+		query, board.Name, board.Description, nullString(board.Icon), nullString(board.KeyPrefix), board.CreatedAt, board.UpdatedAt,
+	).Scan(&board.ID)
 	if err != nil {
-		return fmt.Errorf("failed to create board: %w", err)
+		return boardWriteError("failed to create board", err)
 	}
 
 	return nil
 }
 
+// CreateWithLists creates a board together with its initial lists, in the
+// order given. Nothing is created if any insert fails.
+func (r *BoardRepository) CreateWithLists(board *models.Board, lists []models.List) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	board.CreatedAt = now
+	board.UpdatedAt = now
+
+	err = tx.QueryRow(`
+		INSERT INTO boards (name, description, icon, key_prefix, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, board.Name, board.Description, nullString(board.Icon), nullString(board.KeyPrefix), board.CreatedAt, board.UpdatedAt).Scan(&board.ID)
+	if err != nil {
+		return boardWriteError("failed to create board", err)
+	}
+
+	for i := range lists {
+		list := &lists[i]
+		list.BoardID = board.ID
+		list.Position = float64(i + 1)
+		list.CreatedAt = now
+		list.UpdatedAt = now
+
+		err := tx.QueryRow(`
+			INSERT INTO lists (board_id, name, position, color, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`, list.BoardID, list.Name, list.Position, list.Color, list.CreatedAt, list.UpdatedAt).Scan(&list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create list: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	board.Lists = lists
+	return nil
+}
+
+// boardWriteError reports a key prefix another board uses as "key prefix
+// taken"
+func boardWriteError(message string, err error) error {
+	if strings.Contains(err.Error(), "UNIQUE") {
+		return fmt.Errorf("key prefix taken")
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
 // GetByID retrieves a board by ID
 func (r *BoardRepository) GetByID(id int) (*models.Board, error) {
 	board := &models.Board{}
+	var settings sql.NullString
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, description, COALESCE(icon, ''), COALESCE(key_prefix, ''), starred, position, settings, created_at, updated_at
 		FROM boards
 		WHERE id = ?
 	`
 
 	err := r.db.QueryRow(query, id).Scan(
-		&board.ID, &board.Name, &board.Description,
-		&board.CreatedAt, &board.UpdatedAt,
+		&board.ID, &board.Name, &board.Description, &board.Icon, &board.KeyPrefix, &board.Starred, &board.Position,
+		&settings, &board.CreatedAt, &board.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("board not found")
@@ -57,51 +118,162 @@ func (r *BoardRepository) GetByID(id int) (*models.Board, error) {
 		return nil, fmt.Errorf("failed to get board: %w", err)
 	}
 
+	if err := decodeBoardSettings(settings, &board.Settings); err != nil {
+		return nil, err
+	}
+
 	return board, nil
 }
 
-// GetAll retrieves all boards
-func (r *BoardRepository) GetAll() ([]models.Board, error) {
+// GetAll retrieves the boards viewer may view, or only those updated at or
+// after updatedAfter
+func (r *BoardRepository) GetAll(updatedAfter *time.Time, viewer *int) ([]models.Board, error) {
+	visible, args := boardVisibleTo("boards.id", viewer)
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, description, COALESCE(icon, ''), COALESCE(key_prefix, ''), starred, position, settings, created_at, updated_at
 		FROM boards
-		ORDER BY created_at DESC
-	`
+		WHERE ` + visible
+	if updatedAfter != nil {
+		query += " AND datetime(updated_at) >= datetime(?)"
+		args = append(args, updatedAfter.UTC().Format("2006-01-02 15:04:05"))
+	}
+	query += " ORDER BY starred DESC, position ASC, created_at DESC"
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get boards: %w", err)
 	}
 	defer rows.Close()
 
-	var boards []models.Board
+	boards := []models.Board{}
 	for rows.Next() {
 		var board models.Board
+		var settings sql.NullString
 		err := rows.Scan(
-			&board.ID, &board.Name, &board.Description,
-			&board.CreatedAt, &board.UpdatedAt,
+			&board.ID, &board.Name, &board.Description, &board.Icon, &board.KeyPrefix, &board.Starred, &board.Position,
+			&settings, &board.CreatedAt, &board.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan board: %w", err)
 		}
+		if err := decodeBoardSettings(settings, &board.Settings); err != nil {
+			return nil, err
+		}
 		boards = append(boards, board)
 	}
 
 	return boards, nil
 }
 
+// Count returns the number of boards
+func (r *BoardRepository) Count() (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM boards").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count boards: %w", err)
+	}
+	return count, nil
+}
+
 // Update updates a board
 func (r *BoardRepository) Update(board *models.Board) error {
 	query := `
 		UPDATE boards
-		SET name = ?, description = ?, updated_at = ?
+		SET name = ?, description = ?, icon = ?, key_prefix = ?, updated_at = ?
 		WHERE id = ?
 	`
 
 	board.UpdatedAt = time.Now()
-	result, err := r.db.Exec(query, board.Name, board.Description, board.UpdatedAt, board.ID)
+	result, err := r.db.Exec(
+		query, board.Name, board.Description, nullString(board.Icon), nullString(board.KeyPrefix), board.UpdatedAt, board.ID,
+	)
+	if err != nil {
+		return boardWriteError("failed to update board", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("board not found")
+	}
+
+	return nil
+}
+
+// SetStarred stars or unstars a board
+func (r *BoardRepository) SetStarred(id int, starred bool) error {
+	query := `
+		UPDATE boards
+		SET starred = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, starred, time.Now(), id)
 	if err != nil {
-		return fmt.Errorf("failed to update board: %w", err)
+		return fmt.Errorf("failed to star board: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("board not found")
+	}
+
+	return nil
+}
+
+// Reorder assigns display positions to boards in the given order
+func (r *BoardRepository) Reorder(boardIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range boardIDs {
+		result, err := tx.Exec(`UPDATE boards SET position = ? WHERE id = ?`, float64(i+1), id)
+		if err != nil {
+			return fmt.Errorf("failed to reorder boards: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("board not found")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit board order: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSettings replaces the settings of a board
+func (r *BoardRepository) UpdateSettings(id int, settings models.BoardSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode board settings: %w", err)
+	}
+
+	query := `
+		UPDATE boards
+		SET settings = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, string(data), time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update board settings: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -119,15 +291,16 @@ func (r *BoardRepository) Update(board *models.Board) error {
 // GetByName retrieves a board by name
 func (r *BoardRepository) GetByName(name string) (*models.Board, error) {
 	board := &models.Board{}
+	var settings sql.NullString
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, description, COALESCE(icon, ''), COALESCE(key_prefix, ''), starred, position, settings, created_at, updated_at
 		FROM boards
 		WHERE name = ?
 	`
 
 	err := r.db.QueryRow(query, name).Scan(
-		&board.ID, &board.Name, &board.Description,
-		&board.CreatedAt, &board.UpdatedAt,
+		&board.ID, &board.Name, &board.Description, &board.Icon, &board.KeyPrefix, &board.Starred, &board.Position,
+		&settings, &board.CreatedAt, &board.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("board not found")
@@ -136,9 +309,37 @@ func (r *BoardRepository) GetByName(name string) (*models.Board, error) {
 		return nil, fmt.Errorf("failed to get board by name: %w", err)
 	}
 
+	if err := decodeBoardSettings(settings, &board.Settings); err != nil {
+		return nil, err
+	}
+
 	return board, nil
 }
 
+// DeletePreview counts the lists, cards, comments, sprints and milestones
+// that deleting a board removes with it
+func (r *BoardRepository) DeletePreview(id int) (*models.DeletePreview, error) {
+	preview := &models.DeletePreview{}
+	err := r.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM lists WHERE board_id = b.id),
+			(SELECT COUNT(*) FROM cards c JOIN lists l ON l.id = c.list_id WHERE l.board_id = b.id),
+			(SELECT COUNT(*) FROM comments cm JOIN cards c ON c.id = cm.card_id JOIN lists l ON l.id = c.list_id WHERE l.board_id = b.id),
+			(SELECT COUNT(*) FROM sprints WHERE board_id = b.id),
+			(SELECT COUNT(*) FROM milestones WHERE board_id = b.id)
+		FROM boards b
+		WHERE b.id = ?
+	`, id).Scan(&preview.Lists, &preview.Cards, &preview.Comments, &preview.Sprints, &preview.Milestones)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("board not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview board delete: %w", err)
+	}
+
+	return preview, nil
+}
+
 // Delete deletes a board
 func (r *BoardRepository) Delete(id int) error {
 	query := `DELETE FROM boards WHERE id = ?`
@@ -158,4 +359,17 @@ func (r *BoardRepository) Delete(id int) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// decodeBoardSettings parses the JSON settings column of a board
+func decodeBoardSettings(raw sql.NullString, settings *models.BoardSettings) error {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(raw.String), settings); err != nil {
+		return fmt.Errorf("failed to decode board settings: %w", err)
+	}
+
+	return nil
+}
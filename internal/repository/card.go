@@ -1,14 +1,70 @@
 package repository
 
 import (
+	"crypto/rand"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/kanban-simple/internal/fuzzy"
 	"github.com/kanban-simple/internal/models"
 )
 
+// cardColumns selects every card field; queries alias the cards table as c
+const cardColumns = `
+	c.id, c.list_id, c.title, c.description, c.position, c.color, c.due_date, c.start_date,
+	c.archived, COALESCE(c.completed, 0), c.completed_at, c.blocked, COALESCE(c.blocked_reason, ''), c.blocked_at,
+	COALESCE(c.priority, ''), c.assignee_id, COALESCE(c.number, 0),
+	COALESCE(c.key, ''), c.sprint_id, c.milestone_id, c.points, COALESCE(c.snooze_count, 0), c.visibility, c.created_by, c.list_entered_at, c.archived_at, c.created_at, c.updated_at, c.revision,
+	COALESCE(datetime(c.sla_breached_at) >= datetime(COALESCE(c.list_entered_at, c.created_at)), 0),
+	` + cardReference + `
+`
+
+// cardReference selects the reference of a card aliased c, such as OPS-214,
+// or an empty string when its board has no key prefix
+const cardReference = `COALESCE((
+	SELECT rb.key_prefix || '-' || c.number FROM lists rl JOIN boards rb ON rb.id = rl.board_id WHERE rl.id = c.list_id
+), '')`
+
+// listSortOrders orders the cards of a list sorted by a field; ties keep
+// their manual order
+var listSortOrders = map[string]string{
+	models.ListSortDueDate:   "c.due_date IS NULL, " + cardSortColumns["due_date"] + ", c.position, c.id",
+	models.ListSortPriority:  cardSortColumns["priority"] + " DESC, c.position, c.id",
+	models.ListSortCreatedAt: cardSortColumns["created_at"] + ", c.id",
+}
+
+// visibleTo limits a query on cards aliased c to those viewer may see: every
+// board-visible card, and restricted ones assigned to or created by them. A
+// nil viewer sees only board-visible cards. Card.VisibleTo is the same rule.
+func visibleTo(viewer *int) (string, []interface{}) {
+	if viewer == nil {
+		return "c.visibility != 'restricted'", nil
+	}
+	return "(c.visibility != 'restricted' OR c.assignee_id = ? OR c.created_by = ?)", []interface{}{*viewer, *viewer}
+}
+
+// readableBy limits a query on cards aliased c that spans boards to the
+// cards viewer may read: those visibleTo allows, on boards boardVisibleTo
+// allows. Queries on one board leave the board check to the route.
+func readableBy(viewer *int) (string, []interface{}) {
+	visible, args := visibleTo(viewer)
+	board, boardArgs := boardVisibleTo("(SELECT board_id FROM lists WHERE id = c.list_id)", viewer)
+	return "(" + visible + " AND " + board + ")", append(args, boardArgs...)
+}
+
+// cardSortColumns maps sort fields to ORDER BY expressions
+var cardSortColumns = map[string]string{
+	"position":     "c.position",
+	"due_date":     "datetime(c.due_date)",
+	"created_at":   "datetime(c.created_at)",
+	"priority":     "CASE c.priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END",
+	"title":        "c.title COLLATE NOCASE",
+	"snooze_count": "c.snooze_count",
+}
+
 // CardRepository handles database operations for cards
 type CardRepository struct {
 	db *sql.DB
@@ -33,40 +89,430 @@ func (r *CardRepository) Create(card *models.Card) error {
 		card.Position = maxPosition.Float64 + 1.0
 	}
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	card.Number, err = nextCardNumber(tx, card.ListID)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO cards (list_id, title, description, position, color, due_date, archived, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO cards (list_id, title, description, position, color, start_date, due_date, archived, priority, assignee_id, sprint_id, milestone_id, points, visibility, created_by, number, key, list_entered_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		RETURNING id
 	`
 	now := time.Now()
 	card.CreatedAt = now
 	card.UpdatedAt = now
+	card.ListEnteredAt = &now
+	card.Revision = 1
+	if card.Visibility == "" {
+		card.Visibility = models.CardVisibilityBoard
+	}
 
-	err := r.db.QueryRow(
-		query, card.ListID, card.Title, card.Description, card.Position,
-		card.Color, card.DueDate, card.Archived, card.CreatedAt, card.UpdatedAt,
-	).Scan(&card.ID)
+	// Keys are random; retry the rare collision with a fresh one
+	keyGiven := card.Key != ""
+	for attempt := 0; ; attempt++ {
+		if !keyGiven {
+			if card.Key, err = newCardKey(); err != nil {
+				return err
+			}
+		}
+		err = tx.QueryRow(
+			query, card.ListID, card.Title, card.Description, card.Position,
+			card.Color, card.StartDate, card.DueDate, card.Archived, nullString(card.Priority), card.AssigneeID,
+			card.SprintID, card.MilestoneID, card.Points, card.Visibility, card.CreatedBy, card.Number, card.Key, card.ListEnteredAt, card.CreatedAt, card.UpdatedAt,
+		).Scan(&card.ID)
+		if err == nil || keyGiven || attempt == 2 || !strings.Contains(err.Error(), "UNIQUE") {
+			break
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create card: %w", err)
 	}
 
+	if err := recordMove(tx, card.ID, nil, card.ListID, card.CreatedBy, "", now); err != nil {
+		return err
+	}
+
+	if err := sortListCards(tx, card.ListID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create card: %w", err)
+	}
+
+	// The list's sort may have moved the card
+	err = r.db.QueryRow("SELECT c.position, "+cardReference+" FROM cards c WHERE c.id = ?", card.ID).Scan(&card.Position, &card.Reference)
+	if err != nil {
+		return fmt.Errorf("failed to get card position: %w", err)
+	}
+
+	return nil
+}
+
+// dbExecer is a *sql.DB or *sql.Tx
+type dbExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// sortListCards renumbers the cards of a list sorted by a field in that
+// order; lists sorted by hand are left alone
+func sortListCards(db dbExecer, listID int) error {
+	var mode sql.NullString
+	err := db.QueryRow("SELECT json_extract(settings, '$.sort') FROM lists WHERE id = ?", listID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get list sort: %w", err)
+	}
+	order, ok := listSortOrders[mode.String]
+	if !ok {
+		return nil
+	}
+
+	_, err = db.Exec(`
+		UPDATE cards SET position = n.num
+		FROM (
+			SELECT c.id, ROW_NUMBER() OVER (ORDER BY `+order+`) AS num
+			FROM cards c
+			WHERE c.list_id = ?
+		) n
+		WHERE n.id = cards.id AND cards.position != n.num
+	`, listID)
+	if err != nil {
+		return fmt.Errorf("failed to sort list: %w", err)
+	}
+	return nil
+}
+
+// recordMove appends to a card's movement history; fromListID is nil on
+// creation, and userID for anonymous callers and automation
+func recordMove(tx *sql.Tx, cardID int, fromListID *int, toListID int, userID *int, reason string, at time.Time) error {
+	_, err := tx.Exec(
+		"INSERT INTO card_moves (card_id, from_list_id, to_list_id, user_id, reason, moved_at) VALUES (?, ?, ?, ?, ?, ?)",
+		cardID, fromListID, toListID, userID, nullString(reason), at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record card move: %w", err)
+	}
 	return nil
 }
 
+// GetMovesByBoard returns the movement history of every card that has been
+// on a board's lists, ordered by card then time
+func (r *CardRepository) GetMovesByBoard(boardID int) ([]models.CardMove, error) {
+	rows, err := r.db.Query(`
+		SELECT m.id, m.card_id, m.from_list_id, m.to_list_id, m.moved_at
+		FROM card_moves m
+		WHERE m.card_id IN (
+			SELECT m2.card_id FROM card_moves m2
+			JOIN lists l ON l.id = m2.to_list_id
+			WHERE l.board_id = ?
+		)
+		ORDER BY m.card_id, datetime(m.moved_at), m.id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card moves: %w", err)
+	}
+	defer rows.Close()
+
+	moves := []models.CardMove{}
+	for rows.Next() {
+		var move models.CardMove
+		if err := rows.Scan(&move.ID, &move.CardID, &move.FromListID, &move.ToListID, &move.MovedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan card move: %w", err)
+		}
+		moves = append(moves, move)
+	}
+
+	return moves, rows.Err()
+}
+
+// GetMovesByCard returns a card's movement history, oldest first, with the
+// names of the lists and of the user who moved it
+func (r *CardRepository) GetMovesByCard(cardID int) ([]models.CardMove, error) {
+	rows, err := r.db.Query(`
+		SELECT m.id, m.card_id, m.from_list_id, COALESCE(f.name, ''), m.to_list_id, COALESCE(t.name, ''),
+			m.user_id, COALESCE(u.username, ''), COALESCE(m.reason, ''), m.moved_at
+		FROM card_moves m
+		LEFT JOIN lists f ON f.id = m.from_list_id
+		LEFT JOIN lists t ON t.id = m.to_list_id
+		LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.card_id = ?
+		ORDER BY datetime(m.moved_at), m.id
+	`, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card moves: %w", err)
+	}
+	defer rows.Close()
+
+	moves := []models.CardMove{}
+	for rows.Next() {
+		var move models.CardMove
+		err := rows.Scan(
+			&move.ID, &move.CardID, &move.FromListID, &move.FromListName, &move.ToListID, &move.ToListName,
+			&move.UserID, &move.Username, &move.Reason, &move.MovedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card move: %w", err)
+		}
+		moves = append(moves, move)
+	}
+
+	return moves, rows.Err()
+}
+
+// GetCurrentStays returns the open stay of every unarchived card on a board:
+// the list it is in and when it entered it. Hours are left to the caller.
+func (r *CardRepository) GetCurrentStays(boardID int) ([]models.ListStay, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, c.list_id, c.list_entered_at, c.created_at
+		FROM cards c
+		JOIN lists l ON l.id = c.list_id
+		WHERE l.board_id = ? AND c.archived = 0
+		ORDER BY c.id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current stays: %w", err)
+	}
+	defer rows.Close()
+
+	stays := []models.ListStay{}
+	for rows.Next() {
+		var stay models.ListStay
+		var enteredAt *time.Time
+		if err := rows.Scan(&stay.CardID, &stay.ListID, &enteredAt, &stay.EnteredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan current stay: %w", err)
+		}
+		if enteredAt != nil {
+			stay.EnteredAt = *enteredAt
+		}
+		stays = append(stays, stay)
+	}
+
+	return stays, rows.Err()
+}
+
+// GetCompletions returns when each of a board's cards was completed and its
+// points, limited to cards with labelID when it is non-zero. Archived cards
+// are included; cards not completed have a nil CompletedAt.
+func (r *CardRepository) GetCompletions(boardID, labelID int) ([]models.CardCompletion, error) {
+	query := `
+		SELECT c.id, c.completed_at, COALESCE(c.points, 0)
+		FROM cards c
+		JOIN lists l ON l.id = c.list_id
+		WHERE l.board_id = ?
+	`
+	args := []interface{}{boardID}
+	if labelID != 0 {
+		query += " AND EXISTS (SELECT 1 FROM card_labels cl WHERE cl.card_id = c.id AND cl.label_id = ?)"
+		args = append(args, labelID)
+	}
+	query += " ORDER BY c.id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card completions: %w", err)
+	}
+	defer rows.Close()
+
+	completions := []models.CardCompletion{}
+	for rows.Next() {
+		var completion models.CardCompletion
+		if err := rows.Scan(&completion.CardID, &completion.CompletedAt, &completion.Points); err != nil {
+			return nil, fmt.Errorf("failed to scan card completion: %w", err)
+		}
+		completions = append(completions, completion)
+	}
+
+	return completions, rows.Err()
+}
+
+// keepCardNumber reserves a card's current number on the board of listID,
+// failing if another card there already has it
+func keepCardNumber(tx *sql.Tx, cardID, listID int) (int, error) {
+	var number int
+	var taken bool
+	err := tx.QueryRow(`
+		SELECT COALESCE(c.number, 0), EXISTS(
+			SELECT 1 FROM cards o
+			JOIN lists ol ON ol.id = o.list_id
+			JOIN lists dst ON dst.id = ?
+			WHERE ol.board_id = dst.board_id AND o.number = c.number AND o.id != c.id
+		)
+		FROM cards c WHERE c.id = ?
+	`, listID, cardID).Scan(&number, &taken)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check card number: %w", err)
+	}
+	if taken || number == 0 {
+		return 0, fmt.Errorf("card number taken")
+	}
+
+	// Later cards on the target board must not be given this number
+	_, err = tx.Exec(`
+		INSERT INTO board_card_counters (board_id, last_number)
+		SELECT board_id, ? FROM lists WHERE id = ?
+		ON CONFLICT(board_id) DO UPDATE SET last_number = MAX(last_number, excluded.last_number)
+	`, number, listID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign card number: %w", err)
+	}
+	return number, nil
+}
+
+// remapCardLabels detaches or replaces a card's labels as opts ask
+func remapCardLabels(tx *sql.Tx, cardID int, opts models.CardMoveOptions) error {
+	if opts.DetachLabels {
+		if _, err := tx.Exec("DELETE FROM card_labels WHERE card_id = ?", cardID); err != nil {
+			return fmt.Errorf("failed to detach labels: %w", err)
+		}
+		return nil
+	}
+
+	for from, to := range opts.LabelMap {
+		if to != 0 && to != from {
+			_, err := tx.Exec(`
+				INSERT OR IGNORE INTO card_labels (card_id, label_id)
+				SELECT card_id, ? FROM card_labels WHERE card_id = ? AND label_id = ?
+			`, to, cardID, from)
+			if err != nil {
+				return fmt.Errorf("failed to remap label: %w", err)
+			}
+		}
+		if to != from {
+			if _, err := tx.Exec("DELETE FROM card_labels WHERE card_id = ? AND label_id = ?", cardID, from); err != nil {
+				return fmt.Errorf("failed to remap label: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// nextCardNumber reserves the next card number on the board owning listID
+func nextCardNumber(tx *sql.Tx, listID int) (int, error) {
+	var number int
+	err := tx.QueryRow(`
+		INSERT INTO board_card_counters (board_id, last_number)
+		SELECT board_id, 1 FROM lists WHERE id = ?
+		ON CONFLICT(board_id) DO UPDATE SET last_number = last_number + 1
+		RETURNING last_number
+	`, listID).Scan(&number)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("list not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign card number: %w", err)
+	}
+	return number, nil
+}
+
+// cardKeyAlphabet is the character set of generated card keys
+const cardKeyAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newCardKey returns a random key such as c_8fk2la0q
+func newCardKey() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate card key: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = cardKeyAlphabet[int(b)%len(cardKeyAlphabet)]
+	}
+	return "c_" + string(buf), nil
+}
+
+// GetByKey retrieves a card by its public key, or by a reference such as
+// OPS-214
+func (r *CardRepository) GetByKey(key string) (*models.Card, error) {
+	if _, _, ok := models.ParseCardReference(key); ok {
+		id, err := r.GetIDByReference(key)
+		if err != nil {
+			return nil, err
+		}
+		return r.GetByID(id)
+	}
+
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards c
+		WHERE c.key = ?
+	`
+
+	card, err := scanCard(r.db.QueryRow(query, key))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	return card, nil
+}
+
+// GetIDByReference returns the ID of the card a reference such as OPS-214
+// names; one that is not a reference is "card not found"
+func (r *CardRepository) GetIDByReference(reference string) (int, error) {
+	prefix, number, ok := models.ParseCardReference(reference)
+	if !ok {
+		return 0, fmt.Errorf("card not found")
+	}
+
+	var id int
+	err := r.db.QueryRow(`
+		SELECT c.id
+		FROM cards c
+		JOIN lists l ON c.list_id = l.id
+		JOIN boards b ON l.board_id = b.id
+		WHERE b.key_prefix = ? AND c.number = ?
+	`, prefix, number).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("card not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetByNumber retrieves a card by its number on a board
+func (r *CardRepository) GetByNumber(boardID, number int) (*models.Card, error) {
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards c
+		JOIN lists l ON c.list_id = l.id
+		WHERE l.board_id = ? AND c.number = ?
+	`
+
+	card, err := scanCard(r.db.QueryRow(query, boardID, number))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	return card, nil
+}
+
 // GetByID retrieves a card by ID
 func (r *CardRepository) GetByID(id int) (*models.Card, error) {
-	card := &models.Card{}
 	query := `
-		SELECT id, list_id, title, description, position, color, due_date, archived, created_at, updated_at
-		FROM cards
-		WHERE id = ?
+		SELECT ` + cardColumns + `
+		FROM cards c
+		WHERE c.id = ?
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&card.ID, &card.ListID, &card.Title, &card.Description,
-		&card.Position, &card.Color, &card.DueDate, &card.Archived,
-		&card.CreatedAt, &card.UpdatedAt,
-	)
+	card, err := scanCard(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("card not found")
 	}
@@ -77,19 +523,75 @@ func (r *CardRepository) GetByID(id int) (*models.Card, error) {
 	return card, nil
 }
 
-// GetByListID retrieves all cards for a list
-func (r *CardRepository) GetByListID(listID int, includeArchived bool) ([]models.Card, error) {
+// GetByListID retrieves a list's cards, filtered and ordered by opts
+func (r *CardRepository) GetByListID(listID int, opts models.CardListOptions) ([]models.Card, error) {
 	query := `
-		SELECT id, list_id, title, description, position, color, due_date, archived, created_at, updated_at
-		FROM cards
-		WHERE list_id = ?
+		SELECT ` + cardColumns + `
+		FROM cards c
+		WHERE c.list_id = ?
 	`
 	args := []interface{}{listID}
 
+	if !opts.IncludeArchived {
+		query += " AND c.archived = 0"
+	}
+	if opts.SprintID != nil {
+		if *opts.SprintID == 0 {
+			query += " AND c.sprint_id IS NULL"
+		} else {
+			query += " AND c.sprint_id = ?"
+			args = append(args, *opts.SprintID)
+		}
+	}
+	if opts.UpdatedAfter != nil {
+		query += " AND datetime(c.updated_at) >= datetime(?)"
+		args = append(args, opts.UpdatedAfter.UTC().Format("2006-01-02 15:04:05"))
+	}
+	visible, visibleArgs := visibleTo(opts.ViewerID)
+	query += " AND " + visible
+	args = append(args, visibleArgs...)
+	query += " ORDER BY " + cardOrderBy(opts.Sort)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []models.Card
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		cards = append(cards, *card)
+	}
+
+	// Ensure we never return nil, always return empty array
+	if cards == nil {
+		cards = []models.Card{}
+	}
+
+	return cards, nil
+}
+
+// GetByBoardID retrieves all cards on a board that viewer may see, ordered by
+// list then position
+func (r *CardRepository) GetByBoardID(boardID int, includeArchived bool, viewer *int) ([]models.Card, error) {
+	query := `
+		SELECT ` + cardColumns + `
+		FROM cards c
+		JOIN lists l ON c.list_id = l.id
+		WHERE l.board_id = ?
+	`
+	args := []interface{}{boardID}
+
 	if !includeArchived {
-		query += " AND archived = 0"
+		query += " AND c.archived = 0"
 	}
-	query += " ORDER BY position"
+	visible, visibleArgs := visibleTo(viewer)
+	query += " AND " + visible + " ORDER BY l.position, c.position"
+	args = append(args, visibleArgs...)
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -97,105 +599,919 @@ func (r *CardRepository) GetByListID(listID int, includeArchived bool) ([]models
 	}
 	defer rows.Close()
 
-	var cards []models.Card
-	for rows.Next() {
-		var card models.Card
-		err := rows.Scan(
-			&card.ID, &card.ListID, &card.Title, &card.Description,
-			&card.Position, &card.Color, &card.DueDate, &card.Archived,
-			&card.CreatedAt, &card.UpdatedAt,
-		)
+	var cards []models.Card
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		cards = append(cards, *card)
+	}
+
+	// Ensure we never return nil, always return empty array
+	if cards == nil {
+		cards = []models.Card{}
+	}
+
+	return cards, nil
+}
+
+// GetStale retrieves a board's open cards, of those viewer may see, that have
+// not been updated or moved since before, least recently touched first
+func (r *CardRepository) GetStale(boardID int, before time.Time, viewer *int) ([]models.Card, error) {
+	cutoff := before.UTC().Format("2006-01-02 15:04:05")
+	visible, visibleArgs := visibleTo(viewer)
+	rows, err := r.db.Query(`
+		SELECT `+cardColumns+`
+		FROM cards c
+		JOIN lists l ON c.list_id = l.id
+		WHERE l.board_id = ? AND c.archived = 0 AND COALESCE(c.completed, 0) = 0
+		  AND datetime(c.updated_at) < datetime(?)
+		  AND datetime(COALESCE(c.list_entered_at, c.created_at)) < datetime(?)
+		  AND `+visible+`
+		ORDER BY datetime(c.updated_at) ASC, c.id ASC
+	`, append([]interface{}{boardID, cutoff, cutoff}, visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale cards: %w", err)
+	}
+	defer rows.Close()
+
+	cards := []models.Card{}
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		cards = append(cards, *card)
+	}
+
+	return cards, rows.Err()
+}
+
+// LoadSummaries fills in labels and comment counts for cards with one query each
+func (r *CardRepository) LoadSummaries(cards []models.Card) error {
+	if err := r.LoadLabels(cards); err != nil {
+		return err
+	}
+	return r.LoadCommentCounts(cards)
+}
+
+// LoadLabels fills in the labels of cards with one query
+func (r *CardRepository) LoadLabels(cards []models.Card) error {
+	if len(cards) == 0 {
+		return nil
+	}
+
+	index, in, args := cardIndex(cards)
+	rows, err := r.db.Query(`
+		SELECT cl.card_id, l.id, l.name, l.color, l.created_at
+		FROM card_labels cl
+		INNER JOIN labels l ON l.id = cl.label_id
+		WHERE cl.card_id IN (`+in+`)
+		ORDER BY l.name ASC`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get card labels: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cardID int
+		var label models.Label
+		if err := rows.Scan(&cardID, &label.ID, &label.Name, &label.Color, &label.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan label: %w", err)
+		}
+		i := index[cardID]
+		cards[i].Labels = append(cards[i].Labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating labels: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCommentCounts fills in the comment counts of cards with one query
+func (r *CardRepository) LoadCommentCounts(cards []models.Card) error {
+	if len(cards) == 0 {
+		return nil
+	}
+
+	index, in, args := cardIndex(cards)
+	for i := range cards {
+		count := 0
+		cards[i].CommentCount = &count
+	}
+
+	countRows, err := r.db.Query(`
+		SELECT card_id, COUNT(*)
+		FROM comments
+		WHERE card_id IN (`+in+`)
+		GROUP BY card_id`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to count comments: %w", err)
+	}
+	defer countRows.Close()
+
+	for countRows.Next() {
+		var cardID, count int
+		if err := countRows.Scan(&cardID, &count); err != nil {
+			return fmt.Errorf("failed to scan comment count: %w", err)
+		}
+		*cards[index[cardID]].CommentCount = count
+	}
+
+	return countRows.Err()
+}
+
+// LoadComments fills in the comments of cards, newest first, with one query
+func (r *CardRepository) LoadComments(cards []models.Card) error {
+	if len(cards) == 0 {
+		return nil
+	}
+
+	index, in, args := cardIndex(cards)
+	rows, err := r.db.Query(`
+		SELECT `+commentColumns+`
+		FROM comments cm
+		LEFT JOIN users u ON cm.author_id = u.id
+		WHERE cm.card_id IN (`+in+`)
+		ORDER BY cm.created_at DESC`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan comment: %w", err)
+		}
+		i := index[comment.CardID]
+		cards[i].Comments = append(cards[i].Comments, *comment)
+	}
+
+	return rows.Err()
+}
+
+// cardIndex maps card IDs to their position in cards and builds the IN list
+// and arguments selecting them
+func cardIndex(cards []models.Card) (map[int]int, string, []interface{}) {
+	index := make(map[int]int, len(cards))
+	placeholders := make([]string, len(cards))
+	args := make([]interface{}, len(cards))
+	for i := range cards {
+		index[cards[i].ID] = i
+		placeholders[i] = "?"
+		args[i] = cards[i].ID
+	}
+	return index, strings.Join(placeholders, ", "), args
+}
+
+// Update updates a card
+func (r *CardRepository) Update(card *models.Card) error {
+	return r.UpdateRevision(card, 0)
+}
+
+// UpdateRevision updates a card only if it is still at revision, or at any
+// revision when that is 0. The revision is checked by the UPDATE itself, so
+// a change committed since the card was read fails with "card has changed".
+func (r *CardRepository) UpdateRevision(card *models.Card, revision int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE cards
+		SET title = ?, description = ?, color = ?, start_date = ?, due_date = ?, priority = ?, assignee_id = ?, sprint_id = ?, milestone_id = ?, points = ?, visibility = ?, updated_at = ?
+		WHERE id = ? AND (? = 0 OR revision = ?)
+	`
+
+	card.UpdatedAt = time.Now()
+	result, err := tx.Exec(
+		query, card.Title, card.Description, card.Color, card.StartDate, card.DueDate,
+		nullString(card.Priority), card.AssigneeID, card.SprintID, card.MilestoneID, card.Points, card.Visibility, card.UpdatedAt, card.ID,
+		revision, revision,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update card: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM cards WHERE id = ?)`, card.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check card: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("card has changed")
+		}
+		return fmt.Errorf("card not found")
+	}
+
+	// A new due date or priority can move the card in a sorted list, the
+	// one it is in now rather than when it was read
+	if err := tx.QueryRow(`SELECT list_id FROM cards WHERE id = ?`, card.ID).Scan(&card.ListID); err != nil {
+		return fmt.Errorf("failed to get card list: %w", err)
+	}
+	if err := sortListCards(tx, card.ListID); err != nil {
+		return err
+	}
+
+	// Triggers raise the revision when a field changed and store updated_at
+	// to the second; read both back, and the position a sort may have
+	// changed, so clients can send them with later updates
+	err = tx.QueryRow(`SELECT revision, updated_at, position FROM cards WHERE id = ?`, card.ID).Scan(&card.Revision, &card.UpdatedAt, &card.Position)
+	if err != nil {
+		return fmt.Errorf("failed to get card revision: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update card: %w", err)
+	}
+	return nil
+}
+
+// Move moves a card to a different list and/or position. A card moving to
+// another board leaves its sprint and milestone, which belong to the old board.
+func (r *CardRepository) Move(cardID int, newListID int, newPosition float64, opts models.CardMoveOptions) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentListID int
+	err = tx.QueryRow("SELECT list_id FROM cards WHERE id = ?", cardID).Scan(&currentListID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("card not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move card: %w", err)
+	}
+
+	// A card moving to another board takes the next number there
+	var sameBoard bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM cards c
+			JOIN lists cur ON cur.id = c.list_id
+			JOIN lists dst ON dst.id = ?
+			WHERE c.id = ? AND cur.board_id = dst.board_id
+		)
+	`, newListID, cardID).Scan(&sameBoard)
+	if err != nil {
+		return fmt.Errorf("failed to move card: %w", err)
+	}
+
+	// Reordering within a list keeps the time the card entered it
+	now := time.Now()
+	enteredAt := "CASE WHEN list_id = ? THEN list_entered_at ELSE ? END"
+
+	var result sql.Result
+	if sameBoard {
+		result, err = tx.Exec(`
+			UPDATE cards
+			SET list_entered_at = `+enteredAt+`, list_id = ?, position = ?, updated_at = ?
+			WHERE id = ?
+		`, newListID, now, newListID, newPosition, now, cardID)
+	} else {
+		var number int
+		var numErr error
+		if opts.KeepNumber {
+			number, numErr = keepCardNumber(tx, cardID, newListID)
+		} else {
+			number, numErr = nextCardNumber(tx, newListID)
+		}
+		if numErr != nil {
+			return numErr
+		}
+		result, err = tx.Exec(`
+			UPDATE cards
+			SET list_entered_at = `+enteredAt+`, list_id = ?, position = ?, number = ?, sprint_id = NULL, milestone_id = NULL, updated_at = ?
+			WHERE id = ?
+		`, newListID, now, newListID, newPosition, number, now, cardID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to move card: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("card not found")
+	}
+
+	if err := remapCardLabels(tx, cardID, opts); err != nil {
+		return err
+	}
+
+	if currentListID != newListID {
+		if err := recordMove(tx, cardID, &currentListID, newListID, opts.UserID, opts.Reason, now); err != nil {
+			return err
+		}
+	}
+
+	if err := sortListCards(tx, newListID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to move card: %w", err)
+	}
+
+	return nil
+}
+
+// autoMoveCandidates selects the cards board automation should move: open
+// cards past their due date and completed cards outside the target list. A
+// card taken out of the target list by hand after it became overdue or was
+// completed is left where it was put.
+const autoMoveCandidates = `
+	SELECT c.id, l.board_id, c.list_id, t.id, '` + models.AutoMoveOverdue + `'
+	FROM cards c
+	JOIN lists l ON l.id = c.list_id
+	JOIN boards b ON b.id = l.board_id
+	JOIN lists t ON t.id = json_extract(b.settings, '$.overdue_list_id') AND t.board_id = b.id
+	WHERE c.archived = 0 AND COALESCE(c.completed, 0) = 0 AND c.list_id != t.id
+		AND datetime(c.due_date) < datetime(?)
+		AND NOT EXISTS (
+			SELECT 1 FROM card_moves m
+			WHERE m.card_id = c.id AND m.from_list_id = t.id AND datetime(m.moved_at) >= datetime(c.due_date)
+		)
+	UNION ALL
+	SELECT c.id, l.board_id, c.list_id, t.id, '` + models.AutoMoveCompleted + `'
+	FROM cards c
+	JOIN lists l ON l.id = c.list_id
+	JOIN boards b ON b.id = l.board_id
+	JOIN lists t ON t.id = json_extract(b.settings, '$.completed_list_id') AND t.board_id = b.id
+	WHERE c.archived = 0 AND c.completed = 1 AND c.list_id != t.id
+		AND NOT EXISTS (
+			SELECT 1 FROM card_moves m
+			WHERE m.card_id = c.id AND m.from_list_id = t.id AND datetime(m.moved_at) >= datetime(COALESCE(c.completed_at, c.created_at))
+		)
+	ORDER BY 1
+`
+
+// RunAutoMoves moves every card matched by its board's automation settings
+// to the end of the target list, recording each move in the card's history
+func (r *CardRepository) RunAutoMoves(now time.Time) ([]models.AutoMove, error) {
+	rows, err := r.db.Query(autoMoveCandidates, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cards to move: %w", err)
+	}
+
+	var candidates []models.AutoMove
+	for rows.Next() {
+		var move models.AutoMove
+		if err := rows.Scan(&move.CardID, &move.BoardID, &move.FromListID, &move.ToListID, &move.Reason); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan card to move: %w", err)
+		}
+		candidates = append(candidates, move)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find cards to move: %w", err)
+	}
+
+	moves := []models.AutoMove{}
+	for _, move := range candidates {
+		var maxPosition sql.NullFloat64
+		err := r.db.QueryRow("SELECT MAX(position) FROM cards WHERE list_id = ?", move.ToListID).Scan(&maxPosition)
+		if err != nil {
+			return moves, fmt.Errorf("failed to get max position: %w", err)
+		}
+		if err := r.Move(move.CardID, move.ToListID, maxPosition.Float64+1.0, models.CardMoveOptions{Reason: move.Reason}); err != nil {
+			return moves, err
+		}
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
+
+// overdueCandidates selects the open cards past their due date on boards
+// with overdue escalation, skipping those already escalated since their
+// current due date was set to pass. A label that no longer exists is
+// ignored.
+const overdueCandidates = `
+	SELECT c.id, l.board_id, c.assignee_id, lb.id,
+		COALESCE(json_extract(b.settings, '$.overdue_color'), ''),
+		COALESCE(json_extract(b.settings, '$.overdue_notify'), 0)
+	FROM cards c
+	JOIN lists l ON l.id = c.list_id
+	JOIN boards b ON b.id = l.board_id
+	LEFT JOIN labels lb ON lb.id = json_extract(b.settings, '$.overdue_label_id')
+	WHERE ` + openCard + ` AND datetime(c.due_date) < datetime(?)
+		AND (c.overdue_escalated_at IS NULL OR datetime(c.overdue_escalated_at) < datetime(c.due_date))
+		AND (lb.id IS NOT NULL
+			OR COALESCE(json_extract(b.settings, '$.overdue_color'), '') != ''
+			OR COALESCE(json_extract(b.settings, '$.overdue_notify'), 0) = 1)
+	ORDER BY c.id
+`
+
+// RunOverdueEscalations applies each overdue card's board escalation: its
+// overdue label and color, and whether its assignee is to be notified,
+// which is left to the caller. Each card is escalated in its own
+// transaction.
+func (r *CardRepository) RunOverdueEscalations(now time.Time) ([]models.OverdueEscalation, error) {
+	rows, err := r.db.Query(overdueCandidates, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find overdue cards: %w", err)
+	}
+
+	type candidate struct {
+		escalation models.OverdueEscalation
+		labelID    sql.NullInt64
+		color      string
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var cand candidate
+		e := &cand.escalation
+		if err := rows.Scan(&e.CardID, &e.BoardID, &e.AssigneeID, &cand.labelID, &cand.color, &e.Notify); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan overdue card: %w", err)
+		}
+		candidates = append(candidates, cand)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find overdue cards: %w", err)
+	}
+
+	escalations := []models.OverdueEscalation{}
+	for _, cand := range candidates {
+		escalation := cand.escalation
+		changed, err := r.escalateOverdue(escalation.CardID, cand.labelID, cand.color, now)
+		if err != nil {
+			return escalations, err
+		}
+		escalation.Changed = changed
+		escalation.Notify = escalation.Notify && escalation.AssigneeID != nil
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, nil
+}
+
+// escalateOverdue labels and colors one overdue card and records that it
+// was escalated, reporting whether the card changed
+func (r *CardRepository) escalateOverdue(cardID int, labelID sql.NullInt64, color string, now time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	changed := false
+	if labelID.Valid {
+		result, err := tx.Exec(`
+			INSERT INTO card_labels (card_id, label_id)
+			SELECT ?, ? WHERE NOT EXISTS (SELECT 1 FROM card_labels WHERE card_id = ? AND label_id = ?)
+		`, cardID, labelID.Int64, cardID, labelID.Int64)
+		if err != nil {
+			return false, fmt.Errorf("failed to label overdue card: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n > 0 {
+			changed = true
+		}
+	}
+
+	if color != "" {
+		result, err := tx.Exec(
+			"UPDATE cards SET color = ?, updated_at = ? WHERE id = ? AND COALESCE(color, '') != ?",
+			color, now, cardID, color,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to color overdue card: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n > 0 {
+			changed = true
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE cards SET overdue_escalated_at = ? WHERE id = ?", now, cardID); err != nil {
+		return false, fmt.Errorf("failed to record overdue escalation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to escalate overdue card: %w", err)
+	}
+
+	return changed, nil
+}
+
+// RunSLAChecks flags the open cards that have stayed in a list longer than
+// its SLA, once for each time a card enters the list, and returns them.
+// Lists with an SLA that does not parse are skipped.
+func (r *CardRepository) RunSLAChecks(now time.Time) ([]models.SLABreach, error) {
+	rows, err := r.db.Query("SELECT id, json_extract(settings, '$.sla') FROM lists WHERE COALESCE(json_extract(settings, '$.sla'), '') != '' ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list SLAs: %w", err)
+	}
+
+	slas := make(map[int]time.Duration)
+	var listIDs []int
+	for rows.Next() {
+		var listID int
+		var sla string
+		if err := rows.Scan(&listID, &sla); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan list SLA: %w", err)
+		}
+		if d, err := models.ParseRelativeDuration(sla); err == nil {
+			slas[listID] = d
+			listIDs = append(listIDs, listID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get list SLAs: %w", err)
+	}
+
+	query := `
+		UPDATE cards
+		SET sla_breached_at = ?
+		WHERE list_id = ? AND archived = 0 AND COALESCE(completed, 0) = 0
+			AND datetime(COALESCE(list_entered_at, created_at)) < datetime(?)
+			AND (sla_breached_at IS NULL OR datetime(sla_breached_at) < datetime(COALESCE(list_entered_at, created_at)))
+		RETURNING id, list_entered_at, created_at
+	`
+
+	breaches := []models.SLABreach{}
+	for _, listID := range listIDs {
+		rows, err := r.db.Query(query, now, listID, now.Add(-slas[listID]))
+		if err != nil {
+			return breaches, fmt.Errorf("failed to flag SLA breaches: %w", err)
+		}
+		for rows.Next() {
+			breach := models.SLABreach{ListID: listID}
+			var enteredAt *time.Time
+			if err := rows.Scan(&breach.CardID, &enteredAt, &breach.EnteredAt); err != nil {
+				rows.Close()
+				return breaches, fmt.Errorf("failed to scan SLA breach: %w", err)
+			}
+			if enteredAt != nil {
+				breach.EnteredAt = *enteredAt
+			}
+			breaches = append(breaches, breach)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return breaches, fmt.Errorf("failed to flag SLA breaches: %w", err)
+		}
+	}
+
+	return breaches, nil
+}
+
+// Archive archives or unarchives a card
+func (r *CardRepository) Archive(id int, archive bool) error {
+	query := `
+		UPDATE cards
+		SET archived = ?, archived_at = CASE WHEN ? THEN COALESCE(archived_at, ?) END, updated_at = ?
+		WHERE id = ?
+	`
+
+	now := time.Now()
+	result, err := r.db.Exec(query, archive, archive, now, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to archive card: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("card not found")
+	}
+
+	return nil
+}
+
+// ArchiveByList archives a list's unarchived cards matching opts in one
+// transaction and returns the IDs of the cards archived
+func (r *CardRepository) ArchiveByList(listID int, opts models.ArchiveCardsOptions) ([]int, error) {
+	now := time.Now()
+	visible, visibleArgs := visibleTo(opts.ViewerID)
+	query := "UPDATE cards AS c SET archived = 1, archived_at = ?, updated_at = ? WHERE list_id = ? AND archived = 0 AND " + visible
+	args := append([]interface{}{now, now, listID}, visibleArgs...)
+
+	if opts.Completed != nil {
+		query += " AND COALESCE(completed, 0) = ?"
+		args = append(args, *opts.Completed)
+	}
+	if opts.UpdatedBefore != nil {
+		query += " AND datetime(updated_at) < datetime(?)"
+		args = append(args, *opts.UpdatedBefore)
+	}
+	query += " RETURNING id"
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive cards: %w", err)
+	}
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan archived card: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to archive cards: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// Complete marks a card completed or not; completed_at keeps the first
+// completion time. Completing a card unblocks the cards it was the last open
+// blocker of, whose IDs are returned.
+func (r *CardRepository) Complete(id int, completed bool) ([]int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE cards
+		SET completed = ?,
+		    completed_at = CASE WHEN ? THEN COALESCE(completed_at, ?) ELSE NULL END,
+		    updated_at = ?
+		WHERE id = ?
+	`
+
+	now := time.Now()
+	result, err := tx.Exec(query, completed, completed, now, now, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete card: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("card not found")
+	}
+
+	unblocked := []int{}
+	if completed {
+		rows, err := tx.Query(`
+			SELECT b.card_id
+			FROM card_blockers b
+			JOIN cards c ON c.id = b.card_id
+			WHERE b.blocker_id = ? AND c.blocked = 1
+			  AND NOT EXISTS (
+				SELECT 1 FROM card_blockers o
+				JOIN cards x ON x.id = o.blocker_id
+				WHERE o.card_id = b.card_id AND COALESCE(x.completed, 0) = 0
+			  )
+			ORDER BY b.card_id
+		`, id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan card: %w", err)
+			return nil, fmt.Errorf("failed to find blocked cards: %w", err)
+		}
+		for rows.Next() {
+			var cardID int
+			if err := rows.Scan(&cardID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan blocked card: %w", err)
+			}
+			unblocked = append(unblocked, cardID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to find blocked cards: %w", err)
+		}
+
+		for _, cardID := range unblocked {
+			if err := clearBlocked(tx, cardID, now); err != nil {
+				return nil, err
+			}
 		}
-		cards = append(cards, card)
 	}
 
-	// Ensure we never return nil, always return empty array
-	if cards == nil {
-		cards = []models.Card{}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return cards, nil
+	return unblocked, nil
 }
 
-// Update updates a card
-func (r *CardRepository) Update(card *models.Card) error {
-	query := `
+// Block flags a card blocked with a reason and replaces the cards that block
+// it. Blocking an already blocked card keeps when it was first blocked.
+func (r *CardRepository) Block(cardID int, reason string, blockerIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
 		UPDATE cards
-		SET title = ?, description = ?, color = ?, due_date = ?, updated_at = ?
+		SET blocked = 1, blocked_reason = ?, blocked_at = COALESCE(blocked_at, ?), updated_at = ?
 		WHERE id = ?
-	`
-
-	card.UpdatedAt = time.Now()
-	result, err := r.db.Exec(
-		query, card.Title, card.Description, card.Color,
-		card.DueDate, card.UpdatedAt, card.ID,
-	)
+	`, nullString(reason), now, now, cardID)
 	if err != nil {
-		return fmt.Errorf("failed to update card: %w", err)
+		return fmt.Errorf("failed to block card: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("card not found")
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if _, err := tx.Exec("DELETE FROM card_blockers WHERE card_id = ?", cardID); err != nil {
+		return fmt.Errorf("failed to clear card blockers: %w", err)
+	}
+	for _, blockerID := range blockerIDs {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO card_blockers (card_id, blocker_id) VALUES (?, ?)", cardID, blockerID); err != nil {
+			return fmt.Errorf("failed to add card blocker: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Unblock clears a card's blocked flag, reason and blockers
+func (r *CardRepository) Unblock(cardID int) error {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM cards WHERE id = ?)", cardID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to unblock card: %w", err)
+	}
+	if !exists {
 		return fmt.Errorf("card not found")
 	}
 
+	if err := clearBlocked(tx, cardID, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
-// Move moves a card to a different list and/or position
-func (r *CardRepository) Move(cardID int, newListID int, newPosition float64) error {
-	query := `
+// clearBlocked unblocks a card and forgets its blockers
+func clearBlocked(tx *sql.Tx, cardID int, now time.Time) error {
+	_, err := tx.Exec(`
 		UPDATE cards
-		SET list_id = ?, position = ?, updated_at = ?
-		WHERE id = ?
-	`
+		SET blocked = 0, blocked_reason = NULL, blocked_at = NULL, updated_at = ?
+		WHERE id = ? AND blocked = 1
+	`, now, cardID)
+	if err != nil {
+		return fmt.Errorf("failed to unblock card: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM card_blockers WHERE card_id = ?", cardID); err != nil {
+		return fmt.Errorf("failed to clear card blockers: %w", err)
+	}
+	return nil
+}
 
-	result, err := r.db.Exec(query, newListID, newPosition, time.Now(), cardID)
+// GetOpenBlockers returns the IDs of the uncompleted cards blocking a card
+func (r *CardRepository) GetOpenBlockers(cardID int) ([]int, error) {
+	rows, err := r.db.Query(`
+		SELECT b.blocker_id
+		FROM card_blockers b
+		JOIN cards c ON c.id = b.blocker_id
+		WHERE b.card_id = ? AND COALESCE(c.completed, 0) = 0
+		ORDER BY b.blocker_id
+	`, cardID)
 	if err != nil {
-		return fmt.Errorf("failed to move card: %w", err)
+		return nil, fmt.Errorf("failed to get card blockers: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan card blocker: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Snooze moves a card's due date to until and records the postponement
+func (r *CardRepository) Snooze(cardID int, until time.Time, userID *int) (*models.CardSnooze, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("card not found")
+	snooze := &models.CardSnooze{
+		CardID:    cardID,
+		ToDueDate: until,
+		UserID:    userID,
+		SnoozedAt: time.Now(),
 	}
 
-	return nil
-}
+	var listID int
+	err = tx.QueryRow("SELECT due_date, list_id FROM cards WHERE id = ?", cardID).Scan(&snooze.FromDueDate, &listID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snooze card: %w", err)
+	}
 
-// Archive archives or unarchives a card
-func (r *CardRepository) Archive(id int, archive bool) error {
-	query := `
+	_, err = tx.Exec(`
 		UPDATE cards
-		SET archived = ?, updated_at = ?
+		SET due_date = ?, snooze_count = COALESCE(snooze_count, 0) + 1, updated_at = ?
 		WHERE id = ?
-	`
+	`, until, snooze.SnoozedAt, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snooze card: %w", err)
+	}
 
-	result, err := r.db.Exec(query, archive, time.Now(), id)
+	err = tx.QueryRow(`
+		INSERT INTO card_snoozes (card_id, from_due_date, to_due_date, user_id, snoozed_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`, cardID, snooze.FromDueDate, snooze.ToDueDate, userID, snooze.SnoozedAt).Scan(&snooze.ID)
 	if err != nil {
-		return fmt.Errorf("failed to archive card: %w", err)
+		return nil, fmt.Errorf("failed to record snooze: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if err := sortListCards(tx, listID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to snooze card: %w", err)
+	}
+
+	return snooze, nil
+}
+
+// GetSnoozes returns a card's snooze history, oldest first
+func (r *CardRepository) GetSnoozes(cardID int) ([]models.CardSnooze, error) {
+	rows, err := r.db.Query(`
+		SELECT id, card_id, from_due_date, to_due_date, user_id, snoozed_at
+		FROM card_snoozes
+		WHERE card_id = ?
+		ORDER BY datetime(snoozed_at), id
+	`, cardID)
 	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
+		return nil, fmt.Errorf("failed to get snoozes: %w", err)
 	}
+	defer rows.Close()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("card not found")
+	snoozes := []models.CardSnooze{}
+	for rows.Next() {
+		var snooze models.CardSnooze
+		err := rows.Scan(&snooze.ID, &snooze.CardID, &snooze.FromDueDate, &snooze.ToDueDate, &snooze.UserID, &snooze.SnoozedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan snooze: %w", err)
+		}
+		snoozes = append(snoozes, snooze)
 	}
 
-	return nil
+	return snoozes, nil
 }
 
 // Delete deletes a card
@@ -219,27 +1535,142 @@ func (r *CardRepository) Delete(id int) error {
 	return nil
 }
 
+// maxFuzzyCandidates is how many of the newest cards that may match a fuzzy
+// search are scored
+const maxFuzzyCandidates = 1000
+
 // Search searches for cards based on criteria
 func (r *CardRepository) Search(params models.SearchCardsRequest) ([]models.Card, error) {
-	var conditions []string
-	var args []interface{}
+	query, args := searchQuery(params, "DISTINCT "+cardColumns)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []models.Card
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		cards = append(cards, *card)
+	}
+
+	// Ensure we never return nil, always return empty array
+	if cards == nil {
+		cards = []models.Card{}
+	}
+
+	if params.Fuzzy && params.Query != "" {
+		cards = rankFuzzy(cards, params.Query)
+	}
+
+	return cards, nil
+}
+
+// rankFuzzy keeps the cards matching a fuzzy search, best first, with
+// their scores; cards scoring the same keep their order
+func rankFuzzy(cards []models.Card, query string) []models.Card {
+	matched := cards[:0]
+	for _, card := range cards {
+		card.Score = fuzzy.Score(query, card.Title, card.Description)
+		if card.Score >= fuzzy.MinScore {
+			matched = append(matched, card)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Score > matched[j].Score
+	})
+	return matched
+}
 
+// searchQuery selects columns of the cards matching a search, newest first.
+// For a fuzzy search these are the candidates to score, at most
+// maxFuzzyCandidates of them.
+func searchQuery(params models.SearchCardsRequest, columns string) (string, []interface{}) {
+	where, args := searchConditions(params)
 	query := `
-		SELECT DISTINCT c.id, c.list_id, c.title, c.description, c.position,
-		       c.color, c.due_date, c.archived, c.created_at, c.updated_at
+		SELECT ` + columns + `
 		FROM cards c
 		LEFT JOIN lists l ON c.list_id = l.id
 		LEFT JOIN boards b ON l.board_id = b.id
 		LEFT JOIN card_labels cl ON c.id = cl.card_id
-		WHERE 1=1
+		WHERE ` + where + `
+		ORDER BY c.created_at DESC
 	`
+	if params.Fuzzy && params.Query != "" {
+		query += fmt.Sprintf(" LIMIT %d", maxFuzzyCandidates)
+	}
+	return query, args
+}
+
+// CountSearch counts the cards Search would return
+func (r *CardRepository) CountSearch(params models.SearchCardsRequest) (int, error) {
+	// Fuzzy matches are only known once cards are scored, so score the same
+	// candidates Search does, reading only what scoring needs
+	if params.Fuzzy && params.Query != "" {
+		query, args := searchQuery(params, "DISTINCT c.id, c.title, COALESCE(c.description, '')")
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count cards: %w", err)
+		}
+		defer rows.Close()
+
+		count := 0
+		for rows.Next() {
+			var id int
+			var title, description string
+			if err := rows.Scan(&id, &title, &description); err != nil {
+				return 0, fmt.Errorf("failed to scan card: %w", err)
+			}
+			if fuzzy.Score(params.Query, title, description) >= fuzzy.MinScore {
+				count++
+			}
+		}
+		return count, rows.Err()
+	}
+
+	where, args := searchConditions(params)
+	query := `
+		SELECT COUNT(DISTINCT c.id)
+		FROM cards c
+		LEFT JOIN lists l ON c.list_id = l.id
+		LEFT JOIN boards b ON l.board_id = b.id
+		LEFT JOIN card_labels cl ON c.id = cl.card_id
+		WHERE ` + where
 
-	// Add search conditions
-	if params.Query != "" {
+	var count int
+	if err := r.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cards: %w", err)
+	}
+
+	return count, nil
+}
+
+// searchConditions builds the WHERE clause, and its arguments, selecting the
+// cards that match a search; queries join lists l, boards b and card_labels cl
+func searchConditions(params models.SearchCardsRequest) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	// Add search conditions; fuzzy searches only narrow the cards down to
+	// those that can match, and score them afterwards
+	if params.Query != "" && !params.Fuzzy {
 		conditions = append(conditions, "(c.title LIKE ? OR c.description LIKE ?)")
 		searchTerm := "%" + params.Query + "%"
 		args = append(args, searchTerm, searchTerm)
 	}
+	if params.Query != "" && params.Fuzzy {
+		for _, parts := range fuzzy.Prefilter(params.Query) {
+			likes := make([]string, len(parts))
+			for i, part := range parts {
+				likes[i] = "c.title LIKE ? OR c.description LIKE ?"
+				args = append(args, "%"+part+"%", "%"+part+"%")
+			}
+			conditions = append(conditions, "("+strings.Join(likes, " OR ")+")")
+		}
+	}
 
 	if params.BoardID != 0 {
 		conditions = append(conditions, "b.id = ?")
@@ -261,39 +1692,84 @@ func (r *CardRepository) Search(params models.SearchCardsRequest) ([]models.Card
 		args = append(args, params.LabelID)
 	}
 
-	// Add conditions to query
-	if len(conditions) > 0 {
-		query += " AND " + strings.Join(conditions, " AND ")
+	if params.SprintID != nil {
+		if *params.SprintID == 0 {
+			conditions = append(conditions, "c.sprint_id IS NULL")
+		} else {
+			conditions = append(conditions, "c.sprint_id = ?")
+			args = append(args, *params.SprintID)
+		}
 	}
 
-	query += " ORDER BY c.created_at DESC"
+	if params.MilestoneID != nil {
+		if *params.MilestoneID == 0 {
+			conditions = append(conditions, "c.milestone_id IS NULL")
+		} else {
+			conditions = append(conditions, "c.milestone_id = ?")
+			args = append(args, *params.MilestoneID)
+		}
+	}
 
-	rows, err := r.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search cards: %w", err)
+	if params.Completed != nil {
+		conditions = append(conditions, "COALESCE(c.completed, 0) = ?")
+		args = append(args, *params.Completed)
 	}
-	defer rows.Close()
 
-	var cards []models.Card
-	for rows.Next() {
-		var card models.Card
-		err := rows.Scan(
-			&card.ID, &card.ListID, &card.Title, &card.Description,
-			&card.Position, &card.Color, &card.DueDate, &card.Archived,
-			&card.CreatedAt, &card.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan card: %w", err)
+	if params.Blocked != nil {
+		conditions = append(conditions, "c.blocked = ?")
+		args = append(args, *params.Blocked)
+	}
+
+	// datetime() normalizes stored offsets to UTC before comparing
+	dateFilters := []struct {
+		column string
+		op     string
+		value  *time.Time
+	}{
+		{"c.start_date", "<", params.StartBefore},
+		{"c.start_date", ">=", params.StartAfter},
+		{"c.due_date", "<", params.DueBefore},
+		{"c.due_date", ">=", params.DueAfter},
+		{"c.created_at", "<", params.CreatedBefore},
+		{"c.created_at", ">=", params.CreatedAfter},
+		{"c.updated_at", ">=", params.UpdatedAfter},
+		{"c.completed_at", "<", params.CompletedBefore},
+		{"c.completed_at", ">=", params.CompletedAfter},
+	}
+	for _, f := range dateFilters {
+		if f.value != nil {
+			conditions = append(conditions, fmt.Sprintf("datetime(%s) %s datetime(?)", f.column, f.op))
+			args = append(args, f.value.UTC().Format("2006-01-02 15:04:05"))
 		}
-		cards = append(cards, card)
 	}
 
-	// Ensure we never return nil, always return empty array
-	if cards == nil {
-		cards = []models.Card{}
+	if params.HasDueDate != nil {
+		if *params.HasDueDate {
+			conditions = append(conditions, "c.due_date IS NOT NULL")
+		} else {
+			conditions = append(conditions, "c.due_date IS NULL")
+		}
 	}
 
-	return cards, nil
+	// In progress at some point during the day starting at ActiveOn
+	if params.ActiveOn != nil {
+		dayStart := params.ActiveOn.UTC()
+		dayEnd := dayStart.Add(24 * time.Hour)
+		conditions = append(conditions,
+			"c.start_date IS NOT NULL AND datetime(c.start_date) < datetime(?) AND (c.due_date IS NULL OR datetime(c.due_date) >= datetime(?))")
+		args = append(args, dayEnd.Format("2006-01-02 15:04:05"), dayStart.Format("2006-01-02 15:04:05"))
+	}
+
+	if params.MinSnoozes > 0 {
+		conditions = append(conditions, "c.snooze_count >= ?")
+		args = append(args, params.MinSnoozes)
+	}
+
+	visible, visibleArgs := readableBy(params.ViewerID)
+	conditions = append(conditions, visible)
+	args = append(args, visibleArgs...)
+
+	return strings.Join(conditions, " AND "), args
 }
 
 // GetAdjacentPositions finds positions for drag-drop reordering
@@ -331,13 +1807,13 @@ func (r *CardRepository) GetAdjacentPositions(listID int, targetPosition float64
 // AddComment adds a comment to a card
 func (r *CardRepository) AddComment(comment *models.Comment) error {
 	query := `
-		INSERT INTO comments (card_id, content, created_at)
-		VALUES (?, ?, ?)
+		INSERT INTO comments (card_id, content, author_id, created_at)
+		VALUES (?, ?, ?, ?)
 		RETURNING id
 	`
 	comment.CreatedAt = time.Now()
 
-	err := r.db.QueryRow(query, comment.CardID, comment.Content, comment.CreatedAt).Scan(&comment.ID)
+	err := r.db.QueryRow(query, comment.CardID, comment.Content, comment.AuthorID, comment.CreatedAt).Scan(&comment.ID)
 	if err != nil {
 		return fmt.Errorf("failed to add comment: %w", err)
 	}
@@ -345,30 +1821,214 @@ func (r *CardRepository) AddComment(comment *models.Comment) error {
 	return nil
 }
 
-// GetComments retrieves all comments for a card
-func (r *CardRepository) GetComments(cardID int) ([]models.Comment, error) {
+// commentColumns selects a comment joined with its author
+const commentColumns = `
+	cm.id, cm.card_id, cm.content, cm.author_id, cm.created_at, cm.edited_at,
+	u.id, u.username, COALESCE(u.display_name, ''), COALESCE(u.avatar_url, ''), u.created_at
+`
+
+// GetComments retrieves the comments for a card, optionally only those by one
+// author or those created or edited at or after updatedAfter
+func (r *CardRepository) GetComments(cardID int, authorID int, updatedAfter *time.Time) ([]models.Comment, error) {
 	query := `
-		SELECT id, card_id, content, created_at
-		FROM comments
-		WHERE card_id = ?
-		ORDER BY created_at DESC
+		SELECT ` + commentColumns + `
+		FROM comments cm
+		LEFT JOIN users u ON cm.author_id = u.id
+		WHERE cm.card_id = ?
 	`
+	args := []interface{}{cardID}
+
+	if authorID != 0 {
+		query += " AND cm.author_id = ?"
+		args = append(args, authorID)
+	}
+	// A comment is updated when it is edited
+	if updatedAfter != nil {
+		query += " AND datetime(COALESCE(cm.edited_at, cm.created_at)) >= datetime(?)"
+		args = append(args, updatedAfter.UTC().Format("2006-01-02 15:04:05"))
+	}
+	query += " ORDER BY cm.created_at DESC"
 
-	rows, err := r.db.Query(query, cardID)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments: %w", err)
 	}
 	defer rows.Close()
 
-	var comments []models.Comment
+	comments := []models.Comment{}
 	for rows.Next() {
-		var comment models.Comment
-		err := rows.Scan(&comment.ID, &comment.CardID, &comment.Content, &comment.CreatedAt)
+		comment, err := scanComment(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan comment: %w", err)
 		}
-		comments = append(comments, comment)
+		comments = append(comments, *comment)
 	}
 
 	return comments, nil
-}
\ No newline at end of file
+}
+
+// GetComment retrieves a single comment by ID
+func (r *CardRepository) GetComment(id int) (*models.Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments cm
+		LEFT JOIN users u ON cm.author_id = u.id
+		WHERE cm.id = ?
+	`
+
+	comment, err := scanComment(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("comment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// UpdateComment changes the content of a comment and marks it as edited
+func (r *CardRepository) UpdateComment(comment *models.Comment) error {
+	query := `
+		UPDATE comments
+		SET content = ?, edited_at = ?
+		WHERE id = ?
+	`
+
+	now := time.Now()
+	result, err := r.db.Exec(query, comment.Content, now, comment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("comment not found")
+	}
+
+	comment.EditedAt = &now
+	return nil
+}
+
+// DeleteComment deletes a comment
+func (r *CardRepository) DeleteComment(id int) error {
+	result, err := r.db.Exec(`DELETE FROM comments WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("comment not found")
+	}
+
+	return nil
+}
+
+// cardOrderBy builds an ORDER BY clause for sort. Cards without a due date
+// always sort last, and position breaks ties.
+func cardOrderBy(sort models.CardSort) string {
+	column, ok := cardSortColumns[sort.Field]
+	if !ok {
+		column = cardSortColumns["position"]
+	}
+	direction := " ASC"
+	if sort.Desc {
+		direction = " DESC"
+	}
+
+	order := column + direction
+	if sort.Field == "due_date" {
+		order = "c.due_date IS NULL, " + order
+	}
+	if column != cardSortColumns["position"] {
+		order += ", c.position ASC"
+	}
+	return order
+}
+
+// scanCard scans a row selected with cardColumns
+func scanCard(row rowScanner) (*models.Card, error) {
+	var card models.Card
+	if err := row.Scan(cardFields(&card)...); err != nil {
+		return nil, err
+	}
+	setCardAge(&card, time.Now())
+	return &card, nil
+}
+
+// cardFields returns scan destinations matching cardColumns
+func cardFields(card *models.Card) []interface{} {
+	return []interface{}{
+		&card.ID, &card.ListID, &card.Title, &card.Description,
+		&card.Position, &card.Color, &card.DueDate, &card.StartDate, &card.Archived,
+		&card.Completed, &card.CompletedAt, &card.Blocked, &card.BlockedReason, &card.BlockedAt, &card.Priority, &card.AssigneeID, &card.Number, &card.Key, &card.SprintID, &card.MilestoneID, &card.Points, &card.SnoozeCount, &card.Visibility, &card.CreatedBy, &card.ListEnteredAt, &card.ArchivedAt, &card.CreatedAt, &card.UpdatedAt, &card.Revision,
+		&card.SLABreached, &card.Reference,
+	}
+}
+
+// setCardAge fills in the whole days since the card last changed and since
+// it entered its list
+func setCardAge(card *models.Card, now time.Time) {
+	card.AgeDays = wholeDays(now.Sub(card.UpdatedAt))
+	entered := card.CreatedAt
+	if card.ListEnteredAt != nil {
+		entered = *card.ListEnteredAt
+	}
+	card.DaysInList = wholeDays(now.Sub(entered))
+}
+
+func wholeDays(d time.Duration) int {
+	if d < 0 {
+		return 0
+	}
+	return int(d / (24 * time.Hour))
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanComment scans a row selected with commentColumns
+func scanComment(row rowScanner) (*models.Comment, error) {
+	var comment models.Comment
+	var userID sql.NullInt64
+	var username, displayName, avatarURL sql.NullString
+	var userCreatedAt sql.NullTime
+	err := row.Scan(
+		&comment.ID, &comment.CardID, &comment.Content, &comment.AuthorID, &comment.CreatedAt, &comment.EditedAt,
+		&userID, &username, &displayName, &avatarURL, &userCreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID.Valid {
+		comment.Author = &models.User{
+			ID:          int(userID.Int64),
+			Username:    username.String,
+			DisplayName: displayName.String,
+			AvatarURL:   avatarURL.String,
+			CreatedAt:   userCreatedAt.Time,
+		}
+	}
+
+	return &comment, nil
+}
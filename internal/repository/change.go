@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// ChangeRepository reads and prunes the change log, which database triggers
+// fill as boards, lists, cards, comments and labels change
+type ChangeRepository struct {
+	db *sql.DB
+}
+
+// NewChangeRepository creates a new change repository
+func NewChangeRepository(db *sql.DB) *ChangeRepository {
+	return &ChangeRepository{db: db}
+}
+
+// hiddenFrom limits a query on changes aliased ch to those viewer may see:
+// changes on boards boardVisibleTo does not show viewer are left out, as
+// are changes to restricted cards, and to their comments, for anyone
+// visibleTo does not show the card to. Changes to cards that are gone carry
+// no more than their IDs and are kept.
+func hiddenFrom(viewer *int) (string, []interface{}) {
+	board, args := boardVisibleTo("ch.board_id", viewer)
+	visible, visibleArgs := visibleTo(viewer)
+	return `(ch.board_id IS NULL OR ` + board + `) AND NOT EXISTS (
+		SELECT 1 FROM cards c
+		WHERE c.id = CASE ch.entity
+			WHEN 'card' THEN ch.entity_id
+			WHEN 'comment' THEN (SELECT card_id FROM comments WHERE id = ch.entity_id)
+		END
+		AND NOT ` + visible + `)`, append(args, visibleArgs...)
+}
+
+// Since retrieves up to limit changes with a sequence number above since, in
+// order, optionally only those on one board, that viewer may see. The second
+// result reports whether more follow.
+func (r *ChangeRepository) Since(since int, boardID *int, viewer *int, limit int) ([]models.Change, bool, error) {
+	hidden, hiddenArgs := hiddenFrom(viewer)
+	query := `SELECT ch.seq, ch.entity, ch.entity_id, ch.operation, ch.board_id, ch.changed_at FROM changes ch WHERE ch.seq > ? AND ` + hidden
+	args := append([]interface{}{since}, hiddenArgs...)
+	if boardID != nil {
+		query += " AND ch.board_id = ?"
+		args = append(args, *boardID)
+	}
+	query += " ORDER BY ch.seq LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.Change{}
+	for rows.Next() {
+		var change models.Change
+		var board sql.NullInt64
+		if err := rows.Scan(&change.Seq, &change.Entity, &change.EntityID, &change.Operation, &board, &change.ChangedAt); err != nil {
+			return nil, false, fmt.Errorf("failed to scan change: %w", err)
+		}
+		if board.Valid {
+			id := int(board.Int64)
+			change.BoardID = &id
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	if len(changes) > limit {
+		return changes[:limit], true, nil
+	}
+	return changes, false, nil
+}
+
+// Count counts the changes Since would list without a limit
+func (r *ChangeRepository) Count(since int, boardID *int, viewer *int) (int, error) {
+	hidden, hiddenArgs := hiddenFrom(viewer)
+	args := append([]interface{}{since, boardID, boardID}, hiddenArgs...)
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM changes ch WHERE ch.seq > ? AND (? IS NULL OR ch.board_id = ?) AND `+hidden, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count changes: %w", err)
+	}
+	return count, nil
+}
+
+// Latest returns the sequence number of the most recent change, or 0 if
+// nothing has changed yet. It survives pruning.
+func (r *ChangeRepository) Latest() (int, error) {
+	var latest int
+	err := r.db.QueryRow(`SELECT COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'changes'), 0)`).Scan(&latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest change: %w", err)
+	}
+	return latest, nil
+}
+
+// Pruned reports whether changes after since have been pruned, so reading
+// on from since would silently skip them
+func (r *ChangeRepository) Pruned(since int) (bool, error) {
+	var oldest sql.NullInt64
+	if err := r.db.QueryRow(`SELECT MIN(seq) FROM changes`).Scan(&oldest); err != nil {
+		return false, fmt.Errorf("failed to get oldest change: %w", err)
+	}
+	if oldest.Valid {
+		return int(oldest.Int64) > since+1, nil
+	}
+
+	// Everything may have been pruned
+	latest, err := r.Latest()
+	if err != nil {
+		return false, err
+	}
+	return latest > since, nil
+}
+
+// Prune deletes changes recorded before the given time, returning how many
+// were removed
+func (r *ChangeRepository) Prune(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM changes WHERE changed_at < ?`, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune changes: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// ConsistencyRepository finds and repairs rows that foreign keys should have
+// prevented. Older databases were written with foreign key enforcement on
+// only some pooled connections, so cascades did not always run.
+type ConsistencyRepository struct {
+	db *sql.DB
+}
+
+// NewConsistencyRepository creates a new consistency repository
+func NewConsistencyRepository(db *sql.DB) *ConsistencyRepository {
+	return &ConsistencyRepository{db: db}
+}
+
+// consistencyCheck counts violating rows with count and fixes them with repair.
+// Checks run in order, so rows orphaned by an earlier repair are picked up by
+// a later check in the same run.
+type consistencyCheck struct {
+	name        string
+	description string
+	count       string
+	repair      string
+}
+
+// duplicatePositions renumbers the rows of table sharing a position within
+// their parent, keeping the current order with ID as the tie-breaker
+func duplicatePositions(name, description, table, parent string) consistencyCheck {
+	duplicated := fmt.Sprintf(`
+		SELECT %[2]s FROM %[1]s GROUP BY %[2]s, position HAVING COUNT(*) > 1
+	`, table, parent)
+	return consistencyCheck{
+		name:        name,
+		description: description,
+		count: fmt.Sprintf(`
+			SELECT COUNT(*) FROM %[1]s t
+			WHERE EXISTS (
+				SELECT 1 FROM %[1]s o
+				WHERE o.%[2]s = t.%[2]s AND o.position = t.position AND o.id != t.id
+			)
+		`, table, parent),
+		repair: fmt.Sprintf(`
+			UPDATE %[1]s SET position = n.num
+			FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY %[2]s ORDER BY position, id) AS num
+				FROM %[1]s
+				WHERE %[2]s IN (%[3]s)
+			) n
+			WHERE n.id = %[1]s.id AND %[1]s.position != n.num
+		`, table, parent, duplicated),
+	}
+}
+
+var consistencyChecks = []consistencyCheck{
+	{
+		name:        "orphaned_lists",
+		description: "Lists on boards that no longer exist",
+		count:       `SELECT COUNT(*) FROM lists WHERE board_id NOT IN (SELECT id FROM boards)`,
+		repair:      `DELETE FROM lists WHERE board_id NOT IN (SELECT id FROM boards)`,
+	},
+	{
+		name:        "orphaned_cards",
+		description: "Cards in lists that no longer exist",
+		count:       `SELECT COUNT(*) FROM cards WHERE list_id NOT IN (SELECT id FROM lists)`,
+		repair:      `DELETE FROM cards WHERE list_id NOT IN (SELECT id FROM lists)`,
+	},
+	{
+		name:        "orphaned_comments",
+		description: "Comments on cards that no longer exist",
+		count:       `SELECT COUNT(*) FROM comments WHERE card_id NOT IN (SELECT id FROM cards)`,
+		repair:      `DELETE FROM comments WHERE card_id NOT IN (SELECT id FROM cards)`,
+	},
+	{
+		name:        "orphaned_card_labels",
+		description: "Label assignments for cards or labels that no longer exist",
+		count: `
+			SELECT COUNT(*) FROM card_labels
+			WHERE card_id NOT IN (SELECT id FROM cards) OR label_id NOT IN (SELECT id FROM labels)
+		`,
+		repair: `
+			DELETE FROM card_labels
+			WHERE card_id NOT IN (SELECT id FROM cards) OR label_id NOT IN (SELECT id FROM labels)
+		`,
+	},
+	{
+		name:        "orphaned_card_moves",
+		description: "Move history for cards that no longer exist",
+		count:       `SELECT COUNT(*) FROM card_moves WHERE card_id NOT IN (SELECT id FROM cards)`,
+		repair:      `DELETE FROM card_moves WHERE card_id NOT IN (SELECT id FROM cards)`,
+	},
+	{
+		name:        "orphaned_card_snoozes",
+		description: "Snooze history for cards that no longer exist",
+		count:       `SELECT COUNT(*) FROM card_snoozes WHERE card_id NOT IN (SELECT id FROM cards)`,
+		repair:      `DELETE FROM card_snoozes WHERE card_id NOT IN (SELECT id FROM cards)`,
+	},
+	{
+		name:        "orphaned_mentions",
+		description: "Mentions of missing users, or on missing cards or comments",
+		count: `
+			SELECT COUNT(*) FROM mentions
+			WHERE user_id NOT IN (SELECT id FROM users)
+			   OR card_id NOT IN (SELECT id FROM cards)
+			   OR (comment_id IS NOT NULL AND comment_id NOT IN (SELECT id FROM comments))
+		`,
+		repair: `
+			DELETE FROM mentions
+			WHERE user_id NOT IN (SELECT id FROM users)
+			   OR card_id NOT IN (SELECT id FROM cards)
+			   OR (comment_id IS NOT NULL AND comment_id NOT IN (SELECT id FROM comments))
+		`,
+	},
+	{
+		name:        "orphaned_card_references",
+		description: "Card references from or to missing cards, or in missing comments",
+		count: `
+			SELECT COUNT(*) FROM card_references
+			WHERE card_id NOT IN (SELECT id FROM cards)
+			   OR target_id NOT IN (SELECT id FROM cards)
+			   OR (comment_id IS NOT NULL AND comment_id NOT IN (SELECT id FROM comments))
+		`,
+		repair: `
+			DELETE FROM card_references
+			WHERE card_id NOT IN (SELECT id FROM cards)
+			   OR target_id NOT IN (SELECT id FROM cards)
+			   OR (comment_id IS NOT NULL AND comment_id NOT IN (SELECT id FROM comments))
+		`,
+	},
+	{
+		name:        "orphaned_notifications",
+		description: "Notifications for missing users, or about missing cards or comments",
+		count: `
+			SELECT COUNT(*) FROM notifications
+			WHERE user_id NOT IN (SELECT id FROM users)
+			   OR (card_id IS NOT NULL AND card_id NOT IN (SELECT id FROM cards))
+			   OR (comment_id IS NOT NULL AND comment_id NOT IN (SELECT id FROM comments))
+		`,
+		repair: `
+			DELETE FROM notifications
+			WHERE user_id NOT IN (SELECT id FROM users)
+			   OR (card_id IS NOT NULL AND card_id NOT IN (SELECT id FROM cards))
+			   OR (comment_id IS NOT NULL AND comment_id NOT IN (SELECT id FROM comments))
+		`,
+	},
+	{
+		name:        "orphaned_sprints",
+		description: "Sprints on boards that no longer exist",
+		count:       `SELECT COUNT(*) FROM sprints WHERE board_id NOT IN (SELECT id FROM boards)`,
+		repair:      `DELETE FROM sprints WHERE board_id NOT IN (SELECT id FROM boards)`,
+	},
+	{
+		name:        "orphaned_milestones",
+		description: "Milestones on boards that no longer exist",
+		count:       `SELECT COUNT(*) FROM milestones WHERE board_id NOT IN (SELECT id FROM boards)`,
+		repair:      `DELETE FROM milestones WHERE board_id NOT IN (SELECT id FROM boards)`,
+	},
+	{
+		name:        "orphaned_card_counters",
+		description: "Card number counters for boards that no longer exist",
+		count:       `SELECT COUNT(*) FROM board_card_counters WHERE board_id NOT IN (SELECT id FROM boards)`,
+		repair:      `DELETE FROM board_card_counters WHERE board_id NOT IN (SELECT id FROM boards)`,
+	},
+	{
+		name:        "dangling_card_sprints",
+		description: "Cards in a sprint that is missing or on another board",
+		count: `
+			SELECT COUNT(*) FROM cards c
+			JOIN lists l ON l.id = c.list_id
+			WHERE c.sprint_id IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM sprints s WHERE s.id = c.sprint_id AND s.board_id = l.board_id)
+		`,
+		repair: `
+			UPDATE cards SET sprint_id = NULL
+			WHERE id IN (
+				SELECT c.id FROM cards c
+				JOIN lists l ON l.id = c.list_id
+				WHERE c.sprint_id IS NOT NULL
+				  AND NOT EXISTS (SELECT 1 FROM sprints s WHERE s.id = c.sprint_id AND s.board_id = l.board_id)
+			)
+		`,
+	},
+	{
+		name:        "dangling_card_milestones",
+		description: "Cards in a milestone that is missing or on another board",
+		count: `
+			SELECT COUNT(*) FROM cards c
+			JOIN lists l ON l.id = c.list_id
+			WHERE c.milestone_id IS NOT NULL
+			  AND NOT EXISTS (SELECT 1 FROM milestones m WHERE m.id = c.milestone_id AND m.board_id = l.board_id)
+		`,
+		repair: `
+			UPDATE cards SET milestone_id = NULL
+			WHERE id IN (
+				SELECT c.id FROM cards c
+				JOIN lists l ON l.id = c.list_id
+				WHERE c.milestone_id IS NOT NULL
+				  AND NOT EXISTS (SELECT 1 FROM milestones m WHERE m.id = c.milestone_id AND m.board_id = l.board_id)
+			)
+		`,
+	},
+	{
+		name:        "dangling_card_assignees",
+		description: "Cards assigned to users that no longer exist",
+		count:       `SELECT COUNT(*) FROM cards WHERE assignee_id IS NOT NULL AND assignee_id NOT IN (SELECT id FROM users)`,
+		repair:      `UPDATE cards SET assignee_id = NULL WHERE assignee_id IS NOT NULL AND assignee_id NOT IN (SELECT id FROM users)`,
+	},
+	{
+		name:        "dangling_comment_authors",
+		description: "Comments by users that no longer exist",
+		count:       `SELECT COUNT(*) FROM comments WHERE author_id IS NOT NULL AND author_id NOT IN (SELECT id FROM users)`,
+		repair:      `UPDATE comments SET author_id = NULL WHERE author_id IS NOT NULL AND author_id NOT IN (SELECT id FROM users)`,
+	},
+	duplicatePositions("duplicate_list_positions", "Lists sharing a position on the same board", "lists", "board_id"),
+	duplicatePositions("duplicate_card_positions", "Cards sharing a position in the same list", "cards", "list_id"),
+}
+
+// Check runs every consistency check. With repair set, violations are fixed
+// in a single transaction: orphaned rows are deleted, dangling references are
+// cleared and duplicate positions are renumbered.
+func (r *ConsistencyRepository) Check(repair bool) (*models.ConsistencyReport, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report := &models.ConsistencyReport{
+		CheckedAt: time.Now(),
+		Repair:    repair,
+		Checks:    []models.ConsistencyCheck{},
+	}
+
+	for _, check := range consistencyChecks {
+		result := models.ConsistencyCheck{Name: check.name, Description: check.description}
+		if err := tx.QueryRow(check.count).Scan(&result.Count); err != nil {
+			return nil, fmt.Errorf("failed to run check %s: %w", check.name, err)
+		}
+
+		if repair && result.Count > 0 {
+			res, err := tx.Exec(check.repair)
+			if err != nil {
+				return nil, fmt.Errorf("failed to repair %s: %w", check.name, err)
+			}
+			repaired, err := res.RowsAffected()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			result.Repaired = int(repaired)
+		}
+
+		report.Problems += result.Count
+		report.Checks = append(report.Checks, result)
+	}
+
+	if repair {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+
+	return report, nil
+}
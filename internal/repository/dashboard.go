@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// DashboardRepository aggregates cards and counts across all boards
+type DashboardRepository struct {
+	db *sql.DB
+}
+
+// NewDashboardRepository creates a new dashboard repository
+func NewDashboardRepository(db *sql.DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+// openCard matches cards that are neither archived nor completed
+const openCard = "c.archived = 0 AND COALESCE(c.completed, 0) = 0"
+
+// Get builds the dashboard for the windows in opts
+func (r *DashboardRepository) Get(opts models.DashboardOptions) (*models.Dashboard, error) {
+	now := opts.Now.UTC().Format("2006-01-02 15:04:05")
+	dueBy := opts.DueBy.UTC().Format("2006-01-02 15:04:05")
+	since := opts.UpdatedSince.UTC().Format("2006-01-02 15:04:05")
+
+	dashboard := &models.Dashboard{GeneratedAt: opts.Now}
+	visible, visibleArgs := readableBy(opts.ViewerID)
+	var err error
+
+	dashboard.DueThisWeek, err = dashboardCards(r.db,
+		openCard+" AND datetime(c.due_date) >= datetime(?) AND datetime(c.due_date) < datetime(?) AND "+visible,
+		"datetime(c.due_date) ASC, c.id ASC", opts.Limit, append([]interface{}{now, dueBy}, visibleArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard.Overdue, err = dashboardCards(r.db,
+		openCard+" AND datetime(c.due_date) < datetime(?) AND "+visible,
+		"datetime(c.due_date) ASC, c.id ASC", opts.Limit, append([]interface{}{now}, visibleArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard.RecentlyUpdated, err = dashboardCards(r.db,
+		"c.archived = 0 AND datetime(c.updated_at) >= datetime(?) AND "+visible,
+		"datetime(c.updated_at) DESC, c.id DESC", opts.Limit, append([]interface{}{since}, visibleArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard.Boards, err = r.summaries(now, dueBy, opts.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+// dashboardCards selects up to limit cards aliased c matching where, with
+// their board and list
+func dashboardCards(db *sql.DB, where, order string, limit int, args ...interface{}) ([]models.DashboardCard, error) {
+	query := `
+		SELECT ` + cardColumns + `, b.id, b.name, l.name
+		FROM cards c
+		INNER JOIN lists l ON l.id = c.list_id
+		INNER JOIN boards b ON b.id = l.board_id
+		WHERE ` + where + `
+		ORDER BY ` + order + `
+		LIMIT ?`
+
+	now := time.Now()
+	rows, err := db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard cards: %w", err)
+	}
+	defer rows.Close()
+
+	cards := []models.DashboardCard{}
+	for rows.Next() {
+		var card models.DashboardCard
+		fields := append(cardFields(&card.Card), &card.BoardID, &card.BoardName, &card.ListName)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, fmt.Errorf("failed to scan dashboard card: %w", err)
+		}
+		setCardAge(&card.Card, now)
+		cards = append(cards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dashboard cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+// summaries counts the lists and visible cards of each board viewer may
+// view, in board order
+func (r *DashboardRepository) summaries(now, dueBy string, viewer *int) ([]models.BoardSummary, error) {
+	visible, visibleArgs := visibleTo(viewer)
+	boards, boardArgs := boardVisibleTo("b.id", viewer)
+	args := append(append([]interface{}{now, now, dueBy}, visibleArgs...), boardArgs...)
+	rows, err := r.db.Query(`
+		SELECT b.id, b.name, b.starred,
+			(SELECT COUNT(*) FROM lists WHERE board_id = b.id),
+			COUNT(CASE WHEN `+openCard+` THEN 1 END),
+			COUNT(CASE WHEN c.archived = 0 AND COALESCE(c.completed, 0) = 1 THEN 1 END),
+			COUNT(CASE WHEN `+openCard+` AND datetime(c.due_date) < datetime(?) THEN 1 END),
+			COUNT(CASE WHEN `+openCard+` AND datetime(c.due_date) >= datetime(?) AND datetime(c.due_date) < datetime(?) THEN 1 END)
+		FROM boards b
+		LEFT JOIN lists l ON l.board_id = b.id
+		LEFT JOIN cards c ON c.list_id = l.id AND `+visible+`
+		WHERE `+boards+`
+		GROUP BY b.id
+		ORDER BY b.starred DESC, b.position ASC, b.created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize boards: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []models.BoardSummary{}
+	for rows.Next() {
+		var s models.BoardSummary
+		if err := rows.Scan(&s.BoardID, &s.Name, &s.Starred, &s.Lists, &s.OpenCards, &s.Completed, &s.Overdue, &s.DueThisWeek); err != nil {
+			return nil, fmt.Errorf("failed to scan board summary: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating board summaries: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/metrics"
+	"github.com/kanban-simple/internal/models"
+)
+
+// digestColumns is the column list read by scanDigest
+const digestColumns = `id, user_id, board_id, COALESCE(url, ''), kind, cadence, hour, weekday, stale_days, last_sent_at, created_at, updated_at`
+
+// DigestRepository handles database operations for digests and builds the
+// reports they send
+type DigestRepository struct {
+	db *sql.DB
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(db *sql.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+// Create subscribes to a digest
+func (r *DigestRepository) Create(digest *models.Digest) error {
+	query := `
+		INSERT INTO digests (user_id, board_id, url, kind, cadence, hour, weekday, stale_days, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	now := time.Now()
+	digest.CreatedAt = now
+	digest.UpdatedAt = now
+
+	err := r.db.QueryRow(
+		query, digest.UserID, digest.BoardID, nullString(digest.URL), digest.Kind, digest.Cadence,
+		digest.Hour, digest.Weekday, digest.StaleDays, digest.CreatedAt, digest.UpdatedAt,
+	).Scan(&digest.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create digest: %w", err)
+	}
+
+	digest.ScheduleNext()
+	return nil
+}
+
+// GetByID retrieves a digest by ID
+func (r *DigestRepository) GetByID(id int) (*models.Digest, error) {
+	digest, err := scanDigest(r.db.QueryRow(`SELECT `+digestColumns+` FROM digests WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("digest not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	return digest, nil
+}
+
+// GetAll retrieves every digest, or those of one user or one board, ordered
+// by ID
+func (r *DigestRepository) GetAll(userID, boardID *int) ([]models.Digest, error) {
+	query := `
+		SELECT ` + digestColumns + `
+		FROM digests
+		WHERE (? IS NULL OR user_id = ?) AND (? IS NULL OR board_id = ?)
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(query, userID, userID, boardID, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digests: %w", err)
+	}
+	defer rows.Close()
+
+	digests := []models.Digest{}
+	for rows.Next() {
+		digest, err := scanDigest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan digest: %w", err)
+		}
+		digests = append(digests, *digest)
+	}
+
+	return digests, rows.Err()
+}
+
+// Update saves a digest's recipients, board and schedule
+func (r *DigestRepository) Update(digest *models.Digest) error {
+	query := `
+		UPDATE digests
+		SET user_id = ?, board_id = ?, url = ?, kind = ?, cadence = ?, hour = ?, weekday = ?, stale_days = ?, updated_at = ?
+		WHERE id = ?
+	`
+	digest.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(
+		query, digest.UserID, digest.BoardID, nullString(digest.URL), digest.Kind, digest.Cadence,
+		digest.Hour, digest.Weekday, digest.StaleDays, digest.UpdatedAt, digest.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update digest: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("digest not found")
+	}
+
+	digest.ScheduleNext()
+	return nil
+}
+
+// MarkSent records that a digest's report went out at sentAt
+func (r *DigestRepository) MarkSent(digest *models.Digest, sentAt time.Time) error {
+	result, err := r.db.Exec("UPDATE digests SET last_sent_at = ? WHERE id = ?", sentAt, digest.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark digest sent: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("digest not found")
+	}
+
+	digest.LastSentAt = &sentAt
+	digest.ScheduleNext()
+	return nil
+}
+
+// Delete removes a digest
+func (r *DigestRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM digests WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete digest: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("digest not found")
+	}
+
+	return nil
+}
+
+// Report builds the report a digest sends at now, covering what was created
+// and completed since it last went out, or in the last period when it never
+// has. Restricted cards are left out unless the digest's user may see them.
+func (r *DigestRepository) Report(digest *models.Digest, now time.Time) (*models.DigestReport, error) {
+	since := reportSince(digest, now)
+
+	report := &models.DigestReport{
+		DigestID:    digest.ID,
+		BoardID:     digest.BoardID,
+		Cadence:     digest.Cadence,
+		Since:       since,
+		GeneratedAt: now,
+	}
+
+	format := "2006-01-02 15:04:05"
+	dueBy := now.AddDate(0, 0, 7).UTC().Format(format)
+	from := since.UTC().Format(format)
+	stale := now.AddDate(0, 0, -digest.StaleDays).UTC().Format(format)
+
+	visible, visibleArgs := readableBy(digest.UserID)
+	scope := "(? IS NULL OR b.id = ?) AND " + visible
+	scopeArgs := append([]interface{}{digest.BoardID, digest.BoardID}, visibleArgs...)
+	var err error
+
+	report.DueThisWeek, err = dashboardCards(r.db,
+		openCard+" AND datetime(c.due_date) < datetime(?) AND "+scope,
+		"datetime(c.due_date) ASC, c.id ASC", models.DigestCardLimit, append([]interface{}{dueBy}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Created, err = dashboardCards(r.db,
+		"c.archived = 0 AND datetime(c.created_at) >= datetime(?) AND "+scope,
+		"datetime(c.created_at) DESC, c.id DESC", models.DigestCardLimit, append([]interface{}{from}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Completed, err = dashboardCards(r.db,
+		"COALESCE(c.completed, 0) = 1 AND datetime(c.completed_at) >= datetime(?) AND "+scope,
+		"datetime(c.completed_at) DESC, c.id DESC", models.DigestCardLimit, append([]interface{}{from}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Stale, err = dashboardCards(r.db,
+		openCard+" AND datetime(c.updated_at) < datetime(?) AND datetime(COALESCE(c.list_entered_at, c.created_at)) < datetime(?) AND "+scope,
+		"datetime(c.updated_at) ASC, c.id ASC", models.DigestCardLimit, append([]interface{}{stale, stale}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// StatusReport builds the board report a report digest sends at now,
+// covering the time since it last went out, or the last period when it
+// never has, as the digest's user sees it
+func (r *DigestRepository) StatusReport(digest *models.Digest, now time.Time) (*models.BoardReport, error) {
+	if digest.BoardID == nil {
+		return nil, fmt.Errorf("report digest has no board")
+	}
+	return r.BoardReport(*digest.BoardID, reportSince(digest, now), now, digest.UserID)
+}
+
+// reportSince is the start of the window a digest reports on at now
+func reportSince(digest *models.Digest, now time.Time) time.Time {
+	if digest.LastSentAt != nil {
+		return *digest.LastSentAt
+	}
+	return now.Add(-digest.Period())
+}
+
+// BoardReport builds the status report of a board over [from, to), on the
+// cards viewer may see. Cards count as overdue when they are open and were
+// due before to.
+func (r *DigestRepository) BoardReport(boardID int, from, to time.Time, viewer *int) (*models.BoardReport, error) {
+	report := &models.BoardReport{
+		BoardID:     boardID,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+		TopLabels:   []models.LabelCount{},
+	}
+	err := r.db.QueryRow("SELECT name FROM boards WHERE id = ?", boardID).Scan(&report.BoardName)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("board not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get board: %w", err)
+	}
+
+	// Milliseconds kept, so cards created in the second before to count
+	format := "2006-01-02 15:04:05.000"
+	start := from.UTC().Format(format)
+	end := to.UTC().Format(format)
+
+	visible, visibleArgs := visibleTo(viewer)
+	scope := "b.id = ? AND " + visible
+	scopeArgs := append([]interface{}{boardID}, visibleArgs...)
+
+	created := "julianday(c.created_at) >= julianday(?) AND julianday(c.created_at) < julianday(?) AND " + scope
+	completed := "COALESCE(c.completed, 0) = 1 AND julianday(c.completed_at) >= julianday(?) AND julianday(c.completed_at) < julianday(?) AND " + scope
+	overdue := openCard + " AND julianday(c.due_date) < julianday(?) AND " + scope
+	windowArgs := append([]interface{}{start, end}, scopeArgs...)
+	overdueArgs := append([]interface{}{end}, scopeArgs...)
+
+	report.Created, err = dashboardCards(r.db, created, "datetime(c.created_at) DESC, c.id DESC", models.DigestCardLimit, windowArgs...)
+	if err != nil {
+		return nil, err
+	}
+	report.Completed, err = dashboardCards(r.db, completed, "datetime(c.completed_at) DESC, c.id DESC", models.DigestCardLimit, windowArgs...)
+	if err != nil {
+		return nil, err
+	}
+	report.Overdue, err = dashboardCards(r.db, overdue, "datetime(c.due_date) ASC, c.id ASC", models.DigestCardLimit, overdueArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	count := func(where string, args []interface{}, into ...interface{}) error {
+		return r.db.QueryRow(`
+			SELECT COUNT(*), COALESCE(SUM(c.points), 0)
+			FROM cards c
+			JOIN lists l ON l.id = c.list_id
+			JOIN boards b ON b.id = l.board_id
+			WHERE `+where, args...).Scan(into...)
+	}
+	var ignored int
+	if err := count(created, windowArgs, &report.CreatedCount, &ignored); err != nil {
+		return nil, fmt.Errorf("failed to count created cards: %w", err)
+	}
+	if err := count(completed, windowArgs, &report.CompletedCount, &report.CompletedPoints); err != nil {
+		return nil, fmt.Errorf("failed to count completed cards: %w", err)
+	}
+	if err := count(overdue, overdueArgs, &report.OverdueCount, &ignored); err != nil {
+		return nil, fmt.Errorf("failed to count overdue cards: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT lb.id, lb.name, lb.color, COUNT(*)
+		FROM cards c
+		JOIN lists l ON l.id = c.list_id
+		JOIN boards b ON b.id = l.board_id
+		JOIN card_labels cl ON cl.card_id = c.id
+		JOIN labels lb ON lb.id = cl.label_id
+		WHERE ((`+created+`) OR (`+completed+`))
+		GROUP BY lb.id
+		ORDER BY COUNT(*) DESC, lb.name
+		LIMIT ?
+	`, append(append(windowArgs, windowArgs...), models.ReportLabelLimit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report labels: %w", err)
+	}
+	for rows.Next() {
+		var label models.LabelCount
+		if err := rows.Scan(&label.LabelID, &label.Name, &label.Color, &label.Cards); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan report label: %w", err)
+		}
+		report.TopLabels = append(report.TopLabels, label)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get report labels: %w", err)
+	}
+
+	rows, err = r.db.Query(`
+		SELECT ROUND((julianday(c.completed_at) - julianday(c.created_at)) * 24, 2)
+		FROM cards c
+		JOIN lists l ON l.id = c.list_id
+		JOIN boards b ON b.id = l.board_id
+		WHERE `+completed, windowArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cycle times: %w", err)
+	}
+	defer rows.Close()
+	var hours []float64
+	for rows.Next() {
+		var h float64
+		if err := rows.Scan(&h); err != nil {
+			return nil, fmt.Errorf("failed to scan cycle time: %w", err)
+		}
+		hours = append(hours, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get cycle times: %w", err)
+	}
+	report.CycleTime = metrics.SummarizeHours(hours)
+
+	return report, nil
+}
+
+// scanDigest reads a row selected with digestColumns
+func scanDigest(row rowScanner) (*models.Digest, error) {
+	digest := &models.Digest{}
+	err := row.Scan(
+		&digest.ID, &digest.UserID, &digest.BoardID, &digest.URL, &digest.Kind, &digest.Cadence, &digest.Hour,
+		&digest.Weekday, &digest.StaleDays, &digest.LastSentAt, &digest.CreatedAt, &digest.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	digest.ScheduleNext()
+	return digest, nil
+}
@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// groupColumns selects every group field with its member count; queries
+// alias groups as g
+const groupColumns = `
+	g.id, g.name, COALESCE(g.description, ''),
+	(SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id),
+	g.created_at, g.updated_at
+`
+
+// GroupRepository handles database operations for groups, their members and
+// their grants on boards
+type GroupRepository struct {
+	db *sql.DB
+}
+
+// NewGroupRepository creates a new group repository
+func NewGroupRepository(db *sql.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create creates a new group
+func (r *GroupRepository) Create(group *models.Group) error {
+	query := `
+		INSERT INTO groups (name, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`
+	now := time.Now()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	err := r.db.QueryRow(query, group.Name, group.Description, group.CreatedAt, group.UpdatedAt).Scan(&group.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a group by ID
+func (r *GroupRepository) GetByID(id int) (*models.Group, error) {
+	return r.getOne(`SELECT `+groupColumns+` FROM groups g WHERE g.id = ?`, id)
+}
+
+// GetByName retrieves a group by name, ignoring case
+func (r *GroupRepository) GetByName(name string) (*models.Group, error) {
+	return r.getOne(`SELECT `+groupColumns+` FROM groups g WHERE g.name = ?`, name)
+}
+
+// GetAll retrieves every group ordered by name
+func (r *GroupRepository) GetAll() ([]models.Group, error) {
+	rows, err := r.db.Query(`SELECT ` + groupColumns + ` FROM groups g ORDER BY g.name COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, *group)
+	}
+
+	return groups, rows.Err()
+}
+
+// Update saves a group's name and description
+func (r *GroupRepository) Update(group *models.Group) error {
+	group.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(
+		"UPDATE groups SET name = ?, description = ?, updated_at = ? WHERE id = ?",
+		group.Name, group.Description, group.UpdatedAt, group.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("group not found")
+	}
+
+	return nil
+}
+
+// Delete removes a group, its memberships and its grants
+func (r *GroupRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM groups WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("group not found")
+	}
+
+	return nil
+}
+
+// GetMembers retrieves a group's members ordered by username
+func (r *GroupRepository) GetMembers(groupID int) ([]models.GroupMember, error) {
+	query := `
+		SELECT ` + groupMemberColumns + `
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = ?
+		ORDER BY u.username
+	`
+
+	rows, err := r.db.Query(query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.GroupMember{}
+	for rows.Next() {
+		member, err := scanGroupMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		members = append(members, *member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetMember retrieves one member of a group
+func (r *GroupRepository) GetMember(groupID, userID int) (*models.GroupMember, error) {
+	query := `
+		SELECT ` + groupMemberColumns + `
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = ? AND gm.user_id = ?
+	`
+
+	member, err := scanGroupMember(r.db.QueryRow(query, groupID, userID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group member not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group member: %w", err)
+	}
+
+	return member, nil
+}
+
+// AddMember puts a user in a group. Adding someone who already is in it is
+// "group member already exists".
+func (r *GroupRepository) AddMember(groupID, userID int) (*models.GroupMember, error) {
+	query := `
+		INSERT INTO group_members (group_id, user_id, added_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (group_id, user_id) DO NOTHING
+	`
+	result, err := r.db.Exec(query, groupID, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("group member already exists")
+	}
+
+	return r.GetMember(groupID, userID)
+}
+
+// RemoveMember takes a user out of a group
+func (r *GroupRepository) RemoveMember(groupID, userID int) error {
+	result, err := r.db.Exec("DELETE FROM group_members WHERE group_id = ? AND user_id = ?", groupID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("group member not found")
+	}
+
+	return nil
+}
+
+// GetGrants retrieves the groups a board is shared with, ordered by name
+func (r *GroupRepository) GetGrants(boardID int) ([]models.BoardGroupGrant, error) {
+	query := `
+		SELECT ` + grantColumns + `
+		FROM board_group_grants bg
+		JOIN groups g ON g.id = bg.group_id
+		WHERE bg.board_id = ?
+		ORDER BY g.name COLLATE NOCASE
+	`
+
+	rows, err := r.db.Query(query, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group grants: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []models.BoardGroupGrant{}
+	for rows.Next() {
+		grant, err := scanGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group grant: %w", err)
+		}
+		grants = append(grants, *grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// GetGrant retrieves a group's grant on a board
+func (r *GroupRepository) GetGrant(boardID, groupID int) (*models.BoardGroupGrant, error) {
+	query := `
+		SELECT ` + grantColumns + `
+		FROM board_group_grants bg
+		JOIN groups g ON g.id = bg.group_id
+		WHERE bg.board_id = ? AND bg.group_id = ?
+	`
+
+	grant, err := scanGrant(r.db.QueryRow(query, boardID, groupID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group grant not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group grant: %w", err)
+	}
+
+	return grant, nil
+}
+
+// Grant shares a board with a group. Granting a group that already has a
+// grant is "group grant already exists".
+func (r *GroupRepository) Grant(boardID, groupID int, role string, grantedBy *int) (*models.BoardGroupGrant, error) {
+	query := `
+		INSERT INTO board_group_grants (board_id, group_id, role, granted_by, granted_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (board_id, group_id) DO NOTHING
+	`
+	result, err := r.db.Exec(query, boardID, groupID, role, grantedBy, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant group: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("group grant already exists")
+	}
+
+	return r.GetGrant(boardID, groupID)
+}
+
+// SetGrantRole changes the role a group has on a board
+func (r *GroupRepository) SetGrantRole(boardID, groupID int, role string) (*models.BoardGroupGrant, error) {
+	result, err := r.db.Exec("UPDATE board_group_grants SET role = ? WHERE board_id = ? AND group_id = ?", role, boardID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update group grant: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("group grant not found")
+	}
+
+	return r.GetGrant(boardID, groupID)
+}
+
+// Revoke stops sharing a board with a group
+func (r *GroupRepository) Revoke(boardID, groupID int) error {
+	result, err := r.db.Exec("DELETE FROM board_group_grants WHERE board_id = ? AND group_id = ?", boardID, groupID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke group grant: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("group grant not found")
+	}
+
+	return nil
+}
+
+// getOne runs a single-group query
+func (r *GroupRepository) getOne(query string, arg interface{}) (*models.Group, error) {
+	group, err := scanGroup(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("group not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	return group, nil
+}
+
+// scanGroup reads a row selected with groupColumns
+func scanGroup(row rowScanner) (*models.Group, error) {
+	group := &models.Group{}
+	err := row.Scan(&group.ID, &group.Name, &group.Description, &group.MemberCount, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// groupMemberColumns is the column list read by scanGroupMember
+const groupMemberColumns = `gm.group_id, gm.user_id, u.username, COALESCE(u.display_name, ''), gm.added_at`
+
+// scanGroupMember reads a row selected with groupMemberColumns
+func scanGroupMember(row rowScanner) (*models.GroupMember, error) {
+	member := &models.GroupMember{}
+	err := row.Scan(&member.GroupID, &member.UserID, &member.Username, &member.DisplayName, &member.AddedAt)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// grantColumns is the column list read by scanGrant
+const grantColumns = `bg.board_id, bg.group_id, g.name, bg.role, bg.granted_by, bg.granted_at`
+
+// scanGrant reads a row selected with grantColumns
+func scanGrant(row rowScanner) (*models.BoardGroupGrant, error) {
+	grant := &models.BoardGroupGrant{}
+	err := row.Scan(&grant.BoardID, &grant.GroupID, &grant.GroupName, &grant.Role, &grant.GrantedBy, &grant.GrantedAt)
+	if err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
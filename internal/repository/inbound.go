@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// InboundHookTokenPrefix starts every inbound hook token
+const InboundHookTokenPrefix = "kbh_"
+
+// inboundHookColumns is the column list read by scanInboundHook
+const inboundHookColumns = `id, name, list_id, prefix, title_template, description_template, created_at, last_used_at`
+
+// InboundHookRepository handles database operations for inbound hooks
+type InboundHookRepository struct {
+	db *sql.DB
+}
+
+// NewInboundHookRepository creates a new inbound hook repository
+func NewInboundHookRepository(db *sql.DB) *InboundHookRepository {
+	return &InboundHookRepository{db: db}
+}
+
+// Create creates an inbound hook. The returned hook carries the token,
+// which is not stored and cannot be shown again.
+func (r *InboundHookRepository) Create(hook *models.InboundHook) error {
+	token, err := newInboundHookToken()
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO inbound_hooks (name, list_id, token_hash, prefix, title_template, description_template, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	hook.Prefix = inboundHookDisplayPrefix(token)
+	hook.CreatedAt = time.Now()
+
+	err = r.db.QueryRow(
+		query, hook.Name, hook.ListID, hashToken(token), hook.Prefix,
+		hook.TitleTemplate, hook.DescriptionTemplate, hook.CreatedAt,
+	).Scan(&hook.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create inbound hook: %w", err)
+	}
+
+	hook.Token = token
+	return nil
+}
+
+// GetByID retrieves an inbound hook by ID
+func (r *InboundHookRepository) GetByID(id int) (*models.InboundHook, error) {
+	query := `SELECT ` + inboundHookColumns + ` FROM inbound_hooks WHERE id = ?`
+
+	hook, err := scanInboundHook(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("inbound hook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbound hook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// GetAll retrieves every inbound hook, or only a list's when listID is set
+func (r *InboundHookRepository) GetAll(listID *int) ([]models.InboundHook, error) {
+	query := `SELECT ` + inboundHookColumns + ` FROM inbound_hooks`
+	var args []interface{}
+	if listID != nil {
+		query += " WHERE list_id = ?"
+		args = append(args, *listID)
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbound hooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []models.InboundHook{}
+	for rows.Next() {
+		hook, err := scanInboundHook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inbound hook: %w", err)
+		}
+		hooks = append(hooks, *hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+// Update saves an inbound hook's name, list and templates
+func (r *InboundHookRepository) Update(hook *models.InboundHook) error {
+	query := `
+		UPDATE inbound_hooks
+		SET name = ?, list_id = ?, title_template = ?, description_template = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, hook.Name, hook.ListID, hook.TitleTemplate, hook.DescriptionTemplate, hook.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update inbound hook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("inbound hook not found")
+	}
+
+	return nil
+}
+
+// Rotate replaces an inbound hook's token; the old URL stops working at
+// once. The returned hook carries the new token.
+func (r *InboundHookRepository) Rotate(id int) (*models.InboundHook, error) {
+	token, err := newInboundHookToken()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE inbound_hooks SET token_hash = ?, prefix = ? WHERE id = ?`
+	result, err := r.db.Exec(query, hashToken(token), inboundHookDisplayPrefix(token), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate inbound hook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("inbound hook not found")
+	}
+
+	hook, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	hook.Token = token
+	return hook, nil
+}
+
+// Delete removes an inbound hook; its URL stops working
+func (r *InboundHookRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM inbound_hooks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete inbound hook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("inbound hook not found")
+	}
+
+	return nil
+}
+
+// Authenticate returns the inbound hook matching a token and records that it
+// was used. Unknown tokens are "inbound hook not found".
+func (r *InboundHookRepository) Authenticate(token string) (*models.InboundHook, error) {
+	query := `SELECT ` + inboundHookColumns + ` FROM inbound_hooks WHERE token_hash = ?`
+
+	hook, err := scanInboundHook(r.db.QueryRow(query, hashToken(token)))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("inbound hook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbound hook: %w", err)
+	}
+
+	now := time.Now()
+	if _, err := r.db.Exec(`UPDATE inbound_hooks SET last_used_at = ? WHERE id = ?`, now, hook.ID); err != nil {
+		return nil, fmt.Errorf("failed to update inbound hook: %w", err)
+	}
+	hook.LastUsedAt = &now
+
+	return hook, nil
+}
+
+// newInboundHookToken generates a random inbound hook token
+func newInboundHookToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate inbound hook token: %w", err)
+	}
+	return InboundHookTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// inboundHookDisplayPrefix is the part of a token kept for display
+func inboundHookDisplayPrefix(token string) string {
+	return token[:len(InboundHookTokenPrefix)+6]
+}
+
+// scanInboundHook reads a row selected with inboundHookColumns
+func scanInboundHook(row rowScanner) (*models.InboundHook, error) {
+	hook := &models.InboundHook{}
+	var lastUsedAt sql.NullTime
+	err := row.Scan(
+		&hook.ID, &hook.Name, &hook.ListID, &hook.Prefix, &hook.TitleTemplate,
+		&hook.DescriptionTemplate, &hook.CreatedAt, &lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	hook.LastUsedAt = nullTimePtr(lastUsedAt)
+
+	return hook, nil
+}
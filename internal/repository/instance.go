@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/database"
+	"github.com/kanban-simple/internal/models"
+)
+
+// InstanceRepository reports on the database as a whole
+type InstanceRepository struct {
+	db *sql.DB
+}
+
+// NewInstanceRepository creates a new instance repository
+func NewInstanceRepository(db *sql.DB) *InstanceRepository {
+	return &InstanceRepository{db: db}
+}
+
+// Stats counts what the instance holds and measures its database files.
+// ReadOnly and GeneratedAt are left for the caller.
+func (r *InstanceRepository) Stats() (*models.InstanceStats, error) {
+	stats := &models.InstanceStats{}
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM boards),
+			(SELECT COUNT(*) FROM lists),
+			(SELECT COUNT(*) FROM cards WHERE archived = 0),
+			(SELECT COUNT(*) FROM cards WHERE archived = 1),
+			(SELECT COUNT(*) FROM comments),
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE disabled_at IS NOT NULL),
+			(SELECT COUNT(*) FROM api_tokens WHERE revoked_at IS NULL)
+	`
+	err := r.db.QueryRow(query).Scan(
+		&stats.Boards, &stats.Lists, &stats.Cards, &stats.ArchivedCards, &stats.Comments,
+		&stats.Users, &stats.DisabledUsers, &stats.APITokens,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	// page_count covers in-memory databases too; the files are only
+	// measured when there are any
+	var pageCount, pageSize int64
+	if err := r.db.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return nil, fmt.Errorf("failed to get page count: %w", err)
+	}
+	if err := r.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return nil, fmt.Errorf("failed to get page size: %w", err)
+	}
+	var freelistCount int64
+	if err := r.db.QueryRow("PRAGMA freelist_count").Scan(&freelistCount); err != nil {
+		return nil, fmt.Errorf("failed to get freelist count: %w", err)
+	}
+	stats.PageSize = pageSize
+	stats.PageCount = pageCount
+	stats.FreelistCount = freelistCount
+	stats.FreelistBytes = freelistCount * pageSize
+	stats.DBSizeBytes = pageCount * pageSize
+
+	path, err := r.mainFile()
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if info, err := os.Stat(path); err == nil {
+			stats.DBSizeBytes = info.Size()
+		}
+		stats.WALSizeBytes = walSize(path)
+	}
+
+	return stats, nil
+}
+
+// Checkpoint copies the write-ahead log into the database file in one of
+// models.CheckpointModes; truncate also empties the log file, which SQLite
+// otherwise only ever reuses. Checkpointing a large log can take long, so
+// it is not stopped at the query timeout.
+func (r *InstanceRepository) Checkpoint(mode string) (*models.CheckpointResult, error) {
+	if !models.CheckpointModes[mode] {
+		return nil, fmt.Errorf("invalid checkpoint mode %q", mode)
+	}
+
+	path, err := r.mainFile()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CheckpointResult{Mode: mode, WALSizeBefore: walSize(path)}
+	ctx := database.WithoutTimeout(context.Background())
+	err = r.db.QueryRowContext(ctx, "PRAGMA wal_checkpoint("+strings.ToUpper(mode)+")").
+		Scan(&result.Busy, &result.LogFrames, &result.CheckpointedFrames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkpoint: %w", err)
+	}
+	result.WALSizeAfter = walSize(path)
+	result.CheckpointedAt = time.Now()
+
+	return result, nil
+}
+
+// walSize measures the write-ahead log of the database file at path; zero
+// when there is none
+func walSize(path string) int64 {
+	if path == "" {
+		return 0
+	}
+	info, err := os.Stat(path + "-wal")
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// mainFile returns the path of the main database file, or "" when it has none
+func (r *InstanceRepository) mainFile() (string, error) {
+	rows, err := r.db.Query("PRAGMA database_list")
+	if err != nil {
+		return "", fmt.Errorf("failed to list databases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seq int
+		var name, file string
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return "", fmt.Errorf("failed to scan database: %w", err)
+		}
+		if name == "main" {
+			return file, nil
+		}
+	}
+
+	return "", rows.Err()
+}
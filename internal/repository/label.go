@@ -126,15 +126,8 @@ func (r *LabelRepository) Update(id int, name, color string) (*models.Label, err
 	return &label, nil
 }
 
-// Delete deletes a label
+// Delete deletes a label; its card assignments cascade
 func (r *LabelRepository) Delete(id int) error {
-	// First, remove all associations with cards
-	_, err := r.db.Exec("DELETE FROM card_labels WHERE label_id = ?", id)
-	if err != nil {
-		return fmt.Errorf("failed to remove label associations: %w", err)
-	}
-
-	// Then delete the label
 	result, err := r.db.Exec("DELETE FROM labels WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("failed to delete label: %w", err)
@@ -152,6 +145,23 @@ func (r *LabelRepository) Delete(id int) error {
 	return nil
 }
 
+// GetByIDs retrieves the labels with the given IDs in that order, skipping
+// any that do not exist
+func (r *LabelRepository) GetByIDs(ids []int) ([]models.Label, error) {
+	labels := []models.Label{}
+	for _, id := range ids {
+		label, err := r.GetByID(id)
+		if err != nil {
+			if err.Error() == "label not found" {
+				continue
+			}
+			return nil, err
+		}
+		labels = append(labels, *label)
+	}
+	return labels, nil
+}
+
 // AssignToCard assigns a label to a card
 func (r *LabelRepository) AssignToCard(cardID, labelID int) error {
 	// Check if assignment already exists
@@ -180,6 +190,78 @@ func (r *LabelRepository) AssignToCard(cardID, labelID int) error {
 	return nil
 }
 
+// AssignToCards assigns a label to many cards in a single transaction
+func (r *LabelRepository) AssignToCards(labelID int, cardIDs []int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	assigned := 0
+	for _, cardID := range cardIDs {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM cards WHERE id = ?)", cardID).Scan(&exists); err != nil {
+			return 0, fmt.Errorf("failed to check card: %w", err)
+		}
+		if !exists {
+			return 0, fmt.Errorf("card not found")
+		}
+
+		result, err := tx.Exec(
+			"INSERT OR IGNORE INTO card_labels (card_id, label_id) VALUES (?, ?)",
+			cardID, labelID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to assign label to card: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to check rows affected: %w", err)
+		}
+		assigned += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit label assignments: %w", err)
+	}
+
+	return assigned, nil
+}
+
+// RemoveFromCards removes a label from many cards in a single transaction
+func (r *LabelRepository) RemoveFromCards(labelID int, cardIDs []int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	removed := 0
+	for _, cardID := range cardIDs {
+		result, err := tx.Exec(
+			"DELETE FROM card_labels WHERE card_id = ? AND label_id = ?",
+			cardID, labelID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to remove label from card: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to check rows affected: %w", err)
+		}
+		removed += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit label removals: %w", err)
+	}
+
+	return removed, nil
+}
+
 // RemoveFromCard removes a label from a card
 func (r *LabelRepository) RemoveFromCard(cardID, labelID int) error {
 	result, err := r.db.Exec(
@@ -241,4 +323,4 @@ func (r *LabelRepository) GetCardLabels(cardID int) ([]models.Label, error) {
 	}
 
 	return labels, nil
-}
\ No newline at end of file
+}
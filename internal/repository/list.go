@@ -2,12 +2,17 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/kanban-simple/internal/models"
 )
 
+// listColumns selects every list field, in scanList order
+const listColumns = `id, board_id, name, position, color, settings, created_at, updated_at,
+	(SELECT COUNT(*) FROM cards WHERE cards.list_id = lists.id AND cards.archived = 0)`
+
 // ListRepository handles database operations for lists
 type ListRepository struct {
 	db *sql.DB
@@ -54,17 +59,13 @@ func (r *ListRepository) Create(list *models.List) error {
 
 // GetByID retrieves a list by ID
 func (r *ListRepository) GetByID(id int) (*models.List, error) {
-	list := &models.List{}
 	query := `
-		SELECT id, board_id, name, position, color, created_at, updated_at
+		SELECT ` + listColumns + `
 		FROM lists
 		WHERE id = ?
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&list.ID, &list.BoardID, &list.Name, &list.Position,
-		&list.Color, &list.CreatedAt, &list.UpdatedAt,
-	)
+	list, err := scanList(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("list not found")
 	}
@@ -75,37 +76,48 @@ func (r *ListRepository) GetByID(id int) (*models.List, error) {
 	return list, nil
 }
 
-// GetByBoardID retrieves all lists for a board
-func (r *ListRepository) GetByBoardID(boardID int) ([]models.List, error) {
+// GetByBoardID retrieves all lists for a board, or only those updated at or
+// after updatedAfter
+func (r *ListRepository) GetByBoardID(boardID int, updatedAfter *time.Time) ([]models.List, error) {
 	query := `
-		SELECT id, board_id, name, position, color, created_at, updated_at
+		SELECT ` + listColumns + `
 		FROM lists
 		WHERE board_id = ?
-		ORDER BY position
 	`
+	args := []interface{}{boardID}
+	if updatedAfter != nil {
+		query += " AND datetime(updated_at) >= datetime(?)"
+		args = append(args, updatedAfter.UTC().Format("2006-01-02 15:04:05"))
+	}
+	query += " ORDER BY position"
 
-	rows, err := r.db.Query(query, boardID)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get lists: %w", err)
 	}
 	defer rows.Close()
 
-	var lists []models.List
+	lists := []models.List{}
 	for rows.Next() {
-		var list models.List
-		err := rows.Scan(
-			&list.ID, &list.BoardID, &list.Name, &list.Position,
-			&list.Color, &list.CreatedAt, &list.UpdatedAt,
-		)
+		list, err := scanList(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan list: %w", err)
 		}
-		lists = append(lists, list)
+		lists = append(lists, *list)
 	}
 
 	return lists, nil
 }
 
+// CountByBoard returns the number of lists on a board
+func (r *ListRepository) CountByBoard(boardID int) (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM lists WHERE board_id = ?", boardID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count lists: %w", err)
+	}
+	return count, nil
+}
+
 // Update updates a list
 func (r *ListRepository) Update(list *models.List) error {
 	query := `
@@ -160,19 +172,46 @@ func (r *ListRepository) UpdatePosition(id int, position float64) error {
 	return nil
 }
 
+// UpdateSettings replaces the settings of a list
+func (r *ListRepository) UpdateSettings(id int, settings models.ListSettings) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode list settings: %w", err)
+	}
+
+	query := `
+		UPDATE lists
+		SET settings = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, string(data), time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update list settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("list not found")
+	}
+
+	// A list given a sort is put in order right away
+	return sortListCards(r.db, id)
+}
+
 // GetByBoardAndName retrieves a list by board ID and list name
 func (r *ListRepository) GetByBoardAndName(boardID int, name string) (*models.List, error) {
-	list := &models.List{}
 	query := `
-		SELECT id, board_id, name, position, color, created_at, updated_at
+		SELECT ` + listColumns + `
 		FROM lists
 		WHERE board_id = ? AND name = ?
 	`
 
-	err := r.db.QueryRow(query, boardID, name).Scan(
-		&list.ID, &list.BoardID, &list.Name, &list.Position,
-		&list.Color, &list.CreatedAt, &list.UpdatedAt,
-	)
+	list, err := scanList(r.db.QueryRow(query, boardID, name))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("list not found")
 	}
@@ -183,6 +222,26 @@ func (r *ListRepository) GetByBoardAndName(boardID int, name string) (*models.Li
 	return list, nil
 }
 
+// DeletePreview counts the cards and comments that deleting a list removes with it
+func (r *ListRepository) DeletePreview(id int) (*models.DeletePreview, error) {
+	preview := &models.DeletePreview{Lists: 1}
+	err := r.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM cards WHERE list_id = l.id),
+			(SELECT COUNT(*) FROM comments cm JOIN cards c ON c.id = cm.card_id WHERE c.list_id = l.id)
+		FROM lists l
+		WHERE l.id = ?
+	`, id).Scan(&preview.Cards, &preview.Comments)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("list not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview list delete: %w", err)
+	}
+
+	return preview, nil
+}
+
 // Delete deletes a list
 func (r *ListRepository) Delete(id int) error {
 	query := `DELETE FROM lists WHERE id = ?`
@@ -234,4 +293,26 @@ func (r *ListRepository) GetAdjacentPositions(boardID int, targetPosition float6
 	}
 
 	return prev.Float64, next.Float64, nil
+}
+
+// scanList scans a row selected with listColumns
+func scanList(row rowScanner) (*models.List, error) {
+	var list models.List
+	var settings sql.NullString
+	err := row.Scan(
+		&list.ID, &list.BoardID, &list.Name, &list.Position,
+		&list.Color, &settings, &list.CreatedAt, &list.UpdatedAt, &list.CardCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Valid && settings.String != "" {
+		if err := json.Unmarshal([]byte(settings.String), &list.Settings); err != nil {
+			return nil, fmt.Errorf("failed to decode list settings: %w", err)
+		}
+	}
+	list.OverCapacity = list.Settings.Capacity > 0 && list.CardCount > list.Settings.Capacity
+
+	return &list, nil
 }
\ No newline at end of file
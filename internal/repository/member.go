@@ -0,0 +1,419 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// InvitationTokenPrefix starts every invitation token, so they are not
+// mistaken for API tokens
+const InvitationTokenPrefix = "kbi_"
+
+// MemberRepository handles database operations for board members and
+// invitations
+type MemberRepository struct {
+	db *sql.DB
+}
+
+// NewMemberRepository creates a new member repository
+func NewMemberRepository(db *sql.DB) *MemberRepository {
+	return &MemberRepository{db: db}
+}
+
+// GetByBoardID retrieves a board's members, owners first
+func (r *MemberRepository) GetByBoardID(boardID int) ([]models.BoardMember, error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM board_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.board_id = ?
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, u.username
+	`
+
+	rows, err := r.db.Query(query, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []models.BoardMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, *member)
+	}
+
+	return members, rows.Err()
+}
+
+// Get retrieves one member of a board
+func (r *MemberRepository) Get(boardID, userID int) (*models.BoardMember, error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM board_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.board_id = ? AND m.user_id = ?
+	`
+
+	member, err := scanMember(r.db.QueryRow(query, boardID, userID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("member not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return member, nil
+}
+
+// Add makes a user a member of a board. Adding someone who already is a
+// member is "member already exists".
+func (r *MemberRepository) Add(boardID, userID int, role string, addedBy *int) (*models.BoardMember, error) {
+	query := `
+		INSERT INTO board_members (board_id, user_id, role, added_by, added_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (board_id, user_id) DO NOTHING
+	`
+	result, err := r.db.Exec(query, boardID, userID, role, addedBy, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("member already exists")
+	}
+
+	return r.Get(boardID, userID)
+}
+
+// SetRole changes a member's role
+func (r *MemberRepository) SetRole(boardID, userID int, role string) (*models.BoardMember, error) {
+	result, err := r.db.Exec("UPDATE board_members SET role = ? WHERE board_id = ? AND user_id = ?", role, boardID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("member not found")
+	}
+
+	return r.Get(boardID, userID)
+}
+
+// Remove takes a user off a board
+func (r *MemberRepository) Remove(boardID, userID int) error {
+	result, err := r.db.Exec("DELETE FROM board_members WHERE board_id = ? AND user_id = ?", boardID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("member not found")
+	}
+
+	return nil
+}
+
+// Access resolves who can reach a board and with which role, the strongest
+// of each user's direct membership and the grants to their groups. Owners
+// come first, then editors and viewers, each by username. With userID set
+// only that user is resolved.
+func (r *MemberRepository) Access(boardID int, userID *int) ([]models.BoardAccess, error) {
+	query := `
+		SELECT u.id, u.username, COALESCE(u.display_name, ''), m.role, NULL
+		FROM board_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.board_id = ? AND (? IS NULL OR u.id = ?)
+		UNION ALL
+		SELECT u.id, u.username, COALESCE(u.display_name, ''), bg.role, g.name
+		FROM board_group_grants bg
+		JOIN groups g ON g.id = bg.group_id
+		JOIN group_members gm ON gm.group_id = bg.group_id
+		JOIN users u ON u.id = gm.user_id
+		WHERE bg.board_id = ? AND (? IS NULL OR u.id = ?)
+		ORDER BY 2, 5
+	`
+
+	rows, err := r.db.Query(query, boardID, userID, userID, boardID, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get board access: %w", err)
+	}
+	defer rows.Close()
+
+	access := []models.BoardAccess{}
+	index := make(map[int]int)
+	for rows.Next() {
+		var entry models.BoardAccess
+		var group sql.NullString
+		if err := rows.Scan(&entry.UserID, &entry.Username, &entry.DisplayName, &entry.Role, &group); err != nil {
+			return nil, fmt.Errorf("failed to scan board access: %w", err)
+		}
+
+		i, seen := index[entry.UserID]
+		if !seen {
+			i = len(access)
+			index[entry.UserID] = i
+			entry.Groups = []string{}
+			access = append(access, entry)
+		} else if models.RoleRank(entry.Role) > models.RoleRank(access[i].Role) {
+			access[i].Role = entry.Role
+		}
+		if group.Valid {
+			access[i].Groups = append(access[i].Groups, group.String)
+		} else {
+			access[i].Direct = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get board access: %w", err)
+	}
+
+	sort.SliceStable(access, func(i, j int) bool {
+		return models.RoleRank(access[i].Role) > models.RoleRank(access[j].Role)
+	})
+	return access, nil
+}
+
+// Role resolves a user's effective role on a board, as Access does, and
+// whether the board is restricted to its members: that is the case once
+// someone owns it, directly or through a group. Until then the board is
+// open to everyone, as boards were before they could be shared, and adding
+// editors or viewers alone cannot lock everyone out of it. A nil user has
+// no role.
+func (r *MemberRepository) Role(boardID int, userID *int) (string, bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM board_members WHERE board_id = ? AND role = ?
+		) OR EXISTS (
+			SELECT 1 FROM board_group_grants bg
+			JOIN group_members gm ON gm.group_id = bg.group_id
+			WHERE bg.board_id = ? AND bg.role = ?
+		)
+	`
+
+	var restricted bool
+	err := r.db.QueryRow(query, boardID, models.MemberRoleOwner, boardID, models.MemberRoleOwner).Scan(&restricted)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get board role: %w", err)
+	}
+	if !restricted || userID == nil {
+		return "", restricted, nil
+	}
+
+	access, err := r.Access(boardID, userID)
+	if err != nil {
+		return "", false, err
+	}
+	if len(access) == 0 {
+		return "", true, nil
+	}
+	return access[0].Role, true, nil
+}
+
+// boardVisibleTo limits a query to boards viewer may view, board being the
+// SQL expression for the board's ID: boards not restricted to their members,
+// and restricted ones viewer belongs to, directly or through a group. It is
+// the rule Role applies, for queries spanning boards.
+func boardVisibleTo(board string, viewer *int) (string, []interface{}) {
+	open := `(NOT EXISTS (
+		SELECT 1 FROM board_members bm WHERE bm.board_id = ` + board + ` AND bm.role = ?
+	) AND NOT EXISTS (
+		SELECT 1 FROM board_group_grants bg
+		JOIN group_members gm ON gm.group_id = bg.group_id
+		WHERE bg.board_id = ` + board + ` AND bg.role = ?
+	))`
+	args := []interface{}{models.MemberRoleOwner, models.MemberRoleOwner}
+	if viewer == nil {
+		return open, args
+	}
+
+	member := `EXISTS (
+		SELECT 1 FROM board_members bm WHERE bm.board_id = ` + board + ` AND bm.user_id = ?
+	) OR EXISTS (
+		SELECT 1 FROM board_group_grants bg
+		JOIN group_members gm ON gm.group_id = bg.group_id
+		WHERE bg.board_id = ` + board + ` AND gm.user_id = ?
+	)`
+	return "(" + open + " OR " + member + ")", append(args, *viewer, *viewer)
+}
+
+// BoardOf finds the board an entity belongs to, by the route segment naming
+// its kind: boards, lists, cards, comments, sprints, milestones or snippets
+func (r *MemberRepository) BoardOf(kind string, id int) (int, error) {
+	return boardOf(r.db, kind, id)
+}
+
+// Invite creates an invitation to a board. The returned invitation carries
+// the token, which is not stored and cannot be shown again.
+func (r *MemberRepository) Invite(boardID int, email, role string, expiresAt time.Time, invitedBy *int) (*models.BoardInvitation, error) {
+	token, err := newTokenSecret(InvitationTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.BoardInvitation{
+		BoardID:   boardID,
+		Email:     strings.ToLower(email),
+		Role:      role,
+		Token:     token,
+		InvitedBy: invitedBy,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	query := `
+		INSERT INTO board_invitations (board_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = r.db.QueryRow(
+		query, invitation.BoardID, invitation.Email, invitation.Role, hashToken(token),
+		invitation.InvitedBy, invitation.ExpiresAt, invitation.CreatedAt,
+	).Scan(&invitation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// GetPendingInvitations retrieves a board's invitations that are neither
+// accepted nor expired, newest first
+func (r *MemberRepository) GetPendingInvitations(boardID int) ([]models.BoardInvitation, error) {
+	query := `
+		SELECT id, board_id, email, role, invited_by, expires_at, created_at
+		FROM board_invitations
+		WHERE board_id = ? AND accepted_at IS NULL AND datetime(expires_at) > datetime(?)
+		ORDER BY id DESC
+	`
+
+	rows, err := r.db.Query(query, boardID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []models.BoardInvitation{}
+	for rows.Next() {
+		var invitation models.BoardInvitation
+		err := rows.Scan(
+			&invitation.ID, &invitation.BoardID, &invitation.Email, &invitation.Role,
+			&invitation.InvitedBy, &invitation.ExpiresAt, &invitation.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// RevokeInvitation deletes a board's invitation that has not been accepted
+func (r *MemberRepository) RevokeInvitation(boardID, id int) error {
+	result, err := r.db.Exec("DELETE FROM board_invitations WHERE id = ? AND board_id = ? AND accepted_at IS NULL", id, boardID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("invitation not found")
+	}
+
+	return nil
+}
+
+// Accept uses an invitation token to make a user a member of its board.
+// Unknown tokens are "invitation not found"; used and expired ones are
+// "invitation already accepted" and "invitation expired". A user who is
+// already a member keeps their role.
+func (r *MemberRepository) Accept(token string, userID int) (*models.BoardMember, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id, boardID int
+	var role string
+	var invitedBy *int
+	var expiresAt time.Time
+	var acceptedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT id, board_id, role, invited_by, expires_at, accepted_at
+		FROM board_invitations
+		WHERE token_hash = ?
+	`, hashToken(token)).Scan(&id, &boardID, &role, &invitedBy, &expiresAt, &acceptedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invitation not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if acceptedAt.Valid {
+		return nil, fmt.Errorf("invitation already accepted")
+	}
+	now := time.Now()
+	if !now.Before(expiresAt) {
+		return nil, fmt.Errorf("invitation expired")
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO board_members (board_id, user_id, role, added_by, added_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (board_id, user_id) DO NOTHING
+	`, boardID, userID, role, invitedBy, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE board_invitations SET accepted_at = ?, accepted_by = ? WHERE id = ?", now, userID, id); err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.Get(boardID, userID)
+}
+
+// memberColumns is the column list read by scanMember
+const memberColumns = `m.board_id, m.user_id, u.username, COALESCE(u.display_name, ''), COALESCE(u.avatar_url, ''), m.role, m.added_by, m.added_at`
+
+// scanMember reads a row selected with memberColumns
+func scanMember(row rowScanner) (*models.BoardMember, error) {
+	member := &models.BoardMember{}
+	err := row.Scan(
+		&member.BoardID, &member.UserID, &member.Username, &member.DisplayName,
+		&member.AvatarURL, &member.Role, &member.AddedBy, &member.AddedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
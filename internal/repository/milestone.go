@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// milestoneColumns selects every milestone field with card roll-ups; queries alias milestones as m
+const milestoneColumns = `
+	m.id, m.board_id, m.title, COALESCE(m.description, ''), m.due_date, m.created_at,
+	(SELECT COUNT(*) FROM cards c WHERE c.milestone_id = m.id),
+	(SELECT COUNT(*) FROM cards c WHERE c.milestone_id = m.id AND c.completed = 1)
+`
+
+// MilestoneRepository handles database operations for milestones
+type MilestoneRepository struct {
+	db *sql.DB
+}
+
+// NewMilestoneRepository creates a new milestone repository
+func NewMilestoneRepository(db *sql.DB) *MilestoneRepository {
+	return &MilestoneRepository{db: db}
+}
+
+// Create creates a new milestone
+func (r *MilestoneRepository) Create(milestone *models.Milestone) error {
+	query := `
+		INSERT INTO milestones (board_id, title, description, due_date, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	milestone.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		query, milestone.BoardID, milestone.Title, milestone.Description,
+		milestone.DueDate, milestone.CreatedAt,
+	).Scan(&milestone.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create milestone: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a milestone by ID
+func (r *MilestoneRepository) GetByID(id int) (*models.Milestone, error) {
+	query := `SELECT ` + milestoneColumns + ` FROM milestones m WHERE m.id = ?`
+
+	milestone, err := scanMilestone(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("milestone not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
+	}
+
+	return milestone, nil
+}
+
+// GetByBoardID retrieves a board's milestones ordered by due date
+func (r *MilestoneRepository) GetByBoardID(boardID int) ([]models.Milestone, error) {
+	query := `
+		SELECT ` + milestoneColumns + `
+		FROM milestones m
+		WHERE m.board_id = ?
+		ORDER BY m.due_date IS NULL, datetime(m.due_date), m.id
+	`
+
+	rows, err := r.db.Query(query, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestones: %w", err)
+	}
+	defer rows.Close()
+
+	milestones := []models.Milestone{}
+	for rows.Next() {
+		milestone, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		milestones = append(milestones, *milestone)
+	}
+
+	return milestones, rows.Err()
+}
+
+// Update updates a milestone's title, description and due date
+func (r *MilestoneRepository) Update(milestone *models.Milestone) error {
+	query := `
+		UPDATE milestones
+		SET title = ?, description = ?, due_date = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, milestone.Title, milestone.Description, milestone.DueDate, milestone.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update milestone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("milestone not found")
+	}
+
+	return nil
+}
+
+// Delete deletes a milestone; ON DELETE SET NULL unassigns its cards
+func (r *MilestoneRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM milestones WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("milestone not found")
+	}
+
+	return nil
+}
+
+func scanMilestone(row rowScanner) (*models.Milestone, error) {
+	var milestone models.Milestone
+	err := row.Scan(
+		&milestone.ID, &milestone.BoardID, &milestone.Title, &milestone.Description,
+		&milestone.DueDate, &milestone.CreatedAt, &milestone.CardCount, &milestone.CompletedCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if milestone.CardCount > 0 {
+		percent := float64(milestone.CompletedCount) / float64(milestone.CardCount) * 100
+		milestone.PercentComplete = math.Round(percent*10) / 10
+	}
+	return &milestone, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// NotificationRepository handles database operations for mentions and notifications
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// AddMention records a mention unless the same one already exists.
+// It reports whether a new mention was created.
+func (r *NotificationRepository) AddMention(mention *models.Mention) (bool, error) {
+	query := `
+		INSERT INTO mentions (user_id, card_id, comment_id, created_at)
+		SELECT ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM mentions WHERE user_id = ? AND card_id = ? AND comment_id IS ?
+		)
+		RETURNING id
+	`
+	mention.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		query, mention.UserID, mention.CardID, mention.CommentID, mention.CreatedAt,
+		mention.UserID, mention.CardID, mention.CommentID,
+	).Scan(&mention.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to add mention: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetMentionsByUser retrieves the most recent mentions of a user
+func (r *NotificationRepository) GetMentionsByUser(userID int, limit int) ([]models.Mention, error) {
+	query := `
+		SELECT m.id, m.user_id, m.card_id, m.comment_id, c.title, m.created_at
+		FROM mentions m
+		INNER JOIN cards c ON m.card_id = c.id
+		WHERE m.user_id = ?
+		ORDER BY m.created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mentions: %w", err)
+	}
+	defer rows.Close()
+
+	var mentions []models.Mention
+	for rows.Next() {
+		var mention models.Mention
+		err := rows.Scan(
+			&mention.ID, &mention.UserID, &mention.CardID, &mention.CommentID,
+			&mention.CardTitle, &mention.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		mentions = append(mentions, mention)
+	}
+
+	if mentions == nil {
+		mentions = []models.Mention{}
+	}
+
+	return mentions, nil
+}
+
+// Create creates a new notification
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, type, card_id, comment_id, message, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	notification.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		query, notification.UserID, notification.Type, notification.CardID,
+		notification.CommentID, notification.Message, notification.CreatedAt,
+	).Scan(&notification.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUser retrieves the notifications of a user, newest first
+func (r *NotificationRepository) GetByUser(userID int, unreadOnly bool, limit int) ([]models.Notification, error) {
+	query := `
+		SELECT id, user_id, type, card_id, comment_id, message, read_at, created_at
+		FROM notifications
+		WHERE user_id = ?
+	`
+	args := []interface{}{userID}
+
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(
+			&n.ID, &n.UserID, &n.Type, &n.CardID, &n.CommentID,
+			&n.Message, &n.ReadAt, &n.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks a user's notification as read
+func (r *NotificationRepository) MarkRead(id int, userID int) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, ?)
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := r.db.Exec(query, time.Now(), id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("notification not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/references"
+)
+
+// SetReferences records the card references in a card's description, or in
+// one of its comments when commentID is set. References already recorded
+// keep the card they were resolved to, those no longer in the text are
+// dropped, and new ones are resolved: #123 on the card's board, OPS-12 on
+// the board with that key prefix. References to no card, or to the card
+// itself, are not recorded.
+func (r *CardRepository) SetReferences(cardID int, commentID *int, text string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := setReferences(tx, cardID, commentID, text); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record card references: %w", err)
+	}
+	return nil
+}
+
+// setReferences is SetReferences within a transaction
+func setReferences(tx *sql.Tx, cardID int, commentID *int, text string) error {
+	rows, err := tx.Query("SELECT text FROM card_references WHERE card_id = ? AND comment_id IS ?", cardID, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to get card references: %w", err)
+	}
+	recorded := make(map[string]bool)
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan card reference: %w", err)
+		}
+		recorded[text] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get card references: %w", err)
+	}
+
+	found := make(map[string]bool)
+	for _, ref := range references.Parse(text) {
+		found[ref.Text] = true
+		if recorded[ref.Text] {
+			continue
+		}
+
+		var targetID int
+		if ref.Prefix == "" {
+			err = tx.QueryRow(`
+				SELECT c.id
+				FROM cards c
+				JOIN lists l ON c.list_id = l.id
+				WHERE c.number = ? AND l.board_id = (SELECT sl.board_id FROM cards s JOIN lists sl ON s.list_id = sl.id WHERE s.id = ?)
+			`, ref.Number, cardID).Scan(&targetID)
+		} else {
+			err = tx.QueryRow(`
+				SELECT c.id
+				FROM cards c
+				JOIN lists l ON c.list_id = l.id
+				JOIN boards b ON l.board_id = b.id
+				WHERE b.key_prefix = ? AND c.number = ?
+			`, ref.Prefix, ref.Number).Scan(&targetID)
+		}
+		if err == sql.ErrNoRows || targetID == cardID {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve card reference: %w", err)
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO card_references (card_id, comment_id, target_id, text, created_at) VALUES (?, ?, ?, ?, ?)",
+			cardID, commentID, targetID, ref.Text, time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record card reference: %w", err)
+		}
+	}
+
+	for text := range recorded {
+		if found[text] {
+			continue
+		}
+		_, err := tx.Exec("DELETE FROM card_references WHERE card_id = ? AND comment_id IS ? AND text = ?", cardID, commentID, text)
+		if err != nil {
+			return fmt.Errorf("failed to remove card reference: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetReferences retrieves the cards referred to in a card's description and
+// comments that viewer may see, description first, in order of appearance
+func (r *CardRepository) GetReferences(cardID int, viewer *int) ([]models.CardReference, error) {
+	visible, visibleArgs := readableBy(viewer)
+	rows, err := r.db.Query(`
+		SELECT r.text, r.comment_id, c.id, l.board_id, c.title, COALESCE(c.key, ''), `+cardReference+`, COALESCE(c.completed, 0)
+		FROM card_references r
+		JOIN cards c ON c.id = r.target_id
+		JOIN lists l ON c.list_id = l.id
+		WHERE r.card_id = ? AND `+visible+`
+		ORDER BY r.comment_id IS NOT NULL, r.comment_id, r.id
+	`, append([]interface{}{cardID}, visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []models.CardReference
+	for rows.Next() {
+		var ref models.CardReference
+		if err := rows.Scan(&ref.Text, &ref.CommentID, &ref.CardID, &ref.BoardID, &ref.Title, &ref.Key, &ref.Reference, &ref.Completed); err != nil {
+			return nil, fmt.Errorf("failed to scan card reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// GetBacklinks retrieves the references to a card from the descriptions and
+// comments of other cards that viewer may see, newest first
+func (r *CardRepository) GetBacklinks(cardID int, viewer *int) ([]models.CardBacklink, error) {
+	visible, visibleArgs := readableBy(viewer)
+	rows, err := r.db.Query(`
+		SELECT c.id, r.comment_id, r.text, l.board_id, c.title, COALESCE(c.key, ''), `+cardReference+`, COALESCE(c.completed, 0), r.created_at
+		FROM card_references r
+		JOIN cards c ON c.id = r.card_id
+		JOIN lists l ON c.list_id = l.id
+		WHERE r.target_id = ? AND `+visible+`
+		ORDER BY r.created_at DESC, r.id DESC
+	`, append([]interface{}{cardID}, visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card backlinks: %w", err)
+	}
+	defer rows.Close()
+
+	backlinks := []models.CardBacklink{}
+	for rows.Next() {
+		var backlink models.CardBacklink
+		if err := rows.Scan(&backlink.CardID, &backlink.CommentID, &backlink.Text, &backlink.BoardID, &backlink.Title,
+			&backlink.Key, &backlink.Reference, &backlink.Completed, &backlink.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan card backlink: %w", err)
+		}
+		backlinks = append(backlinks, backlink)
+	}
+	return backlinks, rows.Err()
+}
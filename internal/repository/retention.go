@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// RetentionRepository permanently deletes archived cards once they are past
+// their board's retention period
+type RetentionRepository struct {
+	db *sql.DB
+}
+
+// NewRetentionRepository creates a new retention repository
+func NewRetentionRepository(db *sql.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// Purge deletes the archived cards of every board with a retention policy
+// that were archived longer ago than the policy allows. A board's own
+// retention_days wins over the default. Everything is deleted in one
+// transaction; a dry run only reports.
+func (r *RetentionRepository) Purge(opts models.PurgeOptions) (*models.PurgeReport, error) {
+	report := &models.PurgeReport{
+		RanAt:                opts.Now,
+		DryRun:               opts.DryRun,
+		DefaultRetentionDays: opts.DefaultDays,
+		Boards:               []models.BoardPurge{},
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, name, settings FROM boards ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boards: %w", err)
+	}
+	for rows.Next() {
+		var purge models.BoardPurge
+		var raw sql.NullString
+		var settings models.BoardSettings
+		if err := rows.Scan(&purge.BoardID, &purge.BoardName, &raw); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan board: %w", err)
+		}
+		if err := decodeBoardSettings(raw, &settings); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		purge.RetentionDays = settings.RetentionDays
+		if purge.RetentionDays == 0 {
+			purge.RetentionDays = opts.DefaultDays
+		}
+		if purge.RetentionDays == 0 {
+			continue
+		}
+		purge.Before = opts.Now.AddDate(0, 0, -purge.RetentionDays)
+		purge.CardIDs = []int{}
+		report.Boards = append(report.Boards, purge)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get boards: %w", err)
+	}
+
+	var ids []interface{}
+	for i := range report.Boards {
+		purge := &report.Boards[i]
+		rows, err := tx.Query(`
+			SELECT c.id FROM cards c
+			JOIN lists l ON l.id = c.list_id
+			WHERE l.board_id = ? AND c.archived = 1 AND datetime(c.archived_at) < datetime(?)
+			ORDER BY c.id
+		`, purge.BoardID, purge.Before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find expired cards: %w", err)
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan expired card: %w", err)
+			}
+			purge.CardIDs = append(purge.CardIDs, id)
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to find expired cards: %w", err)
+		}
+		purge.Cards = len(purge.CardIDs)
+	}
+
+	report.Cards = len(ids)
+	if len(ids) == 0 {
+		return report, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	err = tx.QueryRow("SELECT COUNT(*) FROM comments WHERE card_id IN ("+placeholders+")", ids...).Scan(&report.Comments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// Comments, labels, moves and the rest go with their cards by cascade
+	if _, err := tx.Exec("DELETE FROM cards WHERE id IN ("+placeholders+")", ids...); err != nil {
+		return nil, fmt.Errorf("failed to purge cards: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// snippetColumns is the column list read by scanSnippet
+const snippetColumns = `id, board_id, name, content, created_at, updated_at`
+
+// SnippetRepository handles database operations for description snippets
+type SnippetRepository struct {
+	db *sql.DB
+}
+
+// NewSnippetRepository creates a new snippet repository
+func NewSnippetRepository(db *sql.DB) *SnippetRepository {
+	return &SnippetRepository{db: db}
+}
+
+// Create creates a new snippet. A name the board already uses, ignoring
+// case, is "snippet name already exists".
+func (r *SnippetRepository) Create(snippet *models.Snippet) error {
+	query := `
+		INSERT INTO snippets (board_id, name, content, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (board_id, name) DO NOTHING
+		RETURNING id
+	`
+	now := time.Now()
+	snippet.CreatedAt = now
+	snippet.UpdatedAt = now
+
+	err := r.db.QueryRow(query, snippet.BoardID, snippet.Name, snippet.Content, snippet.CreatedAt, snippet.UpdatedAt).Scan(&snippet.ID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("snippet name already exists")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create snippet: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a snippet by ID
+func (r *SnippetRepository) GetByID(id int) (*models.Snippet, error) {
+	snippet, err := scanSnippet(r.db.QueryRow(`SELECT `+snippetColumns+` FROM snippets WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("snippet not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snippet: %w", err)
+	}
+
+	return snippet, nil
+}
+
+// GetByBoardID retrieves a board's snippets ordered by name
+func (r *SnippetRepository) GetByBoardID(boardID int) ([]models.Snippet, error) {
+	rows, err := r.db.Query(`SELECT `+snippetColumns+` FROM snippets WHERE board_id = ? ORDER BY name`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snippets: %w", err)
+	}
+	defer rows.Close()
+
+	snippets := []models.Snippet{}
+	for rows.Next() {
+		snippet, err := scanSnippet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan snippet: %w", err)
+		}
+		snippets = append(snippets, *snippet)
+	}
+
+	return snippets, rows.Err()
+}
+
+// GetByNames retrieves a board's snippets by name, ignoring case, in the
+// order the names are given. The first name the board has no snippet for is
+// returned with "snippet not found".
+func (r *SnippetRepository) GetByNames(boardID int, names []string) ([]models.Snippet, string, error) {
+	all, err := r.GetByBoardID(boardID)
+	if err != nil {
+		return nil, "", err
+	}
+	byName := make(map[string]models.Snippet, len(all))
+	for _, snippet := range all {
+		byName[strings.ToLower(snippet.Name)] = snippet
+	}
+
+	snippets := make([]models.Snippet, 0, len(names))
+	for _, name := range names {
+		snippet, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, name, fmt.Errorf("snippet not found")
+		}
+		snippets = append(snippets, snippet)
+	}
+
+	return snippets, "", nil
+}
+
+// Update saves a snippet's name and content. A name another of the board's
+// snippets uses is "snippet name already exists".
+func (r *SnippetRepository) Update(snippet *models.Snippet) error {
+	snippet.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(
+		"UPDATE snippets SET name = ?, content = ?, updated_at = ? WHERE id = ?",
+		snippet.Name, snippet.Content, snippet.UpdatedAt, snippet.ID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("snippet name already exists")
+		}
+		return fmt.Errorf("failed to update snippet: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("snippet not found")
+	}
+
+	return nil
+}
+
+// Delete removes a snippet; cards made with it keep their descriptions
+func (r *SnippetRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM snippets WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete snippet: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("snippet not found")
+	}
+
+	return nil
+}
+
+// scanSnippet reads a row selected with snippetColumns
+func scanSnippet(row rowScanner) (*models.Snippet, error) {
+	snippet := &models.Snippet{}
+	err := row.Scan(&snippet.ID, &snippet.BoardID, &snippet.Name, &snippet.Content, &snippet.CreatedAt, &snippet.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return snippet, nil
+}
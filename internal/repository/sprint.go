@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// sprintColumns selects every sprint field with card roll-ups; queries alias sprints as s
+const sprintColumns = `
+	s.id, s.board_id, s.name, COALESCE(s.goal, ''), s.start_date, s.end_date, s.status,
+	s.started_at, s.closed_at, s.created_at,
+	(SELECT COUNT(*) FROM cards c WHERE c.sprint_id = s.id),
+	(SELECT COUNT(*) FROM cards c WHERE c.sprint_id = s.id AND c.completed = 1)
+`
+
+// SprintRepository handles database operations for sprints
+type SprintRepository struct {
+	db *sql.DB
+}
+
+// NewSprintRepository creates a new sprint repository
+func NewSprintRepository(db *sql.DB) *SprintRepository {
+	return &SprintRepository{db: db}
+}
+
+// Create creates a new sprint in the planned state
+func (r *SprintRepository) Create(sprint *models.Sprint) error {
+	query := `
+		INSERT INTO sprints (board_id, name, goal, start_date, end_date, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	sprint.Status = models.SprintPlanned
+	sprint.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		query, sprint.BoardID, sprint.Name, sprint.Goal, sprint.StartDate,
+		sprint.EndDate, sprint.Status, sprint.CreatedAt,
+	).Scan(&sprint.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create sprint: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a sprint by ID
+func (r *SprintRepository) GetByID(id int) (*models.Sprint, error) {
+	query := `SELECT ` + sprintColumns + ` FROM sprints s WHERE s.id = ?`
+
+	sprint, err := scanSprint(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint: %w", err)
+	}
+
+	return sprint, nil
+}
+
+// GetByBoardID retrieves a board's sprints, optionally only those with status
+func (r *SprintRepository) GetByBoardID(boardID int, status string) ([]models.Sprint, error) {
+	query := `SELECT ` + sprintColumns + ` FROM sprints s WHERE s.board_id = ?`
+	args := []interface{}{boardID}
+
+	if status != "" {
+		query += " AND s.status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY s.start_date IS NULL, datetime(s.start_date), s.id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprints: %w", err)
+	}
+	defer rows.Close()
+
+	sprints := []models.Sprint{}
+	for rows.Next() {
+		sprint, err := scanSprint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sprint: %w", err)
+		}
+		sprints = append(sprints, *sprint)
+	}
+
+	return sprints, rows.Err()
+}
+
+// Update updates a sprint's name, goal and dates
+func (r *SprintRepository) Update(sprint *models.Sprint) error {
+	query := `
+		UPDATE sprints
+		SET name = ?, goal = ?, start_date = ?, end_date = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate, sprint.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update sprint: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("sprint not found")
+	}
+
+	return nil
+}
+
+// Start makes a planned sprint active. A board has at most one active sprint.
+func (r *SprintRepository) Start(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var boardID int
+	var status string
+	err = tx.QueryRow("SELECT board_id, status FROM sprints WHERE id = ?", id).Scan(&boardID, &status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get sprint: %w", err)
+	}
+	if status != models.SprintPlanned {
+		return fmt.Errorf("sprint is not planned")
+	}
+
+	var active bool
+	err = tx.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM sprints WHERE board_id = ? AND status = ?)",
+		boardID, models.SprintActive,
+	).Scan(&active)
+	if err != nil {
+		return fmt.Errorf("failed to check active sprint: %w", err)
+	}
+	if active {
+		return fmt.Errorf("board already has an active sprint")
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE sprints SET status = ?, started_at = ? WHERE id = ?",
+		models.SprintActive, time.Now(), id,
+	); err != nil {
+		return fmt.Errorf("failed to start sprint: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Close closes an active sprint and moves its unfinished (not completed)
+// cards to nextSprintID, or to the backlog when it is nil. It returns the
+// number of cards moved.
+func (r *SprintRepository) Close(id int, nextSprintID *int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM sprints WHERE id = ?", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("sprint not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sprint: %w", err)
+	}
+	if status != models.SprintActive {
+		return 0, fmt.Errorf("sprint is not active")
+	}
+
+	result, err := tx.Exec(`
+		UPDATE cards SET sprint_id = ?, updated_at = ?
+		WHERE sprint_id = ? AND COALESCE(completed, 0) = 0
+	`, nextSprintID, time.Now(), id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move unfinished cards: %w", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE sprints SET status = ?, closed_at = ? WHERE id = ?",
+		models.SprintClosed, time.Now(), id,
+	); err != nil {
+		return 0, fmt.Errorf("failed to close sprint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to close sprint: %w", err)
+	}
+
+	return int(moved), nil
+}
+
+// Delete deletes a sprint; ON DELETE SET NULL returns its cards to the backlog
+func (r *SprintRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM sprints WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("sprint not found")
+	}
+
+	return nil
+}
+
+func scanSprint(row rowScanner) (*models.Sprint, error) {
+	var sprint models.Sprint
+	err := row.Scan(
+		&sprint.ID, &sprint.BoardID, &sprint.Name, &sprint.Goal, &sprint.StartDate,
+		&sprint.EndDate, &sprint.Status, &sprint.StartedAt, &sprint.ClosedAt,
+		&sprint.CreatedAt, &sprint.CardCount, &sprint.DoneCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// SyncRepository reads card revision history and remembers applied sync
+// operations
+type SyncRepository struct {
+	db *sql.DB
+}
+
+// NewSyncRepository creates a new sync repository
+func NewSyncRepository(db *sql.DB) *SyncRepository {
+	return &SyncRepository{db: db}
+}
+
+// ChangedFields returns the fields of a card changed by revisions after
+// since up to and including until. The second result is false when part of
+// that history has been pruned, so the list may be incomplete.
+func (r *SyncRepository) ChangedFields(cardID, since, until int) ([]string, bool, error) {
+	rows, err := r.db.Query(`
+		SELECT fields FROM card_revisions
+		WHERE card_id = ? AND revision > ? AND revision <= ?
+	`, cardID, since, until)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get card revisions: %w", err)
+	}
+	defer rows.Close()
+
+	fields := []string{}
+	seen := make(map[string]bool)
+	revisions := 0
+	for rows.Next() {
+		var list string
+		if err := rows.Scan(&list); err != nil {
+			return nil, false, fmt.Errorf("failed to scan card revision: %w", err)
+		}
+		revisions++
+		for _, field := range strings.Split(list, ",") {
+			if field != "" && !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to get card revisions: %w", err)
+	}
+
+	return fields, revisions == until-since, nil
+}
+
+// GetOp retrieves an operation applied for a user (nil for anonymous
+// requests) by its ID; op IDs are only unique per user
+func (r *SyncRepository) GetOp(userID *int, opID string) (*models.SyncedOp, error) {
+	op := &models.SyncedOp{}
+	err := r.db.QueryRow(`
+		SELECT op_id, type, status, card_id, base_revision, revision, payload_hash
+		FROM sync_ops WHERE user_id = ? AND op_id = ?
+	`, syncOwner(userID), opID).Scan(&op.OpID, &op.Type, &op.Status, &op.CardID, &op.BaseRevision, &op.Revision, &op.PayloadHash)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sync op not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync op: %w", err)
+	}
+	return op, nil
+}
+
+// SaveOp remembers an operation applied for a user; saving an ID again
+// keeps the first
+func (r *SyncRepository) SaveOp(userID *int, op *models.SyncedOp) error {
+	_, err := r.db.Exec(`
+		INSERT OR IGNORE INTO sync_ops (user_id, op_id, type, status, card_id, base_revision, revision, payload_hash, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, syncOwner(userID), op.OpID, op.Type, op.Status, op.CardID, op.BaseRevision, op.Revision, op.PayloadHash, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save sync op: %w", err)
+	}
+	return nil
+}
+
+// syncOwner is the sync_ops user_id of a user, 0 for anonymous requests
+func syncOwner(userID *int) int {
+	if userID == nil {
+		return 0
+	}
+	return *userID
+}
+
+// Prune deletes card revisions and sync operations recorded before the
+// given time, returning how many were removed
+func (r *SyncRepository) Prune(before time.Time) (int64, error) {
+	cutoff := before.UTC().Format("2006-01-02 15:04:05")
+	var pruned int64
+	for _, query := range []string{
+		`DELETE FROM card_revisions WHERE datetime(changed_at) < datetime(?)`,
+		`DELETE FROM sync_ops WHERE datetime(created_at) < datetime(?)`,
+	} {
+		result, err := r.db.Exec(query, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("failed to prune sync history: %w", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		pruned += rows
+	}
+	return pruned, nil
+}
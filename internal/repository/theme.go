@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// GetTheme retrieves the theme set through the admin API; fields that were
+// not set are empty
+func (r *InstanceRepository) GetTheme() (models.Theme, error) {
+	return scanTheme(r.db.QueryRow(themeQuery))
+}
+
+// themeQuery selects the theme set through the admin API
+const themeQuery = "SELECT instance_name, primary_color, accent_color, logo_url FROM instance_theme WHERE id = 1"
+
+// scanTheme scans the row of themeQuery; no row is an empty theme
+func scanTheme(row rowScanner) (models.Theme, error) {
+	var name, primary, accent, logo sql.NullString
+	err := row.Scan(&name, &primary, &accent, &logo)
+	if err == sql.ErrNoRows {
+		return models.Theme{}, nil
+	}
+	if err != nil {
+		return models.Theme{}, fmt.Errorf("failed to get theme: %w", err)
+	}
+
+	return models.Theme{
+		InstanceName: name.String,
+		PrimaryColor: primary.String,
+		AccentColor:  accent.String,
+		LogoURL:      logo.String,
+	}, nil
+}
+
+// SetTheme changes the fields given in req of the theme set through the
+// admin API, clearing those given as empty strings, and returns the result
+func (r *InstanceRepository) SetTheme(req *models.UpdateThemeRequest) (models.Theme, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Theme{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	theme, err := scanTheme(tx.QueryRow(themeQuery))
+	if err != nil {
+		return theme, err
+	}
+
+	if req.InstanceName != nil {
+		theme.InstanceName = *req.InstanceName
+	}
+	if req.PrimaryColor != nil {
+		theme.PrimaryColor = *req.PrimaryColor
+	}
+	if req.AccentColor != nil {
+		theme.AccentColor = *req.AccentColor
+	}
+	if req.LogoURL != nil {
+		theme.LogoURL = *req.LogoURL
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO instance_theme (id, instance_name, primary_color, accent_color, logo_url, updated_at)
+		VALUES (1, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			instance_name = excluded.instance_name,
+			primary_color = excluded.primary_color,
+			accent_color = excluded.accent_color,
+			logo_url = excluded.logo_url,
+			updated_at = excluded.updated_at
+	`, nullString(theme.InstanceName), nullString(theme.PrimaryColor), nullString(theme.AccentColor), nullString(theme.LogoURL), time.Now())
+	if err != nil {
+		return theme, fmt.Errorf("failed to set theme: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return theme, fmt.Errorf("failed to set theme: %w", err)
+	}
+	return theme, nil
+}
+
+// ResetTheme clears the theme set through the admin API, leaving the
+// configured one
+func (r *InstanceRepository) ResetTheme() error {
+	if _, err := r.db.Exec("DELETE FROM instance_theme"); err != nil {
+		return fmt.Errorf("failed to reset theme: %w", err)
+	}
+	return nil
+}
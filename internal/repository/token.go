@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// APITokenPrefix starts every API token secret, so they can be told apart
+// from other bearer credentials such as the admin token
+const APITokenPrefix = "kb_"
+
+// tokenTouchInterval limits how often a token's last use is written back
+const tokenTouchInterval = time.Minute
+
+// APITokenRepository handles database operations for API tokens
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// Create issues a new token for a user. The returned token carries the
+// secret, which is not stored and cannot be shown again.
+func (r *APITokenRepository) Create(userID int, req *models.CreateAPITokenRequest) (*models.APIToken, error) {
+	secret, err := newTokenSecret(APITokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = models.DefaultTokenScopes
+	}
+
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, prefix, scopes, rate_limit, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	var id int
+	err = r.db.QueryRow(
+		query, userID, req.Name, hashToken(secret), tokenDisplayPrefix(secret),
+		joinScopes(scopes), req.RateLimit, time.Now(),
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	token, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	token.Token = secret
+	return token, nil
+}
+
+// GetByID retrieves a token by ID
+func (r *APITokenRepository) GetByID(id int) (*models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.id = ?
+	`
+
+	token, err := scanAPIToken(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetAll retrieves every token, or only a user's when userID is set, newest
+// first. Revoked tokens are included so their history stays visible.
+func (r *APITokenRepository) GetAll(userID *int) ([]models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id
+	`
+	var args []interface{}
+	if userID != nil {
+		query += " WHERE t.user_id = ?"
+		args = append(args, *userID)
+	}
+	query += " ORDER BY t.id DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Update changes a token's name, scopes or rate limit. Revoked tokens can
+// still be renamed but stay revoked.
+func (r *APITokenRepository) Update(id int, req *models.UpdateAPITokenRequest) (*models.APIToken, error) {
+	token, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		token.Name = *req.Name
+	}
+	if req.Scopes != nil {
+		token.Scopes = req.Scopes
+	}
+	if req.RateLimit != nil {
+		token.RateLimit = *req.RateLimit
+	}
+
+	query := "UPDATE api_tokens SET name = ?, scopes = ?, rate_limit = ? WHERE id = ?"
+	if _, err := r.db.Exec(query, token.Name, joinScopes(token.Scopes), token.RateLimit, id); err != nil {
+		return nil, fmt.Errorf("failed to update api token: %w", err)
+	}
+
+	return r.GetByID(id)
+}
+
+// Rotate replaces a token's secret; the old secret stops working at once.
+// Revoked tokens cannot be rotated.
+func (r *APITokenRepository) Rotate(id int) (*models.APIToken, error) {
+	secret, err := newTokenSecret(APITokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE api_tokens
+		SET token_hash = ?, prefix = ?, rotated_at = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`
+	result, err := r.db.Exec(query, hashToken(secret), tokenDisplayPrefix(secret), time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate api token: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("api token not found")
+	}
+
+	token, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	token.Token = secret
+	return token, nil
+}
+
+// Revoke permanently disables a token
+func (r *APITokenRepository) Revoke(id int) error {
+	result, err := r.db.Exec("UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("api token not found")
+	}
+
+	return nil
+}
+
+// AnyActive reports whether a token that has not been revoked exists
+func (r *APITokenRepository) AnyActive() (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM api_tokens WHERE revoked_at IS NULL)").Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check api tokens: %w", err)
+	}
+	return exists, nil
+}
+
+// Authenticate returns the live token matching a secret and records that it
+// was used. Unknown and revoked secrets are "api token not found".
+func (r *APITokenRepository) Authenticate(secret string) (*models.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = ? AND t.revoked_at IS NULL
+	`
+
+	token, err := scanAPIToken(r.db.QueryRow(query, hashToken(secret)))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api token not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api token: %w", err)
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= tokenTouchInterval {
+		if _, err := r.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", now, token.ID); err != nil {
+			return nil, fmt.Errorf("failed to update api token: %w", err)
+		}
+		token.LastUsedAt = &now
+	}
+
+	return token, nil
+}
+
+// apiTokenColumns is the column list read by scanAPIToken
+const apiTokenColumns = `t.id, t.user_id, u.username, t.name, t.prefix, t.scopes, t.rate_limit, t.created_at, t.rotated_at, t.last_used_at, t.revoked_at`
+
+// scanAPIToken reads a row selected with apiTokenColumns
+func scanAPIToken(row rowScanner) (*models.APIToken, error) {
+	token := &models.APIToken{}
+	var scopes string
+	var rotatedAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Username, &token.Name, &token.Prefix, &scopes,
+		&token.RateLimit, &token.CreatedAt, &rotatedAt, &lastUsedAt, &revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	token.Scopes = splitScopes(scopes)
+	token.RotatedAt = nullTimePtr(rotatedAt)
+	token.LastUsedAt = nullTimePtr(lastUsedAt)
+	token.RevokedAt = nullTimePtr(revokedAt)
+
+	return token, nil
+}
+
+// joinScopes stores scopes as a sorted, de-duplicated comma-separated list
+func joinScopes(scopes []string) string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	sort.Strings(unique)
+	return strings.Join(unique, ",")
+}
+
+// splitScopes reads a list stored by joinScopes
+func splitScopes(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// newTokenSecret generates a random token secret starting with prefix
+func newTokenSecret(prefix string) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return prefix + hex.EncodeToString(buf), nil
+}
+
+// hashToken is how a secret is stored and looked up
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenDisplayPrefix is the part of a secret kept for display
+func tokenDisplayPrefix(secret string) string {
+	return secret[:len(APITokenPrefix)+6]
+}
+
+// IsAPIToken reports whether a bearer credential looks like an API token
+func IsAPIToken(credential string) bool {
+	return strings.HasPrefix(credential, APITokenPrefix)
+}
+
+// nullTimePtr converts a nullable time to a pointer
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
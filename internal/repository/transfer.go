@@ -0,0 +1,943 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/references"
+)
+
+// defaultLabelColor is used for imported labels that arrive without a color
+const defaultLabelColor = "#6b7280"
+
+// TransferRepository exports boards to and imports them from the native
+// JSON format
+type TransferRepository struct {
+	db *sql.DB
+}
+
+// NewTransferRepository creates a new transfer repository
+func NewTransferRepository(db *sql.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// Export builds a self-contained copy of a board, including archived cards.
+// With since set, the export is incremental: it holds the board, its labels,
+// sprints and milestones as usual, but only the lists and cards changed after
+// that change log sequence number, a card counting as changed when one of its
+// comments did, and tombstones for what was removed. Lists with changed cards
+// are included with only those cards. Restricted cards viewer may not see are
+// left out.
+func (r *TransferRepository) Export(boardID int, since *int, viewer *int) (*models.BoardExport, error) {
+	export := &models.BoardExport{
+		Format:     models.ExportFormat,
+		Version:    models.ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Since:      since,
+		Labels:     []models.ExportedLabel{},
+		Sprints:    []models.ExportedSprint{},
+		Milestones: []models.ExportedMilestone{},
+		Lists:      []models.ExportedList{},
+	}
+
+	var settings sql.NullString
+	err := r.db.QueryRow(
+		"SELECT name, COALESCE(description, ''), COALESCE(icon, ''), COALESCE(key_prefix, ''), settings FROM boards WHERE id = ?", boardID,
+	).Scan(&export.Board.Name, &export.Board.Description, &export.Board.Icon, &export.Board.KeyPrefix, &settings)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("board not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get board: %w", err)
+	}
+	if err := decodeBoardSettings(settings, &export.Board.Settings); err != nil {
+		return nil, err
+	}
+
+	usernames, err := r.usernames()
+	if err != nil {
+		return nil, err
+	}
+
+	var changedLists, changedCards map[int]bool
+	if since != nil {
+		changedLists, changedCards, export.Tombstones, err = r.changedSince(boardID, *since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Lists, remembering where each one's cards go
+	rows, err := r.db.Query(
+		"SELECT "+listColumns+" FROM lists WHERE board_id = ? ORDER BY position", boardID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+	listIndex := make(map[int]int)
+	var listRules []models.ListSettings
+	for rows.Next() {
+		l, err := scanList(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan list: %w", err)
+		}
+		id := l.ID
+		list := models.ExportedList{ID: id, Name: l.Name, Position: l.Position, Color: l.Color}
+		listRules = append(listRules, l.Settings)
+		list.Cards = []models.ExportedCard{}
+		listIndex[id] = len(export.Lists)
+		export.Lists = append(export.Lists, list)
+		if q := export.Board.Settings.QuickCreateListID; q != nil && *q == id {
+			export.Board.QuickCreateList = list.Name
+		}
+		if o := export.Board.Settings.OverdueListID; o != nil && *o == id {
+			export.Board.OverdueList = list.Name
+		}
+		if d := export.Board.Settings.CompletedListID; d != nil && *d == id {
+			export.Board.CompletedList = list.Name
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+	export.Board.Settings.QuickCreateListID = nil
+	export.Board.Settings.OverdueListID = nil
+	export.Board.Settings.CompletedListID = nil
+
+	labels, err := r.exportLabels(boardID, export)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.exportListRules(export, listRules); err != nil {
+		return nil, err
+	}
+	comments, err := r.exportComments(boardID, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, visibleArgs := visibleTo(viewer)
+	rows, err = r.db.Query(`
+		SELECT `+cardColumns+`
+		FROM cards c
+		JOIN lists l ON c.list_id = l.id
+		WHERE l.board_id = ? AND `+visible+`
+		ORDER BY l.position, c.position
+	`, append([]interface{}{boardID}, visibleArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards: %w", err)
+	}
+	for rows.Next() {
+		card, err := scanCard(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan card: %w", err)
+		}
+		if since != nil && !changedCards[card.ID] {
+			continue
+		}
+		exported := models.ExportedCard{
+			ID:            card.ID,
+			Key:           card.Key,
+			Number:        card.Number,
+			Title:         card.Title,
+			Description:   card.Description,
+			Position:      card.Position,
+			Color:         card.Color,
+			StartDate:     card.StartDate,
+			DueDate:       card.DueDate,
+			Archived:      card.Archived,
+			ArchivedAt:    card.ArchivedAt,
+			Completed:     card.Completed,
+			CompletedAt:   card.CompletedAt,
+			Blocked:       card.Blocked,
+			BlockedReason: card.BlockedReason,
+			BlockedAt:     card.BlockedAt,
+			Priority:      card.Priority,
+			Points:        card.Points,
+			SprintID:      card.SprintID,
+			MilestoneID:   card.MilestoneID,
+			Labels:        labels[card.ID],
+			Comments:      comments[card.ID],
+			CreatedAt:     card.CreatedAt,
+			UpdatedAt:     card.UpdatedAt,
+		}
+		if card.AssigneeID != nil {
+			exported.Assignee = usernames[*card.AssigneeID]
+		}
+		if card.Visibility == models.CardVisibilityRestricted {
+			exported.Visibility = card.Visibility
+		}
+		if card.CreatedBy != nil {
+			exported.CreatedBy = usernames[*card.CreatedBy]
+		}
+		i := listIndex[card.ListID]
+		export.Lists[i].Cards = append(export.Lists[i].Cards, exported)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get cards: %w", err)
+	}
+	if since != nil {
+		kept := []models.ExportedList{}
+		for _, list := range export.Lists {
+			if changedLists[list.ID] || len(list.Cards) > 0 {
+				kept = append(kept, list)
+			}
+		}
+		export.Lists = kept
+	}
+
+	rows, err = r.db.Query(`SELECT `+sprintColumns+` FROM sprints s WHERE s.board_id = ? ORDER BY s.id`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprints: %w", err)
+	}
+	for rows.Next() {
+		sprint, err := scanSprint(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan sprint: %w", err)
+		}
+		export.Sprints = append(export.Sprints, models.ExportedSprint{
+			ID: sprint.ID, Name: sprint.Name, Goal: sprint.Goal,
+			StartDate: sprint.StartDate, EndDate: sprint.EndDate, Status: sprint.Status,
+			StartedAt: sprint.StartedAt, ClosedAt: sprint.ClosedAt, CreatedAt: sprint.CreatedAt,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get sprints: %w", err)
+	}
+
+	rows, err = r.db.Query(`SELECT `+milestoneColumns+` FROM milestones m WHERE m.board_id = ? ORDER BY m.id`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestones: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		milestone, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		export.Milestones = append(export.Milestones, models.ExportedMilestone{
+			ID: milestone.ID, Title: milestone.Title, Description: milestone.Description,
+			DueDate: milestone.DueDate, CreatedAt: milestone.CreatedAt,
+		})
+	}
+
+	return export, rows.Err()
+}
+
+// changedSince finds the lists and cards of a board changed after since,
+// counting a card as changed when one of its comments was, and tombstones
+// for the lists, cards and comments no longer on the board
+func (r *TransferRepository) changedSince(boardID, since int) (map[int]bool, map[int]bool, []models.ExportTombstone, error) {
+	rows, err := r.db.Query(`
+		SELECT ch.entity, ch.entity_id, MAX(ch.seq), ch.changed_at,
+		       CASE ch.entity
+		           WHEN 'list' THEN (SELECT l.id FROM lists l WHERE l.id = ch.entity_id AND l.board_id = ?)
+		           WHEN 'card' THEN (SELECT c.id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = ch.entity_id AND l.board_id = ?)
+		           ELSE (SELECT c.id FROM comments cm JOIN cards c ON c.id = cm.card_id JOIN lists l ON l.id = c.list_id
+		                 WHERE cm.id = ch.entity_id AND l.board_id = ?)
+		       END
+		FROM changes ch
+		WHERE ch.board_id = ? AND ch.seq > ? AND ch.entity IN ('list', 'card', 'comment')
+		GROUP BY ch.entity, ch.entity_id
+		ORDER BY MAX(ch.seq)
+	`, boardID, boardID, boardID, boardID, since)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+	defer rows.Close()
+
+	lists := make(map[int]bool)
+	cards := make(map[int]bool)
+	tombstones := []models.ExportTombstone{}
+	for rows.Next() {
+		var tombstone models.ExportTombstone
+		var seq int
+		var current sql.NullInt64 // The list or card to export; NULL when gone
+		if err := rows.Scan(&tombstone.Entity, &tombstone.ID, &seq, &tombstone.RemovedAt, &current); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+		switch {
+		case !current.Valid:
+			tombstones = append(tombstones, tombstone)
+		case tombstone.Entity == "list":
+			lists[int(current.Int64)] = true
+		default:
+			cards[int(current.Int64)] = true
+		}
+	}
+
+	return lists, cards, tombstones, rows.Err()
+}
+
+// exportListRules attaches each list's rules and the board's overdue label
+// to the export by label name, adding labels no card uses to the export's
+// labels
+func (r *TransferRepository) exportListRules(export *models.BoardExport, rules []models.ListSettings) error {
+	rows, err := r.db.Query("SELECT id, name, color FROM labels")
+	if err != nil {
+		return fmt.Errorf("failed to get labels: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int]models.ExportedLabel)
+	for rows.Next() {
+		var id int
+		var label models.ExportedLabel
+		if err := rows.Scan(&id, &label.Name, &label.Color); err != nil {
+			return fmt.Errorf("failed to scan label: %w", err)
+		}
+		byID[id] = label
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get labels: %w", err)
+	}
+
+	exported := make(map[string]bool)
+	for _, label := range export.Labels {
+		exported[label.Name] = true
+	}
+
+	if id := export.Board.Settings.OverdueLabelID; id != nil {
+		if label, ok := byID[*id]; ok {
+			export.Board.OverdueLabel = label.Name
+			if !exported[label.Name] {
+				exported[label.Name] = true
+				export.Labels = append(export.Labels, label)
+			}
+		}
+		export.Board.Settings.OverdueLabelID = nil
+	}
+
+	for i, settings := range rules {
+		if settings.DefaultCardColor == "" && settings.DueIn == "" && len(settings.LabelIDs) == 0 && settings.Capacity == 0 && settings.SLA == "" {
+			continue
+		}
+		out := &models.ExportedListSettings{
+			DefaultCardColor: settings.DefaultCardColor,
+			DueIn:            settings.DueIn,
+			Capacity:         settings.Capacity,
+			SLA:              settings.SLA,
+		}
+		for _, id := range settings.LabelIDs {
+			label, ok := byID[id]
+			if !ok {
+				continue
+			}
+			out.Labels = append(out.Labels, label.Name)
+			if !exported[label.Name] {
+				exported[label.Name] = true
+				export.Labels = append(export.Labels, label)
+			}
+		}
+		export.Lists[i].Settings = out
+	}
+
+	return nil
+}
+
+// exportLabels adds every label used on the board to the export and returns
+// each card's label names
+func (r *TransferRepository) exportLabels(boardID int, export *models.BoardExport) (map[int][]string, error) {
+	rows, err := r.db.Query(`
+		SELECT cl.card_id, lb.name, lb.color
+		FROM card_labels cl
+		JOIN labels lb ON lb.id = cl.label_id
+		JOIN cards c ON c.id = cl.card_id
+		JOIN lists l ON l.id = c.list_id
+		WHERE l.board_id = ?
+		ORDER BY lb.name
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card labels: %w", err)
+	}
+	defer rows.Close()
+
+	byCard := make(map[int][]string)
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var cardID int
+		var label models.ExportedLabel
+		if err := rows.Scan(&cardID, &label.Name, &label.Color); err != nil {
+			return nil, fmt.Errorf("failed to scan card label: %w", err)
+		}
+		byCard[cardID] = append(byCard[cardID], label.Name)
+		if !seen[label.Name] {
+			seen[label.Name] = true
+			export.Labels = append(export.Labels, label)
+		}
+	}
+
+	return byCard, rows.Err()
+}
+
+// exportComments returns the comments on the board's cards, oldest first
+func (r *TransferRepository) exportComments(boardID int, usernames map[int]string) (map[int][]models.ExportedComment, error) {
+	rows, err := r.db.Query(`
+		SELECT cm.id, cm.card_id, cm.content, cm.author_id, cm.created_at, cm.edited_at
+		FROM comments cm
+		JOIN cards c ON c.id = cm.card_id
+		JOIN lists l ON l.id = c.list_id
+		WHERE l.board_id = ?
+		ORDER BY datetime(cm.created_at), cm.id
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	defer rows.Close()
+
+	byCard := make(map[int][]models.ExportedComment)
+	for rows.Next() {
+		var cardID int
+		var authorID *int
+		var comment models.ExportedComment
+		if err := rows.Scan(&comment.ID, &cardID, &comment.Content, &authorID, &comment.CreatedAt, &comment.EditedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		if authorID != nil {
+			comment.Author = usernames[*authorID]
+		}
+		byCard[cardID] = append(byCard[cardID], comment)
+	}
+
+	return byCard, rows.Err()
+}
+
+// usernames maps user IDs to usernames
+func (r *TransferRepository) usernames() (map[int]string, error) {
+	rows, err := r.db.Query("SELECT id, username FROM users")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		names[id] = name
+	}
+
+	return names, rows.Err()
+}
+
+// Import recreates an exported board with new IDs in a single transaction.
+// Labels are shared across boards, so an existing label with the same name
+// (ignoring case) is reused rather than duplicated. Card keys are kept unless
+// already taken on this instance, and card numbers unless repeated, so card
+// references in descriptions and comments are resolved against the imported
+// cards. Assignees and comment authors are matched by username; unknown
+// users are dropped and reported as skipped. Restricted cards whose creator
+// is unknown are recorded as created by importedBy. A dry run performs the
+// same work and rolls it back, so the report is exact.
+func (r *TransferRepository) Import(export *models.BoardExport, dryRun bool, importedBy *int) (*models.ImportResult, error) {
+	result := &models.ImportResult{DryRun: dryRun, Valid: true, Conflicts: []string{}, Skipped: []string{}}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Key prefixes are unique, so importing a copy of a board on the same
+	// server leaves the copy without one
+	keyPrefix, droppedPrefix := export.Board.KeyPrefix, ""
+	if keyPrefix != "" {
+		var taken bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM boards WHERE key_prefix = ?)", keyPrefix).Scan(&taken); err != nil {
+			return nil, fmt.Errorf("failed to check key prefix: %w", err)
+		}
+		if taken {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("Key prefix %q is used by another board; board created without one", keyPrefix))
+			keyPrefix, droppedPrefix = "", keyPrefix
+		}
+	}
+
+	now := time.Now()
+	err = tx.QueryRow(
+		"INSERT INTO boards (name, description, icon, key_prefix, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING id",
+		export.Board.Name, export.Board.Description, nullString(export.Board.Icon), nullString(keyPrefix), now, now,
+	).Scan(&result.BoardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create board: %w", err)
+	}
+	result.Created.Boards = 1
+
+	labelIDs, err := importLabels(tx, export, result)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := userIDsByName(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	sprintIDs := make(map[int]int)
+	for _, sprint := range export.Sprints {
+		var id int
+		err := tx.QueryRow(`
+			INSERT INTO sprints (board_id, name, goal, start_date, end_date, status, started_at, closed_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`, result.BoardID, sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate, sprint.Status,
+			sprint.StartedAt, sprint.ClosedAt, sprint.CreatedAt,
+		).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sprint: %w", err)
+		}
+		sprintIDs[sprint.ID] = id
+		result.Created.Sprints++
+	}
+
+	milestoneIDs := make(map[int]int)
+	for _, milestone := range export.Milestones {
+		var id int
+		err := tx.QueryRow(`
+			INSERT INTO milestones (board_id, title, description, due_date, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			RETURNING id
+		`, result.BoardID, milestone.Title, milestone.Description, milestone.DueDate, milestone.CreatedAt,
+		).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create milestone: %w", err)
+		}
+		milestoneIDs[milestone.ID] = id
+		result.Created.Milestones++
+	}
+
+	settings := export.Board.Settings
+	settings.QuickCreateListID = nil
+	settings.OverdueListID = nil
+	settings.CompletedListID = nil
+	settings.OverdueLabelID = nil
+	if id, ok := labelIDs[strings.ToLower(export.Board.OverdueLabel)]; ok {
+		settings.OverdueLabelID = &id
+	}
+
+	numbers := make(map[int]bool)
+	for _, list := range export.Lists {
+		rules, err := importListRules(list.Settings, labelIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		var listID int
+		err = tx.QueryRow(`
+			INSERT INTO lists (board_id, name, position, color, settings, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`, result.BoardID, list.Name, list.Position, nullString(list.Color), rules, now, now,
+		).Scan(&listID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create list: %w", err)
+		}
+		result.Created.Lists++
+		if export.Board.QuickCreateList != "" && list.Name == export.Board.QuickCreateList && settings.QuickCreateListID == nil {
+			settings.QuickCreateListID = &listID
+		}
+		if export.Board.OverdueList != "" && list.Name == export.Board.OverdueList && settings.OverdueListID == nil {
+			settings.OverdueListID = &listID
+		}
+		if export.Board.CompletedList != "" && list.Name == export.Board.CompletedList && settings.CompletedListID == nil {
+			settings.CompletedListID = &listID
+		}
+
+		for _, card := range list.Cards {
+			if droppedPrefix != "" {
+				card = unprefixCard(card, droppedPrefix)
+			}
+			if err := importCard(tx, listID, card, labelIDs, userIDs, sprintIDs, milestoneIDs, numbers, importedBy, result); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := importReferences(tx, result.BoardID); err != nil {
+		return nil, err
+	}
+
+	if export.Board.QuickCreateList != "" && settings.QuickCreateListID == nil {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("Quick-create list %q not found; setting cleared", export.Board.QuickCreateList))
+	}
+	if export.Board.OverdueList != "" && settings.OverdueListID == nil {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("Overdue list %q not found; setting cleared", export.Board.OverdueList))
+	}
+	if export.Board.CompletedList != "" && settings.CompletedListID == nil {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("Completed list %q not found; setting cleared", export.Board.CompletedList))
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode board settings: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE boards SET settings = ? WHERE id = ?", string(data), result.BoardID); err != nil {
+		return nil, fmt.Errorf("failed to update board settings: %w", err)
+	}
+
+	if dryRun {
+		result.BoardID = 0
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to import board: %w", err)
+	}
+
+	return result, nil
+}
+
+// importLabels resolves every label named in the export to a label ID,
+// reusing labels that already exist and creating the rest
+func importLabels(tx *sql.Tx, export *models.BoardExport, result *models.ImportResult) (map[string]int, error) {
+	existing := make(map[string]models.Label)
+	rows, err := tx.Query("SELECT id, name, color FROM labels")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+	for rows.Next() {
+		var label models.Label
+		if err := rows.Scan(&label.ID, &label.Name, &label.Color); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan label: %w", err)
+		}
+		existing[strings.ToLower(label.Name)] = label
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+
+	// Cards, list rules and the overdue setting may name labels missing from the
+	// label list; give those the default color
+	wanted := append([]models.ExportedLabel{}, export.Labels...)
+	wanted = append(wanted, models.ExportedLabel{Name: export.Board.OverdueLabel})
+	for _, list := range export.Lists {
+		for _, card := range list.Cards {
+			for _, name := range card.Labels {
+				wanted = append(wanted, models.ExportedLabel{Name: name})
+			}
+		}
+		if list.Settings != nil {
+			for _, name := range list.Settings.Labels {
+				wanted = append(wanted, models.ExportedLabel{Name: name})
+			}
+		}
+	}
+
+	ids := make(map[string]int)
+	for _, label := range wanted {
+		key := strings.ToLower(label.Name)
+		if _, done := ids[key]; done || label.Name == "" {
+			continue
+		}
+
+		if match, ok := existing[key]; ok {
+			ids[key] = match.ID
+			if label.Color != "" && !strings.EqualFold(label.Color, match.Color) {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf(
+					"Label %q already exists with color %s; reused it instead of %s", match.Name, match.Color, label.Color))
+			}
+			continue
+		}
+
+		color := label.Color
+		if color == "" {
+			color = defaultLabelColor
+		}
+		var id int
+		if err := tx.QueryRow("INSERT INTO labels (name, color) VALUES (?, ?) RETURNING id", label.Name, color).Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to create label: %w", err)
+		}
+		ids[key] = id
+		result.Created.Labels++
+	}
+
+	return ids, nil
+}
+
+// importListRules encodes an exported list's rules for the settings column
+// with label names resolved to the IDs created or reused by the import
+func importListRules(settings *models.ExportedListSettings, labelIDs map[string]int) (interface{}, error) {
+	if settings == nil {
+		return nil, nil
+	}
+
+	rules := models.ListSettings{
+		DefaultCardColor: settings.DefaultCardColor,
+		DueIn:            settings.DueIn,
+		Capacity:         settings.Capacity,
+		SLA:              settings.SLA,
+	}
+	for _, name := range settings.Labels {
+		if id, ok := labelIDs[strings.ToLower(name)]; ok {
+			rules.LabelIDs = append(rules.LabelIDs, id)
+		}
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode list settings: %w", err)
+	}
+	return string(data), nil
+}
+
+// importCard creates one exported card with its labels, comments and a
+// creation entry in its movement history
+func importCard(tx *sql.Tx, listID int, card models.ExportedCard, labelIDs, userIDs map[string]int, sprintIDs, milestoneIDs map[int]int, numbers map[int]bool, importedBy *int, result *models.ImportResult) error {
+	number, err := importCardNumber(tx, listID, card.Number, numbers)
+	if err != nil {
+		return err
+	}
+
+	key := card.Key
+	if key != "" {
+		var taken bool
+		if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM cards WHERE key = ?)", key).Scan(&taken); err != nil {
+			return fmt.Errorf("failed to check card key: %w", err)
+		}
+		if taken {
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("Card key %s is already in use; %q got a new key", key, card.Title))
+			key = ""
+		}
+	}
+	if key == "" {
+		if key, err = newCardKey(); err != nil {
+			return err
+		}
+	}
+
+	var assigneeID *int
+	if card.Assignee != "" {
+		if id, ok := userIDs[strings.ToLower(card.Assignee)]; ok {
+			assigneeID = &id
+		} else {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("Assignee %q of %q not found; card left unassigned", card.Assignee, card.Title))
+		}
+	}
+
+	visibility := card.Visibility
+	if visibility == "" {
+		visibility = models.CardVisibilityBoard
+	}
+	var createdBy *int
+	if card.CreatedBy != "" {
+		if id, ok := userIDs[strings.ToLower(card.CreatedBy)]; ok {
+			createdBy = &id
+		}
+	}
+	// A restricted card whose creator is not here stays visible to whoever
+	// imports it rather than to no one
+	if visibility == models.CardVisibilityRestricted && createdBy == nil {
+		createdBy = importedBy
+		if card.CreatedBy != "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("Creator %q of restricted card %q not found; it is kept restricted to its assignee and the importer", card.CreatedBy, card.Title))
+		}
+	}
+
+	var sprintID, milestoneID *int
+	if card.SprintID != nil {
+		if id, ok := sprintIDs[*card.SprintID]; ok {
+			sprintID = &id
+		} else {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("Sprint %d of %q not in export; card left in the backlog", *card.SprintID, card.Title))
+		}
+	}
+	if card.MilestoneID != nil {
+		if id, ok := milestoneIDs[*card.MilestoneID]; ok {
+			milestoneID = &id
+		} else {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("Milestone %d of %q not in export; card left without a milestone", *card.MilestoneID, card.Title))
+		}
+	}
+
+	createdAt, updatedAt := card.CreatedAt, card.UpdatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+	var archivedAt *time.Time
+	if card.Archived {
+		archivedAt = card.ArchivedAt
+		if archivedAt == nil {
+			archivedAt = &updatedAt
+		}
+	}
+
+	var blockedAt *time.Time
+	if card.Blocked {
+		blockedAt = card.BlockedAt
+		if blockedAt == nil {
+			blockedAt = &updatedAt
+		}
+	}
+
+	var cardID int
+	err = tx.QueryRow(`
+		INSERT INTO cards (list_id, title, description, position, color, start_date, due_date, archived, archived_at, completed, completed_at,
+			blocked, blocked_reason, blocked_at, priority, assignee_id, sprint_id, milestone_id, points, visibility, created_by, number, key,
+			list_entered_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, listID, card.Title, card.Description, card.Position, card.Color, card.StartDate, card.DueDate, card.Archived, archivedAt,
+		card.Completed, card.CompletedAt, card.Blocked, nullString(card.BlockedReason), blockedAt, nullString(card.Priority), assigneeID, sprintID, milestoneID,
+		card.Points, visibility, createdBy, number, key, createdAt, createdAt, updatedAt,
+	).Scan(&cardID)
+	if err != nil {
+		return fmt.Errorf("failed to create card: %w", err)
+	}
+	result.Created.Cards++
+
+	if err := recordMove(tx, cardID, nil, listID, createdBy, "", createdAt); err != nil {
+		return err
+	}
+
+	for _, name := range card.Labels {
+		if id, ok := labelIDs[strings.ToLower(name)]; ok {
+			if _, err := tx.Exec("INSERT OR IGNORE INTO card_labels (card_id, label_id) VALUES (?, ?)", cardID, id); err != nil {
+				return fmt.Errorf("failed to assign label to card: %w", err)
+			}
+		}
+	}
+
+	for _, comment := range card.Comments {
+		var authorID *int
+		if comment.Author != "" {
+			if id, ok := userIDs[strings.ToLower(comment.Author)]; ok {
+				authorID = &id
+			} else {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("Comment author %q on %q not found; comment kept without an author", comment.Author, card.Title))
+			}
+		}
+		commentedAt := comment.CreatedAt
+		if commentedAt.IsZero() {
+			commentedAt = createdAt
+		}
+		_, err := tx.Exec(
+			"INSERT INTO comments (card_id, content, author_id, created_at, edited_at) VALUES (?, ?, ?, ?, ?)",
+			cardID, comment.Content, authorID, commentedAt, comment.EditedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+		result.Created.Comments++
+	}
+
+	return nil
+}
+
+// importCardNumber gives an imported card its exported number, so #123
+// references in the export keep naming the same cards, unless it has none or
+// a card imported before it took that number; those get the next free one.
+// used holds the numbers given out so far.
+func importCardNumber(tx *sql.Tx, listID, number int, used map[int]bool) (int, error) {
+	if number < 1 || used[number] {
+		next, err := nextCardNumber(tx, listID)
+		if err != nil {
+			return 0, err
+		}
+		used[next] = true
+		return next, nil
+	}
+
+	// Later cards without a number must not be given this one
+	_, err := tx.Exec(`
+		INSERT INTO board_card_counters (board_id, last_number)
+		SELECT board_id, ? FROM lists WHERE id = ?
+		ON CONFLICT(board_id) DO UPDATE SET last_number = MAX(last_number, excluded.last_number)
+	`, number, listID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign card number: %w", err)
+	}
+	used[number] = true
+	return number, nil
+}
+
+// unprefixCard rewrites the references with a key prefix the imported board
+// could not keep, such as OPS-12, to #12 in a card's description and
+// comments, so they name the imported cards rather than the original ones
+func unprefixCard(card models.ExportedCard, prefix string) models.ExportedCard {
+	card.Description = references.Unprefix(card.Description, prefix)
+	comments := make([]models.ExportedComment, len(card.Comments))
+	for i, comment := range card.Comments {
+		comment.Content = references.Unprefix(comment.Content, prefix)
+		comments[i] = comment
+	}
+	card.Comments = comments
+	return card
+}
+
+// importReferences records the card references in the descriptions and
+// comments of an imported board's cards
+func importReferences(tx *sql.Tx, boardID int) error {
+	type text struct {
+		cardID    int
+		commentID *int
+		content   string
+	}
+	var texts []text
+
+	rows, err := tx.Query(`
+		SELECT c.id, NULL, c.description FROM cards c JOIN lists l ON c.list_id = l.id WHERE l.board_id = ?
+		UNION ALL
+		SELECT cm.card_id, cm.id, cm.content FROM comments cm JOIN cards c ON c.id = cm.card_id JOIN lists l ON c.list_id = l.id WHERE l.board_id = ?
+	`, boardID, boardID)
+	if err != nil {
+		return fmt.Errorf("failed to get card texts: %w", err)
+	}
+	for rows.Next() {
+		var t text
+		var content sql.NullString
+		if err := rows.Scan(&t.cardID, &t.commentID, &content); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan card text: %w", err)
+		}
+		t.content = content.String
+		texts = append(texts, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get card texts: %w", err)
+	}
+
+	for _, t := range texts {
+		if err := setReferences(tx, t.cardID, t.commentID, t.content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userIDsByName maps lowercased usernames to user IDs
+func userIDsByName(tx *sql.Tx) (map[string]int, error) {
+	rows, err := tx.Query("SELECT id, username FROM users")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		ids[strings.ToLower(name)] = id
+	}
+
+	return ids, rows.Err()
+}
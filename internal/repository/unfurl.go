@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// SaveLinkPreview stores the preview of a link, replacing any earlier one
+func (r *CardRepository) SaveLinkPreview(preview *models.LinkPreview) error {
+	_, err := r.db.Exec(`
+		INSERT INTO link_previews (url, title, description, site_name, favicon_url, image_url, failed, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			site_name = excluded.site_name,
+			favicon_url = excluded.favicon_url,
+			image_url = excluded.image_url,
+			failed = excluded.failed,
+			fetched_at = excluded.fetched_at
+	`, preview.URL, preview.Title, preview.Description, preview.SiteName, preview.FaviconURL, preview.ImageURL, preview.Failed, preview.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save link preview: %w", err)
+	}
+	return nil
+}
+
+// GetLinkPreviews retrieves the stored previews of links, by link. Links
+// never fetched are missing.
+func (r *CardRepository) GetLinkPreviews(links []string) (map[string]models.LinkPreview, error) {
+	previews := make(map[string]models.LinkPreview)
+	if len(links) == 0 {
+		return previews, nil
+	}
+
+	args := make([]interface{}, len(links))
+	for i, link := range links {
+		args[i] = link
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(links)), ",")
+	rows, err := r.db.Query(`
+		SELECT url, title, description, site_name, favicon_url, image_url, failed, fetched_at
+		FROM link_previews
+		WHERE url IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link previews: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.LinkPreview
+		if err := rows.Scan(&p.URL, &p.Title, &p.Description, &p.SiteName, &p.FaviconURL, &p.ImageURL, &p.Failed, &p.FetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan link preview: %w", err)
+		}
+		previews[p.URL] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating link previews: %w", err)
+	}
+
+	return previews, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// UserRepository handles database operations for users
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new user repository
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create creates a new user
+func (r *UserRepository) Create(user *models.User) error {
+	query := `
+		INSERT INTO users (username, display_name, avatar_url, locale, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	user.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(query, user.Username, user.DisplayName, user.AvatarURL, nullString(user.Locale), user.CreatedAt).Scan(&user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(id int) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = ?
+	`
+
+	return r.getOne(query, id)
+}
+
+// GetByUsername retrieves a user by username
+func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE username = ?
+	`
+
+	return r.getOne(query, username)
+}
+
+// GetAll retrieves all users
+func (r *UserRepository) GetAll() ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		ORDER BY username ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	if users == nil {
+		users = []models.User{}
+	}
+
+	return users, nil
+}
+
+// Update saves a user's profile fields
+func (r *UserRepository) Update(user *models.User) error {
+	query := `
+		UPDATE users
+		SET display_name = ?, avatar_url = ?, locale = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, user.DisplayName, user.AvatarURL, nullString(user.Locale), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// SetDisabled disables or re-enables a user. Disabled users keep their
+// comments and assignments but can no longer act through the API.
+func (r *UserRepository) SetDisabled(id int, disabled bool) error {
+	query := `
+		UPDATE users
+		SET disabled_at = CASE WHEN ? THEN COALESCE(disabled_at, ?) END
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, disabled, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// getOne runs a single-user query
+func (r *UserRepository) getOne(query string, arg interface{}) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// userColumns is the column list read by scanUser
+const userColumns = `id, username, COALESCE(display_name, ''), COALESCE(avatar_url, ''), COALESCE(locale, ''), disabled_at, created_at`
+
+// scanUser reads a row selected with userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	var disabledAt sql.NullTime
+	err := row.Scan(&user.ID, &user.Username, &user.DisplayName, &user.AvatarURL, &user.Locale, &disabledAt, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	user.Disabled = disabledAt.Valid
+	user.DisabledAt = nullTimePtr(disabledAt)
+
+	return user, nil
+}
@@ -0,0 +1,433 @@
+package repository
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+)
+
+// webhookColumns selects every webhook field but the secret
+const webhookColumns = `id, url, board_id, list_id, events, active, created_at, updated_at`
+
+// WebhookSecretPrefix starts every generated webhook secret
+const WebhookSecretPrefix = "whsec_"
+
+// WebhookRepository handles database operations for webhooks and the outbox
+// they are delivered from
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create registers a webhook, generating its secret unless one is set
+func (r *WebhookRepository) Create(webhook *models.Webhook) error {
+	if webhook.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return err
+		}
+		webhook.Secret = secret
+	}
+
+	query := `
+		INSERT INTO webhooks (url, board_id, list_id, events, active, secret, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	err := r.db.QueryRow(
+		query, webhook.URL, webhook.BoardID, webhook.ListID, strings.Join(webhook.Events, ","),
+		webhook.Active, webhook.Secret, webhook.CreatedAt, webhook.UpdatedAt,
+	).Scan(&webhook.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a webhook by ID
+func (r *WebhookRepository) GetByID(id int) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+
+	webhook, err := scanWebhook(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// GetAll retrieves the webhooks of one board, or every webhook when boardID
+// is nil
+func (r *WebhookRepository) GetAll(boardID *int) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks`
+	args := []interface{}{}
+	if boardID != nil {
+		query += " WHERE board_id = ?"
+		args = append(args, *boardID)
+	}
+	query += " ORDER BY id"
+
+	return r.query(query, false, args...)
+}
+
+// GetActive retrieves the webhooks that receive events, with their secrets
+func (r *WebhookRepository) GetActive() ([]models.Webhook, error) {
+	return r.query(`SELECT `+webhookColumns+`, secret FROM webhooks WHERE active = 1 ORDER BY id`, true)
+}
+
+// query runs a query selecting webhookColumns, followed by the secret when
+// withSecret is set
+func (r *WebhookRepository) query(query string, withSecret bool, args ...interface{}) ([]models.Webhook, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var secret string
+		var extra []interface{}
+		if withSecret {
+			extra = append(extra, &secret)
+		}
+		webhook, err := scanWebhook(rows, extra...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhook.Secret = secret
+		webhooks = append(webhooks, *webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Update saves a webhook's URL, board, list, events and active flag
+func (r *WebhookRepository) Update(webhook *models.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = ?, board_id = ?, list_id = ?, events = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`
+	webhook.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(
+		query, webhook.URL, webhook.BoardID, webhook.ListID, strings.Join(webhook.Events, ","),
+		webhook.Active, webhook.UpdatedAt, webhook.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
+
+// RotateSecret replaces a webhook's secret with a new random one, which the
+// returned webhook carries
+func (r *WebhookRepository) RotateSecret(id int) (*models.Webhook, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.Exec(`UPDATE webhooks SET secret = ?, updated_at = ? WHERE id = ?`, secret, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("webhook not found")
+	}
+
+	webhook, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+	return webhook, nil
+}
+
+// Delete removes a webhook; events not yet delivered to it are dropped
+func (r *WebhookRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
+
+// Enqueue adds a direct delivery of entry's payload to entry's URL to the
+// outbox
+func (r *WebhookRepository) Enqueue(entry *models.OutboxEntry) error {
+	query := `
+		INSERT INTO outbox (event, entity_id, board_id, url, payload, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	entry.CreatedAt = time.Now()
+
+	err := r.db.QueryRow(
+		query, entry.Event, entry.EntityID, entry.BoardID, entry.URL, entry.Payload, entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue event: %w", err)
+	}
+
+	return nil
+}
+
+// Pending retrieves up to limit undelivered outbox entries after afterID,
+// oldest first
+func (r *WebhookRepository) Pending(afterID, limit int) ([]models.OutboxEntry, error) {
+	query := `
+		SELECT id, event, entity_id, board_id, list_id, from_list_id, COALESCE(url, ''), COALESCE(payload, ''), created_at
+		FROM outbox
+		WHERE dispatched_at IS NULL AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending events: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.OutboxEntry{}
+	for rows.Next() {
+		var entry models.OutboxEntry
+		var boardID sql.NullInt64
+		err := rows.Scan(
+			&entry.ID, &entry.Event, &entry.EntityID, &boardID, &entry.ListID, &entry.FromListID,
+			&entry.URL, &entry.Payload, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pending event: %w", err)
+		}
+		if boardID.Valid {
+			id := int(boardID.Int64)
+			entry.BoardID = &id
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Deliveries retrieves the delivery state of an outbox entry for each
+// recipient it has been tried on, keyed by webhook ID or 0 for a direct
+// delivery
+func (r *WebhookRepository) Deliveries(outboxID int) (map[int]models.WebhookDelivery, error) {
+	query := `
+		SELECT outbox_id, webhook_id, state, attempts, next_attempt_at
+		FROM webhook_deliveries
+		WHERE outbox_id = ?
+	`
+
+	rows, err := r.db.Query(query, outboxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make(map[int]models.WebhookDelivery)
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var webhookID sql.NullInt64
+		var nextAttempt sql.NullTime
+		err := rows.Scan(&delivery.OutboxID, &webhookID, &delivery.State, &delivery.Attempts, &nextAttempt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		key := 0
+		if webhookID.Valid {
+			key = int(webhookID.Int64)
+			delivery.WebhookID = &key
+		}
+		delivery.NextAttemptAt = nullTimePtr(nextAttempt)
+		deliveries[key] = delivery
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt logs a delivery attempt and saves the delivery state it led to
+func (r *WebhookRepository) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	attempt.AttemptedAt = time.Now()
+	err = tx.QueryRow(`
+		INSERT INTO webhook_attempts (outbox_id, webhook_id, attempt, status_code, latency_ms, error, payload, attempted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		delivery.OutboxID, delivery.WebhookID, delivery.Attempts, attempt.StatusCode,
+		attempt.LatencyMS, nullString(attempt.Error), string(attempt.Payload), attempt.AttemptedAt,
+	).Scan(&attempt.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record attempt: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO webhook_deliveries (outbox_id, webhook_id, state, attempts, next_attempt_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (outbox_id, IFNULL(webhook_id, 0)) DO UPDATE SET
+			state = excluded.state,
+			attempts = excluded.attempts,
+			next_attempt_at = excluded.next_attempt_at,
+			updated_at = excluded.updated_at
+	`,
+		delivery.OutboxID, delivery.WebhookID, delivery.State, delivery.Attempts,
+		delivery.NextAttemptAt, attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save delivery: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetAttempts retrieves a webhook's most recent delivery attempts, newest
+// first, optionally only those whose delivery is in the given state
+func (r *WebhookRepository) GetAttempts(webhookID int, state string, limit int) ([]models.WebhookAttempt, error) {
+	query := `
+		SELECT a.id, a.outbox_id, o.event, a.webhook_id, a.attempt, a.status_code, a.latency_ms,
+			COALESCE(a.error, ''), a.payload, d.state, d.next_attempt_at, a.attempted_at
+		FROM webhook_attempts a
+		INNER JOIN outbox o ON o.id = a.outbox_id
+		INNER JOIN webhook_deliveries d ON d.outbox_id = a.outbox_id AND d.webhook_id = a.webhook_id
+		WHERE a.webhook_id = ?
+	`
+	args := []interface{}{webhookID}
+	if state != "" {
+		query += " AND d.state = ?"
+		args = append(args, state)
+	}
+	query += " ORDER BY a.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []models.WebhookAttempt{}
+	for rows.Next() {
+		var attempt models.WebhookAttempt
+		var id int
+		var payload string
+		var nextAttempt sql.NullTime
+		err := rows.Scan(
+			&attempt.ID, &attempt.EventID, &attempt.Event, &id, &attempt.Attempt,
+			&attempt.StatusCode, &attempt.LatencyMS, &attempt.Error, &payload,
+			&attempt.State, &nextAttempt, &attempt.AttemptedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attempt: %w", err)
+		}
+		attempt.WebhookID = &id
+		attempt.Payload = json.RawMessage(payload)
+		attempt.NextAttemptAt = nullTimePtr(nextAttempt)
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// MarkDispatched notes that an outbox entry has reached every recipient
+func (r *WebhookRepository) MarkDispatched(outboxID int) error {
+	if _, err := r.db.Exec(`UPDATE outbox SET dispatched_at = ? WHERE id = ?`, time.Now(), outboxID); err != nil {
+		return fmt.Errorf("failed to mark event dispatched: %w", err)
+	}
+	return nil
+}
+
+// PurgeDispatched deletes outbox entries dispatched before the given time,
+// reporting how many were deleted
+func (r *WebhookRepository) PurgeDispatched(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM outbox WHERE dispatched_at IS NOT NULL AND dispatched_at < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dispatched events: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// newWebhookSecret generates a random webhook secret
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return WebhookSecretPrefix + hex.EncodeToString(buf), nil
+}
+
+// scanWebhook scans a row selected with webhookColumns, followed by any
+// extra columns
+func scanWebhook(row rowScanner, extra ...interface{}) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var boardID sql.NullInt64
+	var eventList string
+	err := row.Scan(append([]interface{}{
+		&webhook.ID, &webhook.URL, &boardID, &webhook.ListID, &eventList,
+		&webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
+	}, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if boardID.Valid {
+		id := int(boardID.Int64)
+		webhook.BoardID = &id
+	}
+	webhook.Events = []string{}
+	for _, event := range strings.Split(eventList, ",") {
+		if event != "" {
+			webhook.Events = append(webhook.Events, event)
+		}
+	}
+
+	return &webhook, nil
+}
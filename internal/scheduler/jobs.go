@@ -0,0 +1,387 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/kanban-simple/internal/events"
+	"github.com/kanban-simple/internal/i18n"
+	"github.com/kanban-simple/internal/markdown"
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/netguard"
+	"github.com/kanban-simple/internal/repository"
+)
+
+// AutoMove returns the job that applies boards' overdue and completed list
+// automation, publishing a card.moved event for each card it moves
+func AutoMove(cards *repository.CardRepository, bus *events.Bus) func(now time.Time) error {
+	return func(now time.Time) error {
+		moves, err := cards.RunAutoMoves(now)
+		for _, move := range moves {
+			log.Printf("Moved card %d from list %d to list %d (%s)", move.CardID, move.FromListID, move.ToListID, move.Reason)
+
+			card, cardErr := cards.GetByID(move.CardID)
+			if cardErr != nil {
+				continue
+			}
+			bus.Publish(events.Event{
+				Type:       events.CardMoved,
+				BoardID:    move.BoardID,
+				Card:       card,
+				FromListID: move.FromListID,
+				Reason:     move.Reason,
+				OccurredAt: now,
+			})
+		}
+		return err
+	}
+}
+
+// EscalateOverdue returns the job that applies boards' overdue escalation,
+// publishing a card.updated event for each card it labels or recolors and
+// notifying assignees when the board asks for it
+func EscalateOverdue(cards *repository.CardRepository, notifications *repository.NotificationRepository, users *repository.UserRepository, bus *events.Bus) func(now time.Time) error {
+	return func(now time.Time) error {
+		escalations, err := cards.RunOverdueEscalations(now)
+		for _, escalation := range escalations {
+			card, cardErr := cards.GetByID(escalation.CardID)
+			if cardErr != nil {
+				continue
+			}
+
+			if escalation.Changed {
+				bus.Publish(events.Event{
+					Type:       events.CardUpdated,
+					BoardID:    escalation.BoardID,
+					Card:       card,
+					OccurredAt: now,
+				})
+			}
+
+			if escalation.Notify {
+				notifyOverdue(card, *escalation.AssigneeID, notifications, users)
+			}
+		}
+		return err
+	}
+}
+
+// notifyOverdue tells a card's assignee that it is overdue, in their
+// locale. Disabled users are not notified.
+func notifyOverdue(card *models.Card, userID int, notifications *repository.NotificationRepository, users *repository.UserRepository) {
+	user, err := users.GetByID(userID)
+	if err != nil || user.Disabled {
+		return
+	}
+
+	cardID := card.ID
+	notification := &models.Notification{
+		UserID:  user.ID,
+		Type:    models.NotificationOverdue,
+		CardID:  &cardID,
+		Message: i18n.Sprintf(user.Locale, "Card %q is overdue", card.Title),
+	}
+	if err := notifications.Create(notification); err != nil {
+		log.Printf("Failed to notify %s of overdue card %d: %v", user.Username, card.ID, err)
+	}
+}
+
+// CheckSLAs returns the job that flags cards that have stayed in a list
+// longer than its SLA, notifying the card's assignee and the list's SLA
+// recipients and posting an alert to the list's SLA webhook through the
+// outbox
+func CheckSLAs(cards *repository.CardRepository, lists *repository.ListRepository, notifications *repository.NotificationRepository, users *repository.UserRepository, webhooks *repository.WebhookRepository) func(now time.Time) error {
+	return func(now time.Time) error {
+		breaches, err := cards.RunSLAChecks(now)
+		byID := make(map[int]*models.List)
+		for _, breach := range breaches {
+			card, loadErr := cards.GetByID(breach.CardID)
+			if loadErr != nil {
+				continue
+			}
+			list, ok := byID[breach.ListID]
+			if !ok {
+				if list, loadErr = lists.GetByID(breach.ListID); loadErr != nil {
+					continue
+				}
+				byID[breach.ListID] = list
+			}
+			log.Printf("Card %d has been in list %d longer than its SLA of %s", card.ID, list.ID, list.Settings.SLA)
+
+			notifySLABreach(card, list, notifications, users)
+			if url := list.Settings.SLAWebhookURL; url != "" {
+				enqueueSLAAlert(url, models.SLAAlert{
+					Event:     "card.sla_breached",
+					BoardID:   list.BoardID,
+					ListID:    list.ID,
+					ListName:  list.Name,
+					SLA:       list.Settings.SLA,
+					CardID:    card.ID,
+					CardTitle: card.Title,
+					EnteredAt: breach.EnteredAt,
+					CreatedAt: now,
+				}, webhooks)
+			}
+		}
+		return err
+	}
+}
+
+// notifySLABreach sends an sla_breach notification to the card's assignee
+// and the list's SLA recipients, each once and in their locale. Recipients
+// who cannot see the card or are disabled are skipped.
+func notifySLABreach(card *models.Card, list *models.List, notifications *repository.NotificationRepository, users *repository.UserRepository) {
+	recipients := append([]int{}, list.Settings.SLANotifyUserIDs...)
+	if card.AssigneeID != nil {
+		recipients = append([]int{*card.AssigneeID}, recipients...)
+	}
+
+	notified := make(map[int]bool)
+	for _, userID := range recipients {
+		if notified[userID] || !card.VisibleTo(&userID) {
+			continue
+		}
+		notified[userID] = true
+
+		user, err := users.GetByID(userID)
+		if err != nil || user.Disabled {
+			continue
+		}
+
+		cardID := card.ID
+		notification := &models.Notification{
+			UserID:  user.ID,
+			Type:    models.NotificationSLABreach,
+			CardID:  &cardID,
+			Message: i18n.Sprintf(user.Locale, "Card %q has been in %q longer than its SLA of %s", card.Title, list.Name, list.Settings.SLA),
+		}
+		if err := notifications.Create(notification); err != nil {
+			log.Printf("Failed to notify %s of SLA breach on card %d: %v", user.Username, card.ID, err)
+		}
+	}
+}
+
+// enqueueSLAAlert queues an alert for the webhook dispatcher, logging any
+// failure
+func enqueueSLAAlert(url string, alert models.SLAAlert, webhooks *repository.WebhookRepository) {
+	// A URL saved before internal addresses were refused would only fail
+	// delivery; the dispatcher's client refuses it either way
+	if !netguard.PublicURL(url) {
+		log.Printf("Not sending SLA alert for card %d to internal URL %s", alert.CardID, url)
+		return
+	}
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		log.Printf("Failed to encode SLA alert: %v", err)
+		return
+	}
+
+	boardID := alert.BoardID
+	entry := &models.OutboxEntry{
+		Event:    alert.Event,
+		EntityID: alert.CardID,
+		BoardID:  &boardID,
+		URL:      url,
+		Payload:  string(body),
+	}
+	if err := webhooks.Enqueue(entry); err != nil {
+		log.Printf("Failed to queue SLA alert for card %d: %v", alert.CardID, err)
+	}
+}
+
+// Purge returns the job that deletes archived cards past their board's
+// retention period, or defaultDays for boards without one
+func Purge(retention *repository.RetentionRepository, defaultDays int) func(now time.Time) error {
+	return func(now time.Time) error {
+		report, err := retention.Purge(models.PurgeOptions{Now: now, DefaultDays: defaultDays})
+		if err != nil {
+			return err
+		}
+		if report.Cards > 0 {
+			log.Printf("Purged %d archived cards and %d comments past retention", report.Cards, report.Comments)
+		}
+		return nil
+	}
+}
+
+// PruneChanges returns the job that deletes change log entries older than
+// days
+func PruneChanges(changes *repository.ChangeRepository, days int) func(now time.Time) error {
+	return func(now time.Time) error {
+		pruned, err := changes.Prune(now.AddDate(0, 0, -days))
+		if err != nil {
+			return err
+		}
+		if pruned > 0 {
+			log.Printf("Pruned %d change log entries older than %d days", pruned, days)
+		}
+		return nil
+	}
+}
+
+// PruneSync returns the job that deletes card revision history and applied
+// sync operation IDs older than days
+func PruneSync(sync *repository.SyncRepository, days int) func(now time.Time) error {
+	return func(now time.Time) error {
+		pruned, err := sync.Prune(now.AddDate(0, 0, -days))
+		if err != nil {
+			return err
+		}
+		if pruned > 0 {
+			log.Printf("Pruned %d card revisions and sync operations older than %d days", pruned, days)
+		}
+		return nil
+	}
+}
+
+// Checkpoint returns the job that truncates the write-ahead log once every
+// interval, as SQLite's own checkpoints never shrink the file. It can run
+// no more often than the scheduler ticks.
+func Checkpoint(instance *repository.InstanceRepository, every time.Duration) func(now time.Time) error {
+	var last time.Time
+	return func(now time.Time) error {
+		if now.Sub(last) < every {
+			return nil
+		}
+		result, err := instance.Checkpoint(models.CheckpointTruncate)
+		if err != nil {
+			return err
+		}
+		last = now
+		if result.Busy {
+			log.Printf("WAL checkpoint could not finish while the database was busy; retrying in %s", every)
+		} else if result.WALSizeBefore > result.WALSizeAfter {
+			log.Printf("Checkpointed the WAL, shrinking it from %d to %d bytes", result.WALSizeBefore, result.WALSizeAfter)
+		}
+		return nil
+	}
+}
+
+// Digests returns the job that sends every digest that has come due: a
+// notification to its user and a post to its URL through the webhook
+// outbox. Reports with nothing in them are not sent, but still count as the
+// digest's turn; board status reports are always sent. A digest that fails
+// is logged and retried on the next tick.
+func Digests(digests *repository.DigestRepository, webhooks *repository.WebhookRepository, notifications *repository.NotificationRepository, users *repository.UserRepository) func(now time.Time) error {
+	return func(now time.Time) error {
+		all, err := digests.GetAll(nil, nil)
+		if err != nil {
+			return err
+		}
+
+		for i := range all {
+			digest := &all[i]
+			if now.Before(digest.NextSendAt) {
+				continue
+			}
+
+			if digest.Kind == models.DigestKindReport {
+				report, err := digests.StatusReport(digest, now)
+				if err != nil {
+					log.Printf("Failed to build digest %d: %v", digest.ID, err)
+					continue
+				}
+				if !sendStatusReport(digest, report, webhooks, notifications, users) {
+					continue
+				}
+			} else {
+				report, err := digests.Report(digest, now)
+				if err != nil {
+					log.Printf("Failed to build digest %d: %v", digest.ID, err)
+					continue
+				}
+				if !report.Empty() && !sendDigest(digest, report, webhooks, notifications, users) {
+					continue
+				}
+			}
+			if err := digests.MarkSent(digest, now); err != nil {
+				log.Printf("Failed to mark digest %d sent: %v", digest.ID, err)
+			}
+		}
+		return nil
+	}
+}
+
+// sendDigest sends a digest's report, summarized in its user's locale
+func sendDigest(digest *models.Digest, report *models.DigestReport, webhooks *repository.WebhookRepository, notifications *repository.NotificationRepository, users *repository.UserRepository) bool {
+	return deliverDigest(digest, models.DigestPayload{Report: report}, func(locale string) string {
+		if digest.Cadence == models.DigestWeekly {
+			return i18n.Sprintf(locale, "Weekly digest: %d due this week, %d new, %d completed, %d stale",
+				len(report.DueThisWeek), len(report.Created), len(report.Completed), len(report.Stale))
+		}
+		return i18n.Sprintf(locale, "Daily digest: %d due this week, %d new, %d completed, %d stale",
+			len(report.DueThisWeek), len(report.Created), len(report.Completed), len(report.Stale))
+	}, webhooks, notifications, users)
+}
+
+// sendStatusReport sends a report digest's board status report, with its
+// Markdown rendering for the URL to post as is
+func sendStatusReport(digest *models.Digest, report *models.BoardReport, webhooks *repository.WebhookRepository, notifications *repository.NotificationRepository, users *repository.UserRepository) bool {
+	payload := models.DigestPayload{BoardReport: report, Markdown: markdown.Report(report)}
+	return deliverDigest(digest, payload, func(locale string) string {
+		return i18n.Sprintf(locale, "Status report for %s: %d created, %d completed, %d overdue",
+			report.BoardName, report.CreatedCount, report.CompletedCount, report.OverdueCount)
+	}, webhooks, notifications, users)
+}
+
+// deliverDigest notifies a digest's user and queues the post of payload to
+// its URL, reporting whether both went through. The summary is in the
+// user's locale.
+func deliverDigest(digest *models.Digest, payload models.DigestPayload, summarize func(locale string) string, webhooks *repository.WebhookRepository, notifications *repository.NotificationRepository, users *repository.UserRepository) bool {
+	var user *models.User
+	if digest.UserID != nil {
+		var err error
+		if user, err = users.GetByID(*digest.UserID); err != nil {
+			log.Printf("Failed to load the user of digest %d: %v", digest.ID, err)
+			return false
+		}
+	}
+
+	locale := ""
+	if user != nil {
+		locale = user.Locale
+	}
+	summary := summarize(locale)
+
+	// A URL saved before internal addresses were refused would only fail
+	// delivery; the dispatcher's client refuses it either way
+	if digest.URL != "" && !netguard.PublicURL(digest.URL) {
+		log.Printf("Not posting digest %d to internal URL %s", digest.ID, digest.URL)
+	} else if digest.URL != "" {
+		payload.Event = "digest"
+		payload.Text = summary
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("Failed to encode digest %d: %v", digest.ID, err)
+			return false
+		}
+		entry := &models.OutboxEntry{
+			Event:    "digest",
+			EntityID: digest.ID,
+			BoardID:  digest.BoardID,
+			URL:      digest.URL,
+			Payload:  string(body),
+		}
+		if err := webhooks.Enqueue(entry); err != nil {
+			log.Printf("Failed to queue digest %d: %v", digest.ID, err)
+			return false
+		}
+	}
+
+	// Disabled users keep their subscriptions but are not notified
+	if user != nil && !user.Disabled {
+		notification := &models.Notification{
+			UserID:  user.ID,
+			Type:    models.NotificationDigest,
+			Message: summary,
+		}
+		if err := notifications.Create(notification); err != nil {
+			log.Printf("Failed to notify %s of digest %d: %v", user.Username, digest.ID, err)
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work run on every tick
+type Job struct {
+	Name string
+	Run  func(now time.Time) error
+}
+
+// Scheduler runs its jobs one after another at a fixed interval. A failing
+// job is logged and retried on the next tick; it does not stop the others.
+type Scheduler struct {
+	interval time.Duration
+	jobs     []Job
+	paused   func() bool
+	stop     chan struct{}
+	done     sync.WaitGroup
+}
+
+// New creates a scheduler that ticks every interval
+func New(interval time.Duration) *Scheduler {
+	return &Scheduler{interval: interval, stop: make(chan struct{})}
+}
+
+// Add registers a job; jobs run in the order they were added
+func (s *Scheduler) Add(name string, run func(now time.Time) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Run: run})
+}
+
+// PauseWhile skips ticks for as long as paused reports true
+func (s *Scheduler) PauseWhile(paused func() bool) {
+	s.paused = paused
+}
+
+// Start runs the jobs once immediately and then on every tick until Stop
+func (s *Scheduler) Start() {
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.RunOnce(time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				s.RunOnce(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the scheduler, waiting for a running tick to finish
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.done.Wait()
+}
+
+// RunOnce runs every job for the given time
+func (s *Scheduler) RunOnce(now time.Time) {
+	if s.paused != nil && s.paused() {
+		return
+	}
+	for _, job := range s.jobs {
+		if err := job.Run(now); err != nil {
+			log.Printf("Scheduled job %s failed: %v", job.Name, err)
+		}
+	}
+}
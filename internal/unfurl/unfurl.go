@@ -0,0 +1,269 @@
+// Package unfurl finds links in card descriptions and comments and fetches
+// previews of the pages they point to: their titles, descriptions and icons.
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/netguard"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// MaxLinks is the most links unfurled in one description or comment
+	MaxLinks = 5
+
+	// RefreshAfter is how long a preview is kept before the page is fetched
+	// again
+	RefreshAfter = 7 * 24 * time.Hour
+
+	// RetryAfter is how long a failed fetch is kept before it is tried again
+	RetryAfter = 24 * time.Hour
+
+	// maxPageBytes is how much of a page is read looking for its head
+	maxPageBytes = 512 << 10
+
+	// concurrency is how many pages are fetched at once
+	concurrency = 4
+
+	maxTitle       = 200
+	maxDescription = 500
+)
+
+// ErrNotHTML is returned for links to something other than a web page
+var ErrNotHTML = errors.New("not an HTML page")
+
+// linkPattern matches http and https URLs in text, bare or in Markdown links
+var linkPattern = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+
+// Find returns the unique http and https links in text, in order of
+// appearance, up to MaxLinks. Punctuation ending a sentence after a bare
+// link is not taken as part of it.
+func Find(text string) []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:!?*_~")
+		u, err := url.Parse(link)
+		if err != nil || u.Host == "" || seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+		if len(links) == MaxLinks {
+			break
+		}
+	}
+
+	return links
+}
+
+// Unfurler fetches link previews in the background, handing each to save.
+// Pages are fetched with netguard, so links cannot be used to reach the
+// server's own network.
+type Unfurler struct {
+	client  *http.Client
+	save    func(*models.LinkPreview) error
+	slots   chan struct{}
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+// New creates an unfurler storing the previews it fetches with save
+func New(save func(*models.LinkPreview) error) *Unfurler {
+	return &Unfurler{
+		client:  netguard.NewClient(10*time.Second, 5),
+		save:    save,
+		slots:   make(chan struct{}, concurrency),
+		pending: make(map[string]bool),
+	}
+}
+
+// Queue fetches previews of links in the background. Links already being
+// fetched are skipped. Failures are saved as failed previews and logged.
+func (u *Unfurler) Queue(links ...string) {
+	for _, link := range links {
+		u.mu.Lock()
+		if u.pending[link] {
+			u.mu.Unlock()
+			continue
+		}
+		u.pending[link] = true
+		u.mu.Unlock()
+
+		go u.unfurl(link)
+	}
+}
+
+// unfurl fetches and saves the preview of one link
+func (u *Unfurler) unfurl(link string) {
+	defer func() {
+		u.mu.Lock()
+		delete(u.pending, link)
+		u.mu.Unlock()
+	}()
+
+	u.slots <- struct{}{}
+	preview, err := u.Fetch(context.Background(), link)
+	<-u.slots
+	if err != nil {
+		preview = &models.Unfurl{URL: link, FetchedAt: time.Now()}
+	}
+
+	if err := u.save(&models.LinkPreview{Unfurl: *preview, Failed: err != nil}); err != nil {
+		log.Printf("Failed to save link preview of %s: %v", link, err)
+	}
+}
+
+// Fetch returns the preview of the page at an http or https link. The
+// title and description come from the page's Open Graph tags, falling back
+// to its title and meta description, then to its host name; the icon from
+// its icon link, falling back to /favicon.ico; the image from its Open Graph
+// or Twitter card image.
+func (u *Unfurler) Fetch(ctx context.Context, link string) (*models.Unfurl, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil || (req.URL.Scheme != "http" && req.URL.Scheme != "https") {
+		return nil, fmt.Errorf("invalid link")
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; kanban-simple-unfurl)")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch page: %s", resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxPageBytes), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %w", err)
+	}
+
+	head := parseHead(body)
+	page := resp.Request.URL
+	preview := &models.Unfurl{
+		URL:         link,
+		Title:       clip(first(head["og:title"], head["twitter:title"], head["title"], page.Hostname()), maxTitle),
+		Description: clip(first(head["og:description"], head["twitter:description"], head["description"]), maxDescription),
+		SiteName:    clip(head["og:site_name"], maxTitle),
+		FetchedAt:   time.Now(),
+	}
+
+	preview.FaviconURL = resolve(page, first(head["icon"], "/favicon.ico"))
+	preview.ImageURL = resolve(page, first(head["og:image"], head["twitter:image"]))
+
+	return preview, nil
+}
+
+// parseHead reads the title, the meta tags and the icon link from a page's
+// head, stopping at its body
+func parseHead(r io.Reader) map[string]string {
+	head := make(map[string]string)
+	tokens := html.NewTokenizer(r)
+
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			return head
+		case html.EndTagToken:
+			if name, _ := tokens.TagName(); string(name) == "head" {
+				return head
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokens.Token()
+			switch token.Data {
+			case "body":
+				return head
+			case "title":
+				if tokens.Next() == html.TextToken && head["title"] == "" {
+					head["title"] = string(tokens.Text())
+				}
+			case "meta":
+				key := strings.ToLower(first(attr(token, "property"), attr(token, "name")))
+				if key != "" && head[key] == "" {
+					head[key] = attr(token, "content")
+				}
+			case "link":
+				rel := strings.Fields(strings.ToLower(attr(token, "rel")))
+				for _, r := range rel {
+					if r == "icon" && head["icon"] == "" {
+						head["icon"] = attr(token, "href")
+					}
+				}
+			}
+		}
+	}
+}
+
+// resolve returns the http or https URL of a reference from a page, or ""
+func resolve(page *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := page.Parse(strings.TrimSpace(ref))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// attr returns the value of a tag's attribute, or ""
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// first returns the first of values that is not blank
+func first(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clip collapses the whitespace in s and cuts it to at most max
+// characters, ending it with an ellipsis when it was cut
+func clip(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-1]) + "…"
+}
+
+// Stale reports whether a stored preview is due to be fetched again
+func Stale(preview models.LinkPreview) bool {
+	age := time.Since(preview.FetchedAt)
+	if preview.Failed {
+		return age > RetryAfter
+	}
+	return age > RefreshAfter
+}
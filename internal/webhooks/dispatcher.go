@@ -0,0 +1,347 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kanban-simple/internal/models"
+	"github.com/kanban-simple/internal/netguard"
+	"github.com/kanban-simple/internal/repository"
+)
+
+const (
+	// batchSize is how many outbox entries are loaded at a time
+	batchSize = 100
+
+	// retention is how long dispatched entries are kept before being purged
+	retention = 7 * 24 * time.Hour
+
+	// retryBase and retryCap bound the wait before retrying a failed
+	// delivery, which doubles with every attempt
+	retryBase = 30 * time.Second
+	retryCap  = time.Hour
+)
+
+// Dispatcher delivers outbox entries to webhooks. An entry stays in the
+// outbox until every matching webhook has accepted it or been given up on,
+// so deliveries are at least once: a crash or a failing receiver leads to a
+// retry, never to a lost event. A failed delivery is retried with
+// exponential backoff until it has been attempted maxAttempts times, and is
+// then left in the dead state.
+type Dispatcher struct {
+	webhooks    *repository.WebhookRepository
+	boards      *repository.BoardRepository
+	lists       *repository.ListRepository
+	cards       *repository.CardRepository
+	client      *http.Client
+	interval    time.Duration
+	maxAttempts int
+	purged      time.Time
+	wake        chan struct{}
+	stop        chan struct{}
+	done        sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher that checks the outbox every interval
+// and whenever it is notified, trying each delivery up to maxAttempts times
+func NewDispatcher(webhooks *repository.WebhookRepository, boards *repository.BoardRepository, lists *repository.ListRepository, cards *repository.CardRepository, interval time.Duration, maxAttempts int) *Dispatcher {
+	return &Dispatcher{
+		webhooks:    webhooks,
+		boards:      boards,
+		lists:       lists,
+		cards:       cards,
+		client:      netguard.NewClient(10*time.Second, 3),
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Notify wakes the dispatcher to deliver new entries without waiting for
+// the next tick. It never blocks.
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start delivers pending entries immediately and then on every tick or
+// notification until Stop
+func (d *Dispatcher) Start() {
+	d.done.Add(1)
+	go func() {
+		defer d.done.Done()
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		d.run(time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				d.run(now)
+			case <-d.wake:
+				d.run(time.Now())
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the dispatcher, waiting for a running pass to finish
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	d.done.Wait()
+}
+
+// run makes one pass, logging any failure
+func (d *Dispatcher) run(now time.Time) {
+	if err := d.RunOnce(now); err != nil {
+		log.Printf("Webhook dispatch failed: %v", err)
+	}
+}
+
+// RunOnce attempts every delivery that is due. After a recipient fails, it
+// gets no more requests until the next pass.
+func (d *Dispatcher) RunOnce(now time.Time) error {
+	hooks, err := d.webhooks.GetActive()
+	if err != nil {
+		return err
+	}
+
+	failed := make(map[string]bool)
+	afterID := 0
+	for {
+		entries, err := d.webhooks.Pending(afterID, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := d.dispatch(entry, hooks, failed, now); err != nil {
+				return err
+			}
+			afterID = entry.ID
+		}
+		if len(entries) < batchSize {
+			break
+		}
+	}
+
+	if now.Sub(d.purged) >= time.Hour {
+		d.purged = now
+		if _, err := d.webhooks.PurgeDispatched(now.Add(-retention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recipient is somewhere an outbox entry is delivered to
+type recipient struct {
+	key     string // Identifies the recipient within a pass
+	webhook *models.Webhook
+	url     string
+	secret  string // Empty for direct deliveries, which are not signed
+}
+
+// dispatch attempts the deliveries of one entry that are due, marking the
+// entry dispatched once every recipient has it or has been given up on
+func (d *Dispatcher) dispatch(entry models.OutboxEntry, hooks []models.Webhook, failed map[string]bool, now time.Time) error {
+	var recipients []recipient
+	if entry.URL != "" {
+		recipients = append(recipients, recipient{key: "url:" + entry.URL, url: entry.URL})
+	} else if hidden, err := d.restricted(entry); err != nil {
+		return err
+	} else if !hidden {
+		for i := range hooks {
+			if hooks[i].Wants(entry) {
+				recipients = append(recipients, recipient{key: "webhook:" + strconv.Itoa(hooks[i].ID), webhook: &hooks[i], url: hooks[i].URL, secret: hooks[i].Secret})
+			}
+		}
+	}
+
+	deliveries, err := d.webhooks.Deliveries(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	settled := true
+	for _, to := range recipients {
+		key := 0
+		if to.webhook != nil {
+			key = to.webhook.ID
+		}
+		delivery := deliveries[key]
+		delivery.OutboxID = entry.ID
+		if to.webhook != nil {
+			delivery.WebhookID = &to.webhook.ID
+		}
+
+		if delivery.State == models.DeliveryDelivered || delivery.State == models.DeliveryDead {
+			continue
+		}
+		if failed[to.key] || (delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(now)) {
+			settled = false
+			continue
+		}
+
+		if body == nil {
+			if to.webhook == nil {
+				body = []byte(entry.Payload)
+			} else if body, err = d.payload(entry); err != nil {
+				return err
+			}
+		}
+
+		attempt := models.WebhookAttempt{Payload: body}
+		started := time.Now()
+		attempt.StatusCode, err = d.post(to.url, to.secret, entry.Event, entry.ID, body)
+		attempt.LatencyMS = time.Since(started).Milliseconds()
+		delivery.Attempts++
+		delivery.NextAttemptAt = nil
+
+		switch {
+		case err == nil:
+			delivery.State = models.DeliveryDelivered
+		case delivery.Attempts >= d.maxAttempts:
+			attempt.Error = err.Error()
+			delivery.State = models.DeliveryDead
+			failed[to.key] = true
+			log.Printf("Gave up delivering %s %d to %s after %d attempts: %v", entry.Event, entry.ID, to.url, delivery.Attempts, err)
+		default:
+			attempt.Error = err.Error()
+			delivery.State = models.DeliveryPending
+			next := now.Add(retryDelay(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+			failed[to.key] = true
+			settled = false
+			log.Printf("Failed to deliver %s %d to %s (attempt %d): %v", entry.Event, entry.ID, to.url, delivery.Attempts, err)
+		}
+
+		if err := d.webhooks.RecordAttempt(&delivery, &attempt); err != nil {
+			return err
+		}
+	}
+
+	if !settled {
+		return nil
+	}
+	return d.webhooks.MarkDispatched(entry.ID)
+}
+
+// restricted reports whether an entry is about a restricted card or one of
+// its comments. Webhooks act for no one, so like anonymous users they are
+// not told of these. Cards that are gone can't be checked and are reported.
+func (d *Dispatcher) restricted(entry models.OutboxEntry) (bool, error) {
+	cardID := entry.EntityID
+	switch strings.SplitN(entry.Event, ".", 2)[0] {
+	case "card":
+	case "comment":
+		comment, err := d.cards.GetComment(entry.EntityID)
+		if err != nil {
+			if strings.HasSuffix(err.Error(), "not found") {
+				return false, nil
+			}
+			return false, err
+		}
+		cardID = comment.CardID
+	default:
+		return false, nil
+	}
+
+	card, err := d.cards.GetByID(cardID)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			return false, nil
+		}
+		return false, err
+	}
+	return !card.VisibleTo(nil), nil
+}
+
+// payload encodes the body posted for an entry, with the entity as it is
+// now. An entity deleted since the change is left out.
+func (d *Dispatcher) payload(entry models.OutboxEntry) ([]byte, error) {
+	payload := models.WebhookPayload{
+		ID:         entry.ID,
+		Event:      entry.Event,
+		BoardID:    entry.BoardID,
+		EntityID:   entry.EntityID,
+		OccurredAt: entry.CreatedAt,
+	}
+
+	if !strings.HasSuffix(entry.Event, ".deleted") {
+		var data interface{}
+		var err error
+		switch strings.SplitN(entry.Event, ".", 2)[0] {
+		case "board":
+			data, err = d.boards.GetByID(entry.EntityID)
+		case "list":
+			data, err = d.lists.GetByID(entry.EntityID)
+		case "card":
+			data, err = d.cards.GetByID(entry.EntityID)
+		case "comment":
+			data, err = d.cards.GetComment(entry.EntityID)
+		}
+		if err == nil {
+			payload.Data = data
+		} else if !strings.HasSuffix(err.Error(), "not found") {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(payload)
+}
+
+// post sends a body to a receiver, signed with secret unless it is empty,
+// treating any 2xx response as accepted
+func (d *Dispatcher) post(url, secret, event string, id int, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kanban-simple-webhooks")
+	req.Header.Set("X-Kanban-Event", event)
+	req.Header.Set("X-Kanban-Delivery", strconv.Itoa(id))
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, time.Now(), body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay is how long to wait after a delivery's attempts-th failure:
+// retryBase doubled for each earlier failure, capped at retryCap, of which a
+// random half is taken off so receivers coming back up are not hit by every
+// retry at once
+func retryDelay(attempts int) time.Duration {
+	delay := retryCap
+	if attempts < 32 {
+		if d := retryBase << (attempts - 1); d > 0 && d < retryCap {
+			delay = d
+		}
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
@@ -0,0 +1,6 @@
+-- Board favorites and explicit display ordering
+
+ALTER TABLE boards ADD COLUMN starred INTEGER DEFAULT 0;
+ALTER TABLE boards ADD COLUMN position REAL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_boards_display_order ON boards(starred DESC, position);
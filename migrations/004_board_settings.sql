@@ -0,0 +1,3 @@
+-- Per-board settings stored as a JSON document
+
+ALTER TABLE boards ADD COLUMN settings TEXT DEFAULT '{}';
@@ -0,0 +1,3 @@
+-- Track when comments are edited
+
+ALTER TABLE comments ADD COLUMN edited_at DATETIME;
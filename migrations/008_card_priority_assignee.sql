@@ -0,0 +1,6 @@
+-- Card priority and assignee
+
+ALTER TABLE cards ADD COLUMN priority TEXT;
+ALTER TABLE cards ADD COLUMN assignee_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cards_assignee ON cards(assignee_id);
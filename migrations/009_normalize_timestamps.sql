@@ -0,0 +1,82 @@
+-- Normalize timestamps written in Go's time.String() format
+-- ("2006-01-02 15:04:05.999 -0700 MST m=+0.01") to the SQLite format
+-- ("2006-01-02 15:04:05.999-07:00") so they can be compared with datetime()
+
+-- Drop the updated_at triggers so normalizing does not overwrite updated_at
+DROP TRIGGER IF EXISTS update_boards_timestamp;
+DROP TRIGGER IF EXISTS update_lists_timestamp;
+DROP TRIGGER IF EXISTS update_cards_timestamp;
+
+UPDATE boards SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE boards SET updated_at = substr(updated_at, 1, instr(updated_at, ' +') + instr(updated_at, ' -') - 1)
+    || substr(updated_at, instr(updated_at, ' +') + instr(updated_at, ' -') + 1, 3) || ':' || substr(updated_at, instr(updated_at, ' +') + instr(updated_at, ' -') + 4, 2)
+WHERE updated_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE lists SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE lists SET updated_at = substr(updated_at, 1, instr(updated_at, ' +') + instr(updated_at, ' -') - 1)
+    || substr(updated_at, instr(updated_at, ' +') + instr(updated_at, ' -') + 1, 3) || ':' || substr(updated_at, instr(updated_at, ' +') + instr(updated_at, ' -') + 4, 2)
+WHERE updated_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE cards SET due_date = substr(due_date, 1, instr(due_date, ' +') + instr(due_date, ' -') - 1)
+    || substr(due_date, instr(due_date, ' +') + instr(due_date, ' -') + 1, 3) || ':' || substr(due_date, instr(due_date, ' +') + instr(due_date, ' -') + 4, 2)
+WHERE due_date GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE cards SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE cards SET updated_at = substr(updated_at, 1, instr(updated_at, ' +') + instr(updated_at, ' -') - 1)
+    || substr(updated_at, instr(updated_at, ' +') + instr(updated_at, ' -') + 1, 3) || ':' || substr(updated_at, instr(updated_at, ' +') + instr(updated_at, ' -') + 4, 2)
+WHERE updated_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE comments SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE comments SET edited_at = substr(edited_at, 1, instr(edited_at, ' +') + instr(edited_at, ' -') - 1)
+    || substr(edited_at, instr(edited_at, ' +') + instr(edited_at, ' -') + 1, 3) || ':' || substr(edited_at, instr(edited_at, ' +') + instr(edited_at, ' -') + 4, 2)
+WHERE edited_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE labels SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE users SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE mentions SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE notifications SET read_at = substr(read_at, 1, instr(read_at, ' +') + instr(read_at, ' -') - 1)
+    || substr(read_at, instr(read_at, ' +') + instr(read_at, ' -') + 1, 3) || ':' || substr(read_at, instr(read_at, ' +') + instr(read_at, ' -') + 4, 2)
+WHERE read_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+UPDATE notifications SET created_at = substr(created_at, 1, instr(created_at, ' +') + instr(created_at, ' -') - 1)
+    || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 1, 3) || ':' || substr(created_at, instr(created_at, ' +') + instr(created_at, ' -') + 4, 2)
+WHERE created_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]* [+-][0-9][0-9][0-9][0-9] *';
+
+CREATE TRIGGER IF NOT EXISTS update_boards_timestamp
+AFTER UPDATE ON boards
+BEGIN
+    UPDATE boards SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_lists_timestamp
+AFTER UPDATE ON lists
+BEGIN
+    UPDATE lists SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_cards_timestamp
+AFTER UPDATE ON cards
+BEGIN
+    UPDATE cards SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,34 @@
+-- Per-board sequential card numbers
+
+CREATE TABLE IF NOT EXISTS board_card_counters (
+    board_id INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL,
+    FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+);
+
+ALTER TABLE cards ADD COLUMN number INTEGER;
+
+-- Number existing cards in creation order without touching updated_at
+DROP TRIGGER IF EXISTS update_cards_timestamp;
+
+UPDATE cards SET number = n.num
+FROM (
+    SELECT c.id, ROW_NUMBER() OVER (PARTITION BY l.board_id ORDER BY c.id) AS num
+    FROM cards c
+    JOIN lists l ON l.id = c.list_id
+) n
+WHERE n.id = cards.id;
+
+CREATE TRIGGER IF NOT EXISTS update_cards_timestamp
+AFTER UPDATE ON cards
+BEGIN
+    UPDATE cards SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+INSERT INTO board_card_counters (board_id, last_number)
+SELECT l.board_id, MAX(c.number)
+FROM cards c
+JOIN lists l ON l.id = c.list_id
+GROUP BY l.board_id;
+
+CREATE INDEX IF NOT EXISTS idx_cards_number ON cards(number);
@@ -0,0 +1,19 @@
+-- Stable public card keys (e.g. c_8fk2la0q) for permalinks
+
+ALTER TABLE cards ADD COLUMN key TEXT;
+
+-- Backfill existing cards with distinct keys derived from the id.
+-- Multiplying by an odd constant modulo 2^32 is a bijection, so keys
+-- are unique while not revealing the id order at a glance.
+DROP TRIGGER IF EXISTS update_cards_timestamp;
+
+UPDATE cards SET key = 'c_' || printf('%08x', (id * 2654435761) % 4294967296)
+WHERE key IS NULL;
+
+CREATE TRIGGER IF NOT EXISTS update_cards_timestamp
+AFTER UPDATE ON cards
+BEGIN
+    UPDATE cards SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cards_key ON cards(key);
@@ -0,0 +1,6 @@
+-- Card completion, tracked separately from archiving
+
+ALTER TABLE cards ADD COLUMN completed BOOLEAN DEFAULT 0;
+ALTER TABLE cards ADD COLUMN completed_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_cards_completed ON cards(completed);
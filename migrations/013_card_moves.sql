@@ -0,0 +1,17 @@
+-- Card movement history, used for cycle time and throughput metrics
+
+CREATE TABLE IF NOT EXISTS card_moves (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    card_id INTEGER NOT NULL,
+    from_list_id INTEGER,
+    to_list_id INTEGER NOT NULL,
+    moved_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (card_id) REFERENCES cards(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_moves_card ON card_moves(card_id, moved_at);
+CREATE INDEX IF NOT EXISTS idx_card_moves_to_list ON card_moves(to_list_id);
+
+-- Existing cards have no history; record them as created in their current list
+INSERT INTO card_moves (card_id, from_list_id, to_list_id, moved_at)
+SELECT id, NULL, list_id, created_at FROM cards;
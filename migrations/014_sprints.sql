@@ -0,0 +1,21 @@
+-- Sprints (iterations) per board
+
+CREATE TABLE IF NOT EXISTS sprints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    board_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    goal TEXT,
+    start_date DATETIME,
+    end_date DATETIME,
+    status TEXT NOT NULL DEFAULT 'planned',
+    started_at DATETIME,
+    closed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sprints_board ON sprints(board_id, status);
+
+ALTER TABLE cards ADD COLUMN sprint_id INTEGER REFERENCES sprints(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cards_sprint ON cards(sprint_id);
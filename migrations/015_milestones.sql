@@ -0,0 +1,17 @@
+-- Milestones per board
+
+CREATE TABLE IF NOT EXISTS milestones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    board_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    due_date DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_milestones_board ON milestones(board_id);
+
+ALTER TABLE cards ADD COLUMN milestone_id INTEGER REFERENCES milestones(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cards_milestone ON cards(milestone_id);
@@ -0,0 +1,3 @@
+-- Story point estimates on cards
+
+ALTER TABLE cards ADD COLUMN points INTEGER;
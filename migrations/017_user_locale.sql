@@ -0,0 +1,3 @@
+-- Preferred language for messages addressed to a user
+
+ALTER TABLE users ADD COLUMN locale TEXT;
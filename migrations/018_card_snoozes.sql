@@ -0,0 +1,16 @@
+-- Due date snoozes, so chronically postponed cards can be found
+
+ALTER TABLE cards ADD COLUMN snooze_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS card_snoozes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    card_id INTEGER NOT NULL,
+    from_due_date DATETIME,
+    to_due_date DATETIME NOT NULL,
+    user_id INTEGER,
+    snoozed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (card_id) REFERENCES cards(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_snoozes_card ON card_snoozes(card_id, snoozed_at);
@@ -0,0 +1,5 @@
+-- Start dates, so cards can be planned as date ranges
+
+ALTER TABLE cards ADD COLUMN start_date DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_cards_start_date ON cards(start_date);
@@ -0,0 +1,7 @@
+-- Foreign keys are now enforced on every connection. The constraints have
+-- been declared since they were introduced, but only one pooled connection
+-- enforced them, so older databases can hold rows that violate them.
+-- Those rows are left in place: GET /api/admin/consistency reports them and
+-- POST /api/admin/consistency/repair removes them once they have been
+-- reviewed. SQLite only checks a constraint when a row's own keys change,
+-- so existing violations do not block other writes.
@@ -0,0 +1,17 @@
+-- When each card entered its current list, for days-in-list and stale cards
+
+ALTER TABLE cards ADD COLUMN list_entered_at DATETIME;
+
+-- Backfill from the move history without touching updated_at
+DROP TRIGGER IF EXISTS update_cards_timestamp;
+
+UPDATE cards SET list_entered_at = COALESCE(
+    (SELECT MAX(m.moved_at) FROM card_moves m WHERE m.card_id = cards.id AND m.to_list_id = cards.list_id),
+    created_at
+);
+
+CREATE TRIGGER IF NOT EXISTS update_cards_timestamp
+AFTER UPDATE ON cards
+BEGIN
+    UPDATE cards SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,3 @@
+-- Per-list rules applied to cards created in or moved into the list
+
+ALTER TABLE lists ADD COLUMN settings TEXT;
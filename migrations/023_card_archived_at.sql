@@ -0,0 +1,17 @@
+-- When each card was archived, for retention purges
+
+ALTER TABLE cards ADD COLUMN archived_at DATETIME;
+
+-- Archiving was the last change for most archived cards; backfill from
+-- updated_at without touching it
+DROP TRIGGER IF EXISTS update_cards_timestamp;
+
+UPDATE cards SET archived_at = updated_at WHERE archived = 1;
+
+CREATE TRIGGER IF NOT EXISTS update_cards_timestamp
+AFTER UPDATE ON cards
+BEGIN
+    UPDATE cards SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE INDEX IF NOT EXISTS idx_cards_archived_at ON cards(archived_at) WHERE archived = 1;
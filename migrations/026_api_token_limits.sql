@@ -0,0 +1,5 @@
+-- What each API token may do, and how fast. Existing tokens keep full
+-- non-admin access and no rate limit.
+
+ALTER TABLE api_tokens ADD COLUMN scopes TEXT NOT NULL DEFAULT 'read,write';
+ALTER TABLE api_tokens ADD COLUMN rate_limit INTEGER NOT NULL DEFAULT 0;
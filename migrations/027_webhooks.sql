@@ -0,0 +1,169 @@
+-- Outgoing webhooks and the outbox they are delivered from. Triggers write
+-- an outbox row for every board, list, card and comment change, in the same
+-- transaction as the change itself, so an event cannot be lost between a
+-- commit and its delivery. Later migrations that rewrite many rows should
+-- drop and recreate these triggers, as 009 does for the timestamp ones.
+
+-- board_id is not a foreign key: a board's webhooks outlive it long enough
+-- to receive board.deleted
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    board_id INTEGER,
+    events TEXT NOT NULL DEFAULT '',
+    active INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_board ON webhooks(board_id);
+
+-- Rows with a url and payload are direct deliveries, such as list capacity
+-- alerts; the rest fan out to the matching webhooks
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    board_id INTEGER,
+    url TEXT,
+    payload TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(id) WHERE dispatched_at IS NULL;
+
+-- Deliveries that succeeded, so a retried event skips the webhooks that
+-- already have it
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    outbox_id INTEGER NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    status INTEGER NOT NULL,
+    delivered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (outbox_id, webhook_id)
+);
+
+-- Boards
+
+CREATE TRIGGER IF NOT EXISTS outbox_board_created
+AFTER INSERT ON boards
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id) VALUES ('board.created', NEW.id, NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_board_updated
+AFTER UPDATE OF name, description, settings ON boards
+WHEN OLD.name IS NOT NEW.name OR OLD.description IS NOT NEW.description OR OLD.settings IS NOT NEW.settings
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id) VALUES ('board.updated', NEW.id, NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_board_deleted
+AFTER DELETE ON boards
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id) VALUES ('board.deleted', OLD.id, OLD.id);
+END;
+
+-- Lists; deletes cascading from their board are covered by board.deleted
+
+CREATE TRIGGER IF NOT EXISTS outbox_list_created
+AFTER INSERT ON lists
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id) VALUES ('list.created', NEW.id, NEW.board_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_list_updated
+AFTER UPDATE OF name, position, color, settings ON lists
+WHEN OLD.name IS NOT NEW.name OR OLD.position IS NOT NEW.position OR OLD.color IS NOT NEW.color OR OLD.settings IS NOT NEW.settings
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id) VALUES ('list.updated', NEW.id, NEW.board_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_list_deleted
+AFTER DELETE ON lists
+WHEN EXISTS (SELECT 1 FROM boards WHERE id = OLD.board_id)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id) VALUES ('list.deleted', OLD.id, OLD.board_id);
+END;
+
+-- Cards; deletes cascading from their list are covered by list.deleted
+
+CREATE TRIGGER IF NOT EXISTS outbox_card_created
+AFTER INSERT ON cards
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES ('card.created', NEW.id, (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_card_updated
+AFTER UPDATE OF title, description, color, start_date, due_date, priority, assignee_id, sprint_id, milestone_id, points ON cards
+WHEN OLD.title IS NOT NEW.title OR OLD.description IS NOT NEW.description OR OLD.color IS NOT NEW.color
+    OR OLD.start_date IS NOT NEW.start_date OR OLD.due_date IS NOT NEW.due_date OR OLD.priority IS NOT NEW.priority
+    OR OLD.assignee_id IS NOT NEW.assignee_id OR OLD.sprint_id IS NOT NEW.sprint_id
+    OR OLD.milestone_id IS NOT NEW.milestone_id OR OLD.points IS NOT NEW.points
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES ('card.updated', NEW.id, (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_card_moved
+AFTER UPDATE OF list_id ON cards
+WHEN OLD.list_id IS NOT NEW.list_id
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES ('card.moved', NEW.id, (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_card_archived
+AFTER UPDATE OF archived ON cards
+WHEN OLD.archived IS NOT NEW.archived
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES (CASE WHEN NEW.archived THEN 'card.archived' ELSE 'card.unarchived' END, NEW.id,
+            (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_card_completed
+AFTER UPDATE OF completed ON cards
+WHEN COALESCE(OLD.completed, 0) IS NOT COALESCE(NEW.completed, 0)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES (CASE WHEN NEW.completed THEN 'card.completed' ELSE 'card.uncompleted' END, NEW.id,
+            (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_card_deleted
+AFTER DELETE ON cards
+WHEN EXISTS (SELECT 1 FROM lists WHERE id = OLD.list_id)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES ('card.deleted', OLD.id, (SELECT board_id FROM lists WHERE id = OLD.list_id));
+END;
+
+-- Comments; deletes cascading from their card are covered by card.deleted
+
+CREATE TRIGGER IF NOT EXISTS outbox_comment_created
+AFTER INSERT ON comments
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES ('comment.created', NEW.id,
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = NEW.card_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_comment_updated
+AFTER UPDATE OF content ON comments
+WHEN OLD.content IS NOT NEW.content
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES ('comment.updated', NEW.id,
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = NEW.card_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS outbox_comment_deleted
+AFTER DELETE ON comments
+WHEN EXISTS (SELECT 1 FROM cards WHERE id = OLD.card_id)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id)
+    VALUES ('comment.deleted', OLD.id,
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = OLD.card_id));
+END;
@@ -0,0 +1,35 @@
+-- Retries for webhook deliveries. webhook_deliveries now holds the state of
+-- each event for each recipient, including failures waiting for a retry and
+-- those given up on, and webhook_attempts logs every request made so
+-- receivers can be debugged.
+
+CREATE TABLE webhook_deliveries_new (
+    outbox_id INTEGER NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    webhook_id INTEGER REFERENCES webhooks(id) ON DELETE CASCADE, -- NULL for direct deliveries
+    state TEXT NOT NULL DEFAULT 'pending', -- pending, delivered or dead
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO webhook_deliveries_new (outbox_id, webhook_id, state, attempts, updated_at)
+SELECT outbox_id, webhook_id, 'delivered', 1, delivered_at FROM webhook_deliveries;
+
+DROP TABLE webhook_deliveries;
+ALTER TABLE webhook_deliveries_new RENAME TO webhook_deliveries;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_recipient ON webhook_deliveries(outbox_id, IFNULL(webhook_id, 0));
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    outbox_id INTEGER NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    webhook_id INTEGER REFERENCES webhooks(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0, -- 0 when no response arrived
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    payload TEXT NOT NULL,
+    attempted_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_webhook ON webhook_attempts(webhook_id, id);
@@ -0,0 +1,5 @@
+-- Per-webhook secrets for signing deliveries. Existing webhooks get a random
+-- secret, which can be read by rotating it.
+ALTER TABLE webhooks ADD COLUMN secret TEXT NOT NULL DEFAULT '';
+
+UPDATE webhooks SET secret = 'whsec_' || lower(hex(randomblob(24))) WHERE secret = '';
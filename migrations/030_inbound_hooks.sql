@@ -0,0 +1,15 @@
+-- Inbound hooks turn payloads posted to /api/hooks/<token> into cards on a
+-- list. Like API tokens, only a hash of the token is stored.
+CREATE TABLE IF NOT EXISTS inbound_hooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    title_template TEXT NOT NULL,
+    description_template TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbound_hooks_list ON inbound_hooks(list_id);
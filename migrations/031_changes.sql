@@ -0,0 +1,163 @@
+-- A change log with a global sequence, read through GET /api/changes by
+-- mirrors and caches that sync incrementally. Triggers record every
+-- create, update and delete of a board, list, card, comment or label in the
+-- same transaction as the change. Updates are watched column by column,
+-- leaving out updated_at so the timestamp triggers do not log a second
+-- update; migrations that add a column should add it to the trigger, and
+-- those that rewrite many rows should drop and recreate these triggers.
+
+CREATE TABLE IF NOT EXISTS changes (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    operation TEXT NOT NULL,
+    board_id INTEGER,
+    changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_changes_board ON changes(board_id, seq);
+CREATE INDEX IF NOT EXISTS idx_changes_changed_at ON changes(changed_at);
+
+-- Boards
+
+CREATE TRIGGER IF NOT EXISTS changes_board_created
+AFTER INSERT ON boards
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id) VALUES ('board', NEW.id, 'created', NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_board_updated
+AFTER UPDATE OF name, description, starred, position, settings ON boards
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id) VALUES ('board', NEW.id, 'updated', NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_board_deleted
+AFTER DELETE ON boards
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id) VALUES ('board', OLD.id, 'deleted', OLD.id);
+END;
+
+-- Lists; deletes cascading from their board are implied by its deletion
+
+CREATE TRIGGER IF NOT EXISTS changes_list_created
+AFTER INSERT ON lists
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id) VALUES ('list', NEW.id, 'created', NEW.board_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_list_updated
+AFTER UPDATE OF board_id, name, position, color, settings ON lists
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id) VALUES ('list', NEW.id, 'updated', NEW.board_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_list_deleted
+AFTER DELETE ON lists
+WHEN EXISTS (SELECT 1 FROM boards WHERE id = OLD.board_id)
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id) VALUES ('list', OLD.id, 'deleted', OLD.board_id);
+END;
+
+-- Cards; deletes cascading from their list are implied by its deletion
+
+CREATE TRIGGER IF NOT EXISTS changes_card_created
+AFTER INSERT ON cards
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('card', NEW.id, 'created', (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_card_updated
+AFTER UPDATE OF list_id, title, description, position, color, due_date, archived, priority, assignee_id,
+    number, key, completed, completed_at, sprint_id, milestone_id, points, snooze_count, start_date,
+    list_entered_at, archived_at ON cards
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('card', NEW.id, 'updated', (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+-- A card moved to another board is also reported on the board it left, so
+-- mirrors of that board learn it is gone
+CREATE TRIGGER IF NOT EXISTS changes_card_left_board
+AFTER UPDATE OF list_id ON cards
+WHEN (SELECT board_id FROM lists WHERE id = OLD.list_id) IS NOT (SELECT board_id FROM lists WHERE id = NEW.list_id)
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('card', NEW.id, 'updated', (SELECT board_id FROM lists WHERE id = OLD.list_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_card_deleted
+AFTER DELETE ON cards
+WHEN EXISTS (SELECT 1 FROM lists WHERE id = OLD.list_id)
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('card', OLD.id, 'deleted', (SELECT board_id FROM lists WHERE id = OLD.list_id));
+END;
+
+-- Assigning or removing a label updates the card; removals cascading from
+-- the card or the label are implied by their deletion
+
+CREATE TRIGGER IF NOT EXISTS changes_card_label_added
+AFTER INSERT ON card_labels
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('card', NEW.card_id, 'updated',
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = NEW.card_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_card_label_removed
+AFTER DELETE ON card_labels
+WHEN EXISTS (SELECT 1 FROM cards WHERE id = OLD.card_id) AND EXISTS (SELECT 1 FROM labels WHERE id = OLD.label_id)
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('card', OLD.card_id, 'updated',
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = OLD.card_id));
+END;
+
+-- Comments; deletes cascading from their card are implied by its deletion
+
+CREATE TRIGGER IF NOT EXISTS changes_comment_created
+AFTER INSERT ON comments
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('comment', NEW.id, 'created',
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = NEW.card_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_comment_updated
+AFTER UPDATE OF card_id, content, author_id, edited_at ON comments
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('comment', NEW.id, 'updated',
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = NEW.card_id));
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_comment_deleted
+AFTER DELETE ON comments
+WHEN EXISTS (SELECT 1 FROM cards WHERE id = OLD.card_id)
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('comment', OLD.id, 'deleted',
+            (SELECT l.board_id FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = OLD.card_id));
+END;
+
+-- Labels belong to no board
+
+CREATE TRIGGER IF NOT EXISTS changes_label_created
+AFTER INSERT ON labels
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation) VALUES ('label', NEW.id, 'created');
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_label_updated
+AFTER UPDATE OF name, color ON labels
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation) VALUES ('label', NEW.id, 'updated');
+END;
+
+CREATE TRIGGER IF NOT EXISTS changes_label_deleted
+AFTER DELETE ON labels
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation) VALUES ('label', OLD.id, 'deleted');
+END;
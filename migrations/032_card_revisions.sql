@@ -0,0 +1,62 @@
+-- Card revisions for the offline sync protocol (POST /api/sync). Every card
+-- starts at revision 1 and a trigger raises it whenever a synced field
+-- changes, recording which fields changed so edits made offline against an
+-- older revision can be merged with those made since. Applied sync
+-- operations are remembered by their client-chosen IDs so a client can
+-- resend its queue after a dropped connection without applying twice.
+
+ALTER TABLE cards ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS card_revisions (
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    fields TEXT NOT NULL,
+    changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (card_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_revisions_changed_at ON card_revisions(changed_at);
+
+-- Writes that leave every field as it was, such as saving an unchanged
+-- card, do not make a revision
+CREATE TRIGGER IF NOT EXISTS card_revision
+AFTER UPDATE OF list_id, title, description, position, color, start_date, due_date, archived, completed,
+    priority, assignee_id, sprint_id, milestone_id, points ON cards
+WHEN OLD.list_id IS NOT NEW.list_id OR OLD.title IS NOT NEW.title OR OLD.description IS NOT NEW.description
+    OR OLD.position IS NOT NEW.position OR OLD.color IS NOT NEW.color OR OLD.start_date IS NOT NEW.start_date
+    OR OLD.due_date IS NOT NEW.due_date OR OLD.archived IS NOT NEW.archived OR OLD.completed IS NOT NEW.completed
+    OR OLD.priority IS NOT NEW.priority OR OLD.assignee_id IS NOT NEW.assignee_id OR OLD.sprint_id IS NOT NEW.sprint_id
+    OR OLD.milestone_id IS NOT NEW.milestone_id OR OLD.points IS NOT NEW.points
+BEGIN
+    UPDATE cards SET revision = OLD.revision + 1 WHERE id = NEW.id;
+    INSERT INTO card_revisions (card_id, revision, fields)
+    VALUES (NEW.id, OLD.revision + 1, rtrim(
+        CASE WHEN OLD.list_id IS NOT NEW.list_id THEN 'list_id,' ELSE '' END ||
+        CASE WHEN OLD.title IS NOT NEW.title THEN 'title,' ELSE '' END ||
+        CASE WHEN OLD.description IS NOT NEW.description THEN 'description,' ELSE '' END ||
+        CASE WHEN OLD.position IS NOT NEW.position THEN 'position,' ELSE '' END ||
+        CASE WHEN OLD.color IS NOT NEW.color THEN 'color,' ELSE '' END ||
+        CASE WHEN OLD.start_date IS NOT NEW.start_date THEN 'start_date,' ELSE '' END ||
+        CASE WHEN OLD.due_date IS NOT NEW.due_date THEN 'due_date,' ELSE '' END ||
+        CASE WHEN OLD.archived IS NOT NEW.archived THEN 'archived,' ELSE '' END ||
+        CASE WHEN OLD.completed IS NOT NEW.completed THEN 'completed,' ELSE '' END ||
+        CASE WHEN OLD.priority IS NOT NEW.priority THEN 'priority,' ELSE '' END ||
+        CASE WHEN OLD.assignee_id IS NOT NEW.assignee_id THEN 'assignee_id,' ELSE '' END ||
+        CASE WHEN OLD.sprint_id IS NOT NEW.sprint_id THEN 'sprint_id,' ELSE '' END ||
+        CASE WHEN OLD.milestone_id IS NOT NEW.milestone_id THEN 'milestone_id,' ELSE '' END ||
+        CASE WHEN OLD.points IS NOT NEW.points THEN 'points,' ELSE '' END, ','));
+END;
+
+-- Sync operations that were applied, by client-chosen ID. base_revision and
+-- revision are the card's revision before and after.
+CREATE TABLE IF NOT EXISTS sync_ops (
+    op_id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    status TEXT NOT NULL,
+    card_id INTEGER NOT NULL,
+    base_revision INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_ops_created_at ON sync_ops(created_at);
@@ -0,0 +1,30 @@
+-- Board members and pending invitations, managed through
+-- /api/boards/:id/members and /api/boards/:id/invitations. Invitations are
+-- addressed to an email and accepted by whoever presents their token before
+-- it expires; tokens are stored as SHA-256 hashes and only shown when issued.
+
+CREATE TABLE IF NOT EXISTS board_members (
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'editor',
+    added_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (board_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_members_user ON board_members(user_id);
+
+CREATE TABLE IF NOT EXISTS board_invitations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'editor',
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at DATETIME NOT NULL,
+    accepted_at DATETIME,
+    accepted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_invitations_board ON board_invitations(board_id);
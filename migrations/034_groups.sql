@@ -0,0 +1,31 @@
+-- Groups of users that boards can be shared with as a whole. A grant gives
+-- every member of a group a role on a board; a user's access to a board is
+-- the strongest of their direct membership and their groups' grants.
+
+CREATE TABLE IF NOT EXISTS groups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    description TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS group_members (
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_members_user ON group_members(user_id);
+
+CREATE TABLE IF NOT EXISTS board_group_grants (
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'editor',
+    granted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    granted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (board_id, group_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_group_grants_group ON board_group_grants(group_id);
@@ -0,0 +1,9 @@
+-- Card visibility. Board-visible cards are seen by everyone who can see the
+-- board; restricted ones only by their assignee and the user who created
+-- them, for boards that mix everyday tasks with sensitive items. Cards made
+-- before this have no recorded creator.
+
+ALTER TABLE cards ADD COLUMN visibility TEXT NOT NULL DEFAULT 'board';
+ALTER TABLE cards ADD COLUMN created_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cards_visibility ON cards(visibility) WHERE visibility != 'board';
@@ -0,0 +1,129 @@
+-- Webhooks scoped to a list. The outbox now records the list each list,
+-- card and comment event happened in, and for card.moved the list the card
+-- left, so the dispatcher can match list-scoped webhooks without reading
+-- the entity, which may have moved on or been deleted by delivery time.
+
+-- list_id is not a foreign key, like board_id: a list's webhooks outlive it
+-- long enough to receive list.deleted
+ALTER TABLE webhooks ADD COLUMN list_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_list ON webhooks(list_id);
+
+ALTER TABLE outbox ADD COLUMN list_id INTEGER;
+ALTER TABLE outbox ADD COLUMN from_list_id INTEGER; -- The list a moved card left
+
+-- Lists
+
+DROP TRIGGER IF EXISTS outbox_list_created;
+CREATE TRIGGER outbox_list_created
+AFTER INSERT ON lists
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id) VALUES ('list.created', NEW.id, NEW.board_id, NEW.id);
+END;
+
+DROP TRIGGER IF EXISTS outbox_list_updated;
+CREATE TRIGGER outbox_list_updated
+AFTER UPDATE OF name, position, color, settings ON lists
+WHEN OLD.name IS NOT NEW.name OR OLD.position IS NOT NEW.position OR OLD.color IS NOT NEW.color OR OLD.settings IS NOT NEW.settings
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id) VALUES ('list.updated', NEW.id, NEW.board_id, NEW.id);
+END;
+
+DROP TRIGGER IF EXISTS outbox_list_deleted;
+CREATE TRIGGER outbox_list_deleted
+AFTER DELETE ON lists
+WHEN EXISTS (SELECT 1 FROM boards WHERE id = OLD.board_id)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id) VALUES ('list.deleted', OLD.id, OLD.board_id, OLD.id);
+END;
+
+-- Cards
+
+DROP TRIGGER IF EXISTS outbox_card_created;
+CREATE TRIGGER outbox_card_created
+AFTER INSERT ON cards
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    VALUES ('card.created', NEW.id, (SELECT board_id FROM lists WHERE id = NEW.list_id), NEW.list_id);
+END;
+
+DROP TRIGGER IF EXISTS outbox_card_updated;
+CREATE TRIGGER outbox_card_updated
+AFTER UPDATE OF title, description, color, start_date, due_date, priority, assignee_id, sprint_id, milestone_id, points ON cards
+WHEN OLD.title IS NOT NEW.title OR OLD.description IS NOT NEW.description OR OLD.color IS NOT NEW.color
+    OR OLD.start_date IS NOT NEW.start_date OR OLD.due_date IS NOT NEW.due_date OR OLD.priority IS NOT NEW.priority
+    OR OLD.assignee_id IS NOT NEW.assignee_id OR OLD.sprint_id IS NOT NEW.sprint_id
+    OR OLD.milestone_id IS NOT NEW.milestone_id OR OLD.points IS NOT NEW.points
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    VALUES ('card.updated', NEW.id, (SELECT board_id FROM lists WHERE id = NEW.list_id), NEW.list_id);
+END;
+
+DROP TRIGGER IF EXISTS outbox_card_moved;
+CREATE TRIGGER outbox_card_moved
+AFTER UPDATE OF list_id ON cards
+WHEN OLD.list_id IS NOT NEW.list_id
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id, from_list_id)
+    VALUES ('card.moved', NEW.id, (SELECT board_id FROM lists WHERE id = NEW.list_id), NEW.list_id, OLD.list_id);
+END;
+
+DROP TRIGGER IF EXISTS outbox_card_archived;
+CREATE TRIGGER outbox_card_archived
+AFTER UPDATE OF archived ON cards
+WHEN OLD.archived IS NOT NEW.archived
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    VALUES (CASE WHEN NEW.archived THEN 'card.archived' ELSE 'card.unarchived' END, NEW.id,
+            (SELECT board_id FROM lists WHERE id = NEW.list_id), NEW.list_id);
+END;
+
+DROP TRIGGER IF EXISTS outbox_card_completed;
+CREATE TRIGGER outbox_card_completed
+AFTER UPDATE OF completed ON cards
+WHEN COALESCE(OLD.completed, 0) IS NOT COALESCE(NEW.completed, 0)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    VALUES (CASE WHEN NEW.completed THEN 'card.completed' ELSE 'card.uncompleted' END, NEW.id,
+            (SELECT board_id FROM lists WHERE id = NEW.list_id), NEW.list_id);
+END;
+
+DROP TRIGGER IF EXISTS outbox_card_deleted;
+CREATE TRIGGER outbox_card_deleted
+AFTER DELETE ON cards
+WHEN EXISTS (SELECT 1 FROM lists WHERE id = OLD.list_id)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    VALUES ('card.deleted', OLD.id, (SELECT board_id FROM lists WHERE id = OLD.list_id), OLD.list_id);
+END;
+
+-- Comments
+
+DROP TRIGGER IF EXISTS outbox_comment_created;
+CREATE TRIGGER outbox_comment_created
+AFTER INSERT ON comments
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    SELECT 'comment.created', NEW.id, l.board_id, l.id
+    FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = NEW.card_id;
+END;
+
+DROP TRIGGER IF EXISTS outbox_comment_updated;
+CREATE TRIGGER outbox_comment_updated
+AFTER UPDATE OF content ON comments
+WHEN OLD.content IS NOT NEW.content
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    SELECT 'comment.updated', NEW.id, l.board_id, l.id
+    FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = NEW.card_id;
+END;
+
+DROP TRIGGER IF EXISTS outbox_comment_deleted;
+CREATE TRIGGER outbox_comment_deleted
+AFTER DELETE ON comments
+WHEN EXISTS (SELECT 1 FROM cards WHERE id = OLD.card_id)
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    SELECT 'comment.deleted', OLD.id, l.board_id, l.id
+    FROM cards c JOIN lists l ON l.id = c.list_id WHERE c.id = OLD.card_id;
+END;
@@ -0,0 +1,20 @@
+-- Daily or weekly digests of cards due this week, newly created, recently
+-- completed and stale, sent to a user as a notification, posted to a URL
+-- such as a chat webhook, or both.
+
+CREATE TABLE IF NOT EXISTS digests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE, -- Notified, and whose restricted cards are included
+    board_id INTEGER REFERENCES boards(id) ON DELETE CASCADE, -- NULL for every board
+    url TEXT,
+    cadence TEXT NOT NULL CHECK (cadence IN ('daily', 'weekly')),
+    hour INTEGER NOT NULL DEFAULT 8, -- UTC
+    weekday INTEGER NOT NULL DEFAULT 1, -- Weekly digests only; 0 is Sunday
+    stale_days INTEGER NOT NULL DEFAULT 14,
+    last_sent_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_digests_user ON digests(user_id);
+CREATE INDEX IF NOT EXISTS idx_digests_board ON digests(board_id);
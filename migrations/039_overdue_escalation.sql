@@ -0,0 +1,6 @@
+-- Overdue escalation. When a card's due date passes, the scheduler applies
+-- its board's overdue label, color and assignee notification once, and
+-- records when so a card is escalated again only after missing a later due
+-- date. The column is not tracked by the change log or card revisions.
+
+ALTER TABLE cards ADD COLUMN overdue_escalated_at DATETIME;
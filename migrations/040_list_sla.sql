@@ -0,0 +1,6 @@
+-- Per-list SLAs. The scheduler records when it found a card had stayed in
+-- its list longer than the list's SLA; the card counts as breached while
+-- that is after it entered the list, so moving it on clears the flag. The
+-- column is not tracked by the change log or card revisions.
+
+ALTER TABLE cards ADD COLUMN sla_breached_at DATETIME;
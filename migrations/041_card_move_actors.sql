@@ -0,0 +1,6 @@
+-- Who moved a card, and why the scheduler did. Moves recorded before this,
+-- by anonymous callers and by the MCP server have no user; automatic moves
+-- have a reason instead.
+
+ALTER TABLE card_moves ADD COLUMN user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE card_moves ADD COLUMN reason TEXT;
@@ -0,0 +1,43 @@
+-- Blocked cards. A card can be flagged blocked with a reason, optionally
+-- naming the cards that block it; completing the last open one of those
+-- unblocks it. Blocking is reported by the change log and card.updated
+-- webhooks but is not a synced field with its own revisions.
+
+ALTER TABLE cards ADD COLUMN blocked INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE cards ADD COLUMN blocked_reason TEXT;
+ALTER TABLE cards ADD COLUMN blocked_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_cards_blocked ON cards(blocked) WHERE blocked = 1;
+
+-- blocker_id blocks card_id
+CREATE TABLE IF NOT EXISTS card_blockers (
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    blocker_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    PRIMARY KEY (card_id, blocker_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_blockers_blocker ON card_blockers(blocker_id);
+
+DROP TRIGGER IF EXISTS changes_card_updated;
+CREATE TRIGGER changes_card_updated
+AFTER UPDATE OF list_id, title, description, position, color, due_date, archived, priority, assignee_id,
+    number, key, completed, completed_at, sprint_id, milestone_id, points, snooze_count, start_date,
+    list_entered_at, archived_at, blocked, blocked_reason ON cards
+BEGIN
+    INSERT INTO changes (entity, entity_id, operation, board_id)
+    VALUES ('card', NEW.id, 'updated', (SELECT board_id FROM lists WHERE id = NEW.list_id));
+END;
+
+DROP TRIGGER IF EXISTS outbox_card_updated;
+CREATE TRIGGER outbox_card_updated
+AFTER UPDATE OF title, description, color, start_date, due_date, priority, assignee_id, sprint_id, milestone_id, points,
+    blocked, blocked_reason ON cards
+WHEN OLD.title IS NOT NEW.title OR OLD.description IS NOT NEW.description OR OLD.color IS NOT NEW.color
+    OR OLD.start_date IS NOT NEW.start_date OR OLD.due_date IS NOT NEW.due_date OR OLD.priority IS NOT NEW.priority
+    OR OLD.assignee_id IS NOT NEW.assignee_id OR OLD.sprint_id IS NOT NEW.sprint_id
+    OR OLD.milestone_id IS NOT NEW.milestone_id OR OLD.points IS NOT NEW.points
+    OR OLD.blocked IS NOT NEW.blocked OR OLD.blocked_reason IS NOT NEW.blocked_reason
+BEGIN
+    INSERT INTO outbox (event, entity_id, board_id, list_id)
+    VALUES ('card.updated', NEW.id, (SELECT board_id FROM lists WHERE id = NEW.list_id), NEW.list_id);
+END;
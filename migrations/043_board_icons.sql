@@ -0,0 +1,3 @@
+-- Board icons: an emoji or the name of an icon from the UI's icon set,
+-- shown next to the board's name in the board picker
+ALTER TABLE boards ADD COLUMN icon TEXT;
@@ -0,0 +1,8 @@
+-- Board key prefixes. A board with a prefix such as OPS gives its cards
+-- references like OPS-214, built from the prefix and the card's number.
+-- Prefixes are stored uppercase and unique across boards, so a reference
+-- names one card.
+
+ALTER TABLE boards ADD COLUMN key_prefix TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_boards_key_prefix ON boards(key_prefix) WHERE key_prefix IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Card references. #123 and OPS-12 in a card's description or comments are
+-- resolved to the cards they name when written and kept, so they still lead
+-- to the same card after it moves to another board or is renumbered.
+
+CREATE TABLE IF NOT EXISTS card_references (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE, -- The card whose text holds the reference
+    comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE, -- Set when the text is a comment
+    target_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE, -- The card referred to
+    text TEXT NOT NULL, -- As written, e.g. #123
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_references_card ON card_references(card_id, comment_id);
+CREATE INDEX IF NOT EXISTS idx_card_references_target ON card_references(target_id);
@@ -0,0 +1,6 @@
+-- Indexes for user activity feeds, which gather the moves, comments and
+-- snoozes made by one user
+
+CREATE INDEX IF NOT EXISTS idx_card_moves_user ON card_moves(user_id, moved_at);
+CREATE INDEX IF NOT EXISTS idx_comments_author ON comments(author_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_card_snoozes_user ON card_snoozes(user_id, snoozed_at);
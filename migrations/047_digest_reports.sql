@@ -0,0 +1,5 @@
+-- Report digests. A digest of kind report sends a status report of its
+-- board over the period since the last one, in JSON and Markdown, rather
+-- than lists of cards to look at.
+
+ALTER TABLE digests ADD COLUMN kind TEXT NOT NULL DEFAULT 'cards' CHECK (kind IN ('cards', 'report'));
@@ -0,0 +1,12 @@
+-- Theme set through the admin API, over the one configured with
+-- INSTANCE_NAME and THEME_* settings. A single row; NULL columns keep the
+-- configured value.
+
+CREATE TABLE IF NOT EXISTS instance_theme (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    instance_name TEXT,
+    primary_color TEXT,
+    accent_color TEXT,
+    logo_url TEXT,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
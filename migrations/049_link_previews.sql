@@ -0,0 +1,13 @@
+-- Previews of web pages linked from card descriptions and comments, shared
+-- by every card linking to the same URL. Failed fetches are kept so they
+-- are not retried on every read.
+
+CREATE TABLE IF NOT EXISTS link_previews (
+    url TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    favicon_url TEXT NOT NULL DEFAULT '',
+    failed INTEGER NOT NULL DEFAULT 0,
+    fetched_at DATETIME NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Preview images (og:image) of linked pages, embedded in cards created from
+-- a URL and offered alongside link previews.
+
+ALTER TABLE link_previews ADD COLUMN image_url TEXT NOT NULL DEFAULT '';
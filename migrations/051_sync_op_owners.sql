@@ -0,0 +1,26 @@
+-- Sync operation IDs are chosen by clients, so they are only unique per
+-- acting user: sync_ops is keyed by the user (0 for anonymous requests) and
+-- the op_id, and remembers a hash of each operation so an op_id reused for a
+-- different operation is rejected rather than replayed. Operations applied
+-- before this migration stay with anonymous requests, without a hash.
+
+CREATE TABLE sync_ops_new (
+    user_id INTEGER NOT NULL DEFAULT 0,
+    op_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    status TEXT NOT NULL,
+    card_id INTEGER NOT NULL,
+    base_revision INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    payload_hash TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, op_id)
+);
+
+INSERT INTO sync_ops_new (user_id, op_id, type, status, card_id, base_revision, revision, created_at)
+SELECT 0, op_id, type, status, card_id, base_revision, revision, created_at FROM sync_ops;
+
+DROP TABLE sync_ops;
+ALTER TABLE sync_ops_new RENAME TO sync_ops;
+
+CREATE INDEX IF NOT EXISTS idx_sync_ops_created_at ON sync_ops(created_at);
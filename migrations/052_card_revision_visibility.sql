@@ -0,0 +1,36 @@
+-- Card revisions also move on when a card's visibility or blocked state
+-- changes, so ETags and If-Match checks see those changes. Only the trigger
+-- changes; earlier revisions stay as they were.
+
+DROP TRIGGER IF EXISTS card_revision;
+CREATE TRIGGER card_revision
+AFTER UPDATE OF list_id, title, description, position, color, start_date, due_date, archived, completed,
+    priority, assignee_id, sprint_id, milestone_id, points, visibility, blocked, blocked_reason ON cards
+WHEN OLD.list_id IS NOT NEW.list_id OR OLD.title IS NOT NEW.title OR OLD.description IS NOT NEW.description
+    OR OLD.position IS NOT NEW.position OR OLD.color IS NOT NEW.color OR OLD.start_date IS NOT NEW.start_date
+    OR OLD.due_date IS NOT NEW.due_date OR OLD.archived IS NOT NEW.archived OR OLD.completed IS NOT NEW.completed
+    OR OLD.priority IS NOT NEW.priority OR OLD.assignee_id IS NOT NEW.assignee_id OR OLD.sprint_id IS NOT NEW.sprint_id
+    OR OLD.milestone_id IS NOT NEW.milestone_id OR OLD.points IS NOT NEW.points
+    OR OLD.visibility IS NOT NEW.visibility OR OLD.blocked IS NOT NEW.blocked OR OLD.blocked_reason IS NOT NEW.blocked_reason
+BEGIN
+    UPDATE cards SET revision = OLD.revision + 1 WHERE id = NEW.id;
+    INSERT INTO card_revisions (card_id, revision, fields)
+    VALUES (NEW.id, OLD.revision + 1, rtrim(
+        CASE WHEN OLD.list_id IS NOT NEW.list_id THEN 'list_id,' ELSE '' END ||
+        CASE WHEN OLD.title IS NOT NEW.title THEN 'title,' ELSE '' END ||
+        CASE WHEN OLD.description IS NOT NEW.description THEN 'description,' ELSE '' END ||
+        CASE WHEN OLD.position IS NOT NEW.position THEN 'position,' ELSE '' END ||
+        CASE WHEN OLD.color IS NOT NEW.color THEN 'color,' ELSE '' END ||
+        CASE WHEN OLD.start_date IS NOT NEW.start_date THEN 'start_date,' ELSE '' END ||
+        CASE WHEN OLD.due_date IS NOT NEW.due_date THEN 'due_date,' ELSE '' END ||
+        CASE WHEN OLD.archived IS NOT NEW.archived THEN 'archived,' ELSE '' END ||
+        CASE WHEN OLD.completed IS NOT NEW.completed THEN 'completed,' ELSE '' END ||
+        CASE WHEN OLD.priority IS NOT NEW.priority THEN 'priority,' ELSE '' END ||
+        CASE WHEN OLD.assignee_id IS NOT NEW.assignee_id THEN 'assignee_id,' ELSE '' END ||
+        CASE WHEN OLD.sprint_id IS NOT NEW.sprint_id THEN 'sprint_id,' ELSE '' END ||
+        CASE WHEN OLD.milestone_id IS NOT NEW.milestone_id THEN 'milestone_id,' ELSE '' END ||
+        CASE WHEN OLD.points IS NOT NEW.points THEN 'points,' ELSE '' END ||
+        CASE WHEN OLD.visibility IS NOT NEW.visibility THEN 'visibility,' ELSE '' END ||
+        CASE WHEN OLD.blocked IS NOT NEW.blocked THEN 'blocked,' ELSE '' END ||
+        CASE WHEN OLD.blocked_reason IS NOT NEW.blocked_reason THEN 'blocked_reason,' ELSE '' END, ','));
+END;
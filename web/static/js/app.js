@@ -6,15 +6,23 @@ class KanbanApp {
         this.lists = [];
         this.sortables = [];
         this.currentCard = null;
-        this.apiBase = '/api';
+        // Under a base path such as /kanban, the server fills in the base-path meta tag
+        this.basePath = document.querySelector('meta[name="base-path"]').content;
+        this.apiBase = this.basePath + '/api';
 
         this.init();
     }
 
     async init() {
         this.setupEventListeners();
-        this.currentBoard = 1; // Use board ID 1
+        this.loadTheme();
+        // Card permalinks (/c/:key) redirect here with ?board=&card=
+        const params = new URLSearchParams(window.location.search);
+        this.currentBoard = parseInt(params.get('board')) || 1; // Default to board ID 1
         await this.loadBoardData();
+        if (params.get('card')) {
+            await this.editCard(params.get('card'));
+        }
     }
 
     setupEventListeners() {
@@ -341,12 +349,20 @@ class KanbanApp {
     }
 
     async deleteList(listId) {
-        if (!confirm('Are you sure you want to delete this list and all its cards?')) {
+        let preview;
+        try {
+            preview = await this.apiCall(`/lists/${listId}/delete-preview`);
+        } catch (error) {
+            return;
+        }
+
+        const impact = `${preview.cards} card(s) and ${preview.comments} comment(s)`;
+        if (!confirm(`Are you sure you want to delete this list? This also deletes ${impact}.`)) {
             return;
         }
 
         try {
-            await this.apiCall(`/lists/${listId}`, 'DELETE');
+            await this.apiCall(`/lists/${listId}?confirm=${preview.confirm_token}`, 'DELETE');
             this.lists = this.lists.filter(l => l.id !== listId);
             await this.renderBoard();
             this.showAlert('List deleted successfully', 'success');
@@ -657,7 +673,9 @@ class KanbanApp {
     // Comments
     async loadComments(cardId) {
         try {
-            const comments = await this.apiCall(`/cards/${cardId}/comments`);
+            // Comments come back rendered and sanitized by the server; the
+            // raw content is never inserted as HTML
+            const comments = await this.apiCall(`/cards/${cardId}/comments?render=html&expand=unfurls`);
             const container = document.getElementById('commentsList');
             container.innerHTML = '';
 
@@ -665,9 +683,10 @@ class KanbanApp {
                 const commentDiv = document.createElement('div');
                 commentDiv.className = 'comment';
                 commentDiv.innerHTML = `
-                    <div class="comment-content">${this.escapeHtml(comment.content)}</div>
+                    <div class="comment-content">${comment.content_html || this.escapeHtml(comment.content)}</div>
                     <div class="comment-date">${new Date(comment.created_at).toLocaleString()}</div>
                 `;
+                commentDiv.insertBefore(this.renderUnfurls(comment.unfurls), commentDiv.lastElementChild);
                 container.appendChild(commentDiv);
             });
         } catch (error) {
@@ -806,12 +825,73 @@ class KanbanApp {
     }
 
     // Utility Methods
+    // Link previews, built as elements so titles and URLs are never parsed
+    // as HTML
+    renderUnfurls(unfurls) {
+        const fragment = document.createDocumentFragment();
+        (unfurls || []).forEach(unfurl => {
+            const link = document.createElement('a');
+            link.className = 'unfurl';
+            link.href = unfurl.url;
+            link.target = '_blank';
+            link.rel = 'nofollow noreferrer';
+
+            if (unfurl.favicon_url) {
+                const icon = document.createElement('img');
+                icon.className = 'unfurl-icon';
+                icon.src = unfurl.favicon_url;
+                icon.alt = '';
+                icon.onerror = () => icon.remove();
+                link.appendChild(icon);
+            }
+
+            const text = document.createElement('div');
+            const title = document.createElement('div');
+            title.className = 'unfurl-title';
+            title.textContent = unfurl.site_name ? `${unfurl.site_name}: ${unfurl.title}` : unfurl.title;
+            text.appendChild(title);
+            if (unfurl.description) {
+                const description = document.createElement('div');
+                description.className = 'unfurl-description';
+                description.textContent = unfurl.description;
+                text.appendChild(description);
+            }
+            link.appendChild(text);
+            fragment.appendChild(link);
+        });
+        return fragment;
+    }
+
     escapeHtml(text) {
         const div = document.createElement('div');
         div.textContent = text || '';
         return div.innerHTML;
     }
 
+    // Branding set by the instance; the built-in look stays if it cannot be loaded
+    async loadTheme() {
+        try {
+            const response = await fetch(this.apiBase + '/theme');
+            if (!response.ok) {
+                return;
+            }
+            const theme = await response.json();
+            const root = document.documentElement.style;
+            root.setProperty('--theme-primary', theme.primary_color);
+            root.setProperty('--theme-accent', theme.accent_color);
+            document.title = theme.instance_name;
+            document.getElementById('brandName').textContent = theme.instance_name;
+            if (theme.logo_url) {
+                const logo = document.getElementById('brandLogo');
+                logo.src = theme.logo_url;
+                logo.classList.remove('d-none');
+                document.getElementById('brandIcon').classList.add('d-none');
+            }
+        } catch (error) {
+            console.error('Theme Error:', error);
+        }
+    }
+
     showAlert(message, type = 'info') {
         const alertDiv = document.createElement('div');
         alertDiv.className = `alert alert-${type} alert-dismissible fade show position-fixed top-0 start-50 translate-middle-x mt-3`;